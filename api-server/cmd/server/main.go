@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"net/http"
+	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
 	"time"
 
 	"contrib.go.opencensus.io/exporter/ocagent"
-	prometheusmiddleware "github.com/albertogviana/prometheus-middleware"
 	"github.com/go-chi/chi"
 	"github.com/piontec/go-chi-middleware-server/pkg/server"
 	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
@@ -20,6 +23,15 @@ import (
 	"github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo"
 )
 
+const (
+	// apiVersionPrefix is the path prefix of the current version of the API; new versions
+	// get their own r.Route block next to it
+	apiVersionPrefix = "/v1"
+	// todoPath is the path of todo resources within an API version
+	todoPath = "/todo"
+)
+
+// build info reported by GET /version, stamped by the build with -ldflags "-X main.version=..."
 var (
 	version = "v0.1.0-dev-build"
 	commit  = "none"
@@ -50,23 +62,100 @@ func initTracing(config *todo.Config) {
 	}()
 }
 
+// redirectToCurrentVersion permanently redirects requests of unversioned paths to the current
+// API version, keeping their method and body
+func redirectToCurrentVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Deprecation", "true")
+	http.Redirect(w, r, apiVersionPrefix+r.URL.RequestURI(), http.StatusPermanentRedirect)
+}
+
 func main() {
+	log.SetFormatter(&log.JSONFormatter{})
 	config := todo.NewConfig()
 	if config.EnableTracing {
 		initTracing(config)
 	}
 
-	promMiddleware := prometheusmiddleware.NewPrometheusMiddleware(prometheusmiddleware.Opts{})
+	handlerTimeout := config.HandlerTimeout
+	if handlerTimeout == 0 {
+		// a zero RouterOptions.HandlerTimeout means the default one
+		handlerTimeout = -1
+	}
+	circuitBreakerThreshold := config.CircuitBreakerThreshold
+	if circuitBreakerThreshold == 0 {
+		// a zero RouterOptions.CircuitBreakerThreshold means the default one
+		circuitBreakerThreshold = -1
+	}
+	todoRouter, err := todo.NewRouter(config.TodoURL, &todo.RouterOptions{
+		TLSCAFile:                config.TodoTLSCAFile,
+		Insecure:                 config.TodoTLSCAFile == "",
+		CORSAllowedOrigins:       config.CORSAllowedOrigins,
+		RateLimit:                config.RateLimit,
+		RateLimitBurst:           config.RateLimitBurst,
+		WebhookURLs:              config.WebhookURLs,
+		WebhookSecret:            config.WebhookSecret,
+		HandlerTimeout:           handlerTimeout,
+		MaxRecvMsgSize:           config.GrpcMaxMessageSize,
+		MaxSendMsgSize:           config.GrpcMaxMessageSize,
+		CircuitBreakerThreshold:  circuitBreakerThreshold,
+		CircuitBreakerCooldown:   config.CircuitBreakerCooldown,
+		TodoCacheTTL:             config.TodoCacheTTL,
+		LogPayloads:              config.LogPayloads,
+		PayloadLogMaxBytes:       config.PayloadLogMaxBytes,
+		PayloadLogRedactedFields: config.PayloadLogRedactedFields,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create todo router: %v", err)
+	}
+	todoRouter.MaxTextLength = config.MaxTextLength
+	todoRouter.DefaultPageSize = config.DefaultPageSize
+	todoRouter.MaxPageSize = config.MaxPageSize
+	todoRouter.DedupeWindow = config.DedupeWindow
+	todoRouter.SetReadOnly(config.ReadOnly)
+
+	var authMiddleware func(http.Handler) http.Handler
+	if config.JWTPublicKeyFile != "" {
+		keyfunc, err := todo.NewRSAKeyfuncFromFile(config.JWTPublicKeyFile)
+		if err != nil {
+			log.Fatalf("Failed to load JWT public key: %v", err)
+		}
+		authMiddleware = todo.AuthMiddleware(keyfunc)
+	}
+	if config.BasicAuthFile != "" {
+		verify, err := todo.NewBasicAuthVerifierFromFile(config.BasicAuthFile)
+		if err != nil {
+			log.Fatalf("Failed to load basic auth credentials: %v", err)
+		}
+		authMiddleware = todo.BasicAuthMiddleware("todo", verify)
+	}
+	if config.APIKeysFile != "" {
+		verify, err := todo.NewAPIKeyVerifierFromFile(config.APIKeysFile)
+		if err != nil {
+			log.Fatalf("Failed to load API keys: %v", err)
+		}
+		authMiddleware = todo.APIKeyMiddleware(verify)
+	}
+
+	todoRoutes := todoRouter.GetRouter()
+	openAPIHandler, err := todo.NewOpenAPIHandler(version, apiVersionPrefix+todoPath, todoRoutes)
+	if err != nil {
+		log.Fatalf("Failed to build the OpenAPI spec: %v", err)
+	}
+	graphQLHandler, err := todoRouter.NewGraphQLHandler()
+	if err != nil {
+		log.Fatalf("Failed to build the GraphQL schema: %v", err)
+	}
 
 	server := server.NewChiServer(func(r *chi.Mux) {
-		r.Use(promMiddleware.InstrumentHandlerDuration)
+		r.Use(todo.MetricsMiddleware)
 		r.Use(func(handler http.Handler) http.Handler {
 			return &ochttp.Handler{
 				Handler:          handler,
 				IsPublicEndpoint: false,
 				Propagation:      &b3.HTTPFormat{},
 				IsHealthEndpoint: func(r *http.Request) bool {
-					if r.URL.Path == "/ping" {
+					switch r.URL.Path {
+					case "/ping", "/healthz", "/readyz", "/version":
 						return true
 					}
 					return false
@@ -76,11 +165,38 @@ func main() {
 		if config.EnableFailures {
 			r.Use(todo.FailureMiddleware)
 		}
-		r.Route("/v1", func(r chi.Router) {
-			r.Mount("/todo",
-				todo.NewRouter(config.TodoURL).GetRouter())
+		r.Get("/healthz", todoRouter.Healthz)
+		r.Get("/readyz", todoRouter.Readyz)
+		r.Get("/version", todo.NewVersionHandler(version, commit, date))
+		r.Get("/openapi.json", openAPIHandler)
+		r.Route(apiVersionPrefix, func(r chi.Router) {
+			if authMiddleware != nil {
+				r.Use(authMiddleware)
+			}
+			r.Use(todo.TracingMiddleware)
+			r.Use(todo.NewGzipMiddleware(todo.DefaultGzipMinSize))
+			r.Mount(todoPath, todoRoutes)
+			r.Handle("/graphql", graphQLHandler)
 		})
+		r.Route("/admin", func(r chi.Router) {
+			if config.AdminToken != "" {
+				r.Group(func(r chi.Router) {
+					r.Use(todo.AdminTokenMiddleware(config.AdminToken))
+					r.Get("/read-only", todoRouter.GetReadOnly)
+					r.Put("/read-only", todoRouter.PutReadOnly)
+				})
+			}
+			// admins are told apart by the roles of their JWT, the other auth methods have none
+			if authMiddleware != nil {
+				r.With(authMiddleware).Mount("/todos", todoRouter.GetAdminRouter())
+			}
+		})
+		r.HandleFunc(todoPath, redirectToCurrentVersion)
+		r.HandleFunc(todoPath+"/*", redirectToCurrentVersion)
 		r.Mount("/metrics", promhttp.Handler())
+		if config.EnableDebugRoutes {
+			r.Get("/_routes", todo.NewRoutesHandler(r))
+		}
 	}, &server.ChiServerOptions{
 		HTTPPort:              8080,
 		DisableOIDCMiddleware: true,
@@ -101,5 +217,42 @@ func main() {
 		server.GetLogger().Warn("Failures Middleware is enabled")
 	}
 	server.GetLogger().Infof("Tracing instrumentation is %v", config.EnableTracing)
+	if config.EnableDebugRoutes {
+		server.GetLogger().Warn("Debug routes are enabled")
+	}
+	if config.LogPayloads {
+		server.GetLogger().Warn("Request and response payloads are logged")
+	}
+	server.GetLogger().Infof("JWT authentication is %v", config.JWTPublicKeyFile != "")
+	server.GetLogger().Infof("Basic authentication is %v", config.BasicAuthFile != "")
+	server.GetLogger().Infof("API key authentication is %v", config.APIKeysFile != "")
+	server.GetLogger().Infof("TLS to todo-manager is %v", config.TodoTLSCAFile != "")
+	server.GetLogger().Infof("Webhooks are sent to %d URLs", len(config.WebhookURLs))
+	server.GetLogger().Infof("Admin endpoints are %v", config.AdminToken != "")
+	if config.HandlerTimeout > 0 {
+		server.GetLogger().Infof("Handlers not streaming their response time out after %v", config.HandlerTimeout)
+	}
+	if config.GrpcMaxMessageSize > 0 {
+		server.GetLogger().Infof("Max gRPC message size is %d bytes", config.GrpcMaxMessageSize)
+	}
+	if config.DedupeWindow > 0 {
+		server.GetLogger().Infof("Todos with the same text are deduplicated within %v", config.DedupeWindow)
+	}
+	go func() {
+		// Run stops the server on interrupt only, pods are stopped with SIGTERM
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM)
+		<-signals
+		server.GetLogger().Infof("Draining in-flight requests for up to %v", config.ShutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		defer cancel()
+		if err := todoRouter.Shutdown(ctx); err != nil {
+			server.GetLogger().Errorf("Error shutting down todo router: %v", err)
+		}
+		server.Stop()
+	}()
 	server.Run()
+	if err := todoRouter.Close(); err != nil && err != todo.ErrRouterClosed {
+		server.GetLogger().Errorf("Error closing todo router: %v", err)
+	}
 }
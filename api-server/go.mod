@@ -4,17 +4,23 @@ go 1.13
 
 require (
 	contrib.go.opencensus.io/exporter/ocagent v0.7.0
-	github.com/albertogviana/prometheus-middleware v0.0.1
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/giantswarm/giantswarm-todo-app/todo-manager v0.0.0-20201112102441-ba1c9188359a // indirect
 	github.com/go-chi/chi v4.0.2+incompatible
+	github.com/go-chi/cors v1.1.1
 	github.com/go-chi/render v1.0.1
 	github.com/golang/protobuf v1.4.2
+	github.com/graph-gophers/graphql-go v1.3.0
+	github.com/grpc-ecosystem/go-grpc-middleware v1.2.0
 	github.com/jinzhu/gorm v1.9.16 // indirect
 	github.com/piontec/go-chi-middleware-server v0.1.2
 	github.com/prometheus/client_golang v1.7.1
 	github.com/sirupsen/logrus v1.4.2
 	go.opencensus.io v0.22.3
+	golang.org/x/net v0.0.0-20200602114024-627f9648deb9
 	golang.org/x/sys v0.0.0-20200625212154-ddb9806d33ae // indirect
+	golang.org/x/text v0.3.2
+	google.golang.org/genproto v0.0.0-20200604104852-0b0486081ffb
 	google.golang.org/grpc v1.29.1
 	google.golang.org/protobuf v1.25.0 // indirect
 )
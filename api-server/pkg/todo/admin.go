@@ -0,0 +1,81 @@
+package todo
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// errAdminRoleRequired is returned for requests to the admin endpoints of clients without AdminRole
+var errAdminRoleRequired = errors.New("the admin role is required")
+
+// errOwnerRequired is returned for admin requests without the owner whose todos they act on
+var errOwnerRequired = errors.New("owner query param is required")
+
+// GetAdminRouter returns the sub-router of the admin endpoints acting on the todos of any owner,
+// meant to be mounted at /admin/todos behind one of the auth middlewares. Only clients with
+// AdminRole can use it, the others are rejected with 403 Forbidden; every request is logged for
+// audit.
+func (t *Router) GetAdminRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Use(t.trackInFlight)
+	r.Use(RequestIDMiddleware)
+	r.Use(NegotiateContentType)
+	r.Use(t.requestTimeout)
+	r.Use(NewRecoveryMiddleware(t.options.Logger))
+	r.Use(t.requireAdmin)
+
+	r.Get("/", t.AdminListTodos) // GET /?owner=alice
+
+	handleMethods(r) // OPTIONS and 405 of all the routes above
+	r.NotFound(notFound)
+
+	return r
+}
+
+// requireAdmin allows only requests of clients with AdminRole and logs all of them for audit,
+// with the authenticated client and the owner whose todos are accessed
+func (t *Router) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin, _ := OwnerFromContext(r.Context())
+		allowed := HasRole(r.Context(), AdminRole)
+		entry := t.options.Logger.WithFields(logrus.Fields{
+			"req_id":  chimiddleware.GetReqID(r.Context()),
+			"method":  r.Method,
+			"uri":     r.RequestURI,
+			"admin":   admin,
+			"owner":   r.URL.Query().Get("owner"),
+			"allowed": allowed,
+		})
+		if !allowed {
+			entry.Warn("Admin access denied")
+			render.Render(w, r, errForbidden(errAdminRoleRequired))
+			return
+		}
+		entry.Info("Admin access")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminListTodos lists the todos of the owner in the owner query param, instead of the one of the
+// request, with the same query params as ListTodos
+func (t *Router) AdminListTodos(w http.ResponseWriter, r *http.Request) {
+	owner := strings.TrimSpace(r.URL.Query().Get("owner"))
+	if owner == "" {
+		render.Render(w, r, middleware.ErrInvalidRequest(errOwnerRequired))
+		return
+	}
+	middleware.LogEntrySetField(r, "owner", owner)
+	req, err := t.newListTodosReq(w, r, owner)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	t.listTodos(w, r, req)
+}
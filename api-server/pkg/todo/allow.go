@@ -0,0 +1,66 @@
+package todo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+// allowableMethods are the methods listed in the Allow header of OPTIONS responses, in order
+var allowableMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// handleMethods registers OPTIONS handlers for all the routes of r, responding 204 No Content with
+// an Allow header listing the methods of the route, and a MethodNotAllowed handler rendering
+// 405 Method Not Allowed with the same header. It has to be called once all the routes of r are
+// registered, and sub-routers need their own call. CORS preflight requests are answered before
+// by the CORS middleware.
+func handleMethods(r chi.Router) {
+	allowed := map[string]string{}
+	for _, route := range r.Routes() {
+		if route.SubRoutes != nil {
+			continue
+		}
+		var methods []string
+		for _, method := range allowableMethods {
+			if _, found := route.Handlers[method]; found {
+				methods = append(methods, method)
+			}
+		}
+		allow := strings.Join(append(methods, http.MethodOptions), ", ")
+		allowed[route.Pattern] = allow
+		r.Options(route.Pattern, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+
+	r.MethodNotAllowed(func(w http.ResponseWriter, req *http.Request) {
+		// the pattern isn't recorded for requests with a wrong method, but every route has
+		// an OPTIONS handler now
+		path := chi.RouteContext(req.Context()).RoutePath
+		if path == "" {
+			path = req.URL.Path
+		}
+		rctx := chi.NewRouteContext()
+		if r.Match(rctx, http.MethodOptions, path) {
+			w.Header().Set("Allow", allowed[rctx.RoutePattern()])
+		}
+		render.Render(w, req, errMethodNotAllowed(fmt.Errorf("Method %s is not allowed for %s", req.Method, req.URL.Path)))
+	})
+}
+
+// notFound renders the JSON error of requests not matching any route
+func notFound(w http.ResponseWriter, r *http.Request) {
+	render.Render(w, r, middleware.ErrNotFound)
+}
@@ -0,0 +1,102 @@
+package todo
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+// APIKeyHeader is the header API keys are sent in
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyVerifier returns the owner an API key belongs to; false is returned for unknown keys
+type APIKeyVerifier func(key string) (string, bool)
+
+// apiKeyEntry is an API key known to a verifier, stored as its hash
+type apiKeyEntry struct {
+	hash  [sha256.Size]byte
+	owner string
+}
+
+// NewAPIKeyVerifier returns an APIKeyVerifier accepting the keys of owners, mapping API keys to
+// the owners they belong to. The keys are copied, so the verifier can be used concurrently, and
+// every key is compared with all of them in constant time, so that the response time doesn't
+// tell how much of a key is right.
+func NewAPIKeyVerifier(owners map[string]string) APIKeyVerifier {
+	entries := make([]apiKeyEntry, 0, len(owners))
+	for key, owner := range owners {
+		entries = append(entries, apiKeyEntry{hash: sha256.Sum256([]byte(key)), owner: owner})
+	}
+	return func(key string) (string, bool) {
+		hash := sha256.Sum256([]byte(key))
+		owner, found := "", false
+		for _, entry := range entries {
+			if subtle.ConstantTimeCompare(hash[:], entry.hash[:]) == 1 {
+				owner, found = entry.owner, true
+			}
+		}
+		return owner, found
+	}
+}
+
+// NewAPIKeyVerifierFromFile loads API keys from a file with an owner:key pair on every line;
+// empty lines and lines starting with # are skipped
+func NewAPIKeyVerifierFromFile(path string) (APIKeyVerifier, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read API keys: %v", err)
+	}
+	defer f.Close()
+	owners := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.SplitN(text, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("API keys line %d is not an owner:key pair", line)
+		}
+		if _, found := owners[parts[1]]; found {
+			return nil, fmt.Errorf("API keys line %d repeats a key", line)
+		}
+		owners[parts[1]] = parts[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read API keys: %v", err)
+	}
+	if len(owners) == 0 {
+		return nil, errors.New("API keys file has no keys")
+	}
+	return NewAPIKeyVerifier(owners), nil
+}
+
+// APIKeyMiddleware looks up the API key from the X-API-Key header with verify and stores the
+// owner it belongs to in the request context; it can be used instead of AuthMiddleware.
+// Requests without a known key are rejected with 401 Unauthorized.
+func APIKeyMiddleware(verify APIKeyVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				render.Render(w, r, middleware.ErrAuth(errors.New(APIKeyHeader+" header is missing")))
+				return
+			}
+			owner, ok := verify(key)
+			if !ok || owner == "" {
+				render.Render(w, r, middleware.ErrAuth(errors.New("unknown API key")))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ContextWithOwner(r.Context(), owner)))
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package todo
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// ArchiveTodo archives a todo with specified user and todo ID, hiding it from the default list
+func (t *Router) ArchiveTodo(w http.ResponseWriter, r *http.Request) {
+	t.setArchived(w, r, true)
+}
+
+// UnarchiveTodo moves an archived todo with specified user and todo ID back to the default list
+func (t *Router) UnarchiveTodo(w http.ResponseWriter, r *http.Request) {
+	t.setArchived(w, r, false)
+}
+
+// setArchived patches the archived state of a todo with specified user and todo ID
+func (t *Router) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	todoID := chi.URLParam(r, "todoID")
+	id, err := parseTodoID(todoID)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		render.Render(w, r, errPreconditionFailed(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	var trailer metadata.MD
+	grpcTodo, err := t.grpcClient.PatchTodo(ctx, &todomgrpb.TodoPatch{
+		Id:              id,
+		Owner:           owner,
+		Archived:        &wrappers.BoolValue{Value: archived},
+		ExpectedVersion: version,
+	}, grpc.Trailer(&trailer))
+	if err != nil {
+		setVersionETag(w, trailer)
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	w.Header().Set("ETag", versionETag(todo.version))
+	if err := render.Render(w, r, todo); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	t.patchOneCounter.WithLabelValues(owner).Inc()
+}
@@ -0,0 +1,131 @@
+package todo
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+// ownerCtxKey is the context key under which the authenticated owner is stored
+type ownerCtxKey struct{}
+
+// ContextWithOwner returns a copy of ctx carrying the authenticated owner
+func ContextWithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerCtxKey{}, owner)
+}
+
+// OwnerFromContext returns the owner stored in ctx by one of the auth middlewares
+func OwnerFromContext(ctx context.Context) (string, bool) {
+	owner, ok := ctx.Value(ownerCtxKey{}).(string)
+	return owner, ok && owner != ""
+}
+
+// AdminRole is the role of support staff, allowed to use the admin endpoints acting on the todos
+// of any owner
+const AdminRole = "admin"
+
+// rolesCtxKey is the context key under which the roles of the authenticated client are stored
+type rolesCtxKey struct{}
+
+// ContextWithRoles returns a copy of ctx carrying the roles of the authenticated client
+func ContextWithRoles(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, rolesCtxKey{}, roles)
+}
+
+// RolesFromContext returns the roles stored in ctx by one of the auth middlewares; only
+// AuthMiddleware stores roles, clients authenticated otherwise have none
+func RolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesCtxKey{}).([]string)
+	return roles
+}
+
+// HasRole checks if the authenticated client of ctx has role
+func HasRole(ctx context.Context, role string) bool {
+	for _, r := range RolesFromContext(ctx) {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// claimRoles returns the roles of a 'roles' claim, either an array of strings or a space
+// separated string like the 'scope' claim
+func claimRoles(claims jwt.MapClaims) []string {
+	switch value := claims["roles"].(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		roles := make([]string, 0, len(value))
+		for _, item := range value {
+			if role, ok := item.(string); ok && role != "" {
+				roles = append(roles, role)
+			}
+		}
+		return roles
+	}
+	return nil
+}
+
+// AuthMiddleware validates the JWT bearer token from the Authorization header using keyfunc
+// and stores its 'sub' claim as the owner in the request context, with the roles of its 'roles'
+// claim
+func AuthMiddleware(keyfunc jwt.Keyfunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				render.Render(w, r, middleware.ErrAuth(errors.New("Authorization header is missing")))
+				return
+			}
+			if !strings.HasPrefix(header, "Bearer ") {
+				render.Render(w, r, middleware.ErrAuth(errors.New("Authorization header is not a bearer token")))
+				return
+			}
+			claims := jwt.MapClaims{}
+			if _, err := jwt.ParseWithClaims(strings.TrimPrefix(header, "Bearer "), claims, keyfunc); err != nil {
+				render.Render(w, r, middleware.ErrAuth(err))
+				return
+			}
+			sub, _ := claims["sub"].(string)
+			if sub == "" {
+				render.Render(w, r, middleware.ErrAuth(errors.New("sub claim not found in token")))
+				return
+			}
+			ctx := ContextWithRoles(ContextWithOwner(r.Context(), sub), claimRoles(claims))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// NewRSAKeyfunc returns a jwt.Keyfunc accepting only RSA signed tokens verified with key
+func NewRSAKeyfunc(key *rsa.PublicKey) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key, nil
+	}
+}
+
+// NewRSAKeyfuncFromFile loads a PEM encoded RSA public key from path and returns
+// a jwt.Keyfunc verifying tokens with it
+func NewRSAKeyfuncFromFile(path string) (jwt.Keyfunc, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read JWT public key: %v", err)
+	}
+	key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse JWT public key: %v", err)
+	}
+	return NewRSAKeyfunc(key), nil
+}
@@ -0,0 +1,127 @@
+package todo
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// signToken returns a bearer token with claims signed with key
+func signToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("can't sign token: %v", err)
+	}
+	return "Bearer " + token
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("can't generate key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("can't generate key: %v", err)
+	}
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice"}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("can't sign token: %v", err)
+	}
+	expires := time.Now().Add(time.Hour).Unix()
+
+	handler := AuthMiddleware(NewRSAKeyfunc(&key.PublicKey))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		owner, _ := OwnerFromContext(r.Context())
+		if !HasRole(r.Context(), AdminRole) {
+			owner += " (user)"
+		}
+		w.Write([]byte(owner))
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+		owner         string
+	}{
+		{
+			name:          "valid token",
+			authorization: signToken(t, key, jwt.MapClaims{"sub": "alice", "exp": expires}),
+			status:        http.StatusOK,
+			owner:         "alice (user)",
+		},
+		{
+			name:          "valid token with roles",
+			authorization: signToken(t, key, jwt.MapClaims{"sub": "bob", "exp": expires, "roles": []string{AdminRole}}),
+			status:        http.StatusOK,
+			owner:         "bob",
+		},
+		{
+			name:   "missing header",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:          "not a bearer token",
+			authorization: "Basic YWxpY2U6c2VjcmV0",
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:          "expired token",
+			authorization: signToken(t, key, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(-time.Minute).Unix()}),
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:          "token signed with another key",
+			authorization: signToken(t, otherKey, jwt.MapClaims{"sub": "alice", "exp": expires}),
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:          "token signed with HMAC",
+			authorization: "Bearer " + hmacToken,
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:          "token without sub",
+			authorization: signToken(t, key, jwt.MapClaims{"exp": expires}),
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:          "malformed token",
+			authorization: "Bearer not-a-jwt",
+			status:        http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, r)
+			if res.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, res.Code, res.Body.String())
+			}
+			if tt.status == http.StatusOK && res.Body.String() != tt.owner {
+				t.Errorf("expected owner %q in context, got %q", tt.owner, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestDefaultOwnerFromRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if owner, _ := DefaultOwnerFromRequest(r); owner != Username {
+		t.Errorf("expected owner %q without auth, got %q", Username, owner)
+	}
+	r = r.WithContext(ContextWithOwner(r.Context(), "alice"))
+	if owner, _ := DefaultOwnerFromRequest(r); owner != "alice" {
+		t.Errorf("expected owner from context, got %q", owner)
+	}
+}
@@ -0,0 +1,92 @@
+package todo
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+// BasicAuthVerifier checks the password of a user authenticating with HTTP Basic auth
+type BasicAuthVerifier func(username, password string) bool
+
+// NewBasicAuthVerifier returns a BasicAuthVerifier accepting the passwords of credentials, keyed
+// by username. Passwords are compared in constant time, also for unknown usernames, so that the
+// response time doesn't tell how much of a password is right or if a user exists.
+func NewBasicAuthVerifier(credentials map[string]string) BasicAuthVerifier {
+	hashes := make(map[string][sha256.Size]byte, len(credentials))
+	for username, password := range credentials {
+		hashes[username] = sha256.Sum256([]byte(password))
+	}
+	// unknown usernames are compared against a hash no password matches
+	var unknown [sha256.Size]byte
+	return func(username, password string) bool {
+		hash := sha256.Sum256([]byte(password))
+		expected, found := hashes[username]
+		if !found {
+			expected = unknown
+		}
+		return subtle.ConstantTimeCompare(hash[:], expected[:]) == 1 && found
+	}
+}
+
+// NewBasicAuthVerifierFromFile loads the credentials of Basic auth from a file with a
+// username:password pair on every line; empty lines and lines starting with # are skipped
+func NewBasicAuthVerifierFromFile(path string) (BasicAuthVerifier, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read basic auth credentials: %v", err)
+	}
+	defer f.Close()
+	credentials := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.SplitN(text, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("basic auth credentials line %d is not a username:password pair", line)
+		}
+		credentials[parts[0]] = parts[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read basic auth credentials: %v", err)
+	}
+	if len(credentials) == 0 {
+		return nil, errors.New("basic auth credentials file has no users")
+	}
+	return NewBasicAuthVerifier(credentials), nil
+}
+
+// BasicAuthMiddleware validates the HTTP Basic auth credentials from the Authorization header
+// with verify and stores the username as the owner in the request context; it can be used
+// instead of AuthMiddleware. Requests without valid credentials are rejected with 401
+// Unauthorized, asking for credentials of realm with the WWW-Authenticate header.
+func BasicAuthMiddleware(realm string, verify BasicAuthVerifier) func(http.Handler) http.Handler {
+	challenge := fmt.Sprintf(`Basic realm=%q, charset="UTF-8"`, realm)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if !ok {
+				w.Header().Set("WWW-Authenticate", challenge)
+				render.Render(w, r, middleware.ErrAuth(errors.New("Authorization header is missing or not basic auth")))
+				return
+			}
+			if !verify(username, password) {
+				w.Header().Set("WWW-Authenticate", challenge)
+				render.Render(w, r, middleware.ErrAuth(errors.New("invalid username or password")))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ContextWithOwner(r.Context(), username)))
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// batchWorkers is the max number of concurrent gRPC calls issued for a single batch request
+const batchWorkers = 8
+
+// maxBatchGetIDs is the max number of todos that can be fetched with a single batch get request
+const maxBatchGetIDs = 100
+
+// BatchCreateTodos creates all the todos from a JSON array for a given user; if any of them
+// is invalid, none is created
+func (t *Router) BatchCreateTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	var data []*Todo
+	if err := render.DecodeJSON(r.Body, &data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	for i, todo := range data {
+		if todo == nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: can't be null", i)))
+			return
+		}
+		if err := todo.Bind(r); err != nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: %v", i, err)))
+			return
+		}
+		if err := todo.Validate(); err != nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: %v", i, err)))
+			return
+		}
+		if err := t.checkTextLength(todo.Text); err != nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: %v", i, err)))
+			return
+		}
+		todo.ID = "0"
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	stream, err := t.grpcClient.BatchCreateTodos(ctx)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	for _, todo := range data {
+		if err := stream.Send(todo.ToGRPCTodo(owner)); err != nil {
+			// the real error is returned by CloseAndRecv below
+			break
+		}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	todoList := []render.Renderer{}
+	for _, grpcTodo := range res.GetTodos() {
+		todo, _ := FromGRPCTodo(grpcTodo)
+		todoList = append(todoList, todo)
+	}
+	if err := render.RenderList(w, r, todoList); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	t.createOneCounter.WithLabelValues(owner).Add(float64(len(todoList)))
+}
+
+// BatchDeleteTodos deletes all the todos with IDs listed in the request for a given user;
+// IDs that are not found don't fail the whole request, but are reported in the response
+func (t *Router) BatchDeleteTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	data := &BatchDeleteReq{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ids := make([]uint64, len(data.IDs))
+	for i, todoID := range data.IDs {
+		id, err := parseTodoID(todoID)
+		if err != nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("ID at index %d: %v", i, err)))
+			return
+		}
+		ids[i] = id
+	}
+
+	// run the deletes with a bounded pool of workers, each storing the result under the ID's index
+	codesByIndex := make([]codes.Code, len(ids))
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < batchWorkers && i < len(ids); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				ctx, cancel := t.callContext(r)
+				_, err := t.grpcClient.DeleteTodo(ctx, &todomgrpb.DeleteTodoReq{
+					Id:    ids[idx],
+					Owner: owner,
+				})
+				cancel()
+				codesByIndex[idx] = status.Code(err)
+			}
+		}()
+	}
+	for i := range ids {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	res := &BatchDeleteRes{
+		Deleted:  []string{},
+		NotFound: []string{},
+	}
+	for i, code := range codesByIndex {
+		switch code {
+		case codes.OK:
+			res.Deleted = append(res.Deleted, data.IDs[i])
+		case codes.NotFound:
+			res.NotFound = append(res.NotFound, data.IDs[i])
+		default:
+			res.Failed = append(res.Failed, data.IDs[i])
+		}
+	}
+	if err := render.Render(w, r, res); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	t.deleteOneCounter.WithLabelValues(owner).Add(float64(len(res.Deleted)))
+}
+
+// BatchGetTodos gets all the todos with IDs listed in the comma separated ids query param for a
+// given user, in the same order; IDs that are not found don't fail the whole request, but are
+// marked as not found in the response
+func (t *Router) BatchGetTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	var todoIDs []string
+	if v := r.URL.Query().Get("ids"); v != "" {
+		todoIDs = strings.Split(v, ",")
+	}
+	if len(todoIDs) == 0 {
+		render.Render(w, r, middleware.ErrInvalidRequest(errors.New("ids must list at least one ID")))
+		return
+	}
+	if len(todoIDs) > maxBatchGetIDs {
+		render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("ids can't list more than %d IDs", maxBatchGetIDs)))
+		return
+	}
+	ids := make([]uint64, len(todoIDs))
+	for i, todoID := range todoIDs {
+		id, err := parseTodoID(strings.TrimSpace(todoID))
+		if err != nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("ID at index %d: %v", i, err)))
+			return
+		}
+		ids[i] = id
+	}
+
+	// run the gets with a bounded pool of workers, each storing the result under the ID's index
+	todos := make([]*todomgrpb.Todo, len(ids))
+	errs := make([]error, len(ids))
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < batchWorkers && i < len(ids); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				ctx, cancel := t.callContext(r)
+				todos[idx], errs[idx] = t.grpcClient.GetTodo(ctx, &todomgrpb.TodoIdReq{
+					Id:    ids[idx],
+					Owner: owner,
+				})
+				cancel()
+			}
+		}()
+	}
+	for i := range ids {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	res := &BatchGetRes{Todos: make([]BatchGetItem, len(ids))}
+	found := 0
+	for i, err := range errs {
+		res.Todos[i].ID = strconv.FormatUint(ids[i], 10)
+		switch status.Code(err) {
+		case codes.OK:
+			res.Todos[i].Todo, _ = FromGRPCTodo(todos[i])
+			found++
+		case codes.NotFound:
+			res.Todos[i].NotFound = true
+		default:
+			// a partial response would hide todos that exist, so other errors fail the request
+			t.renderGRPCError(w, r, err)
+			return
+		}
+	}
+	if err := render.Render(w, r, res); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	t.getOneCounter.WithLabelValues(owner).Add(float64(found))
+}
+
+// BatchCompleteTodos marks done all the todos owned by a user matching the filters of the query
+// params, like ListTodos; the number of todos changed is returned
+func (t *Router) BatchCompleteTodos(w http.ResponseWriter, r *http.Request) {
+	t.batchSetDone(w, r, true)
+}
+
+// BatchUncompleteTodos marks not done all the todos owned by a user matching the filters of the
+// query params, like ListTodos; the number of todos changed is returned
+func (t *Router) BatchUncompleteTodos(w http.ResponseWriter, r *http.Request) {
+	t.batchSetDone(w, r, false)
+}
+
+// batchSetDone sets the done state of all the matching todos with a single gRPC call
+func (t *Router) batchSetDone(w http.ResponseWriter, r *http.Request, done bool) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	filter := &todomgrpb.ListTodosReq{Owner: owner}
+	if err := parseFilters(r, filter); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	res, err := t.grpcClient.SetTodosDone(ctx, &todomgrpb.SetTodosDoneReq{
+		Filter: filter,
+		Done:   done,
+	})
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	if err := render.Render(w, r, FromGRPCCountRes(res)); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+}
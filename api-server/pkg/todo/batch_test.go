@@ -0,0 +1,87 @@
+package todo
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+func TestBatchCreateTodos(t *testing.T) {
+	client := newFakeClient()
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodPost, "/batch", `[{"text": "first"}, {"text": "second", "priority": "high"}]`)
+	expectStatus(t, res, http.StatusOK)
+	var todos []Todo
+	decodeJSONRes(t, res, &todos)
+	if len(todos) != 2 || todos[0].Text != "first" || todos[1].Text != "second" || todos[1].Priority != PriorityHigh {
+		t.Fatalf("expected the created todos in order, got %+v", todos)
+	}
+	if todos[0].ID == todos[1].ID {
+		t.Errorf("expected todos with different IDs, got %q", todos[0].ID)
+	}
+}
+
+func TestBatchCreateTodosInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		index string
+	}{
+		{name: "empty text", body: `[{"text": "valid"}, {"text": "  "}, {"text": "valid too"}]`, index: "index 1"},
+		{name: "invalid priority", body: `[{"text": "valid"}, {"text": "valid too"}, {"text": "bad", "priority": "urgent"}]`, index: "index 2"},
+		{name: "null todo", body: `[null, {"text": "valid"}]`, index: "index 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClient()
+			router := newTestRouter(client, nil)
+			defer router.Close()
+
+			res := serve(router.GetRouter(), http.MethodPost, "/batch", tt.body)
+			expectStatus(t, res, http.StatusBadRequest)
+			body := &ErrorRes{}
+			decodeJSONRes(t, res, body)
+			if !strings.Contains(body.Error, tt.index) {
+				t.Errorf("expected error naming %s, got %q", tt.index, body.Error)
+			}
+			if client.callCount("BatchCreateTodos") != 0 || len(client.todos) != 0 {
+				t.Errorf("expected no todo to be created")
+			}
+		})
+	}
+}
+
+func TestBatchDeleteTodos(t *testing.T) {
+	client := newFakeClient(
+		&todomgrpb.Todo{Text: "mine", Owner: Username},
+		&todomgrpb.Todo{Text: "someone else's", Owner: "alice"},
+		&todomgrpb.Todo{Text: "mine too", Owner: Username},
+	)
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodDelete, "/batch", `{"ids": ["1", "2", "3", "42"]}`)
+	expectStatus(t, res, http.StatusOK)
+	body := &BatchDeleteRes{}
+	decodeJSONRes(t, res, body)
+	sort.Strings(body.Deleted)
+	if strings.Join(body.Deleted, ",") != "1,3" {
+		t.Errorf("expected todos 1 and 3 to be deleted, got %v", body.Deleted)
+	}
+	if strings.Join(body.NotFound, ",") != "2,42" {
+		t.Errorf("expected todos 2 and 42 not to be found, got %v", body.NotFound)
+	}
+	if client.todo(2).DeletedAt != nil {
+		t.Errorf("expected the todo of another owner not to be deleted")
+	}
+
+	res = serve(handler, http.MethodDelete, "/batch", `{"ids": ["1", "two"]}`)
+	expectStatus(t, res, http.StatusBadRequest)
+}
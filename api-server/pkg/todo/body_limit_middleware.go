@@ -0,0 +1,45 @@
+package todo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+// DefaultMaxBodyBytes is the default max size of the JSON body of a request
+const DefaultMaxBodyBytes = 1 << 20
+
+// NewBodyLimitMiddleware returns a middleware responding 413 Request Entity Too Large to requests
+// with a body larger than maxBytes. The body is read before calling the handler, so the limit
+// applies also to chunked requests without a Content-Length; maxBytes should be small enough
+// to hold the body in memory.
+func NewBodyLimitMiddleware(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				render.Render(w, r, errRequestEntityTooLarge(errBodyTooLarge))
+				return
+			}
+			body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+			if err != nil {
+				if isBodyTooLarge(err) {
+					render.Render(w, r, errRequestEntityTooLarge(err))
+				} else {
+					render.Render(w, r, middleware.ErrInvalidRequest(err))
+				}
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isBodyTooLarge checks if err is returned by a http.MaxBytesReader after reading past its limit;
+// the error is not typed
+func isBodyTooLarge(err error) bool {
+	return err.Error() == errBodyTooLarge.Error()
+}
@@ -0,0 +1,209 @@
+package todo
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// todoManagerMethodPrefix prefixes the methods of the TodoManager service; the circuit breaker
+// applies only to them, so that readiness checks see the actual state of todo-manager
+const todoManagerMethodPrefix = "/todo_mgr.TodoManager/"
+
+// errCircuitOpen is returned for calls short-circuited by the circuit breaker, reported like
+// todo-manager being unavailable
+var errCircuitOpen = status.Error(codes.Unavailable, "circuit breaker is open: todo-manager failed repeatedly, calls are rejected until it recovers")
+
+// circuitState is the state of the circuit breaker of the calls to todo-manager
+type circuitState int
+
+const (
+	// circuitClosed lets all calls through
+	circuitClosed circuitState = iota
+	// circuitHalfOpen lets a single call through to probe if todo-manager recovered
+	circuitHalfOpen
+	// circuitOpen rejects all calls until the cooldown is over
+	circuitOpen
+)
+
+// String returns the name of the state reported by readiness checks
+func (s circuitState) String() string {
+	switch s {
+	case circuitHalfOpen:
+		return "half-open"
+	case circuitOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker fails calls to todo-manager fast once it looks down, instead of having every
+// request wait for the call timeout. It opens after threshold consecutive calls fail with
+// Unavailable or DeadlineExceeded and rejects calls for cooldown; then it's half-open, letting
+// a probe call through until one succeeds, closing it, or fails, opening it again. Calls timing
+// out within a timeout requested by the client, shorter than CallTimeout, aren't counted, so a
+// single client can't open the breaker for everyone.
+type circuitBreaker struct {
+	threshold   int
+	cooldown    time.Duration
+	callTimeout time.Duration
+	logger      logrus.FieldLogger
+	rejected    prometheus.Counter
+
+	lock     sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// newCircuitBreaker returns the circuit breaker configured in options, registering its metrics;
+// nil is returned when it's disabled
+func newCircuitBreaker(options *RouterOptions) *circuitBreaker {
+	if options.CircuitBreakerThreshold < 0 {
+		return nil
+	}
+	factory := promauto.With(options.Registerer)
+	b := &circuitBreaker{
+		threshold:   options.CircuitBreakerThreshold,
+		cooldown:    options.CircuitBreakerCooldown,
+		callTimeout: options.CallTimeout,
+		logger:      options.Logger,
+		rejected: factory.NewCounter(prometheus.CounterOpts{
+			Subsystem: "todo",
+			Name:      "circuit_breaker_rejected_total",
+			Help:      "The total number of gRPC calls to todo-manager rejected by the open circuit breaker",
+		}),
+	}
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Subsystem: "todo",
+		Name:      "circuit_breaker_state",
+		Help:      "The state of the circuit breaker of the gRPC calls to todo-manager: 0 closed, 1 half-open, 2 open",
+	}, func() float64 {
+		return float64(b.State())
+	})
+	return b
+}
+
+// State returns the current state of the breaker; open breakers are half-open after the cooldown
+func (b *circuitBreaker) State() circuitState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.currentState()
+}
+
+// currentState is State; the lock has to be held
+func (b *circuitBreaker) currentState() circuitState {
+	if b.state == circuitOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = circuitHalfOpen
+	}
+	return b.state
+}
+
+// allow checks if a call can be made, returning errCircuitOpen if it can't; probe is true for
+// the call testing if todo-manager recovered, whose result decides the next state
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	switch b.currentState() {
+	case circuitClosed:
+		return false, nil
+	case circuitHalfOpen:
+		if !b.probing {
+			b.probing = true
+			return true, nil
+		}
+	}
+	b.rejected.Inc()
+	return false, errCircuitOpen
+}
+
+// record updates the breaker with the result of a call made with ctx, allowed by allow
+func (b *circuitBreaker) record(ctx context.Context, probe bool, err error) {
+	code := status.Code(err)
+	timedOut := code == codes.DeadlineExceeded && !b.clientTimeout(ctx)
+	failed := code == codes.Unavailable || timedOut
+	// calls cancelled by the client, or timing out sooner than CallTimeout because it asked so,
+	// say nothing about todo-manager
+	ignored := code == codes.Canceled || code == codes.DeadlineExceeded && !timedOut
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	switch {
+	case probe:
+		b.probing = false
+		if ignored {
+			return
+		}
+		if failed {
+			b.open()
+			return
+		}
+		b.state = circuitClosed
+		b.failures = 0
+		b.logger.Info("circuit breaker of todo-manager closed, calls are let through again")
+	case b.state == circuitClosed && failed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	case b.state == circuitClosed && !ignored:
+		b.failures = 0
+	}
+}
+
+// clientTimeout checks if the calls made with ctx have a timeout requested with X-Request-Timeout
+// shorter than CallTimeout
+func (b *circuitBreaker) clientTimeout(ctx context.Context) bool {
+	timeout, ok := ctx.Value(callTimeoutCtxKey{}).(time.Duration)
+	return ok && timeout < b.callTimeout
+}
+
+// open opens the breaker for the cooldown; the lock has to be held
+func (b *circuitBreaker) open() {
+	b.state = circuitOpen
+	b.openedAt = time.Now()
+	b.failures = 0
+	b.logger.WithField("cooldown", b.cooldown.String()).Warn("circuit breaker of todo-manager opened, calls are rejected")
+}
+
+// unaryInterceptor short-circuits unary calls to todo-manager while the breaker is open; it wraps
+// retries, so a call failing after all its attempts counts as a single failure
+func (b *circuitBreaker) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if !strings.HasPrefix(method, todoManagerMethodPrefix) {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	probe, err := b.allow()
+	if err != nil {
+		return err
+	}
+	err = invoker(ctx, method, req, reply, cc, opts...)
+	b.record(ctx, probe, err)
+	return err
+}
+
+// streamInterceptor short-circuits streaming calls to todo-manager while the breaker is open;
+// only errors starting the stream are counted as failures
+func (b *circuitBreaker) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+	streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if !strings.HasPrefix(method, todoManagerMethodPrefix) {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+	probe, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	b.record(ctx, probe, err)
+	return stream, err
+}
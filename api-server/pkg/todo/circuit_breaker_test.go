@@ -0,0 +1,126 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const getTodoMethod = "/todo_mgr.TodoManager/GetTodo"
+
+// newTestBreaker returns a circuit breaker opening after 3 failures for cooldown
+func newTestBreaker(cooldown time.Duration) *circuitBreaker {
+	options := testOptions(&RouterOptions{CircuitBreakerThreshold: 3, CircuitBreakerCooldown: cooldown})
+	options.fillDefaults()
+	return newCircuitBreaker(options)
+}
+
+// invokeN makes n calls through the unary interceptor of b with an invoker returning err
+func invokeN(ctx context.Context, b *circuitBreaker, n int, err error) (attempts int) {
+	for i := 0; i < n; i++ {
+		b.unaryInterceptor(ctx, getTodoMethod, nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			attempts++
+			return err
+		})
+	}
+	return attempts
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	b := newTestBreaker(20 * time.Millisecond)
+	unavailable := status.Error(codes.Unavailable, "todo-manager is down")
+
+	// successes reset the count of consecutive failures
+	invokeN(context.Background(), b, 2, unavailable)
+	invokeN(context.Background(), b, 1, nil)
+	invokeN(context.Background(), b, 2, unavailable)
+	if state := b.State(); state != circuitClosed {
+		t.Fatalf("expected the breaker to stay closed, got %s", state)
+	}
+	invokeN(context.Background(), b, 1, status.Error(codes.DeadlineExceeded, "too slow"))
+	if state := b.State(); state != circuitOpen {
+		t.Fatalf("expected the breaker to open after 3 failures, got %s", state)
+	}
+
+	// open breakers fail fast
+	attempts := 0
+	err := b.unaryInterceptor(context.Background(), getTodoMethod, nil, nil, nil, failingInvoker(&attempts))
+	if err != errCircuitOpen || attempts != 0 {
+		t.Errorf("expected the call to be rejected without calling todo-manager, got %v after %d attempts", err, attempts)
+	}
+	// calls to other services go through
+	if err := b.unaryInterceptor(context.Background(), "/grpc.health.v1.Health/Check", nil, nil, nil, failingInvoker(&attempts)); err != nil || attempts != 1 {
+		t.Errorf("expected the health check to go through, got %v", err)
+	}
+
+	// after the cooldown, a failed probe opens the breaker again, a successful one closes it
+	time.Sleep(20 * time.Millisecond)
+	if attempts := invokeN(context.Background(), b, 1, unavailable); attempts != 1 || b.State() != circuitOpen {
+		t.Fatalf("expected a failed probe to open the breaker again, got %s", b.State())
+	}
+	time.Sleep(20 * time.Millisecond)
+	if state := b.State(); state != circuitHalfOpen {
+		t.Fatalf("expected the breaker to be half-open after the cooldown, got %s", state)
+	}
+	if attempts := invokeN(context.Background(), b, 2, nil); attempts != 2 || b.State() != circuitClosed {
+		t.Errorf("expected a successful probe to close the breaker, got %s after %d attempts", b.State(), attempts)
+	}
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	b := newTestBreaker(time.Millisecond)
+	invokeN(context.Background(), b, 3, status.Error(codes.Unavailable, "todo-manager is down"))
+	time.Sleep(time.Millisecond)
+
+	probe, err := b.allow()
+	if !probe || err != nil {
+		t.Fatalf("expected a probe to be let through, got %v", err)
+	}
+	if _, err := b.allow(); err != errCircuitOpen {
+		t.Errorf("expected calls to be rejected while probing, got %v", err)
+	}
+	// cancelled probes leave the breaker half-open
+	b.record(context.Background(), probe, status.Error(codes.Canceled, "client is gone"))
+	if probe, _ := b.allow(); !probe {
+		t.Errorf("expected another probe to be let through")
+	}
+}
+
+func TestCircuitBreakerClientTimeout(t *testing.T) {
+	b := newTestBreaker(time.Minute)
+	deadlineExceeded := status.Error(codes.DeadlineExceeded, "too slow")
+
+	// timeouts clients asked for aren't failures of todo-manager
+	ctx := context.WithValue(context.Background(), callTimeoutCtxKey{}, time.Millisecond)
+	invokeN(ctx, b, 10, deadlineExceeded)
+	invokeN(context.Background(), b, 10, status.Error(codes.Canceled, "client is gone"))
+	if state := b.State(); state != circuitClosed {
+		t.Fatalf("expected the breaker to stay closed, got %s", state)
+	}
+
+	// timeouts clamped to CallTimeout are
+	ctx = context.WithValue(context.Background(), callTimeoutCtxKey{}, DefaultCallTimeout)
+	invokeN(ctx, b, 3, deadlineExceeded)
+	if state := b.State(); state != circuitOpen {
+		t.Errorf("expected the breaker to open after 3 calls timing out within CallTimeout, got %s", state)
+	}
+}
+
+func TestCircuitBreakerStream(t *testing.T) {
+	b := newTestBreaker(time.Minute)
+	calls := 0
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		calls++
+		return nil, status.Error(codes.Unavailable, "todo-manager is down")
+	}
+	for i := 0; i < 4; i++ {
+		b.streamInterceptor(context.Background(), &grpc.StreamDesc{}, nil, "/todo_mgr.TodoManager/ListTodos", streamer)
+	}
+	if calls != 3 || b.State() != circuitOpen {
+		t.Errorf("expected the breaker to open after 3 failed streams and reject the next one, got %d calls, %s", calls, b.State())
+	}
+}
@@ -0,0 +1,129 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// Comment data model.
+type Comment struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	// Author and CreatedAt are set by the server; values sent by clients are ignored
+	Author    string `json:"author,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
+
+// Bind allows to set additional properties on Comment object; not used here
+func (c *Comment) Bind(r *http.Request) error {
+	return nil
+}
+
+// Render allows to modify the way Comment object is rendered to text; not used here
+func (c *Comment) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Validate checks if Comment can be stored
+func (c *Comment) Validate() error {
+	if c.Text == "" {
+		return errors.New("Comment text can't be empty")
+	}
+	return nil
+}
+
+// FromGRPCComment returns new Comment object based on gRPC DTO from the upstream todo-manager service
+func FromGRPCComment(grpcComment *todomgrpb.Comment) *Comment {
+	return &Comment{
+		ID:        fmt.Sprintf("%d", grpcComment.GetId()),
+		Text:      grpcComment.GetText(),
+		Author:    grpcComment.GetAuthor(),
+		CreatedAt: formatTimestamp(grpcComment.GetCreatedAt()),
+	}
+}
+
+// AddComment adds a comment authored by the user to a todo with specified todo ID
+func (t *Router) AddComment(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	id, err := parseTodoID(chi.URLParam(r, "todoID"))
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	data := &Comment{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if err := data.Validate(); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	grpcComment, err := t.grpcClient.AddComment(ctx, &todomgrpb.AddCommentReq{
+		TodoId: id,
+		Owner:  owner,
+		Text:   data.Text,
+	})
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	render.Status(r, http.StatusCreated)
+	if err := render.Render(w, r, FromGRPCComment(grpcComment)); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+}
+
+// ListComments lists the comments of a todo with specified user and todo ID, newest first unless
+// the order query param is "asc"; it's paginated with the same params as ListTodos
+func (t *Router) ListComments(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	id, err := parseTodoID(chi.URLParam(r, "todoID"))
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	req := &todomgrpb.ListCommentsReq{
+		TodoId:      id,
+		Owner:       owner,
+		OldestFirst: strings.ToLower(r.URL.Query().Get("order")) == "asc",
+	}
+	if req.Limit, req.Offset, err = t.parsePagination(w, r); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	res, err := t.grpcClient.ListComments(ctx, req)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.FormatUint(res.GetTotal(), 10))
+	commentList := []render.Renderer{}
+	for _, grpcComment := range res.GetComments() {
+		commentList = append(commentList, FromGRPCComment(grpcComment))
+	}
+	if err := render.RenderList(w, r, commentList); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+}
@@ -3,6 +3,8 @@ package todo
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds server configuration
@@ -11,6 +13,67 @@ type Config struct {
 	OcAgentHost    string
 	EnableFailures bool
 	EnableTracing  bool
+	// EnableDebugRoutes exposes GET /_routes listing all the registered routes; it shouldn't be
+	// enabled in production
+	EnableDebugRoutes bool
+	// TodoTLSCAFile is a path to the PEM encoded CA certificate used to verify todo-manager;
+	// the connection to todo-manager is not encrypted when empty
+	TodoTLSCAFile string
+	// JWTPublicKeyFile is a path to the PEM encoded RSA public key used to validate
+	// bearer tokens; JWT auth is disabled when empty
+	JWTPublicKeyFile string
+	// BasicAuthFile is a path to a file with a username:password pair on every line, used to
+	// validate HTTP Basic auth credentials; basic auth is disabled when empty
+	BasicAuthFile string
+	// APIKeysFile is a path to a file with an owner:key pair on every line, used to look up the
+	// owners of API keys sent in the X-API-Key header; API key auth is disabled when empty
+	APIKeysFile string
+	// CORSAllowedOrigins lists origins allowed to call the API from browsers; all are allowed when empty
+	CORSAllowedOrigins []string
+	// RateLimit is the number of requests per second allowed for each owner; disabled when 0
+	RateLimit      float64
+	RateLimitBurst int
+	// WebhookURLs lists the URLs todo events are POSTed to; webhooks are disabled when empty
+	WebhookURLs []string
+	// WebhookSecret is the key used to sign webhook payloads
+	WebhookSecret string
+	// MaxTextLength is the max number of characters of the text of todos; there's no limit when 0
+	MaxTextLength int
+	// DefaultPageSize is the number of todos listed when no limit is requested
+	DefaultPageSize int
+	// MaxPageSize is the max number of todos listed at once, larger limits are clamped; there's no limit when 0
+	MaxPageSize int
+	// DedupeWindow is the time within which creating a todo with the same text as another one
+	// returns the existing one; disabled when 0
+	DedupeWindow time.Duration
+	// TodoCacheTTL is the time for which single todos are cached by the apiserver, invalidated by the
+	// changes made through it; disabled when 0
+	TodoCacheTTL time.Duration
+	// ReadOnly starts the server in read-only mode, rejecting requests changing todos
+	ReadOnly bool
+	// AdminToken is the bearer token of the admin endpoints; they are disabled when empty
+	AdminToken string
+	// HandlerTimeout is the max time handlers of requests that aren't streamed can take to respond;
+	// disabled when 0
+	HandlerTimeout time.Duration
+	// ShutdownTimeout is the max time to wait for in-flight requests when the server is stopped
+	ShutdownTimeout time.Duration
+	// GrpcMaxMessageSize is the max size in bytes of the gRPC messages sent to and received from
+	// todo-manager; the defaults of gRPC are used when 0
+	GrpcMaxMessageSize int
+	// CircuitBreakerThreshold is the number of consecutive failed calls to todo-manager after which
+	// calls are rejected right away for CircuitBreakerCooldown; disabled when 0
+	CircuitBreakerThreshold int
+	// CircuitBreakerCooldown is the time calls are rejected for once the circuit breaker opens
+	CircuitBreakerCooldown time.Duration
+	// LogPayloads logs the request and response bodies of every request; it shouldn't be enabled
+	// in production
+	LogPayloads bool
+	// PayloadLogMaxBytes is the number of bytes of every body logged with LogPayloads
+	PayloadLogMaxBytes int
+	// PayloadLogRedactedFields lists the JSON fields whose values are redacted from logged bodies;
+	// the default ones are redacted when empty
+	PayloadLogRedactedFields []string
 }
 
 // NewConfig loads config from environment variables
@@ -19,6 +82,7 @@ func NewConfig() *Config {
 	if todoURL == "" {
 		panic("Required environment variable 'TODO_URL' not set")
 	}
+	todoTLSCAFile := os.Getenv("TODO_TLS_CA_FILE")
 	ocAgentHost := os.Getenv("OC_AGENT_HOST")
 	boolEnableFailures := false
 	enableFailures := os.Getenv("ENABLE_FAILURES")
@@ -34,14 +98,193 @@ func NewConfig() *Config {
 			boolEnableTracing = b
 		}
 	}
+	boolEnableDebugRoutes := false
+	if enableDebugRoutes := os.Getenv("ENABLE_DEBUG_ROUTES"); enableDebugRoutes != "" {
+		if b, err := strconv.ParseBool(enableDebugRoutes); err == nil {
+			boolEnableDebugRoutes = b
+		}
+	}
 	if boolEnableTracing && ocAgentHost == "" {
 		panic("Required environment variable 'OC_AGENT_HOST' not set")
 	}
 
+	jwtPublicKeyFile := os.Getenv("JWT_PUBLIC_KEY_FILE")
+	basicAuthFile := os.Getenv("BASIC_AUTH_FILE")
+	apiKeysFile := os.Getenv("API_KEYS_FILE")
+	authMethods := 0
+	for _, file := range []string{jwtPublicKeyFile, basicAuthFile, apiKeysFile} {
+		if file != "" {
+			authMethods++
+		}
+	}
+	if authMethods > 1 {
+		panic("Only one of environment variables 'JWT_PUBLIC_KEY_FILE', 'BASIC_AUTH_FILE' and 'API_KEYS_FILE' can be set")
+	}
+	var corsAllowedOrigins []string
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		corsAllowedOrigins = strings.Split(origins, ",")
+	}
+	var webhookURLs []string
+	if urls := os.Getenv("WEBHOOK_URLS"); urls != "" {
+		webhookURLs = strings.Split(urls, ",")
+	}
+	webhookSecret := os.Getenv("WEBHOOK_SECRET")
+	if len(webhookURLs) > 0 && webhookSecret == "" {
+		panic("Required environment variable 'WEBHOOK_SECRET' not set")
+	}
+	rateLimit := 0.0
+	if v := os.Getenv("RATE_LIMIT_RPS"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			panic("Environment variable 'RATE_LIMIT_RPS' must be a non-negative number")
+		}
+		rateLimit = f
+	}
+	rateLimitBurst := 0
+	if v := os.Getenv("RATE_LIMIT_BURST"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			panic("Environment variable 'RATE_LIMIT_BURST' must be a non-negative integer")
+		}
+		rateLimitBurst = i
+	}
+	maxTextLength := DefaultMaxTextLength
+	if v := os.Getenv("MAX_TEXT_LENGTH"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			panic("Environment variable 'MAX_TEXT_LENGTH' must be a non-negative integer")
+		}
+		maxTextLength = i
+	}
+	defaultPageSize := DefaultPageSize
+	if v := os.Getenv("DEFAULT_PAGE_SIZE"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			panic("Environment variable 'DEFAULT_PAGE_SIZE' must be a non-negative integer")
+		}
+		defaultPageSize = i
+	}
+	maxPageSize := DefaultMaxPageSize
+	if v := os.Getenv("MAX_PAGE_SIZE"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			panic("Environment variable 'MAX_PAGE_SIZE' must be a non-negative integer")
+		}
+		maxPageSize = i
+	}
+	var dedupeWindow time.Duration
+	if v := os.Getenv("DEDUPE_WINDOW"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			panic("Environment variable 'DEDUPE_WINDOW' must be a non-negative duration")
+		}
+		dedupeWindow = d
+	}
+	var todoCacheTTL time.Duration
+	if v := os.Getenv("TODO_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			panic("Environment variable 'TODO_CACHE_TTL' must be a non-negative duration")
+		}
+		todoCacheTTL = d
+	}
+	readOnly := false
+	if v := os.Getenv("READ_ONLY"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			panic("Environment variable 'READ_ONLY' must be a boolean")
+		}
+		readOnly = b
+	}
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	handlerTimeout := DefaultHandlerTimeout
+	if v := os.Getenv("HANDLER_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			panic("Environment variable 'HANDLER_TIMEOUT' must be a non-negative duration")
+		}
+		handlerTimeout = d
+	}
+	shutdownTimeout := DefaultShutdownTimeout
+	if v := os.Getenv("SHUTDOWN_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			panic("Environment variable 'SHUTDOWN_TIMEOUT' must be a non-negative duration")
+		}
+		shutdownTimeout = d
+	}
+	grpcMaxMessageSize := 0
+	if v := os.Getenv("GRPC_MAX_MESSAGE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			panic("Environment variable 'GRPC_MAX_MESSAGE_SIZE' must be a positive integer")
+		}
+		grpcMaxMessageSize = n
+	}
+	circuitBreakerThreshold := DefaultCircuitBreakerThreshold
+	if v := os.Getenv("CIRCUIT_BREAKER_THRESHOLD"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			panic("Environment variable 'CIRCUIT_BREAKER_THRESHOLD' must be a non-negative integer")
+		}
+		circuitBreakerThreshold = i
+	}
+	circuitBreakerCooldown := DefaultCircuitBreakerCooldown
+	if v := os.Getenv("CIRCUIT_BREAKER_COOLDOWN"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			panic("Environment variable 'CIRCUIT_BREAKER_COOLDOWN' must be a positive duration")
+		}
+		circuitBreakerCooldown = d
+	}
+	logPayloads := false
+	if v := os.Getenv("LOG_PAYLOADS"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			logPayloads = b
+		}
+	}
+	payloadLogMaxBytes := DefaultPayloadLogMaxBytes
+	if v := os.Getenv("LOG_PAYLOADS_MAX_BYTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			panic("Environment variable 'LOG_PAYLOADS_MAX_BYTES' must be a positive integer")
+		}
+		payloadLogMaxBytes = n
+	}
+	var payloadLogRedactedFields []string
+	if fields := os.Getenv("LOG_PAYLOADS_REDACTED_FIELDS"); fields != "" {
+		payloadLogRedactedFields = strings.Split(fields, ",")
+	}
+
 	return &Config{
-		TodoURL:        todoURL,
-		OcAgentHost:    ocAgentHost,
-		EnableFailures: boolEnableFailures,
-		EnableTracing:  boolEnableTracing,
+		TodoURL:                  todoURL,
+		TodoTLSCAFile:            todoTLSCAFile,
+		OcAgentHost:              ocAgentHost,
+		EnableFailures:           boolEnableFailures,
+		EnableTracing:            boolEnableTracing,
+		EnableDebugRoutes:        boolEnableDebugRoutes,
+		JWTPublicKeyFile:         jwtPublicKeyFile,
+		BasicAuthFile:            basicAuthFile,
+		APIKeysFile:              apiKeysFile,
+		CORSAllowedOrigins:       corsAllowedOrigins,
+		RateLimit:                rateLimit,
+		RateLimitBurst:           rateLimitBurst,
+		WebhookURLs:              webhookURLs,
+		WebhookSecret:            webhookSecret,
+		MaxTextLength:            maxTextLength,
+		DefaultPageSize:          defaultPageSize,
+		MaxPageSize:              maxPageSize,
+		DedupeWindow:             dedupeWindow,
+		TodoCacheTTL:             todoCacheTTL,
+		ReadOnly:                 readOnly,
+		AdminToken:               adminToken,
+		HandlerTimeout:           handlerTimeout,
+		ShutdownTimeout:          shutdownTimeout,
+		GrpcMaxMessageSize:       grpcMaxMessageSize,
+		CircuitBreakerThreshold:  circuitBreakerThreshold,
+		CircuitBreakerCooldown:   circuitBreakerCooldown,
+		LogPayloads:              logPayloads,
+		PayloadLogMaxBytes:       payloadLogMaxBytes,
+		PayloadLogRedactedFields: payloadLogRedactedFields,
 	}
 }
@@ -0,0 +1,37 @@
+package todo
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/connectivity"
+)
+
+// connStateWatcher is the part of grpc.ClientConn watched by monitorConnState
+type connStateWatcher interface {
+	GetState() connectivity.State
+	WaitForStateChange(ctx context.Context, sourceState connectivity.State) bool
+}
+
+// monitorConnState logs every transition of the state of the connection to todo-manager until
+// the connection is closed; failures are logged as warnings
+func monitorConnState(conn connStateWatcher, logger logrus.FieldLogger) {
+	state := conn.GetState()
+	logger.WithField("grpc_state", state.String()).Info("todo-manager connection state")
+	for state != connectivity.Shutdown {
+		if !conn.WaitForStateChange(context.Background(), state) {
+			return
+		}
+		previous := state
+		state = conn.GetState()
+		entry := logger.WithFields(logrus.Fields{
+			"grpc_state":          state.String(),
+			"grpc_previous_state": previous.String(),
+		})
+		if state == connectivity.TransientFailure {
+			entry.Warn("todo-manager connection state changed")
+		} else {
+			entry.Info("todo-manager connection state changed")
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package todo
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// RouteInfo is a registered route listed by the debug routes handler
+type RouteInfo struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+}
+
+// walkedPattern returns the pattern of a route reported by chi.Walk, which reports the routes
+// of sub-routers with a wildcard segment next to their prefix
+func walkedPattern(route string) string {
+	return strings.Replace(route, "/*/", "/", -1)
+}
+
+// NewRoutesHandler returns a handler listing all the method and pattern pairs registered in
+// routes, sorted by pattern; the tree is walked on every request, so it includes routes added
+// after the handler is created. It's meant for debugging and shouldn't be exposed in production.
+func NewRoutesHandler(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list := []RouteInfo{}
+		err := chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+			list = append(list, RouteInfo{Method: method, Pattern: walkedPattern(route)})
+			return nil
+		})
+		if err != nil {
+			render.Render(w, r, errInternal(err))
+			return
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Pattern != list[j].Pattern {
+				return list[i].Pattern < list[j].Pattern
+			}
+			return list[i].Method < list[j].Method
+		})
+		render.JSON(w, r, list)
+	}
+}
@@ -0,0 +1,49 @@
+package todo
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// deduplicatedHeader is set on create responses returning an existing todo with the same text
+// instead of creating a new one
+const deduplicatedHeader = "X-Deduplicated"
+
+// findDuplicate returns the newest active todo of owner with exactly the same text, created within
+// DedupeWindow; nil is returned if there's none
+func (t *Router) findDuplicate(ctx context.Context, owner, text string) (*todomgrpb.Todo, error) {
+	createdAfter, err := ptypes.TimestampProto(time.Now().Add(-t.DedupeWindow))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// the text query matches todos containing the text ignoring case, also shared ones
+	stream, err := t.grpcClient.ListTodos(ctx, &todomgrpb.ListTodosReq{
+		Owner:        owner,
+		TextQuery:    text,
+		CreatedAfter: createdAfter,
+		Sort:         "created_at",
+		Order:        todomgrpb.ListTodosReq_DESC,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for {
+		todo, err := stream.Recv()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if todo.GetOwner() == owner && todo.GetText() == text {
+			return todo, nil
+		}
+	}
+}
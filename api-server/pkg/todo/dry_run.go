@@ -0,0 +1,95 @@
+package todo
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// DryRunHeader is set to true in the responses of requests with ?dry_run=true, which are validated
+// and answered with the todo that would be stored without changing anything
+const DryRunHeader = "X-Dry-Run"
+
+// isDryRun reads the dry_run query param of r
+func isDryRun(r *http.Request) (bool, error) {
+	dryRun, err := parseBoolFilter(r, "dry_run")
+	return dryRun.GetValue(), err
+}
+
+// renderDryRun renders the preview of a todo for a dry run request
+func renderDryRun(w http.ResponseWriter, r *http.Request, todo *Todo) {
+	w.Header().Set(DryRunHeader, "true")
+	if err := render.Render(w, r, todo); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+	}
+}
+
+// previewCreate returns the todo CreateTodo would store for req, with its timestamps set to now;
+// the position and the ID, unless the client set it, are assigned only when it's stored, so
+// they're not set
+func previewCreate(req *todomgrpb.Todo) *Todo {
+	req.CreatedAt = ptypes.TimestampNow()
+	req.UpdatedAt = req.CreatedAt
+	todo, _ := FromGRPCTodo(req)
+	if req.Id == 0 {
+		todo.ID = ""
+	}
+	return todo
+}
+
+// dryRunUpdate renders the todo UpdateTodo would store replacing the current todo with req. Like
+// todo-manager, it responds not found unless the owner of req can edit the todo and checks the
+// expected version of req, if it's set.
+func (t *Router) dryRunUpdate(w http.ResponseWriter, r *http.Request, req *todomgrpb.Todo) {
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	current, err := t.grpcClient.GetTodo(ctx, &todomgrpb.TodoIdReq{
+		Id:    req.GetId(),
+		Owner: req.GetOwner(),
+	})
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	if !canEdit(current, req.GetOwner()) {
+		render.Render(w, r, errTodoNotFound)
+		return
+	}
+	w.Header().Set("ETag", versionETag(current.GetVersion()))
+	if req.Version != 0 && req.Version != current.GetVersion() {
+		render.Render(w, r, errPreconditionFailed(fmt.Errorf("Todo version mismatch, current version is %d", current.GetVersion())))
+		return
+	}
+	preview := *current
+	preview.Text = req.Text
+	preview.Done = req.Done
+	preview.DueDate = req.DueDate
+	preview.Priority = req.Priority
+	if preview.Priority == todomgrpb.Priority_PRIORITY_UNSPECIFIED {
+		preview.Priority = todomgrpb.Priority_MEDIUM
+	}
+	preview.Tags = req.Tags
+	preview.Subtasks = req.Subtasks
+	preview.Recurrence = req.Recurrence
+	preview.UpdatedAt = ptypes.TimestampNow()
+	todo, _ := FromGRPCTodo(&preview)
+	renderDryRun(w, r, todo)
+}
+
+// canEdit checks if owner owns todo or it's shared with them with the edit permission
+func canEdit(todo *todomgrpb.Todo, owner string) bool {
+	if todo.GetOwner() == owner {
+		return true
+	}
+	for _, share := range todo.GetSharedWith() {
+		if share.GetUser() == owner && share.GetPermission() == todomgrpb.Permission_EDIT {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package todo
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// DefaultDueSoonWindow is the window of DueSoonTodos when the 'within' query param is omitted
+const DefaultDueSoonWindow = 24 * time.Hour
+
+// DueSoonTodos lists the todos of a user that aren't done and are due from now to the duration in the
+// 'within' query param, like 24h, sorted by due date; it takes the pagination, priority, tag and
+// fields query params of ListTodos, the other filters and the sorting are fixed
+func (t *Router) DueSoonTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	within, err := parseDueSoonWindow(r)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	req, err := t.newListTodosReq(w, r, owner)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	now := time.Now()
+	if req.DueAfter, err = ptypes.TimestampProto(now); err == nil {
+		req.DueBefore, err = ptypes.TimestampProto(now.Add(within))
+	}
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	req.Sort = "due_date"
+	req.Order = todomgrpb.ListTodosReq_ASC
+	req.Done = &wrappers.BoolValue{Value: false}
+	req.Deleted = false
+	req.Archived = false
+	t.listTodos(w, r, req)
+}
+
+// parseDueSoonWindow reads the 'within' query param, a positive duration defaulting to DefaultDueSoonWindow
+func parseDueSoonWindow(r *http.Request) (time.Duration, error) {
+	v := r.URL.Query().Get("within")
+	if v == "" {
+		return DefaultDueSoonWindow, nil
+	}
+	within, err := time.ParseDuration(v)
+	if err != nil || within <= 0 {
+		return 0, fmt.Errorf("within must be a positive duration like 24h, got %q", v)
+	}
+	return within, nil
+}
@@ -0,0 +1,120 @@
+package todo
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// DuplicateReq data model: the fields of the copy made by DuplicateTodo that replace the ones of
+// the source todo; all of them are optional
+type DuplicateReq struct {
+	Text     *string   `json:"text"`
+	DueDate  *string   `json:"due_date"`
+	Priority *string   `json:"priority"`
+	Tags     *[]string `json:"tags"`
+}
+
+// Bind allows to set additional properties on DuplicateReq object; not used here, the copy is
+// normalized like created todos
+func (d *DuplicateReq) Bind(r *http.Request) error {
+	return nil
+}
+
+// apply replaces the fields of todo with the ones set in the DuplicateReq
+func (d *DuplicateReq) apply(todo *Todo) {
+	if d.Text != nil {
+		todo.Text = *d.Text
+	}
+	if d.DueDate != nil {
+		todo.DueDate = *d.DueDate
+	}
+	if d.Priority != nil {
+		todo.Priority = *d.Priority
+	}
+	if d.Tags != nil {
+		todo.Tags = *d.Tags
+	}
+}
+
+// duplicateOf returns a new todo copying the text, the priority, the tags and the subtasks of
+// source; the copy and its subtasks aren't done, and it has no due date
+func duplicateOf(source *Todo) *Todo {
+	todo := &Todo{
+		Text:     source.Text,
+		Priority: source.Priority,
+		Tags:     source.Tags,
+	}
+	for _, subtask := range source.Subtasks {
+		todo.Subtasks = append(todo.Subtasks, Subtask{Text: subtask.Text})
+	}
+	return todo
+}
+
+// DuplicateTodo creates a new todo for the user, copying a todo they can view, like a template;
+// the optional JSON body is a DuplicateReq overriding the fields of the copy
+func (t *Router) DuplicateTodo(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	id, err := parseTodoID(chi.URLParam(r, "todoID"))
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	overrides := &DuplicateReq{}
+	if r.ContentLength != 0 {
+		// io.EOF is returned for empty bodies of unknown length
+		if err := render.Bind(r, overrides); err != nil && err != io.EOF {
+			render.Render(w, r, middleware.ErrInvalidRequest(err))
+			return
+		}
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	grpcSource, err := t.grpcClient.GetTodo(ctx, &todomgrpb.TodoIdReq{
+		Id:    id,
+		Owner: owner,
+	})
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	source, _ := FromGRPCTodo(grpcSource)
+	data := duplicateOf(source)
+	overrides.apply(data)
+	data.Bind(r)
+	if err := t.validateNewTodo(data); err != nil {
+		render.Render(w, r, errValidation(err))
+		return
+	}
+	if data.Priority == "" {
+		data.Priority = PriorityMedium
+	}
+	// todo-manager generates the ID
+	data.ID = "0"
+	newGrpcTodo, err := t.grpcClient.CreateTodo(ctx, data.ToGRPCTodo(owner))
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	todo, _ := FromGRPCTodo(newGrpcTodo)
+	// the copy is at the path of the source todo, like /v1/todo/123/duplicate
+	w.Header().Set("Location", path.Join(path.Dir(path.Dir(strings.TrimSuffix(r.URL.Path, "/"))), todo.ID))
+	w.Header().Set("ETag", versionETag(todo.version))
+	render.Status(r, http.StatusCreated)
+	if err := render.Render(w, r, todo); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	t.createOneCounter.WithLabelValues(owner).Inc()
+	t.webhooks.dispatch(WebhookEventCreated, owner, todo)
+}
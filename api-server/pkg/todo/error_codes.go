@@ -0,0 +1,190 @@
+package todo
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorCode is the machine-readable code of an error response, stable across releases so that
+// clients can branch on it; the message of the response is meant for humans and can change
+type ErrorCode string
+
+// The codes of error responses. New codes should be added only for errors clients can handle
+// differently from the ones already listed.
+const (
+	// CodeInvalidRequest is returned for requests that can't be parsed or have invalid params
+	CodeInvalidRequest ErrorCode = "INVALID_REQUEST"
+	// CodeValidationFailed is returned for request bodies with invalid fields, each of them
+	// listed with the code of its field error
+	CodeValidationFailed ErrorCode = "VALIDATION_FAILED"
+	// CodeAuthenticationFailed is returned for requests without valid credentials
+	CodeAuthenticationFailed ErrorCode = "AUTHENTICATION_FAILED"
+	// CodeForbidden is returned for requests the authenticated client isn't allowed to make
+	CodeForbidden ErrorCode = "FORBIDDEN"
+	// CodeQuotaExceeded is returned for requests creating todos when the owner has reached the
+	// max number of todos
+	CodeQuotaExceeded ErrorCode = "QUOTA_EXCEEDED"
+	// CodeNotFound is returned for requests to routes that don't exist
+	CodeNotFound ErrorCode = "NOT_FOUND"
+	// CodeTodoNotFound is returned for todos that don't exist or the owner of the request can't access
+	CodeTodoNotFound ErrorCode = "TODO_NOT_FOUND"
+	// CodeMethodNotAllowed is returned for methods a route doesn't support
+	CodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+	// CodeConflict is returned when the request conflicts with a todo already stored
+	CodeConflict ErrorCode = "CONFLICT"
+	// CodePreconditionFailed is returned when the todo changed since the version the request
+	// is based on
+	CodePreconditionFailed ErrorCode = "PRECONDITION_FAILED"
+	// CodeBodyTooLarge is returned for request bodies larger than allowed
+	CodeBodyTooLarge ErrorCode = "BODY_TOO_LARGE"
+	// CodeMessageTooLarge is returned when a todo is larger than the max size of the gRPC messages
+	// exchanged with todo-manager
+	CodeMessageTooLarge ErrorCode = "MESSAGE_TOO_LARGE"
+	// CodeUnprocessableEntity is returned for well-formed requests that can't be processed
+	CodeUnprocessableEntity ErrorCode = "UNPROCESSABLE_ENTITY"
+	// CodeRateLimited is returned for requests over the rate limit; they can be retried after
+	// the Retry-After header
+	CodeRateLimited ErrorCode = "RATE_LIMITED"
+	// CodeReadOnly is returned for requests changing todos while the API is in read-only mode
+	CodeReadOnly ErrorCode = "READ_ONLY"
+	// CodeServiceUnavailable is returned when the server can't serve the request at the moment
+	CodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+	// CodeBackendUnavailable is returned when todo-manager can't be reached
+	CodeBackendUnavailable ErrorCode = "BACKEND_UNAVAILABLE"
+	// CodeBackendTimeout is returned when todo-manager didn't answer in time
+	CodeBackendTimeout ErrorCode = "BACKEND_TIMEOUT"
+	// CodeInternalError is returned for bugs and unexpected failures of the server
+	CodeInternalError ErrorCode = "INTERNAL_ERROR"
+)
+
+// fieldErrorCodePrefix prefixes the name of the invalid field, in upper case, in the codes of
+// field errors, like INVALID_TEXT or INVALID_DUE_DATE
+const fieldErrorCodePrefix = "INVALID_"
+
+// errorCodes lists all the codes of error responses, documented in the OpenAPI spec
+var errorCodes = []ErrorCode{
+	CodeInvalidRequest, CodeValidationFailed, CodeAuthenticationFailed, CodeForbidden, CodeQuotaExceeded, CodeNotFound,
+	CodeTodoNotFound, CodeMethodNotAllowed, CodeConflict, CodePreconditionFailed, CodeBodyTooLarge,
+	CodeMessageTooLarge, CodeUnprocessableEntity, CodeRateLimited, CodeReadOnly, CodeServiceUnavailable,
+	CodeBackendUnavailable, CodeBackendTimeout, CodeInternalError,
+}
+
+// grpcErrorCodes maps the gRPC codes of errors returned by todo-manager to error codes; other
+// codes are reported as CodeInternalError
+var grpcErrorCodes = map[codes.Code]ErrorCode{
+	codes.InvalidArgument:    CodeInvalidRequest,
+	codes.Unauthenticated:    CodeAuthenticationFailed,
+	codes.NotFound:           CodeTodoNotFound,
+	codes.PermissionDenied:   CodeTodoNotFound,
+	codes.AlreadyExists:      CodeConflict,
+	codes.FailedPrecondition: CodePreconditionFailed,
+	codes.ResourceExhausted:  CodeRateLimited,
+	codes.Unavailable:        CodeBackendUnavailable,
+	codes.DeadlineExceeded:   CodeBackendTimeout,
+}
+
+// httpErrorCodes maps the HTTP statuses of error responses to the codes of the ones without a
+// more specific code
+var httpErrorCodes = map[int]ErrorCode{
+	http.StatusBadRequest:            CodeInvalidRequest,
+	http.StatusUnauthorized:          CodeAuthenticationFailed,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusPreconditionFailed:    CodePreconditionFailed,
+	http.StatusRequestEntityTooLarge: CodeBodyTooLarge,
+	http.StatusUnprocessableEntity:   CodeUnprocessableEntity,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusServiceUnavailable:    CodeServiceUnavailable,
+	http.StatusGatewayTimeout:        CodeBackendTimeout,
+}
+
+// codedError is an error with the code it's reported with
+type codedError struct {
+	code ErrorCode
+	error
+}
+
+// Unwrap returns the error with the code
+func (e *codedError) Unwrap() error {
+	return e.error
+}
+
+// withCode returns err reported with code in error responses
+func withCode(code ErrorCode, err error) error {
+	return &codedError{code: code, error: err}
+}
+
+// errorCode returns the code of an error response: the one of its error if it has one, the one
+// matching the gRPC code of errors returned by todo-manager or else the one of its HTTP status
+func errorCode(res *middleware.ErrResponse) ErrorCode {
+	var coded *codedError
+	if errors.As(res.Err, &coded) {
+		return coded.code
+	}
+	if s, ok := status.FromError(res.Err); ok && res.Err != nil {
+		if code, found := grpcErrorCodes[s.Code()]; found {
+			return code
+		}
+		return CodeInternalError
+	}
+	if code, found := httpErrorCodes[res.HTTPStatusCode]; found {
+		return code
+	}
+	if res.HTTPStatusCode < http.StatusInternalServerError {
+		return CodeInvalidRequest
+	}
+	return CodeInternalError
+}
+
+// fieldErrorCode returns the code of the error of a field: INVALID_ followed by the name of the
+// top level field in upper case, so that the errors of a list's items have the code of the list
+func fieldErrorCode(field string) ErrorCode {
+	name := strings.FieldsFunc(field, func(r rune) bool { return r == '.' || r == '[' })
+	if len(name) == 0 {
+		return CodeValidationFailed
+	}
+	return ErrorCode(fieldErrorCodePrefix + strings.ToUpper(name[0]))
+}
+
+// ErrorRes is the body of error responses
+type ErrorRes struct {
+	XMLName xml.Name  `json:"-" xml:"error"`
+	Status  string    `json:"status" xml:"status"`
+	Code    ErrorCode `json:"code" xml:"code"`
+	Error   string    `json:"error,omitempty" xml:"message,omitempty"`
+	// Errors lists the errors of all the invalid fields of requests failing validation
+	Errors ValidationErrors `json:"errors,omitempty" xml:"error,omitempty"`
+}
+
+// newErrorRes returns the body of an error response with its code
+func newErrorRes(res *middleware.ErrResponse) *ErrorRes {
+	return &ErrorRes{
+		Status: res.StatusText,
+		Code:   errorCode(res),
+		Error:  res.ErrorText,
+	}
+}
+
+// errorResBody returns the body of v if it's an error response, with its code; false is returned
+// for other values
+func errorResBody(v interface{}) (*ErrorRes, bool) {
+	switch value := v.(type) {
+	case *middleware.ErrResponse:
+		return newErrorRes(value), true
+	case *retryableErrResponse:
+		return newErrorRes(value.ErrResponse), true
+	case *ValidationErrRes:
+		body := newErrorRes(value.ErrResponse)
+		body.Errors = value.Errors
+		return body, true
+	}
+	return nil, false
+}
@@ -0,0 +1,251 @@
+package todo
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// clientErrorCodes lists the gRPC codes of errors caused by the client's request
+var clientErrorCodes = map[codes.Code]bool{
+	codes.InvalidArgument:    true,
+	codes.Unauthenticated:    true,
+	codes.PermissionDenied:   true,
+	codes.NotFound:           true,
+	codes.AlreadyExists:      true,
+	codes.FailedPrecondition: true,
+}
+
+// grpcHTTPStatuses maps the gRPC codes of errors returned by todo-manager to HTTP statuses;
+// other codes are reported as 500 Internal Server Error
+var grpcHTTPStatuses = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+}
+
+// errFromGRPC returns an error response for an error returned by the todo-manager service
+// with the HTTP status matching its gRPC code; responses of errors that can be retried later
+// are rendered with the Retry-After header
+func errFromGRPC(err error) render.Renderer {
+	return retryable(grpcErrResponse(err), defaultRetryAfter)
+}
+
+// grpcErrResponse returns the error response for an error returned by the todo-manager service.
+// Todos the owner of the request can't access are reported as not found, so that clients
+// guessing IDs can't tell they exist.
+func grpcErrResponse(err error) *middleware.ErrResponse {
+	code := status.Code(err)
+	if code == codes.NotFound || code == codes.PermissionDenied {
+		return errTodoNotFound
+	}
+	if isQuotaExceeded(err) {
+		return &middleware.ErrResponse{
+			Err:            withCode(CodeQuotaExceeded, err),
+			HTTPStatusCode: http.StatusForbidden,
+			StatusText:     "Forbidden.",
+			ErrorText:      status.Convert(err).Message(),
+		}
+	}
+	if isMessageTooLarge(err) {
+		return &middleware.ErrResponse{
+			Err:            withCode(CodeMessageTooLarge, err),
+			HTTPStatusCode: http.StatusRequestEntityTooLarge,
+			StatusText:     "Request entity too large.",
+			ErrorText:      "the todo is larger than the max gRPC message size allowed between the API and todo-manager",
+		}
+	}
+	httpStatus, found := grpcHTTPStatuses[code]
+	if !found {
+		httpStatus = http.StatusInternalServerError
+	}
+	return &middleware.ErrResponse{
+		Err:            err,
+		HTTPStatusCode: httpStatus,
+		StatusText:     http.StatusText(httpStatus) + ".",
+		ErrorText:      status.Convert(err).Message(),
+	}
+}
+
+// isQuotaExceeded checks if err is returned by todo-manager when the owner has reached the max
+// number of todos; it's a FailedPrecondition error told apart by its QuotaFailure details
+func isQuotaExceeded(err error) bool {
+	if status.Code(err) != codes.FailedPrecondition {
+		return false
+	}
+	for _, detail := range status.Convert(err).Details() {
+		if _, ok := detail.(*errdetails.QuotaFailure); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isMessageTooLarge checks if err is returned by gRPC for a message larger than the max size
+// allowed by the client or by todo-manager; both sides report it as ResourceExhausted, only
+// the message tells it apart from other errors with the code
+func isMessageTooLarge(err error) bool {
+	return status.Code(err) == codes.ResourceExhausted && strings.Contains(status.Convert(err).Message(), "message larger than max")
+}
+
+// errTodoNotFound is returned for todos that don't exist or the owner of the request can't access;
+// the error text is left empty like the one of other resources not found
+var errTodoNotFound = &middleware.ErrResponse{
+	Err:            withCode(CodeTodoNotFound, errors.New("todo not found")),
+	HTTPStatusCode: http.StatusNotFound,
+	StatusText:     middleware.ErrNotFound.StatusText,
+}
+
+// errPreconditionFailed is returned when the todo was modified since the version the client expects
+func errPreconditionFailed(err error) render.Renderer {
+	return &middleware.ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusPreconditionFailed,
+		StatusText:     "Precondition failed.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// errForbidden is returned for authenticated requests the client isn't allowed to make
+func errForbidden(err error) render.Renderer {
+	return &middleware.ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusForbidden,
+		StatusText:     "Forbidden.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// renderGRPCError logs an error returned by the todo-manager service and renders the matching
+// error response
+func (t *Router) renderGRPCError(w http.ResponseWriter, r *http.Request, err error) {
+	t.logGRPCError(r, err)
+	render.Render(w, r, errFromGRPC(err))
+}
+
+// logGRPCError logs an error returned by the todo-manager service for the request r; errors caused
+// by the client's request are not logged at error level
+func (t *Router) logGRPCError(r *http.Request, err error) {
+	entry := t.options.Logger.WithFields(logrus.Fields{
+		"req_id":    chimiddleware.GetReqID(r.Context()),
+		"uri":       r.RequestURI,
+		"grpc_code": status.Code(err).String(),
+	}).WithError(err)
+	if clientErrorCodes[status.Code(err)] {
+		entry.Info("todo-manager request failed")
+	} else {
+		entry.Error("todo-manager request failed")
+	}
+}
+
+// errUnprocessableEntity is returned for well-formed requests that can't be processed
+func errUnprocessableEntity(err error) render.Renderer {
+	return &middleware.ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusUnprocessableEntity,
+		StatusText:     "Unprocessable entity.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// defaultRetryAfter is the number of seconds clients are asked to wait before retrying requests
+// rejected with 429 Too Many Requests or 503 Service Unavailable, when there's no better estimate
+const defaultRetryAfter = 1
+
+// retryableErrResponse is the error response of a request that can be retried later, rendered
+// with the Retry-After header
+type retryableErrResponse struct {
+	*middleware.ErrResponse
+	retryAfter int
+}
+
+// Render sets the Retry-After header and the response status code
+func (e *retryableErrResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	setRetryAfter(w, e.retryAfter)
+	return e.ErrResponse.Render(w, r)
+}
+
+// retryable returns res rendered with Retry-After set to retryAfter seconds if its status is
+// 429 Too Many Requests or 503 Service Unavailable; other responses are returned unchanged
+func retryable(res *middleware.ErrResponse, retryAfter int) render.Renderer {
+	if res.HTTPStatusCode != http.StatusTooManyRequests && res.HTTPStatusCode != http.StatusServiceUnavailable {
+		return res
+	}
+	return &retryableErrResponse{ErrResponse: res, retryAfter: retryAfter}
+}
+
+// setRetryAfter sets the Retry-After header to seconds, or to defaultRetryAfter if seconds isn't positive
+func setRetryAfter(w http.ResponseWriter, seconds int) {
+	if seconds <= 0 {
+		seconds = defaultRetryAfter
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
+// errTooManyRequests is returned when the client exceeded its rate limit and can retry after
+// retryAfter seconds
+func errTooManyRequests(err error, retryAfter int) render.Renderer {
+	return retryable(&middleware.ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusTooManyRequests,
+		StatusText:     "Too many requests.",
+		ErrorText:      err.Error(),
+	}, retryAfter)
+}
+
+// errServiceUnavailable is returned when the server can't serve requests at the moment
+func errServiceUnavailable(err error) render.Renderer {
+	return retryable(&middleware.ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusServiceUnavailable,
+		StatusText:     "Service unavailable.",
+		ErrorText:      err.Error(),
+	}, defaultRetryAfter)
+}
+
+// errBodyTooLarge is the error of a request body larger than allowed, the same returned by
+// http.MaxBytesReader
+var errBodyTooLarge = errors.New("http: request body too large")
+
+// errRequestEntityTooLarge is returned when the request body is larger than allowed
+func errRequestEntityTooLarge(err error) render.Renderer {
+	return &middleware.ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusRequestEntityTooLarge,
+		StatusText:     "Request entity too large.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// errInternal is returned when the request failed because of a bug of the server
+func errInternal(err error) render.Renderer {
+	return &middleware.ErrResponse{
+		Err:            err,
+		HTTPStatusCode: http.StatusInternalServerError,
+		StatusText:     "Internal server error.",
+		ErrorText:      err.Error(),
+	}
+}
+
+// errMethodNotAllowed is returned for requests with a method the route doesn't support; it's an
+// invalid request reported with its own status
+func errMethodNotAllowed(err error) render.Renderer {
+	res := middleware.ErrInvalidRequest(err).(*middleware.ErrResponse)
+	res.HTTPStatusCode = http.StatusMethodNotAllowed
+	res.StatusText = "Method not allowed."
+	return res
+}
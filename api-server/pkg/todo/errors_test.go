@@ -0,0 +1,143 @@
+package todo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// logLines decodes the JSON log lines written to buf
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		line := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("log line %q isn't valid JSON: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// logLine returns the log line with the message msg, failing the test if there's none
+func logLine(t *testing.T, lines []map[string]interface{}, msg string) map[string]interface{} {
+	t.Helper()
+	for _, line := range lines {
+		if line["msg"] == msg {
+			return line
+		}
+	}
+	t.Fatalf("expected a log line %q, got %v", msg, lines)
+	return nil
+}
+
+func TestRequestLogging(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.Out = buf
+	logger.Formatter = &logrus.JSONFormatter{}
+	client := newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: Username})
+	router := newTestRouter(client, &RouterOptions{Logger: logger})
+	defer router.Close()
+	handler := chimiddleware.RequestID(middleware.NewStructuredLogger(logger, nil, nil)(router.GetRouter()))
+
+	expectStatus(t, serve(handler, http.MethodGet, "/1", "", RequestIDHeader, "req-1"), http.StatusOK)
+	line := logLine(t, logLines(t, buf), "request complete")
+	for field, want := range map[string]interface{}{
+		"http_method": "GET",
+		"uri":         "http://example.com/1",
+		"resp_status": float64(http.StatusOK),
+		"req_id":      "req-1",
+		"owner":       Username,
+	} {
+		if line[field] != want {
+			t.Errorf("expected %s %v in the request log, got %v", field, want, line[field])
+		}
+	}
+	if _, found := line["resp_elapsed_ms"]; !found {
+		t.Errorf("expected the duration in the request log")
+	}
+
+	client.err = status.Error(codes.Unavailable, "todo-manager is down")
+	expectStatus(t, serve(handler, http.MethodGet, "/1", "", RequestIDHeader, "req-2"), http.StatusServiceUnavailable)
+	line = logLine(t, logLines(t, buf), "todo-manager request failed")
+	for field, want := range map[string]interface{}{
+		"level":     "error",
+		"grpc_code": "Unavailable",
+		"error":     "rpc error: code = Unavailable desc = todo-manager is down",
+		"req_id":    "req-2",
+	} {
+		if line[field] != want {
+			t.Errorf("expected %s %v in the error log, got %v", field, want, line[field])
+		}
+	}
+
+	// errors caused by the client aren't logged at error level
+	client.err = status.Error(codes.NotFound, "todo not found")
+	expectStatus(t, serve(handler, http.MethodGet, "/1", ""), http.StatusNotFound)
+	if line = logLine(t, logLines(t, buf), "todo-manager request failed"); line["level"] != "info" {
+		t.Errorf("expected NotFound to be logged at info level, got %v", line["level"])
+	}
+}
+
+func TestGRPCErrResponse(t *testing.T) {
+	for code, want := range map[codes.Code]int{
+		codes.NotFound:           http.StatusNotFound,
+		codes.InvalidArgument:    http.StatusBadRequest,
+		codes.AlreadyExists:      http.StatusConflict,
+		codes.Unauthenticated:    http.StatusUnauthorized,
+		codes.FailedPrecondition: http.StatusPreconditionFailed,
+		codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+		codes.Unavailable:        http.StatusServiceUnavailable,
+		codes.Internal:           http.StatusInternalServerError,
+		codes.Unknown:            http.StatusInternalServerError,
+		codes.DataLoss:           http.StatusInternalServerError,
+	} {
+		if res := grpcErrResponse(status.Error(code, "failed")); res.HTTPStatusCode != want {
+			t.Errorf("expected %s to be mapped to %d, got %d", code, want, res.HTTPStatusCode)
+		}
+	}
+	if res := grpcErrResponse(status.Error(codes.InvalidArgument, "Text can't be empty")); res.ErrorText != "Text can't be empty" {
+		t.Errorf("expected the message of the gRPC error, got %q", res.ErrorText)
+	}
+}
+
+func TestHandlersGRPCErrors(t *testing.T) {
+	client := newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: Username})
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	for _, tc := range []struct {
+		method, target, body string
+	}{
+		{http.MethodGet, "/", ""},
+		{http.MethodGet, "/1", ""},
+		{http.MethodPost, "/", `{"text": "todo"}`},
+		{http.MethodPut, "/1", `{"text": "todo"}`},
+		{http.MethodDelete, "/1", ""},
+	} {
+		for code, want := range map[codes.Code]int{
+			codes.AlreadyExists: http.StatusConflict,
+			codes.Unavailable:   http.StatusServiceUnavailable,
+			codes.Internal:      http.StatusInternalServerError,
+		} {
+			client.err = status.Error(code, "failed")
+			if res := serve(handler, tc.method, tc.target, tc.body); res.Code != want {
+				t.Errorf("expected %s of %s %s to be mapped to %d, got %d", code, tc.method, tc.target, want, res.Code)
+			}
+		}
+	}
+}
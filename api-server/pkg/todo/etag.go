@@ -0,0 +1,106 @@
+package todo
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"google.golang.org/grpc/metadata"
+)
+
+// versionMetadataKey is the gRPC trailer metadata key todo-manager uses to report the current
+// version of a todo when an update is rejected because of a version mismatch
+const versionMetadataKey = "x-todo-version"
+
+// errInvalidIfMatch is returned when the If-Match header doesn't hold an ETag returned by the API
+var errInvalidIfMatch = errors.New("If-Match doesn't match any version of the todo")
+
+// errInvalidCreateIfNoneMatch is returned when the If-None-Match header of a create request isn't *
+var errInvalidCreateIfNoneMatch = errors.New("If-None-Match of a create request can only be *")
+
+// errCreateOnlyWithoutID is returned for create requests with If-None-Match: * and no todo ID
+var errCreateOnlyWithoutID = errors.New("If-None-Match: * requires the id of the todo to create")
+
+// errTodoExists is returned for create requests with If-None-Match: * when a todo with the same
+// ID already exists
+var errTodoExists = errors.New("a todo with the id already exists")
+
+// versionETag returns a strong ETag of a todo's version, which todo-manager increments on every update
+func versionETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// etagMatches checks if etag is listed in the value of an If-None-Match header;
+// weak ETags are compared with the weak comparison function
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// expectedVersion returns the todo version required by the If-Match header of r; 0 is returned
+// if any version is accepted
+func expectedVersion(r *http.Request) (uint64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	version, err := strconv.ParseUint(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version == 0 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
+
+// createOnly checks if a create request has the If-None-Match: * header, asking to create the todo
+// only if no todo with its ID exists; other ETags are rejected, since there's no todo to match yet
+func createOnly(r *http.Request) (bool, error) {
+	header := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if header == "" {
+		return false, nil
+	}
+	if header != "*" {
+		return false, errInvalidCreateIfNoneMatch
+	}
+	return true, nil
+}
+
+// setVersionETag sets the ETag header to the current todo version reported in trailer, if any
+func setVersionETag(w http.ResponseWriter, trailer metadata.MD) {
+	if version := trailer.Get(versionMetadataKey); len(version) > 0 {
+		w.Header().Set("ETag", `"`+version[0]+`"`)
+	}
+}
+
+// setLastModified sets the Last-Modified header to updatedAt and returns the modification time,
+// truncated to the precision of HTTP dates; the zero time is returned if updatedAt isn't valid
+func setLastModified(w http.ResponseWriter, updatedAt *timestamp.Timestamp) time.Time {
+	modified, err := ptypes.Timestamp(updatedAt)
+	if err != nil {
+		return time.Time{}
+	}
+	modified = modified.Truncate(time.Second)
+	w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	return modified
+}
+
+// notModified checks the conditional headers of a GET or HEAD request against the current ETag
+// and modification time of a todo. If-Modified-Since is ignored when If-None-Match is present.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		return etagMatches(match, etag)
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.IsZero() {
+		return false
+	}
+	return !modified.After(since)
+}
@@ -0,0 +1,76 @@
+package todo
+
+import (
+	"net/http"
+	"testing"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+func TestGetTodoETag(t *testing.T) {
+	client := newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: Username})
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodGet, "/1", "")
+	expectStatus(t, res, http.StatusOK)
+	etag := res.Header().Get("ETag")
+	if etag != `"1"` {
+		t.Fatalf(`expected ETag "1" of the first version, got %q`, etag)
+	}
+
+	for _, match := range []string{etag, "W/" + etag, `"7", ` + etag, "*"} {
+		res = serve(handler, http.MethodGet, "/1", "", "If-None-Match", match)
+		expectStatus(t, res, http.StatusNotModified)
+		if res.Body.Len() != 0 {
+			t.Errorf("expected no body with 304, got %q", res.Body.String())
+		}
+		if res.Header().Get("ETag") != etag {
+			t.Errorf("expected the ETag with 304, got %q", res.Header().Get("ETag"))
+		}
+	}
+	expectStatus(t, serve(handler, http.MethodGet, "/1", "", "If-None-Match", `"7"`), http.StatusOK)
+
+	// the ETag changes with every update
+	expectStatus(t, serve(handler, http.MethodPut, "/1", `{"text": "updated"}`), http.StatusOK)
+	res = serve(handler, http.MethodGet, "/1", "", "If-None-Match", etag)
+	expectStatus(t, res, http.StatusOK)
+	if res.Header().Get("ETag") != `"2"` {
+		t.Errorf(`expected ETag "2" after the update, got %q`, res.Header().Get("ETag"))
+	}
+}
+
+func TestUpdateTodoIfMatch(t *testing.T) {
+	client := newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: Username})
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	// both clients saw the first version, the second one updates it first
+	res := serve(handler, http.MethodPut, "/1", `{"text": "second"}`, "If-Match", `"1"`)
+	expectStatus(t, res, http.StatusOK)
+	if etag := res.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf(`expected ETag "2" of the update, got %q`, etag)
+	}
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		res = serve(handler, method, "/1", `{"text": "first"}`, "If-Match", `"1"`)
+		expectStatus(t, res, http.StatusPreconditionFailed)
+		if etag := res.Header().Get("ETag"); etag != `"2"` {
+			t.Errorf(`expected the current version "2" with 412 to %s, got %q`, method, etag)
+		}
+		body := &ErrorRes{}
+		decodeJSONRes(t, res, body)
+		if body.Code != CodePreconditionFailed {
+			t.Errorf("expected code %s, got %s", CodePreconditionFailed, body.Code)
+		}
+	}
+	if text := client.todo(1).Text; text != "second" {
+		t.Errorf("expected stale updates not to be applied, got text %q", text)
+	}
+
+	expectStatus(t, serve(handler, http.MethodPatch, "/1", `{"text": "first"}`, "If-Match", `"2"`), http.StatusOK)
+	expectStatus(t, serve(handler, http.MethodPut, "/1", `{"text": "any"}`, "If-Match", "*"), http.StatusOK)
+	expectStatus(t, serve(handler, http.MethodPut, "/1", `{"text": "any"}`, "If-Match", "yesterday"), http.StatusPreconditionFailed)
+}
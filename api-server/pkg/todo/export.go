@@ -0,0 +1,144 @@
+package todo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// exportCSVHeader is the header row of CSV exports
+var exportCSVHeader = []string{"id", "text", "done", "priority", "due_date", "created_at"}
+
+// todoWriter writes todos to a response in one format
+type todoWriter interface {
+	write(todo *Todo) error
+	close() error
+}
+
+// ExportTodos streams all the todos of a user as an attachment, writing them as they are received
+// from todo-manager; the format query param is one of: csv (default), json. The filters of ListTodos
+// are supported, while pagination is not.
+func (t *Router) ExportTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("format must be one of: csv, json, got %q", format)))
+		return
+	}
+	req := &todomgrpb.ListTodosReq{Owner: owner}
+	if err := parseFilters(r, req); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	// the export takes as long as the client needs to read it, so it's not limited by CallTimeout
+	stream, err := t.grpcClient.ListTodos(r.Context(), req)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	if _, err := stream.Header(); err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("todos-%s.%s", time.Now().UTC().Format("2006-01-02"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	var exporter todoWriter
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		exporter = newCSVExporter(w)
+	} else {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		exporter = &jsonExporter{w: w}
+	}
+	w.WriteHeader(http.StatusOK)
+
+	// the status is already sent, so errors can only end the export early
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			todo, _ := FromGRPCTodo(res)
+			err = exporter.write(todo)
+		}
+		if err != nil {
+			t.options.Logger.WithField("req_id", chimiddleware.GetReqID(r.Context())).WithError(err).Error("todo export failed")
+			return
+		}
+	}
+	if err := exporter.close(); err != nil {
+		t.options.Logger.WithField("req_id", chimiddleware.GetReqID(r.Context())).WithError(err).Error("todo export failed")
+	}
+}
+
+// csvExporter writes todos as CSV rows, starting with a header row
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func newCSVExporter(w io.Writer) *csvExporter {
+	e := &csvExporter{w: csv.NewWriter(w)}
+	e.w.Write(exportCSVHeader)
+	return e
+}
+
+func (e *csvExporter) write(todo *Todo) error {
+	e.w.Write([]string{todo.ID, todo.Text, strconv.FormatBool(todo.Done), todo.Priority, todo.DueDate, todo.CreatedAt})
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExporter) close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonExporter writes todos as a pretty-printed JSON array
+type jsonExporter struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonExporter) write(todo *Todo) error {
+	data, err := json.MarshalIndent(todo, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if e.count == 0 {
+		sep = "[\n  "
+	}
+	e.count++
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExporter) close() error {
+	end := "\n]\n"
+	if e.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
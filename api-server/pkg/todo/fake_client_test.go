@@ -0,0 +1,786 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// fakeClient is an in-memory todo-manager for the tests of the handlers. It keeps the todos of all
+// owners with the access rules of todo-manager: todos of other owners are not found unless they're
+// shared, soft-deleted todos are only listed from the trash. It records the calls it gets; when err
+// is set, every call fails with it instead.
+type fakeClient struct {
+	// calls the fake doesn't implement panic
+	todomgrpb.TodoManagerClient
+
+	lock     sync.Mutex
+	todos    map[uint64]*todomgrpb.Todo
+	comments map[uint64][]*todomgrpb.Comment
+	lastID   uint64
+	err      error
+	// calls counts the calls of every method
+	calls map[string]int
+	// listReq is the request of the last call listing or counting todos
+	listReq *todomgrpb.ListTodosReq
+	// md is the outgoing metadata of the last call
+	md metadata.MD
+	// events are sent to the clients of WatchTodos
+	events chan *todomgrpb.TodoEvent
+}
+
+// newFakeClient returns a fakeClient storing todos; the ones without an ID get the next free one
+func newFakeClient(todos ...*todomgrpb.Todo) *fakeClient {
+	f := &fakeClient{
+		todos:    map[uint64]*todomgrpb.Todo{},
+		comments: map[uint64][]*todomgrpb.Comment{},
+		calls:    map[string]int{},
+		events:   make(chan *todomgrpb.TodoEvent, 10),
+	}
+	for _, todo := range todos {
+		f.create(todo)
+	}
+	return f
+}
+
+// call records a call of method; the lock has to be held
+func (f *fakeClient) call(ctx context.Context, method string) error {
+	f.calls[method]++
+	f.md, _ = metadata.FromOutgoingContext(ctx)
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return f.err
+}
+
+// callCount returns the number of calls of method
+func (f *fakeClient) callCount(method string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.calls[method]
+}
+
+// todo returns a copy of the stored todo with id, or nil if there's none
+func (f *fakeClient) todo(id uint64) *todomgrpb.Todo {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if todo, found := f.todos[id]; found {
+		return proto.Clone(todo).(*todomgrpb.Todo)
+	}
+	return nil
+}
+
+// create stores a copy of todo as a new todo; the lock has to be held
+func (f *fakeClient) create(todo *todomgrpb.Todo) (*todomgrpb.Todo, error) {
+	if todo.GetText() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Text can't be empty")
+	}
+	created := proto.Clone(todo).(*todomgrpb.Todo)
+	if created.Id == 0 {
+		created.Id = f.lastID + 1
+	} else if _, found := f.todos[created.Id]; found {
+		return nil, status.Error(codes.AlreadyExists, "Todo with this ID already exists")
+	}
+	if created.Id > f.lastID {
+		f.lastID = created.Id
+	}
+	if created.CreatedAt == nil {
+		created.CreatedAt = ptypes.TimestampNow()
+	}
+	created.UpdatedAt = created.CreatedAt
+	if created.Priority == todomgrpb.Priority_PRIORITY_UNSPECIFIED {
+		created.Priority = todomgrpb.Priority_MEDIUM
+	}
+	created.Version = 1
+	created.Position = float64(created.Id)
+	f.todos[created.Id] = created
+	return proto.Clone(created).(*todomgrpb.Todo), nil
+}
+
+// find returns the stored todo with id if user can access it with the required permission; the
+// lock has to be held
+func (f *fakeClient) find(id uint64, user string, required todomgrpb.Permission) (*todomgrpb.Todo, error) {
+	todo, found := f.todos[id]
+	if !found || todo.DeletedAt != nil {
+		return nil, status.Error(codes.NotFound, "Todo not found")
+	}
+	if todo.Owner == user {
+		return todo, nil
+	}
+	for _, share := range todo.SharedWith {
+		if share.User != user {
+			continue
+		}
+		if share.Permission < required {
+			return nil, status.Error(codes.PermissionDenied, "Todo is shared with a lower permission")
+		}
+		return todo, nil
+	}
+	return nil, status.Error(codes.NotFound, "Todo not found")
+}
+
+// update marks todo as updated, rejecting the update if expectedVersion is set and doesn't match
+// the version of todo; the lock has to be held
+func (f *fakeClient) update(todo *todomgrpb.Todo, expectedVersion uint64, opts []grpc.CallOption) error {
+	if expectedVersion != 0 && expectedVersion != todo.Version {
+		for _, opt := range opts {
+			if trailer, ok := opt.(grpc.TrailerCallOption); ok {
+				*trailer.TrailerAddr = metadata.Pairs(versionMetadataKey, strconv.FormatUint(todo.Version, 10))
+			}
+		}
+		return status.Error(codes.FailedPrecondition, "Todo was modified since the expected version")
+	}
+	todo.Version++
+	todo.UpdatedAt = ptypes.TimestampNow()
+	return nil
+}
+
+// matches checks if todo is listed by req
+func matches(req *todomgrpb.ListTodosReq, todo *todomgrpb.Todo) bool {
+	if todo.Owner != req.Owner {
+		shared := false
+		for _, share := range todo.SharedWith {
+			shared = shared || share.User == req.Owner
+		}
+		if !shared {
+			return false
+		}
+	}
+	if (todo.DeletedAt != nil) != req.Deleted || todo.Archived != req.Archived {
+		return false
+	}
+	if req.Done != nil && todo.Done != req.Done.Value {
+		return false
+	}
+	if req.Priority != todomgrpb.Priority_PRIORITY_UNSPECIFIED && todo.Priority != req.Priority {
+		return false
+	}
+	if req.DueBefore != nil && (todo.DueDate == nil || !isAfter(req.DueBefore, todo.DueDate)) {
+		return false
+	}
+	if req.TextQuery != "" && !strings.Contains(strings.ToLower(todo.Text), strings.ToLower(req.TextQuery)) {
+		return false
+	}
+	for _, tag := range req.Tags {
+		tagged := false
+		for _, t := range todo.Tags {
+			tagged = tagged || t == tag
+		}
+		if !tagged {
+			return false
+		}
+	}
+	return true
+}
+
+// list returns the todos matching the filters of req in the order of their position, ignoring
+// pagination; the lock has to be held
+func (f *fakeClient) list(req *todomgrpb.ListTodosReq) []*todomgrpb.Todo {
+	f.listReq = proto.Clone(req).(*todomgrpb.ListTodosReq)
+	var todos []*todomgrpb.Todo
+	for _, todo := range f.todos {
+		if matches(req, todo) {
+			todos = append(todos, todo)
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool {
+		return todos[i].Position < todos[j].Position
+	})
+	return todos
+}
+
+func (f *fakeClient) CreateTodo(ctx context.Context, in *todomgrpb.Todo, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "CreateTodo"); err != nil {
+		return nil, err
+	}
+	return f.create(in)
+}
+
+func (f *fakeClient) BatchCreateTodos(ctx context.Context, opts ...grpc.CallOption) (todomgrpb.TodoManager_BatchCreateTodosClient, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "BatchCreateTodos"); err != nil {
+		return nil, err
+	}
+	return &fakeBatchCreateStream{fakeStream: fakeStream{ctx: ctx}, client: f}, nil
+}
+
+func (f *fakeClient) StreamCreateTodos(ctx context.Context, opts ...grpc.CallOption) (todomgrpb.TodoManager_StreamCreateTodosClient, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "StreamCreateTodos"); err != nil {
+		return nil, err
+	}
+	return &fakeStreamCreateStream{fakeStream: fakeStream{ctx: ctx}, client: f, results: make(chan *todomgrpb.CreateTodoRes, 100)}, nil
+}
+
+func (f *fakeClient) ListTodos(ctx context.Context, in *todomgrpb.ListTodosReq, opts ...grpc.CallOption) (todomgrpb.TodoManager_ListTodosClient, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "ListTodos"); err != nil {
+		return nil, err
+	}
+	todos := f.list(in)
+	header := metadata.Pairs(totalCountMetadataKey, strconv.Itoa(len(todos)))
+	if int(in.Offset) < len(todos) {
+		todos = todos[in.Offset:]
+	} else {
+		todos = nil
+	}
+	if in.Limit > 0 && int(in.Limit) < len(todos) {
+		todos = todos[:in.Limit]
+	}
+	stream := &fakeListStream{fakeStream: fakeStream{ctx: ctx, header: header}}
+	for _, todo := range todos {
+		stream.todos = append(stream.todos, proto.Clone(todo).(*todomgrpb.Todo))
+	}
+	return stream, nil
+}
+
+func (f *fakeClient) CountTodos(ctx context.Context, in *todomgrpb.ListTodosReq, opts ...grpc.CallOption) (*todomgrpb.CountTodosRes, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "CountTodos"); err != nil {
+		return nil, err
+	}
+	return &todomgrpb.CountTodosRes{Count: uint64(len(f.list(in)))}, nil
+}
+
+func (f *fakeClient) TodoStats(ctx context.Context, in *todomgrpb.ListTodosReq, opts ...grpc.CallOption) (*todomgrpb.TodoStatsRes, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "TodoStats"); err != nil {
+		return nil, err
+	}
+	res := &todomgrpb.TodoStatsRes{}
+	for _, todo := range f.list(in) {
+		res.Total++
+		if todo.Done {
+			res.Done++
+		}
+		switch todo.Priority {
+		case todomgrpb.Priority_LOW:
+			res.LowPriority++
+		case todomgrpb.Priority_HIGH:
+			res.HighPriority++
+		default:
+			res.MediumPriority++
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeClient) GetTodo(ctx context.Context, in *todomgrpb.TodoIdReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "GetTodo"); err != nil {
+		return nil, err
+	}
+	todo, err := f.find(in.Id, in.Owner, todomgrpb.Permission_VIEW)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(todo).(*todomgrpb.Todo), nil
+}
+
+func (f *fakeClient) UpdateTodo(ctx context.Context, in *todomgrpb.Todo, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "UpdateTodo"); err != nil {
+		return nil, err
+	}
+	todo, err := f.find(in.Id, in.Owner, todomgrpb.Permission_EDIT)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.update(todo, in.Version, opts); err != nil {
+		return nil, err
+	}
+	todo.Text, todo.Done, todo.DueDate, todo.Tags = in.Text, in.Done, in.DueDate, in.Tags
+	todo.Priority, todo.Subtasks, todo.Recurrence = in.Priority, in.Subtasks, in.Recurrence
+	return proto.Clone(todo).(*todomgrpb.Todo), nil
+}
+
+func (f *fakeClient) PatchTodo(ctx context.Context, in *todomgrpb.TodoPatch, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "PatchTodo"); err != nil {
+		return nil, err
+	}
+	todo, err := f.find(in.Id, in.Owner, todomgrpb.Permission_EDIT)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.update(todo, in.ExpectedVersion, opts); err != nil {
+		return nil, err
+	}
+	if in.Text != nil {
+		todo.Text = in.Text.Value
+	}
+	if in.Done != nil {
+		todo.Done = in.Done.Value
+	}
+	if in.Archived != nil {
+		todo.Archived = in.Archived.Value
+	}
+	if in.DueDate != nil || in.ClearDueDate {
+		todo.DueDate = in.DueDate
+	}
+	if in.Priority != todomgrpb.Priority_PRIORITY_UNSPECIFIED {
+		todo.Priority = in.Priority
+	}
+	if in.Tags != nil {
+		todo.Tags = in.Tags.Tags
+	}
+	if in.Recurrence != nil || in.ClearRecurrence {
+		todo.Recurrence = in.Recurrence
+	}
+	return proto.Clone(todo).(*todomgrpb.Todo), nil
+}
+
+func (f *fakeClient) DeleteTodo(ctx context.Context, in *todomgrpb.DeleteTodoReq, opts ...grpc.CallOption) (*todomgrpb.DeleteTodoRes, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "DeleteTodo"); err != nil {
+		return nil, err
+	}
+	todo, found := f.todos[in.Id]
+	if !found || todo.Owner != in.Owner || todo.DeletedAt != nil && !in.Hard {
+		return nil, status.Error(codes.NotFound, "Todo not found")
+	}
+	if in.Hard {
+		delete(f.todos, in.Id)
+	} else {
+		todo.DeletedAt = ptypes.TimestampNow()
+		todo.Version++
+	}
+	return &todomgrpb.DeleteTodoRes{Success: true}, nil
+}
+
+func (f *fakeClient) RestoreTodo(ctx context.Context, in *todomgrpb.TodoIdReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "RestoreTodo"); err != nil {
+		return nil, err
+	}
+	todo, found := f.todos[in.Id]
+	if !found || todo.Owner != in.Owner || todo.DeletedAt == nil {
+		return nil, status.Error(codes.NotFound, "Todo not found in the trash")
+	}
+	todo.DeletedAt = nil
+	todo.Version++
+	return proto.Clone(todo).(*todomgrpb.Todo), nil
+}
+
+func (f *fakeClient) WatchTodos(ctx context.Context, in *todomgrpb.WatchTodosReq, opts ...grpc.CallOption) (todomgrpb.TodoManager_WatchTodosClient, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "WatchTodos"); err != nil {
+		return nil, err
+	}
+	return &fakeWatchStream{fakeStream: fakeStream{ctx: ctx}, events: f.events}, nil
+}
+
+func (f *fakeClient) AddSubtask(ctx context.Context, in *todomgrpb.AddSubtaskReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "AddSubtask"); err != nil {
+		return nil, err
+	}
+	todo, err := f.find(in.Id, in.Owner, todomgrpb.Permission_EDIT)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.update(todo, in.ExpectedVersion, opts); err != nil {
+		return nil, err
+	}
+	todo.Subtasks = append(todo.Subtasks, in.Subtask)
+	return proto.Clone(todo).(*todomgrpb.Todo), nil
+}
+
+func (f *fakeClient) UpdateSubtask(ctx context.Context, in *todomgrpb.UpdateSubtaskReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "UpdateSubtask"); err != nil {
+		return nil, err
+	}
+	todo, err := f.find(in.Id, in.Owner, todomgrpb.Permission_EDIT)
+	if err != nil {
+		return nil, err
+	}
+	if int(in.Index) >= len(todo.Subtasks) {
+		return nil, status.Error(codes.NotFound, "Subtask not found")
+	}
+	if err := f.update(todo, in.ExpectedVersion, opts); err != nil {
+		return nil, err
+	}
+	subtask := todo.Subtasks[in.Index]
+	if in.Text != nil {
+		subtask.Text = in.Text.Value
+	}
+	if in.Done != nil {
+		subtask.Done = in.Done.Value
+	}
+	return proto.Clone(todo).(*todomgrpb.Todo), nil
+}
+
+func (f *fakeClient) RemoveSubtask(ctx context.Context, in *todomgrpb.SubtaskIdReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "RemoveSubtask"); err != nil {
+		return nil, err
+	}
+	todo, err := f.find(in.Id, in.Owner, todomgrpb.Permission_EDIT)
+	if err != nil {
+		return nil, err
+	}
+	if int(in.Index) >= len(todo.Subtasks) {
+		return nil, status.Error(codes.NotFound, "Subtask not found")
+	}
+	if err := f.update(todo, in.ExpectedVersion, opts); err != nil {
+		return nil, err
+	}
+	todo.Subtasks = append(todo.Subtasks[:in.Index], todo.Subtasks[in.Index+1:]...)
+	return proto.Clone(todo).(*todomgrpb.Todo), nil
+}
+
+func (f *fakeClient) AddComment(ctx context.Context, in *todomgrpb.AddCommentReq, opts ...grpc.CallOption) (*todomgrpb.Comment, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "AddComment"); err != nil {
+		return nil, err
+	}
+	if _, err := f.find(in.TodoId, in.Owner, todomgrpb.Permission_VIEW); err != nil {
+		return nil, err
+	}
+	comment := &todomgrpb.Comment{
+		Id:        uint64(len(f.comments[in.TodoId]) + 1),
+		TodoId:    in.TodoId,
+		Author:    in.Owner,
+		Text:      in.Text,
+		CreatedAt: ptypes.TimestampNow(),
+	}
+	f.comments[in.TodoId] = append(f.comments[in.TodoId], comment)
+	return proto.Clone(comment).(*todomgrpb.Comment), nil
+}
+
+func (f *fakeClient) ListComments(ctx context.Context, in *todomgrpb.ListCommentsReq, opts ...grpc.CallOption) (*todomgrpb.CommentList, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "ListComments"); err != nil {
+		return nil, err
+	}
+	if _, err := f.find(in.TodoId, in.Owner, todomgrpb.Permission_VIEW); err != nil {
+		return nil, err
+	}
+	res := &todomgrpb.CommentList{Total: uint64(len(f.comments[in.TodoId]))}
+	for _, comment := range f.comments[in.TodoId] {
+		res.Comments = append(res.Comments, proto.Clone(comment).(*todomgrpb.Comment))
+	}
+	return res, nil
+}
+
+func (f *fakeClient) ShareTodo(ctx context.Context, in *todomgrpb.ShareTodoReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "ShareTodo"); err != nil {
+		return nil, err
+	}
+	todo, found := f.todos[in.Id]
+	if !found || todo.Owner != in.Owner || todo.DeletedAt != nil {
+		return nil, status.Error(codes.NotFound, "Todo not found")
+	}
+	shares := []*todomgrpb.Share{{User: in.User, Permission: in.Permission}}
+	for _, share := range todo.SharedWith {
+		if share.User != in.User {
+			shares = append(shares, share)
+		}
+	}
+	todo.SharedWith = shares
+	return proto.Clone(todo).(*todomgrpb.Todo), nil
+}
+
+func (f *fakeClient) MoveTodo(ctx context.Context, in *todomgrpb.MoveTodoReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "MoveTodo"); err != nil {
+		return nil, err
+	}
+	todo, found := f.todos[in.Id]
+	if !found || todo.Owner != in.Owner || todo.DeletedAt != nil {
+		return nil, status.Error(codes.NotFound, "Todo not found")
+	}
+	if err := f.update(todo, in.ExpectedVersion, opts); err != nil {
+		return nil, err
+	}
+	// the todo goes before the one at the position, or last
+	todos := f.list(&todomgrpb.ListTodosReq{Owner: in.Owner})
+	todo.Position = todos[len(todos)-1].Position + 1
+	if int(in.Position) < len(todos) && todos[in.Position] != todo {
+		todo.Position = todos[in.Position].Position - 0.5
+	}
+	return proto.Clone(todo).(*todomgrpb.Todo), nil
+}
+
+func (f *fakeClient) SetTodosDone(ctx context.Context, in *todomgrpb.SetTodosDoneReq, opts ...grpc.CallOption) (*todomgrpb.CountTodosRes, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.call(ctx, "SetTodosDone"); err != nil {
+		return nil, err
+	}
+	res := &todomgrpb.CountTodosRes{}
+	for _, todo := range f.list(in.Filter) {
+		if todo.Owner == in.Filter.Owner && todo.Done != in.Done {
+			todo.Done = in.Done
+			todo.Version++
+			res.Count++
+		}
+	}
+	return res, nil
+}
+
+// fakeStream is the client side of a gRPC stream of fakeClient
+type fakeStream struct {
+	// methods the fake doesn't implement panic
+	grpc.ClientStream
+
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *fakeStream) Header() (metadata.MD, error) {
+	return s.header, nil
+}
+
+func (s *fakeStream) Trailer() metadata.MD {
+	return nil
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+// fakeListStream streams the todos listed by fakeClient
+type fakeListStream struct {
+	fakeStream
+	todos []*todomgrpb.Todo
+}
+
+func (s *fakeListStream) Recv() (*todomgrpb.Todo, error) {
+	if err := s.ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	if len(s.todos) == 0 {
+		return nil, io.EOF
+	}
+	todo := s.todos[0]
+	s.todos = s.todos[1:]
+	return todo, nil
+}
+
+// fakeBatchCreateStream creates the todos sent to it at once, once it's closed; none is created
+// if any of them can't be
+type fakeBatchCreateStream struct {
+	fakeStream
+	client *fakeClient
+	todos  []*todomgrpb.Todo
+}
+
+func (s *fakeBatchCreateStream) Send(todo *todomgrpb.Todo) error {
+	s.todos = append(s.todos, todo)
+	return nil
+}
+
+func (s *fakeBatchCreateStream) CloseAndRecv() (*todomgrpb.TodoList, error) {
+	s.client.lock.Lock()
+	defer s.client.lock.Unlock()
+	lastID := s.client.lastID
+	res := &todomgrpb.TodoList{}
+	for _, todo := range s.todos {
+		created, err := s.client.create(todo)
+		if err != nil {
+			for _, todo := range res.Todos {
+				delete(s.client.todos, todo.Id)
+			}
+			s.client.lastID = lastID
+			return nil, err
+		}
+		res.Todos = append(res.Todos, created)
+	}
+	return res, nil
+}
+
+// fakeStreamCreateStream creates every todo sent to it at once, streaming back its result
+type fakeStreamCreateStream struct {
+	fakeStream
+	client  *fakeClient
+	results chan *todomgrpb.CreateTodoRes
+}
+
+func (s *fakeStreamCreateStream) Send(todo *todomgrpb.Todo) error {
+	s.client.lock.Lock()
+	defer s.client.lock.Unlock()
+	res := &todomgrpb.CreateTodoRes{}
+	created, err := s.client.create(todo)
+	if err != nil {
+		res.Code, res.Error = uint32(status.Code(err)), status.Convert(err).Message()
+	} else {
+		res.Todo = created
+	}
+	s.results <- res
+	return nil
+}
+
+func (s *fakeStreamCreateStream) CloseSend() error {
+	close(s.results)
+	return nil
+}
+
+func (s *fakeStreamCreateStream) Recv() (*todomgrpb.CreateTodoRes, error) {
+	select {
+	case res, ok := <-s.results:
+		if !ok {
+			return nil, io.EOF
+		}
+		return res, nil
+	case <-s.ctx.Done():
+		return nil, status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+// fakeWatchStream streams the events sent to fakeClient.events until its context is done
+type fakeWatchStream struct {
+	fakeStream
+	events <-chan *todomgrpb.TodoEvent
+}
+
+func (s *fakeWatchStream) Recv() (*todomgrpb.TodoEvent, error) {
+	select {
+	case event := <-s.events:
+		return event, nil
+	case <-s.ctx.Done():
+		return nil, status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+// testOptions sets its own metrics registry and a logger discarding its logs in options, unless
+// they're set already; options can be nil
+func testOptions(options *RouterOptions) *RouterOptions {
+	if options == nil {
+		options = &RouterOptions{}
+	}
+	if options.Registerer == nil {
+		options.Registerer = prometheus.NewRegistry()
+	}
+	if options.Logger == nil {
+		logger := logrus.New()
+		logger.Out = ioutil.Discard
+		options.Logger = logger
+	}
+	return options
+}
+
+// newTestRouter returns a router using client with testOptions; options can be nil
+func newTestRouter(client todomgrpb.TodoManagerClient, options *RouterOptions) *Router {
+	return NewRouterWithClient(client, testOptions(options))
+}
+
+// testServer is a todo-manager gRPC server for the tests going through a real connection, like the
+// ones of the interceptors; the calls it doesn't implement return Unimplemented. When health is
+// set, it's served as the gRPC health check service.
+type testServer struct {
+	todomgrpb.UnimplementedTodoManagerServer
+
+	health  *health.Server
+	getTodo func(ctx context.Context, req *todomgrpb.TodoIdReq) (*todomgrpb.Todo, error)
+}
+
+func (s *testServer) GetTodo(ctx context.Context, req *todomgrpb.TodoIdReq) (*todomgrpb.Todo, error) {
+	if s.getTodo == nil {
+		return s.UnimplementedTodoManagerServer.GetTodo(ctx, req)
+	}
+	return s.getTodo(ctx, req)
+}
+
+// startTestServer serves srv on a random local port and returns its address; the returned server
+// has to be stopped by the test
+func startTestServer(t *testing.T, srv *testServer, opts ...grpc.ServerOption) (string, *grpc.Server) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("can't listen: %v", err)
+	}
+	server := grpc.NewServer(opts...)
+	todomgrpb.RegisterTodoManagerServer(server, srv)
+	if srv.health != nil {
+		healthpb.RegisterHealthServer(server, srv.health)
+	}
+	go server.Serve(listener)
+	return listener.Addr().String(), server
+}
+
+// asOwner returns handler serving requests as authenticated by owner
+func asOwner(owner string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(ContextWithOwner(r.Context(), owner)))
+	})
+}
+
+// serve sends a request to handler and returns the recorded response; headers are pairs of header
+// names and values, a JSON Content-Type is set for requests with a body
+func serve(handler http.Handler, method, target, body string, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	r := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// decodeJSONRes decodes the JSON body of res into v, failing the test if it can't
+func decodeJSONRes(t *testing.T, res *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(res.Body.Bytes(), v); err != nil {
+		t.Fatalf("response body %q isn't valid JSON: %v", res.Body.String(), err)
+	}
+}
+
+// expectStatus fails the test if the status of res isn't want
+func expectStatus(t *testing.T, res *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if res.Code != want {
+		t.Fatalf("expected status %d, got %d: %s", want, res.Code, res.Body.String())
+	}
+}
@@ -0,0 +1,118 @@
+package todo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/render"
+)
+
+// todoFields lists the names of the JSON fields of Todo, the ones that can be selected with the
+// fields query param
+var todoFields = jsonFieldNames(reflect.TypeOf(Todo{}))
+
+// jsonFieldNames returns the names of the fields of a struct type encoded to JSON
+func jsonFieldNames(typ reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// fieldSet is the set of todo fields selected with the fields query param; a nil fieldSet
+// selects all of them
+type fieldSet map[string]bool
+
+// parseFields reads the comma separated list of todo fields of the fields query param; nil is
+// returned if it's not present. Unknown field names are rejected, so that typos don't silently
+// drop fields from responses.
+func parseFields(r *http.Request) (fieldSet, error) {
+	v := r.URL.Query().Get("fields")
+	if v == "" {
+		return nil, nil
+	}
+	fields := fieldSet{}
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !todoFields[name] {
+			return nil, fmt.Errorf("fields has unknown field %q, allowed fields are: %s", name, strings.Join(sortedFieldNames(), ", "))
+		}
+		fields[name] = true
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must list at least one field")
+	}
+	return fields, nil
+}
+
+// sortedFieldNames returns the names of todoFields in alphabetical order
+func sortedFieldNames() []string {
+	names := make([]string, 0, len(todoFields))
+	for name := range todoFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// marshal returns the JSON encoding of todo with only the fields in the set; fields omitted
+// from the encoding because they're empty stay omitted
+func (f fieldSet) marshal(todo *Todo) ([]byte, error) {
+	data, err := json.Marshal(todo)
+	if err != nil || f == nil {
+		return data, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	selected := map[string]json.RawMessage{}
+	for name, value := range all {
+		if f[name] {
+			selected[name] = value
+		}
+	}
+	return json.Marshal(selected)
+}
+
+// projectedTodo is a todo encoded with only the selected fields
+type projectedTodo json.RawMessage
+
+// MarshalJSON implements json.Marshaler
+func (p projectedTodo) MarshalJSON() ([]byte, error) {
+	return p, nil
+}
+
+// Render allows to modify the way projectedTodo object is rendered to text; not used here
+func (p projectedTodo) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// project returns the renderer of todo with only the fields in the set. XML responses always
+// have all the fields, since the fields are selected by their JSON names.
+func (f fieldSet) project(r *http.Request, todo *Todo) (render.Renderer, error) {
+	if f == nil || render.GetAcceptedContentType(r) == render.ContentTypeXML {
+		return todo, nil
+	}
+	data, err := f.marshal(todo)
+	if err != nil {
+		return nil, err
+	}
+	return projectedTodo(data), nil
+}
@@ -0,0 +1,401 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// graphQLSchema is the GraphQL schema of the todo API; it's a subset of the REST API
+const graphQLSchema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	# todos lists the todos of the owner, like GET /v1/todo
+	todos(done: Boolean, limit: Int, offset: Int): [Todo!]!
+	# todo gets a todo of the owner, it's null if there's none with the ID
+	todo(id: ID!): Todo
+}
+
+type Mutation {
+	createTodo(todo: TodoInput!): Todo!
+	# updateTodo replaces a todo, like PUT /v1/todo/{todoID}
+	updateTodo(id: ID!, todo: TodoInput!): Todo!
+	# deleteTodo moves a todo to the trash or deletes it permanently when hard is true
+	deleteTodo(id: ID!, hard: Boolean): Boolean!
+}
+
+type Todo {
+	id: ID!
+	text: String!
+	done: Boolean!
+	createdAt: String
+	updatedAt: String
+	dueDate: String
+	priority: String
+	tags: [String!]!
+	deletedAt: String
+	archived: Boolean!
+	subtasks: [Subtask!]!
+	position: Float!
+}
+
+type Subtask {
+	text: String!
+	done: Boolean!
+}
+
+input TodoInput {
+	text: String!
+	done: Boolean
+	dueDate: String
+	priority: String
+	tags: [String!]
+	subtasks: [SubtaskInput!]
+}
+
+input SubtaskInput {
+	text: String!
+	done: Boolean!
+}
+`
+
+// graphQLRequest is the body of GraphQL requests
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, rejecting requests that aren't valid UTF-8
+func (req *graphQLRequest) UnmarshalJSON(data []byte) error {
+	if err := checkUTF8JSON(data); err != nil {
+		return err
+	}
+	type graphQLRequestJSON graphQLRequest
+	return json.Unmarshal(data, (*graphQLRequestJSON)(req))
+}
+
+// graphQLHandler executes GraphQL requests with the todos of the owner of the request
+type graphQLHandler struct {
+	router *Router
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandler returns the handler of the GraphQL endpoint, using the same todo-manager
+// client, owner of requests and middlewares as the REST routes; queries are POSTed too, so
+// mutations are rejected in read-only mode by their resolvers
+func (t *Router) NewGraphQLHandler() (http.Handler, error) {
+	schema, err := graphql.ParseSchema(graphQLSchema, &graphQLResolver{router: t}, graphql.UseFieldResolvers())
+	if err != nil {
+		return nil, err
+	}
+	handler := NewTimeoutMiddleware(t.options.HandlerTimeout)(&graphQLHandler{router: t, schema: schema})
+	handler = NewBodyLimitMiddleware(t.options.MaxBodyBytes)(handler)
+	return t.middlewares().Handler(handler), nil
+}
+
+// ServeHTTP implements http.Handler; errors of the query are reported in the GraphQL response
+func (h *graphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		render.Render(w, r, errMethodNotAllowed(errors.New("GraphQL requests have to be POSTed")))
+		return
+	}
+	owner, ok := h.router.owner(w, r)
+	if !ok {
+		return
+	}
+	req := &graphQLRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil && err != io.EOF {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if req.Query == "" {
+		render.Render(w, r, middleware.ErrInvalidRequest(errors.New("GraphQL query can't be empty")))
+		return
+	}
+	res := h.schema.Exec(ContextWithOwner(r.Context(), owner), req.Query, req.OperationName, req.Variables)
+	render.JSON(w, r, res)
+}
+
+// graphQLResolver resolves the queries and mutations of graphQLSchema
+type graphQLResolver struct {
+	router *Router
+}
+
+// graphQLError returns the error of a resolver for an error returned by todo-manager, with
+// the same message as the REST error response
+func (q *graphQLResolver) graphQLError(err error) error {
+	res := grpcErrResponse(err)
+	if res.HTTPStatusCode == http.StatusInternalServerError {
+		q.router.options.Logger.WithError(err).Error("todo-manager request failed")
+	}
+	if res.ErrorText == "" {
+		return errors.New(res.StatusText)
+	}
+	return errors.New(res.ErrorText)
+}
+
+// callContext returns the context for a gRPC call made while resolving a field, limited by CallTimeout
+// or the shorter timeout requested with X-Request-Timeout
+func (q *graphQLResolver) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, q.router.callTimeout(ctx))
+}
+
+// resolverOwner returns the owner of the request set in the context by graphQLHandler
+func resolverOwner(ctx context.Context) string {
+	owner, _ := OwnerFromContext(ctx)
+	return owner
+}
+
+// Todos resolves the todos query
+func (q *graphQLResolver) Todos(ctx context.Context, args struct {
+	Done   *bool
+	Limit  *int32
+	Offset *int32
+}) ([]*todoResolver, error) {
+	req := &todomgrpb.ListTodosReq{
+		Owner: resolverOwner(ctx),
+		Limit: uint32(q.router.DefaultPageSize),
+	}
+	if args.Done != nil {
+		req.Done = &wrappers.BoolValue{Value: *args.Done}
+	}
+	if args.Limit != nil {
+		if *args.Limit < 0 {
+			return nil, errors.New("limit must be a non-negative integer")
+		}
+		if *args.Limit > 0 {
+			req.Limit = uint32(*args.Limit)
+		}
+	}
+	if q.router.MaxPageSize > 0 && req.Limit > uint32(q.router.MaxPageSize) {
+		req.Limit = uint32(q.router.MaxPageSize)
+	}
+	if args.Offset != nil {
+		if *args.Offset < 0 {
+			return nil, errors.New("offset must be a non-negative integer")
+		}
+		req.Offset = uint32(*args.Offset)
+	}
+	ctx, cancel := q.callContext(ctx)
+	defer cancel()
+	stream, err := q.router.grpcClient.ListTodos(ctx, req)
+	if err != nil {
+		return nil, q.graphQLError(err)
+	}
+	todos := []*todoResolver{}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, q.graphQLError(err)
+		}
+		todo, _ := FromGRPCTodo(res)
+		todos = append(todos, &todoResolver{*todo})
+	}
+	q.router.getAllCounter.WithLabelValues(req.Owner).Inc()
+	return todos, nil
+}
+
+// Todo resolves the todo query
+func (q *graphQLResolver) Todo(ctx context.Context, args struct{ ID graphql.ID }) (*todoResolver, error) {
+	id, err := parseTodoID(string(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	owner := resolverOwner(ctx)
+	ctx, cancel := q.callContext(ctx)
+	defer cancel()
+	grpcTodo, err := q.router.grpcClient.GetTodo(ctx, &todomgrpb.TodoIdReq{
+		Id:    id,
+		Owner: owner,
+	})
+	if err != nil {
+		if res := grpcErrResponse(err); res == errTodoNotFound {
+			return nil, nil
+		}
+		return nil, q.graphQLError(err)
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	q.router.getOneCounter.WithLabelValues(owner).Inc()
+	return &todoResolver{*todo}, nil
+}
+
+// CreateTodo resolves the createTodo mutation
+func (q *graphQLResolver) CreateTodo(ctx context.Context, args struct{ Todo todoInput }) (*todoResolver, error) {
+	if q.router.ReadOnly() {
+		return nil, errReadOnly
+	}
+	data, err := q.validInput(args.Todo)
+	if err != nil {
+		return nil, err
+	}
+	if data.Priority == "" {
+		data.Priority = PriorityMedium
+	}
+	owner := resolverOwner(ctx)
+	ctx, cancel := q.callContext(ctx)
+	defer cancel()
+	grpcTodo, err := q.router.grpcClient.CreateTodo(ctx, data.ToGRPCTodo(owner))
+	if err != nil {
+		return nil, q.graphQLError(err)
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	q.router.createOneCounter.WithLabelValues(owner).Inc()
+	q.router.webhooks.dispatch(WebhookEventCreated, owner, todo)
+	return &todoResolver{*todo}, nil
+}
+
+// UpdateTodo resolves the updateTodo mutation
+func (q *graphQLResolver) UpdateTodo(ctx context.Context, args struct {
+	ID   graphql.ID
+	Todo todoInput
+}) (*todoResolver, error) {
+	if q.router.ReadOnly() {
+		return nil, errReadOnly
+	}
+	id, err := parseTodoID(string(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	data, err := q.validInput(args.Todo)
+	if err != nil {
+		return nil, err
+	}
+	owner := resolverOwner(ctx)
+	req := data.ToGRPCTodo(owner)
+	req.Id = id
+	ctx, cancel := q.callContext(ctx)
+	defer cancel()
+	grpcTodo, err := q.router.grpcClient.UpdateTodo(ctx, req)
+	if err != nil {
+		return nil, q.graphQLError(err)
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	q.router.updateOneCounter.WithLabelValues(owner).Inc()
+	q.router.webhooks.dispatch(WebhookEventUpdated, grpcTodo.GetOwner(), todo)
+	return &todoResolver{*todo}, nil
+}
+
+// DeleteTodo resolves the deleteTodo mutation
+func (q *graphQLResolver) DeleteTodo(ctx context.Context, args struct {
+	ID   graphql.ID
+	Hard *bool
+}) (bool, error) {
+	if q.router.ReadOnly() {
+		return false, errReadOnly
+	}
+	id, err := parseTodoID(string(args.ID))
+	if err != nil {
+		return false, err
+	}
+	owner := resolverOwner(ctx)
+	ctx, cancel := q.callContext(ctx)
+	defer cancel()
+	res, err := q.router.grpcClient.DeleteTodo(ctx, &todomgrpb.DeleteTodoReq{
+		Id:    id,
+		Owner: owner,
+		Hard:  args.Hard != nil && *args.Hard,
+	})
+	if err != nil {
+		return false, q.graphQLError(err)
+	}
+	q.router.deleteOneCounter.WithLabelValues(owner).Inc()
+	q.router.webhooks.dispatch(WebhookEventDeleted, owner, &Todo{ID: strconv.FormatUint(id, 10)})
+	return res.GetSuccess(), nil
+}
+
+// todoInput is the TodoInput of graphQLSchema
+type todoInput struct {
+	Text     string
+	Done     *bool
+	DueDate  *string
+	Priority *string
+	Tags     *[]string
+	Subtasks *[]Subtask
+}
+
+// validInput returns the Todo of a todo input, checked like the ones of REST requests
+func (q *graphQLResolver) validInput(input todoInput) (*Todo, error) {
+	data := &Todo{Text: normalizeText(input.Text)}
+	if input.Done != nil {
+		data.Done = *input.Done
+	}
+	if input.DueDate != nil {
+		data.DueDate = *input.DueDate
+	}
+	if input.Priority != nil {
+		data.Priority = *input.Priority
+	}
+	if input.Tags != nil {
+		data.Tags = normalizeTags(*input.Tags)
+	}
+	if input.Subtasks != nil {
+		data.Subtasks = *input.Subtasks
+	}
+	if err := q.router.validateTodo(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// todoResolver resolves the fields of a Todo of graphQLSchema; the nullable ones are null
+// instead of omitted when they're not set
+type todoResolver struct {
+	Todo
+}
+
+// ID resolves the id field
+func (r *todoResolver) ID() graphql.ID {
+	return graphql.ID(r.Todo.ID)
+}
+
+// CreatedAt resolves the createdAt field
+func (r *todoResolver) CreatedAt() *string {
+	return optionalField(r.Todo.CreatedAt)
+}
+
+// UpdatedAt resolves the updatedAt field
+func (r *todoResolver) UpdatedAt() *string {
+	return optionalField(r.Todo.UpdatedAt)
+}
+
+// DueDate resolves the dueDate field
+func (r *todoResolver) DueDate() *string {
+	return optionalField(r.Todo.DueDate)
+}
+
+// Priority resolves the priority field
+func (r *todoResolver) Priority() *string {
+	return optionalField(r.Todo.Priority)
+}
+
+// DeletedAt resolves the deletedAt field
+func (r *todoResolver) DeletedAt() *string {
+	return optionalField(r.Todo.DeletedAt)
+}
+
+// optionalField returns nil for empty values of a nullable field
+func optionalField(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,101 @@
+package todo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// graphQLRes is the response of the GraphQL endpoint
+type graphQLRes struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// serveGraphQL POSTs query to the GraphQL handler
+func serveGraphQL(handler http.Handler, query string, headers ...string) (*graphQLRes, int) {
+	body, _ := json.Marshal(graphQLRequest{Query: query})
+	res := serve(handler, http.MethodPost, "/graphql", string(body), headers...)
+	data := &graphQLRes{}
+	json.Unmarshal(res.Body.Bytes(), data)
+	return data, res.Code
+}
+
+func TestGraphQL(t *testing.T) {
+	client := newFakeClient(
+		&todomgrpb.Todo{Text: "first", Owner: Username},
+		&todomgrpb.Todo{Text: "other", Owner: "alice"},
+	)
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler, err := router.NewGraphQLHandler()
+	if err != nil {
+		t.Fatalf("can't build the GraphQL handler: %v", err)
+	}
+
+	res, code := serveGraphQL(handler, `{ todos { id text } todo(id: "1") { text done } }`)
+	if code != http.StatusOK || len(res.Errors) != 0 {
+		t.Fatalf("expected the query to succeed, got %d: %+v", code, res.Errors)
+	}
+	if want := `{"todos":[{"id":"1","text":"first"}],"todo":{"text":"first","done":false}}`; string(res.Data) != want {
+		t.Errorf("expected %s, got %s", want, res.Data)
+	}
+	if res, _ := serveGraphQL(handler, `{ todo(id: "2") { text } }`); string(res.Data) != `{"todo":null}` {
+		t.Errorf("expected another owner's todo to be null, got %s", res.Data)
+	}
+
+	res, _ = serveGraphQL(handler, `mutation { createTodo(todo: {text: "new", tags: ["home"]}) { id text tags } }`)
+	if want := `{"createTodo":{"id":"3","text":"new","tags":["home"]}}`; string(res.Data) != want {
+		t.Errorf("expected %s, got %s: %+v", want, res.Data, res.Errors)
+	}
+	if todo := client.todo(3); todo == nil || todo.Owner != Username {
+		t.Fatalf("expected the todo to be created for the owner of the request, got %v", todo)
+	}
+	res, _ = serveGraphQL(handler, `mutation { updateTodo(id: "3", todo: {text: "edited", done: true}) { done } }`)
+	if want := `{"updateTodo":{"done":true}}`; string(res.Data) != want {
+		t.Errorf("expected %s, got %s: %+v", want, res.Data, res.Errors)
+	}
+	res, _ = serveGraphQL(handler, `mutation { deleteTodo(id: "3", hard: true) }`)
+	if want := `{"deleteTodo":true}`; string(res.Data) != want {
+		t.Errorf("expected %s, got %s: %+v", want, res.Data, res.Errors)
+	}
+	if res, _ := serveGraphQL(handler, `mutation { createTodo(todo: {text: " "}) { id } }`); len(res.Errors) != 1 {
+		t.Errorf("expected an empty text to be rejected, got %s", res.Data)
+	}
+}
+
+func TestGraphQLMiddlewares(t *testing.T) {
+	router := newTestRouter(newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: Username}), &RouterOptions{RateLimit: 1, RateLimitBurst: 2})
+	defer router.Close()
+	handler, err := router.NewGraphQLHandler()
+	if err != nil {
+		t.Fatalf("can't build the GraphQL handler: %v", err)
+	}
+
+	body, _ := json.Marshal(graphQLRequest{Query: `{ todo(id: "1") { text } }`})
+	res := serve(handler, http.MethodPost, "/graphql", string(body), RequestIDHeader, "req-1")
+	expectStatus(t, res, http.StatusOK)
+	if id := res.Header().Get(RequestIDHeader); id != "req-1" {
+		t.Errorf("expected the request ID to be echoed, got %q", id)
+	}
+
+	// queries are POSTed too, so only mutations are rejected in read-only mode
+	router.SetReadOnly(true)
+	if res, _ := serveGraphQL(asOwner("alice", handler), `mutation { createTodo(todo: {text: "new"}) { id } }`); len(res.Errors) != 1 || res.Errors[0].Message != errReadOnly.Error() {
+		t.Errorf("expected the mutation to be rejected in read-only mode, got %s: %+v", res.Data, res.Errors)
+	}
+	router.SetReadOnly(false)
+
+	// the REST routes and the GraphQL endpoint share the rate limits of owners
+	expectStatus(t, serve(router.GetRouter(), http.MethodGet, "/1", ""), http.StatusOK)
+	if _, code := serveGraphQL(handler, `{ todo(id: "1") { text } }`); code != http.StatusTooManyRequests {
+		t.Errorf("expected the GraphQL request to be rate limited, got %d", code)
+	}
+	if _, code := serveGraphQL(asOwner("alice", handler), `{ todos { id } }`); code != http.StatusOK {
+		t.Errorf("expected other owners not to be rate limited, got %d", code)
+	}
+}
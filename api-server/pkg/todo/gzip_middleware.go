@@ -0,0 +1,132 @@
+package todo
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+)
+
+// DefaultGzipMinSize is the default size of response in bytes above which it gets compressed
+const DefaultGzipMinSize = 1024
+
+// NewGzipMiddleware returns a middleware compressing responses larger than minSize bytes
+// for clients accepting gzip encoding. Only the first minSize bytes of a response are
+// buffered, so streamed responses are not held in memory.
+func NewGzipMiddleware(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			// upgraded connections don't have a response body to compress
+			if r.Method == http.MethodHead || !acceptsGzip(r) || isWebSocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			gw := &gzipResponseWriter{
+				ResponseWriter: w,
+				minSize:        minSize,
+				status:         http.StatusOK,
+			}
+			defer gw.Close()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip checks if the client accepts gzip encoded responses
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(strings.ReplaceAll(enc, " ", ""), ";")
+		if parts[0] == "gzip" && (len(parts) == 1 || parts[1] != "q=0") {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter buffers the beginning of a response until it's known if its size
+// exceeds minSize and then either compresses it or writes it as-is
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+	buf     []byte
+	status  int
+	decided bool
+	gz      *gzip.Writer
+}
+
+// WriteHeader delays sending the status code until it's decided if the response is compressed
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if !w.decided {
+		w.status = code
+	}
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends buffered data to the client once it's decided if the response is compressed; until
+// minSize bytes are buffered, flushes are held back, so small streamed responses like short lists
+// aren't compressed. Event streams can't wait for their events to add up and have no end, so they
+// start compression on their first flush.
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+			return
+		}
+		w.start(true)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes out any buffered data and finishes the compressed stream
+func (w *gzipResponseWriter) Close() error {
+	if !w.decided {
+		if err := w.start(false); err != nil {
+			return err
+		}
+	}
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	return nil
+}
+
+// start sends the headers and the buffered data, compressing them if requested and possible
+func (w *gzipResponseWriter) start(compress bool) error {
+	w.decided = true
+	h := w.ResponseWriter.Header()
+	if h.Get("Content-Encoding") != "" || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		compress = false
+	}
+	if compress {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.Write(buf)
+	return err
+}
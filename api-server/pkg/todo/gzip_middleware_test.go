@@ -0,0 +1,122 @@
+package todo
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gunzip returns the decompressed body of res, failing the test if it's not gzip encoded
+func gunzip(t *testing.T, res *httptest.ResponseRecorder) string {
+	t.Helper()
+	if encoding := res.Header().Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("expected gzip encoded response, got Content-Encoding %q", encoding)
+	}
+	reader, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatalf("can't read gzip stream: %v", err)
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("can't decompress body: %v", err)
+	}
+	return string(body)
+}
+
+func TestGzipMiddleware(t *testing.T) {
+	large := strings.Repeat("todo ", 1000)
+	handler := NewGzipMiddleware(DefaultGzipMinSize)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(r.URL.Query().Get("body")))
+	}))
+
+	res := serve(handler, http.MethodGet, "/?body="+strings.ReplaceAll(large, " ", "+"), "", "Accept-Encoding", "gzip, deflate")
+	expectStatus(t, res, http.StatusTeapot)
+	if body := gunzip(t, res); body != large {
+		t.Errorf("expected decompressed body to match, got %d bytes", len(body))
+	}
+	if vary := res.Header().Get("Vary"); vary != "Accept-Encoding" {
+		t.Errorf("expected Vary: Accept-Encoding, got %q", vary)
+	}
+
+	res = serve(handler, http.MethodGet, "/?body=small", "", "Accept-Encoding", "gzip")
+	expectStatus(t, res, http.StatusTeapot)
+	if res.Header().Get("Content-Encoding") != "" || res.Body.String() != "small" {
+		t.Errorf("expected small response not to be compressed, got %q", res.Body.String())
+	}
+
+	for _, accept := range []string{"", "deflate", "gzip;q=0"} {
+		res = serve(handler, http.MethodGet, "/?body="+strings.ReplaceAll(large, " ", "+"), "", "Accept-Encoding", accept)
+		if res.Header().Get("Content-Encoding") != "" || res.Body.String() != large {
+			t.Errorf("expected response not to be compressed for Accept-Encoding %q", accept)
+		}
+	}
+}
+
+func TestGzipMiddlewareListTodos(t *testing.T) {
+	router := newTestRouter(newFakeClient(fakeTodos(Username, 100)...), nil)
+	defer router.Close()
+	handler := NewGzipMiddleware(DefaultGzipMinSize)(router.GetRouter())
+
+	want := serve(handler, http.MethodGet, "/?limit=100", "").Body.String()
+	res := serve(handler, http.MethodGet, "/?limit=100", "", "Accept-Encoding", "gzip")
+	expectStatus(t, res, http.StatusOK)
+	if body := gunzip(t, res); body != want {
+		t.Errorf("expected decompressed list to match the uncompressed one, got %q", body)
+	}
+}
+
+func TestGzipMiddlewareFlush(t *testing.T) {
+	chunk := strings.Repeat("t", 100)
+	stream := func(chunks int, contentType string) *httptest.ResponseRecorder {
+		handler := NewGzipMiddleware(DefaultGzipMinSize)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			for i := 0; i < chunks; i++ {
+				w.Write([]byte(chunk))
+				w.(http.Flusher).Flush()
+			}
+		}))
+		return serve(handler, http.MethodGet, "/", "", "Accept-Encoding", "gzip")
+	}
+
+	// flushes are held back until minSize bytes are written
+	res := stream(3, "application/json")
+	if res.Header().Get("Content-Encoding") != "" || res.Body.String() != strings.Repeat(chunk, 3) {
+		t.Errorf("expected a small flushed response not to be compressed, got %q", res.Body.String())
+	}
+	res = stream(20, "application/json")
+	if body := gunzip(t, res); body != strings.Repeat(chunk, 20) {
+		t.Errorf("expected a large flushed response to be compressed, got %d bytes", len(body))
+	}
+	if !res.Flushed {
+		t.Errorf("expected the response to be flushed once compressed")
+	}
+
+	// event streams are compressed from their first flush
+	res = stream(1, "text/event-stream")
+	if body := gunzip(t, res); body != chunk {
+		t.Errorf("expected the event stream to be compressed, got %q", body)
+	}
+}
+
+func TestGzipMiddlewareSmallList(t *testing.T) {
+	router := newTestRouter(newFakeClient(fakeTodos(Username, 2)...), nil)
+	defer router.Close()
+	handler := NewGzipMiddleware(DefaultGzipMinSize)(router.GetRouter())
+
+	res := serve(handler, http.MethodGet, "/", "", "Accept-Encoding", "gzip")
+	expectStatus(t, res, http.StatusOK)
+	if encoding := res.Header().Get("Content-Encoding"); encoding != "" {
+		t.Errorf("expected a list smaller than the min size not to be compressed, got Content-Encoding %q", encoding)
+	}
+	var todos []Todo
+	decodeJSONRes(t, res, &todos)
+	if len(todos) != 2 {
+		t.Errorf("expected 2 todos, got %d", len(todos))
+	}
+}
@@ -0,0 +1,45 @@
+package todo
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+// HeadTodo responds with the status and headers GetTodo would respond with for the same
+// request, without the body
+func (t *Router) HeadTodo(w http.ResponseWriter, r *http.Request) {
+	todo, owner, ok := t.lookupTodo(w, r)
+	if !ok {
+		return
+	}
+	// the body is encoded only to report its Content-Length
+	hw := &headResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	if err := render.Render(hw, r, todo); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(hw.length))
+	w.WriteHeader(hw.status)
+	t.getOneCounter.WithLabelValues(owner).Inc()
+}
+
+// headResponseWriter counts and discards the body written to it and holds back its status
+type headResponseWriter struct {
+	http.ResponseWriter
+	status int
+	length int
+}
+
+// WriteHeader records the status code to send once the length of the body is known
+func (w *headResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+// Write counts the bytes of the body without writing them
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	w.length += len(b)
+	return len(b), nil
+}
@@ -0,0 +1,90 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// todoManagerDependency is the name of the todo-manager dependency in readiness responses
+	todoManagerDependency = "todo-manager"
+	// readinessTimeout limits the time spent on the health check call to todo-manager
+	readinessTimeout = time.Second
+)
+
+// HealthRes is the response of the health endpoints; Unhealthy maps the names of failing
+// dependencies to the reason of their failure. Connection is the state of the gRPC connection
+// to todo-manager reported by readiness checks, if the router owns one, and CircuitBreaker the
+// state of the circuit breaker of the calls to it: closed, half-open or open.
+type HealthRes struct {
+	Status         string            `json:"status"`
+	Unhealthy      map[string]string `json:"unhealthy,omitempty"`
+	Connection     string            `json:"connection,omitempty"`
+	CircuitBreaker string            `json:"circuit_breaker,omitempty"`
+}
+
+// Render sets the response status code depending on health status
+func (h *HealthRes) Render(w http.ResponseWriter, r *http.Request) error {
+	if len(h.Unhealthy) > 0 {
+		setRetryAfter(w, defaultRetryAfter)
+		render.Status(r, http.StatusServiceUnavailable)
+	}
+	return nil
+}
+
+// Healthz reports that the process is up and serving HTTP requests
+func (t *Router) Healthz(w http.ResponseWriter, r *http.Request) {
+	render.Render(w, r, &HealthRes{Status: "ok"})
+}
+
+// Readyz reports if todo-manager can serve requests, based on the gRPC connection state
+// and the gRPC health check service of todo-manager; 503 is returned if it can't, or if the
+// router is shutting down
+func (t *Router) Readyz(w http.ResponseWriter, r *http.Request) {
+	res := &HealthRes{Status: "ok"}
+	select {
+	case <-t.shutdown:
+		render.Render(w, r, &HealthRes{Status: "shutting down", Unhealthy: map[string]string{"api-server": errShuttingDown.Error()}})
+		return
+	default:
+	}
+	if err := t.checkTodoManager(r.Context()); err != nil {
+		res.Status = "unavailable"
+		res.Unhealthy = map[string]string{todoManagerDependency: err.Error()}
+	}
+	if t.conn != nil {
+		res.Connection = t.conn.GetState().String()
+	}
+	if t.breaker != nil {
+		res.CircuitBreaker = t.breaker.State().String()
+	}
+	render.Render(w, r, res)
+}
+
+// checkTodoManager returns an error if todo-manager is not reachable or not serving;
+// routers using a pre-built client are always considered ready
+func (t *Router) checkTodoManager(ctx context.Context) error {
+	if t.conn == nil {
+		return nil
+	}
+	switch state := t.conn.GetState(); state {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return fmt.Errorf("gRPC connection is in state %s", state)
+	}
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	res, err := t.healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return fmt.Errorf("health check failed: %v", err)
+	}
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("health check returned status %s", res.GetStatus())
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthz(t *testing.T) {
+	router := newTestRouter(newFakeClient(), nil)
+	defer router.Close()
+
+	res := serve(http.HandlerFunc(router.Healthz), http.MethodGet, "/healthz", "")
+	expectStatus(t, res, http.StatusOK)
+	body := &HealthRes{}
+	decodeJSONRes(t, res, body)
+	if body.Status != "ok" {
+		t.Errorf("expected status ok, got %q", body.Status)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	healthServer := health.NewServer()
+	addr, server := startTestServer(t, &testServer{health: healthServer})
+	defer server.Stop()
+	router, err := NewRouter(addr, testOptions(&RouterOptions{Insecure: true}))
+	if err != nil {
+		t.Fatalf("can't create the router: %v", err)
+	}
+	defer router.Close()
+	readyz := http.HandlerFunc(router.Readyz)
+
+	res := serve(readyz, http.MethodGet, "/readyz", "")
+	expectStatus(t, res, http.StatusOK)
+	body := &HealthRes{}
+	decodeJSONRes(t, res, body)
+	if body.Status != "ok" || body.Connection != "READY" {
+		t.Errorf("expected status ok and a ready connection, got %+v", body)
+	}
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	res = serve(readyz, http.MethodGet, "/readyz", "")
+	expectStatus(t, res, http.StatusServiceUnavailable)
+	if res.Header().Get("Retry-After") == "" {
+		t.Errorf("expected a Retry-After header")
+	}
+	body = &HealthRes{}
+	decodeJSONRes(t, res, body)
+	if body.Status != "unavailable" || !strings.Contains(body.Unhealthy[todoManagerDependency], "NOT_SERVING") {
+		t.Errorf("expected todo-manager to be reported as not serving, got %+v", body)
+	}
+
+	server.Stop()
+	res = serve(readyz, http.MethodGet, "/readyz", "")
+	expectStatus(t, res, http.StatusServiceUnavailable)
+	body = &HealthRes{}
+	decodeJSONRes(t, res, body)
+	if body.Unhealthy[todoManagerDependency] == "" {
+		t.Errorf("expected todo-manager to be reported as unreachable, got %+v", body)
+	}
+}
+
+func TestReadyzShuttingDown(t *testing.T) {
+	router := newTestRouter(newFakeClient(), nil)
+	readyz := http.HandlerFunc(router.Readyz)
+	expectStatus(t, serve(readyz, http.MethodGet, "/readyz", ""), http.StatusOK)
+
+	if err := router.Shutdown(context.Background()); err != nil {
+		t.Fatalf("can't shut down: %v", err)
+	}
+	res := serve(readyz, http.MethodGet, "/readyz", "")
+	expectStatus(t, res, http.StatusServiceUnavailable)
+	body := &HealthRes{}
+	decodeJSONRes(t, res, body)
+	if body.Status != "shutting down" {
+		t.Errorf("expected status shutting down, got %q", body.Status)
+	}
+}
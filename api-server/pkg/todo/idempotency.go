@@ -0,0 +1,141 @@
+package todo
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+const (
+	// IdempotencyKeyHeader is the header clients use to make retries of CreateTodo safe
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// DefaultIdempotencyKeyTTL is the default time for which the result of a request with
+	// an idempotency key is remembered
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	// maxIdempotencyKeyLength is the max length of an idempotency key
+	maxIdempotencyKeyLength = 255
+)
+
+var (
+	// errIdempotencyKeyTooLong is returned for keys longer than maxIdempotencyKeyLength
+	errIdempotencyKeyTooLong = errors.New("Idempotency-Key can't be longer than 255 characters")
+	// errIdempotencyKeyReused is returned when a key is sent again with a different request
+	errIdempotencyKeyReused = errors.New("Idempotency-Key was already used with a different request")
+)
+
+// idempotencyKeyCtxKey is the context key under which the idempotency key of the request is stored
+type idempotencyKeyCtxKey struct{}
+
+// hasIdempotencyKey checks if ctx belongs to a call made for a request with an idempotency key
+func hasIdempotencyKey(ctx context.Context) bool {
+	key, _ := ctx.Value(idempotencyKeyCtxKey{}).(string)
+	return key != ""
+}
+
+type idempotencyEntry struct {
+	fingerprint [sha1.Size]byte
+	// done is closed when the request that created the entry finished
+	done    chan struct{}
+	todo    *todomgrpb.Todo
+	expires time.Time
+}
+
+// idempotencyStore remembers the results of create requests by owner and idempotency key
+type idempotencyStore struct {
+	ttl       time.Duration
+	lock      sync.Mutex
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+	return &idempotencyStore{
+		ttl:       ttl,
+		entries:   map[string]*idempotencyEntry{},
+		lastSweep: time.Now(),
+	}
+}
+
+// fingerprintRequest returns a hash identifying the content of a create request
+func fingerprintRequest(req interface{}) ([sha1.Size]byte, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return [sha1.Size]byte{}, err
+	}
+	return sha1.Sum(data), nil
+}
+
+// claim returns the entry stored for key and if it was created by this call, so the request
+// has to be executed by the caller
+func (s *idempotencyStore) claim(key string, fingerprint [sha1.Size]byte, now time.Time) (*idempotencyEntry, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// drop expired entries, so the map doesn't grow forever
+	if now.Sub(s.lastSweep) > s.ttl {
+		for k, e := range s.entries {
+			if e.todo != nil && now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	if e, found := s.entries[key]; found && (e.todo == nil || now.Before(e.expires)) {
+		return e, false
+	}
+	e := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	s.entries[key] = e
+	return e, true
+}
+
+// finish stores the result of the request of a claimed entry; failed requests are forgotten,
+// so they can be retried with the same key
+func (s *idempotencyStore) finish(key string, e *idempotencyEntry, todo *todomgrpb.Todo, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if todo == nil {
+		delete(s.entries, key)
+	} else {
+		e.todo = todo
+		e.expires = now.Add(s.ttl)
+	}
+	close(e.done)
+}
+
+// do runs create once for each owner and idempotency key and returns its result; repeated
+// requests with the same key get the result of the first successful one, with replayed set
+func (s *idempotencyStore) do(ctx context.Context, owner, key string, req interface{},
+	create func(ctx context.Context) (*todomgrpb.Todo, error)) (todo *todomgrpb.Todo, replayed bool, err error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, false, errIdempotencyKeyTooLong
+	}
+	fingerprint, err := fingerprintRequest(req)
+	if err != nil {
+		return nil, false, err
+	}
+	scopedKey := owner + "\x00" + key
+	for {
+		e, claimed := s.claim(scopedKey, fingerprint, time.Now())
+		if e.fingerprint != fingerprint {
+			return nil, false, errIdempotencyKeyReused
+		}
+		if claimed {
+			todo, err := create(context.WithValue(ctx, idempotencyKeyCtxKey{}, key))
+			s.finish(scopedKey, e, todo, time.Now())
+			return todo, false, err
+		}
+		// another request with the same key is in progress, wait for its result
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+		if e.todo != nil {
+			return e.todo, true, nil
+		}
+	}
+}
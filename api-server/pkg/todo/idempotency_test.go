@@ -0,0 +1,116 @@
+package todo
+
+import (
+	"context"
+	"crypto/sha1"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+func TestCreateTodoIdempotencyKey(t *testing.T) {
+	client := newFakeClient()
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	first := serve(handler, http.MethodPost, "/", `{"text": "todo"}`, IdempotencyKeyHeader, "key-1")
+	expectStatus(t, first, http.StatusCreated)
+	second := serve(handler, http.MethodPost, "/", `{"text": "todo"}`, IdempotencyKeyHeader, "key-1")
+	expectStatus(t, second, http.StatusCreated)
+	if calls := client.callCount("CreateTodo"); calls != 1 {
+		t.Fatalf("expected a single create, got %d", calls)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("expected the original result %s, got %s", first.Body.String(), second.Body.String())
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" || second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected only the second response to be replayed")
+	}
+
+	expectStatus(t, serve(handler, http.MethodPost, "/", `{"text": "todo"}`, IdempotencyKeyHeader, "key-2"), http.StatusCreated)
+	expectStatus(t, serve(handler, http.MethodPost, "/", `{"text": "todo"}`), http.StatusCreated)
+	if calls := client.callCount("CreateTodo"); calls != 3 {
+		t.Errorf("expected other keys and requests without a key to create todos, got %d creates", calls)
+	}
+
+	res := serve(handler, http.MethodPost, "/", `{"text": "other"}`, IdempotencyKeyHeader, "key-1")
+	expectStatus(t, res, http.StatusUnprocessableEntity)
+	expectStatus(t, serve(handler, http.MethodPost, "/", `{"text": "todo"}`, IdempotencyKeyHeader, strings.Repeat("k", 256)), http.StatusBadRequest)
+
+	// failed requests can be retried with the same key
+	client.err = status.Error(codes.Internal, "oops")
+	expectStatus(t, serve(handler, http.MethodPost, "/", `{"text": "todo"}`, IdempotencyKeyHeader, "key-3"), http.StatusInternalServerError)
+	client.err = nil
+	res = serve(handler, http.MethodPost, "/", `{"text": "todo"}`, IdempotencyKeyHeader, "key-3")
+	expectStatus(t, res, http.StatusCreated)
+	if res.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("expected the retry of a failed request to create the todo")
+	}
+}
+
+func TestIdempotencyStore(t *testing.T) {
+	s := newIdempotencyStore(time.Hour)
+	creates := 0
+	var lock sync.Mutex
+	create := func(ctx context.Context) (*todomgrpb.Todo, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		creates++
+		// let the concurrent requests wait for this one
+		time.Sleep(10 * time.Millisecond)
+		return &todomgrpb.Todo{Id: uint64(creates)}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if todo, _, err := s.do(context.Background(), "alice", "key", "req", create); err != nil || todo.Id != 1 {
+				t.Errorf("expected the todo of the first request, got %v, %v", todo, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if creates != 1 {
+		t.Errorf("expected concurrent requests with the same key to create once, got %d creates", creates)
+	}
+
+	// keys are scoped per owner
+	if todo, replayed, _ := s.do(context.Background(), "bob", "key", "req", create); replayed || todo.Id != 2 {
+		t.Errorf("expected another owner's request to create a todo, got %v", todo)
+	}
+
+	// keys expire after the TTL
+	now := time.Now()
+	if _, claimed := s.claim("alice\x00key", [sha1.Size]byte{}, now.Add(30*time.Minute)); claimed {
+		t.Errorf("expected the key to be remembered within the TTL")
+	}
+	if _, claimed := s.claim("alice\x00key", [sha1.Size]byte{}, now.Add(2*time.Hour)); !claimed {
+		t.Errorf("expected the key to expire after the TTL")
+	}
+}
+
+func TestCreateRetriedWithIdempotencyKey(t *testing.T) {
+	options := &RouterOptions{RetryBackoff: time.Millisecond}
+	options.fillDefaults()
+	retry := newUnaryRetryInterceptor(options)
+	unavailable := status.Error(codes.Unavailable, "todo-manager is down")
+
+	attempts := 0
+	ctx := context.WithValue(context.Background(), idempotencyKeyCtxKey{}, "key")
+	if err := retry(ctx, "/todo_mgr.TodoManager/CreateTodo", nil, nil, nil, failingInvoker(&attempts, unavailable)); err != nil {
+		t.Errorf("expected the create to be retried, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
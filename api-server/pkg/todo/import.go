@@ -0,0 +1,242 @@
+package todo
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+const (
+	// DefaultImportMaxBytes is the default max size of an import request
+	DefaultImportMaxBytes = 10 << 20
+	// importFileField is the name of the multipart form field carrying the imported file
+	importFileField = "file"
+)
+
+// errImportNoFile is returned when an import request has no file field
+var errImportNoFile = fmt.Errorf("multipart form field %q with the file to import is missing", importFileField)
+
+// ImportFailure describes a record of an imported file that couldn't be imported; Line is the
+// 1-based row number of CSV files, counting the header row, and the 1-based position of the
+// object in the array of JSON files
+type ImportFailure struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportRes summarizes an import
+type ImportRes struct {
+	Imported int             `json:"imported"`
+	Failed   []ImportFailure `json:"failed"`
+}
+
+// Render allows to modify the way ImportRes object is rendered to text; not used here
+func (res *ImportRes) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// ImportTodos creates todos for a user from a CSV or JSON file uploaded as the file field of a multipart
+// form. The file is parsed as it's uploaded and valid records are streamed to todo-manager, which creates
+// them only once the whole file is read; invalid records are skipped and reported in the response.
+// CSV files need a header row with a text column; done, priority and due_date columns are optional and
+// the other ones are ignored, so exports can be imported back. JSON files hold an array of todos.
+func (t *Router) ImportTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, t.options.ImportMaxBytes)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	var file io.Reader
+	var format string
+	for file == nil {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			render.Render(w, r, middleware.ErrInvalidRequest(errImportNoFile))
+			return
+		}
+		if err != nil {
+			render.Render(w, r, importError(err))
+			return
+		}
+		if part.FormName() == importFileField {
+			file = part
+			format = importFormat(part.FileName(), part.Header.Get("Content-Type"))
+		}
+	}
+
+	// the import takes as long as the client needs to upload the file, so it's not limited by CallTimeout
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	stream, err := t.grpcClient.BatchCreateTodos(ctx)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	res := &ImportRes{Failed: []ImportFailure{}}
+	add := func(line int, todo *Todo, err error) error {
+		if err == nil {
+			err = todo.Bind(r)
+		}
+		if err == nil {
+			err = todo.Validate()
+		}
+		if err == nil {
+			err = t.checkTextLength(todo.Text)
+		}
+		if err != nil {
+			res.Failed = append(res.Failed, ImportFailure{Line: line, Error: err.Error()})
+			return nil
+		}
+		todo.ID = "0"
+		if todo.Priority == "" {
+			todo.Priority = PriorityMedium
+		}
+		res.Imported++
+		return stream.Send(todo.ToGRPCTodo(owner))
+	}
+	if format == "json" {
+		err = parseJSONImport(file, add)
+	} else {
+		err = parseCSVImport(file, add)
+	}
+	if err == io.EOF {
+		// sending failed, the real error is returned by CloseAndRecv below
+		err = nil
+	}
+	if err != nil {
+		// cancelling the stream makes todo-manager drop the todos received so far
+		cancel()
+		render.Render(w, r, importError(err))
+		return
+	}
+	if _, err := stream.CloseAndRecv(); err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	if err := render.Render(w, r, res); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	t.createOneCounter.WithLabelValues(owner).Add(float64(res.Imported))
+}
+
+// importFormat returns the format of an imported file based on its name or content type; CSV is the default
+func importFormat(filename, contentType string) string {
+	if strings.ToLower(path.Ext(filename)) == ".json" || strings.Contains(contentType, "json") {
+		return "json"
+	}
+	return "csv"
+}
+
+// importError returns the error response of a file that can't be read or parsed
+func importError(err error) render.Renderer {
+	if isBodyTooLarge(err) {
+		return errRequestEntityTooLarge(err)
+	}
+	return middleware.ErrInvalidRequest(err)
+}
+
+// parseCSVImport calls add for every record of a CSV file with a header row; invalid records are passed
+// to add with an error, while errors returned by add and errors making the rest of the file unreadable
+// stop the parsing
+func parseCSVImport(file io.Reader, add func(int, *Todo, error) error) error {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, found := columns["text"]; !found {
+		return errors.New("CSV header row has no text column")
+	}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return err
+			}
+			if err := add(line, nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+		todo, err := csvTodo(columns, record)
+		if err := add(line, todo, err); err != nil {
+			return err
+		}
+	}
+}
+
+// csvTodo returns the todo of a CSV record, based on the column indexes of the header row
+func csvTodo(columns map[string]int, record []string) (*Todo, error) {
+	field := func(name string) string {
+		if i, found := columns[name]; found && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	todo := &Todo{
+		Text:     field("text"),
+		Priority: field("priority"),
+		DueDate:  field("due_date"),
+	}
+	if done := field("done"); done != "" {
+		b, err := strconv.ParseBool(done)
+		if err != nil {
+			return nil, fmt.Errorf("done must be a boolean, got %q", done)
+		}
+		todo.Done = b
+	}
+	return todo, nil
+}
+
+// parseJSONImport calls add for every object of a JSON array; objects that don't match the todo
+// model or aren't valid UTF-8 are passed to add with an error, while errors returned by add and syntax errors stop the parsing
+func parseJSONImport(file io.Reader, add func(int, *Todo, error) error) error {
+	decoder := json.NewDecoder(file)
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return errors.New("JSON file must hold an array of todos")
+	}
+	for line := 1; decoder.More(); line++ {
+		todo := &Todo{}
+		err := decoder.Decode(todo)
+		if _, ok := err.(*json.UnmarshalTypeError); err != nil && !ok && err != errInvalidUTF8JSON {
+			return err
+		}
+		if err := add(line, todo, err); err != nil {
+			return err
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
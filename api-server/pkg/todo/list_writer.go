@@ -0,0 +1,131 @@
+package todo
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// newListWriter starts a 200 OK response listing todos in the negotiated format, NDJSON being
+// offered in addition to JSON and XML; every todo is flushed as soon as it's written, so clients
+// get them incrementally. JSON and NDJSON todos have only the selected fields.
+func newListWriter(w http.ResponseWriter, r *http.Request, fields fieldSet) todoWriter {
+	flusher, _ := w.(http.Flusher)
+	var list todoWriter
+	if prefersNDJSON(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", ndjsonContentType)
+		list = &ndjsonListWriter{w: w, flusher: flusher, fields: fields}
+	} else if render.GetAcceptedContentType(r) == render.ContentTypeXML {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		list = &xmlListWriter{w: w, flusher: flusher}
+	} else {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		list = &jsonListWriter{w: w, flusher: flusher, fields: fields}
+	}
+	w.WriteHeader(http.StatusOK)
+	return list
+}
+
+// jsonListWriter writes todos as a JSON array
+type jsonListWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+	fields  fieldSet
+	count   int
+}
+
+func (l *jsonListWriter) write(todo *Todo) error {
+	data, err := l.fields.marshal(todo)
+	if err != nil {
+		return err
+	}
+	sep := ","
+	if l.count == 0 {
+		sep = "["
+	}
+	l.count++
+	if _, err := io.WriteString(l.w, sep); err != nil {
+		return err
+	}
+	if _, err := l.w.Write(data); err != nil {
+		return err
+	}
+	flush(l.flusher)
+	return nil
+}
+
+func (l *jsonListWriter) close() error {
+	end := "]\n"
+	if l.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(l.w, end)
+	return err
+}
+
+// ndjsonListWriter writes todos as newline-delimited JSON, one object per line
+type ndjsonListWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+	fields  fieldSet
+}
+
+func (l *ndjsonListWriter) write(todo *Todo) error {
+	data, err := l.fields.marshal(todo)
+	if err != nil {
+		return err
+	}
+	if _, err := l.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	flush(l.flusher)
+	return nil
+}
+
+func (l *ndjsonListWriter) close() error {
+	return nil
+}
+
+// xmlListWriter writes todos as the elements of a list root element, like xmlList
+type xmlListWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+	started bool
+}
+
+func (l *xmlListWriter) start() error {
+	if l.started {
+		return nil
+	}
+	l.started = true
+	_, err := io.WriteString(l.w, xml.Header+"<list>")
+	return err
+}
+
+func (l *xmlListWriter) write(todo *Todo) error {
+	if err := l.start(); err != nil {
+		return err
+	}
+	if err := xml.NewEncoder(l.w).Encode(todo); err != nil {
+		return err
+	}
+	flush(l.flusher)
+	return nil
+}
+
+func (l *xmlListWriter) close() error {
+	if err := l.start(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(l.w, "</list>")
+	return err
+}
+
+// flush sends the buffered part of a response to the client, if the response writer supports it
+func flush(flusher http.Flusher) {
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
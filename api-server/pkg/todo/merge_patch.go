@@ -0,0 +1,186 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// mergePatchContentType is the media type of JSON Merge Patch (RFC 7386) documents
+const mergePatchContentType = "application/merge-patch+json"
+
+// mergePatchFields lists the keys of a todo that can be changed with a merge patch; other keys
+// are ignored
+var mergePatchFields = map[string]bool{
+	"text":       true,
+	"done":       true,
+	"due_date":   true,
+	"priority":   true,
+	"tags":       true,
+	"recurrence": true,
+}
+
+// isMergePatch checks if the body of r is a JSON Merge Patch document
+func isMergePatch(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == mergePatchContentType
+}
+
+// applyMergePatch applies patch to target as defined by RFC 7386: null values remove keys,
+// objects are merged recursively and all the other values replace the target
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = applyMergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// mergePatchDoc is a JSON Merge Patch document of a todo
+type mergePatchDoc map[string]interface{}
+
+// UnmarshalJSON implements json.Unmarshaler, rejecting documents that aren't valid UTF-8
+func (d *mergePatchDoc) UnmarshalJSON(data []byte) error {
+	if err := checkUTF8JSON(data); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, (*map[string]interface{})(d))
+}
+
+// mergeTodo applies the fields of a merge patch document present in mergePatchFields to todo and
+// returns the TodoPatch setting all of them to the merged values. Removed fields are cleared:
+// done becomes false, priority becomes medium and tags become empty, while removing the
+// text makes the patch invalid.
+func mergeTodo(todo *Todo, doc map[string]interface{}) (*TodoPatch, error) {
+	encoded, err := json.Marshal(todo)
+	if err != nil {
+		return nil, err
+	}
+	var target map[string]interface{}
+	if err := json.Unmarshal(encoded, &target); err != nil {
+		return nil, err
+	}
+	for key, value := range doc {
+		if !mergePatchFields[key] {
+			continue
+		}
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		target[key] = applyMergePatch(target[key], value)
+	}
+	if encoded, err = json.Marshal(target); err != nil {
+		return nil, err
+	}
+	merged := &Todo{}
+	if err := json.Unmarshal(encoded, merged); err != nil {
+		return nil, err
+	}
+
+	if merged.Priority == "" {
+		merged.Priority = PriorityMedium
+	}
+	if merged.Tags == nil {
+		merged.Tags = []string{}
+	}
+	patch := &TodoPatch{
+		Text:       &merged.Text,
+		Done:       &merged.Done,
+		Priority:   &merged.Priority,
+		Tags:       &merged.Tags,
+		DueDate:    optionalString{Set: true},
+		Recurrence: optionalRecurrence{Set: true, Value: merged.Recurrence},
+	}
+	if merged.DueDate != "" {
+		patch.DueDate.Value = &merged.DueDate
+	}
+	return patch, nil
+}
+
+// mergePatchTodo updates a todo with specified user and todo ID with a JSON Merge Patch document.
+// The document is applied to the current todo, which is then stored with the version it was
+// read at, so that concurrent updates aren't lost: they are reported as 412 Precondition Failed.
+func (t *Router) mergePatchTodo(w http.ResponseWriter, r *http.Request, owner string, id uint64) {
+	var doc mergePatchDoc
+	if err := render.DecodeJSON(r.Body, &doc); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if doc == nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(errors.New("merge patch must be a JSON object")))
+		return
+	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		render.Render(w, r, errPreconditionFailed(err))
+		return
+	}
+
+	ctx, cancel := t.callContext(r)
+	grpcTodo, err := t.grpcClient.GetTodo(ctx, &todomgrpb.TodoIdReq{
+		Id:    id,
+		Owner: owner,
+	})
+	cancel()
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	current, _ := FromGRPCTodo(grpcTodo)
+	if version == 0 {
+		version = current.version
+	}
+	data, err := mergeTodo(current, doc)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if err := data.Bind(r); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if err := t.validateTodoPatch(data); err != nil {
+		render.Render(w, r, errValidation(err))
+		return
+	}
+
+	patch := data.ToGRPCTodoPatch(id, owner)
+	patch.ExpectedVersion = version
+	ctx, cancel = t.callContext(r)
+	defer cancel()
+	var trailer metadata.MD
+	grpcTodo, err = t.grpcClient.PatchTodo(ctx, patch, grpc.Trailer(&trailer))
+	if err != nil {
+		setVersionETag(w, trailer)
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	w.Header().Set("ETag", versionETag(todo.version))
+	if err := render.Render(w, r, todo); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	t.patchOneCounter.WithLabelValues(owner).Inc()
+	t.webhooks.dispatch(WebhookEventUpdated, grpcTodo.GetOwner(), todo)
+}
@@ -0,0 +1,49 @@
+package todo
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// unmatchedRoute is the path label used for requests not matching any route
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequestsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "How many HTTP requests processed, partitioned by status code, method and route pattern.",
+	}, []string{"code", "method", "path"})
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "How long it took to process the request, partitioned by status code, method and route pattern.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"code", "method", "path"})
+)
+
+// MetricsMiddleware records the count and latency of HTTP requests labeled by method, status code
+// and chi route pattern (as the "path" label), so that IDs in paths don't create new time series
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		begin := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		code := strconv.Itoa(status)
+		httpRequestsCounter.WithLabelValues(code, r.Method, route).Inc()
+		httpRequestDuration.WithLabelValues(code, r.Method, route).Observe(time.Since(begin).Seconds())
+	})
+}
@@ -0,0 +1,79 @@
+package todo
+
+import (
+	"bufio"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// scrape returns the value of the sample of /metrics starting with series, or 0 if there's none
+func scrape(t *testing.T, handler http.Handler, series string) float64 {
+	t.Helper()
+	res := serve(handler, http.MethodGet, "/metrics", "")
+	expectStatus(t, res, http.StatusOK)
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, series+" ") {
+			value, err := strconv.ParseFloat(strings.TrimPrefix(line, series+" "), 64)
+			if err != nil {
+				t.Fatalf("invalid sample %q: %v", line, err)
+			}
+			return value
+		}
+	}
+	return 0
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	router := newTestRouter(newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: Username}), nil)
+	defer router.Close()
+	r := chi.NewRouter()
+	r.Use(MetricsMiddleware)
+	r.Mount("/v1/todo", router.GetRouter())
+	r.Mount("/metrics", promhttp.Handler())
+
+	found := `http_requests_total{code="200",method="GET",path="/v1/todo/{todoID}/"}`
+	notFound := `http_requests_total{code="404",method="GET",path="/v1/todo/{todoID}/"}`
+	before := scrape(t, r, found)
+	beforeNotFound := scrape(t, r, notFound)
+
+	expectStatus(t, serve(r, http.MethodGet, "/v1/todo/1", ""), http.StatusOK)
+	expectStatus(t, serve(r, http.MethodGet, "/v1/todo/1", ""), http.StatusOK)
+	expectStatus(t, serve(r, http.MethodGet, "/v1/todo/2", ""), http.StatusNotFound)
+
+	if after := scrape(t, r, found); after != before+2 {
+		t.Errorf("expected %s to grow by 2 from %v, got %v", found, before, after)
+	}
+	if after := scrape(t, r, notFound); after != beforeNotFound+1 {
+		t.Errorf("expected %s to grow by 1 from %v, got %v", notFound, beforeNotFound, after)
+	}
+	if count := scrape(t, r, `http_request_duration_seconds_count{code="200",method="GET",path="/v1/todo/{todoID}/"}`); count < 2 {
+		t.Errorf("expected the latency of the requests to be observed, got %v observations", count)
+	}
+}
+
+func TestRouterMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	router := newTestRouter(newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: Username}), &RouterOptions{Registerer: registry})
+	defer router.Close()
+	r := chi.NewRouter()
+	r.Mount("/v1/todo", router.GetRouter())
+	r.Mount("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	expectStatus(t, serve(r, http.MethodGet, "/v1/todo/1", ""), http.StatusOK)
+	expectStatus(t, serve(r, http.MethodGet, "/v1/todo/", ""), http.StatusOK)
+	if count := scrape(t, r, `todo_get_one_count_total{user="anonymous"}`); count != 1 {
+		t.Errorf("expected 1 successful get of a todo, got %v", count)
+	}
+	if count := scrape(t, r, `todo_get_all_count_total{user="anonymous"}`); count != 1 {
+		t.Errorf("expected 1 successful list of todos, got %v", count)
+	}
+}
@@ -1,22 +1,106 @@
 package todo
 
 import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
 
 	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
 )
 
 // Todo data model.
 type Todo struct {
-	ID   string `json:"id"`
-	Text string `json:"text"`
-	Done bool   `json:"done"`
+	XMLName xml.Name `json:"-" xml:"todo"`
+
+	ID   string `json:"id" xml:"id"`
+	Text string `json:"text" xml:"text"`
+	Done bool   `json:"done" xml:"done"`
+	// CreatedAt and UpdatedAt are RFC3339 timestamps set by the server; values sent
+	// by clients are ignored
+	CreatedAt string `json:"created_at,omitempty" xml:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty" xml:"updated_at,omitempty"`
+	// DueDate is an optional RFC3339 date-time
+	DueDate string `json:"due_date,omitempty" xml:"due_date,omitempty"`
+	// Priority is one of: low, medium, high
+	Priority string `json:"priority,omitempty" xml:"priority,omitempty"`
+	// Tags are stored trimmed, lowercase and without duplicates
+	Tags []string `json:"tags,omitempty" xml:"tag,omitempty"`
+	// DeletedAt is the RFC3339 timestamp of moving the todo to the trash; set only for todos in the trash
+	DeletedAt string `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
+	// Archived is changed only with the archive and unarchive endpoints; values sent by clients are ignored
+	Archived bool `json:"archived" xml:"archived"`
+	// Subtasks are checklist items of the todo, identified by their index
+	Subtasks []Subtask `json:"subtasks,omitempty" xml:"subtask,omitempty"`
+	// Recurrence makes completing the todo create its next occurrence
+	Recurrence *Recurrence `json:"recurrence,omitempty" xml:"recurrence,omitempty"`
+	// SharedWith lists the users the todo is shared with; it's changed only with the share endpoint
+	SharedWith []Share `json:"shared_with,omitempty" xml:"share,omitempty"`
+	// Position is the sort key of the todo in the list of its owner, the default sort order; it's
+	// changed only with the move endpoint
+	Position float64 `json:"position" xml:"position"`
+	// version is the todo version reported in the ETag header
+	version uint64
 }
 
-// Bind allows to set additional properties on Todo object; not used here
+const (
+	// PriorityLow marks todos that can wait
+	PriorityLow = "low"
+	// PriorityMedium is the default priority of new todos
+	PriorityMedium = "medium"
+	// PriorityHigh marks urgent todos
+	PriorityHigh = "high"
+)
+
+// priorities maps the allowed priority values to their gRPC enum values
+var priorities = map[string]todomgrpb.Priority{
+	PriorityLow:    todomgrpb.Priority_LOW,
+	PriorityMedium: todomgrpb.Priority_MEDIUM,
+	PriorityHigh:   todomgrpb.Priority_HIGH,
+}
+
+// parsePriority returns the gRPC enum value of a priority
+func parsePriority(priority string) (todomgrpb.Priority, error) {
+	p, found := priorities[priority]
+	if !found {
+		return todomgrpb.Priority_PRIORITY_UNSPECIFIED, fmt.Errorf("priority must be one of: low, medium, high, got %q", priority)
+	}
+	return p, nil
+}
+
+// formatPriority returns the priority name of a gRPC enum value
+func formatPriority(p todomgrpb.Priority) string {
+	for name, value := range priorities {
+		if value == p {
+			return name
+		}
+	}
+	return ""
+}
+
+// UnmarshalJSON implements json.Unmarshaler, rejecting todos that aren't valid UTF-8
+func (t *Todo) UnmarshalJSON(data []byte) error {
+	if err := checkUTF8JSON(data); err != nil {
+		return err
+	}
+	// todoJSON has the fields of Todo but not its methods, so it's decoded as a plain struct
+	type todoJSON Todo
+	return json.Unmarshal(data, (*todoJSON)(t))
+}
+
+// Bind normalizes the Todo object decoded from the request
 func (t *Todo) Bind(r *http.Request) error {
+	t.Text = normalizeText(t.Text)
+	t.Tags = normalizeTags(t.Tags)
 	return nil
 }
 
@@ -25,27 +109,298 @@ func (t *Todo) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+// Validate checks if the Todo can be stored; the errors of all the invalid fields are returned
+// as ValidationErrors
+func (t *Todo) Validate() error {
+	var errs ValidationErrors
+	if t.Text == "" {
+		errs.add("text", errors.New("Text can't be empty"))
+	} else {
+		errs.add("text", validateText(t.Text))
+	}
+	if t.DueDate != "" {
+		_, err := parseDueDate(t.DueDate)
+		errs.add("due_date", err)
+	}
+	if t.Priority != "" {
+		_, err := parsePriority(t.Priority)
+		errs.add("priority", err)
+	}
+	for i, subtask := range t.Subtasks {
+		errs.add(fmt.Sprintf("subtasks[%d].text", i), subtask.Validate())
+	}
+	if t.Recurrence != nil {
+		errs.add("recurrence", t.Recurrence.Validate())
+	}
+	errs.add("tags", validateTags(t.Tags))
+	return errs.err()
+}
+
+// Subtask data model.
+type Subtask struct {
+	Text string `json:"text" xml:"text"`
+	Done bool   `json:"done" xml:"done"`
+}
+
+// Bind allows to set additional properties on Subtask object; not used here
+func (s *Subtask) Bind(r *http.Request) error {
+	return nil
+}
+
+// Validate checks if Subtask can be stored
+func (s *Subtask) Validate() error {
+	if s.Text == "" {
+		return errors.New("Subtask text can't be empty")
+	}
+	return nil
+}
+
+// SubtaskPatch is a partial update of a Subtask; nil fields are left untouched
+type SubtaskPatch struct {
+	Text *string `json:"text"`
+	Done *bool   `json:"done"`
+}
+
+// Bind allows to set additional properties on SubtaskPatch object; not used here
+func (p *SubtaskPatch) Bind(r *http.Request) error {
+	return nil
+}
+
+// Validate checks if the fields present in SubtaskPatch can be stored
+func (p *SubtaskPatch) Validate() error {
+	if p.Text != nil && *p.Text == "" {
+		return errors.New("Subtask text can't be empty")
+	}
+	return nil
+}
+
+// subtasksToGRPC returns gRPC DTOs of subtasks
+func subtasksToGRPC(subtasks []Subtask) []*todomgrpb.Subtask {
+	var res []*todomgrpb.Subtask
+	for _, subtask := range subtasks {
+		res = append(res, &todomgrpb.Subtask{Text: subtask.Text, Done: subtask.Done})
+	}
+	return res
+}
+
+// subtasksFromGRPC returns subtasks based on gRPC DTOs
+func subtasksFromGRPC(grpcSubtasks []*todomgrpb.Subtask) []Subtask {
+	var subtasks []Subtask
+	for _, subtask := range grpcSubtasks {
+		subtasks = append(subtasks, Subtask{Text: subtask.GetText(), Done: subtask.GetDone()})
+	}
+	return subtasks
+}
+
+// maxTagLength is the max length of a single tag
+const maxTagLength = 50
+
+// normalizeTags trims, lowercases and deduplicates tags, dropping empty ones
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// validateTags checks if all the tags can be stored
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return fmt.Errorf("tag %q is longer than %d characters", tag, maxTagLength)
+		}
+	}
+	return nil
+}
+
+// minDueDate is the earliest due date accepted; anything older is surely a client bug
+var minDueDate = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// parseDueDate parses and validates a due date in RFC3339 format
+func parseDueDate(dueDate string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, dueDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("due_date must be an RFC3339 date-time: %v", err)
+	}
+	if t.Before(minDueDate) {
+		return time.Time{}, errors.New("due_date can't be before year 2000")
+	}
+	return t, nil
+}
+
 // ToGRPCTodo return gRPC DTO for the upstream todo-manager service
 func (t *Todo) ToGRPCTodo(owner string) *todomgrpb.Todo {
 	id, _ := strconv.ParseUint(t.ID, 10, 64)
-	return &todomgrpb.Todo{
-		Id:    id,
-		Text:  t.Text,
-		Done:  t.Done,
-		Owner: owner,
+	priority, _ := parsePriority(t.Priority)
+	grpcTodo := &todomgrpb.Todo{
+		Id:         id,
+		Text:       t.Text,
+		Done:       t.Done,
+		Owner:      owner,
+		Priority:   priority,
+		Tags:       t.Tags,
+		Subtasks:   subtasksToGRPC(t.Subtasks),
+		Recurrence: t.Recurrence.ToGRPCRecurrence(),
+	}
+	if dueDate, err := time.Parse(time.RFC3339, t.DueDate); err == nil {
+		grpcTodo.DueDate, _ = ptypes.TimestampProto(dueDate)
 	}
+	return grpcTodo
 }
 
 // FromGRPCTodo returns new Todo object and owner info based on gRPC DTO from the
 // upstream todo-manager service
 func FromGRPCTodo(grpcTodo *todomgrpb.Todo) (*Todo, string) {
 	return &Todo{
-		ID:   fmt.Sprintf("%d", grpcTodo.GetId()),
-		Text: grpcTodo.GetText(),
-		Done: grpcTodo.GetDone(),
+		ID:         fmt.Sprintf("%d", grpcTodo.GetId()),
+		Text:       grpcTodo.GetText(),
+		Done:       grpcTodo.GetDone(),
+		CreatedAt:  formatTimestamp(grpcTodo.GetCreatedAt()),
+		UpdatedAt:  formatTimestamp(grpcTodo.GetUpdatedAt()),
+		DueDate:    formatTimestamp(grpcTodo.GetDueDate()),
+		Priority:   formatPriority(grpcTodo.GetPriority()),
+		Tags:       grpcTodo.GetTags(),
+		DeletedAt:  formatTimestamp(grpcTodo.GetDeletedAt()),
+		Archived:   grpcTodo.GetArchived(),
+		Subtasks:   subtasksFromGRPC(grpcTodo.GetSubtasks()),
+		Recurrence: FromGRPCRecurrence(grpcTodo.GetRecurrence()),
+		SharedWith: sharesFromGRPC(grpcTodo.GetSharedWith()),
+		Position:   grpcTodo.GetPosition(),
+		version:    grpcTodo.GetVersion(),
 	}, grpcTodo.GetOwner()
 }
 
+// formatTimestamp returns ts formatted as RFC3339 or an empty string, if it's not set
+func formatTimestamp(ts *timestamp.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// optionalString is a JSON string field that tells apart a missing key from an explicit null
+type optionalString struct {
+	// Set is true if the key was present in JSON
+	Set bool
+	// Value is nil if the key was set to null
+	Value *string
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (o *optionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = nil
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// TodoPatch is a partial update of a Todo; nil fields are left untouched, while
+// DueDate and Recurrence can be cleared with an explicit null
+type TodoPatch struct {
+	ID       *string        `json:"id"`
+	Text     *string        `json:"text"`
+	Done     *bool          `json:"done"`
+	DueDate  optionalString `json:"due_date"`
+	Priority *string        `json:"priority"`
+	// Tags replace all the tags of the Todo, if present
+	Tags       *[]string          `json:"tags"`
+	Recurrence optionalRecurrence `json:"recurrence"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler, rejecting patches that aren't valid UTF-8
+func (p *TodoPatch) UnmarshalJSON(data []byte) error {
+	if err := checkUTF8JSON(data); err != nil {
+		return err
+	}
+	type todoPatchJSON TodoPatch
+	return json.Unmarshal(data, (*todoPatchJSON)(p))
+}
+
+// Bind normalizes the TodoPatch object decoded from the request
+func (p *TodoPatch) Bind(r *http.Request) error {
+	if p.Text != nil {
+		text := normalizeText(*p.Text)
+		p.Text = &text
+	}
+	if p.Tags != nil {
+		tags := normalizeTags(*p.Tags)
+		p.Tags = &tags
+	}
+	return nil
+}
+
+// Validate checks if the fields present in TodoPatch can be stored; the errors of all the invalid
+// fields are returned as ValidationErrors
+func (p *TodoPatch) Validate() error {
+	var errs ValidationErrors
+	if p.Text != nil && *p.Text == "" {
+		errs.add("text", errors.New("Text can't be empty"))
+	} else if p.Text != nil {
+		errs.add("text", validateText(*p.Text))
+	}
+	if p.DueDate.Value != nil {
+		_, err := parseDueDate(*p.DueDate.Value)
+		errs.add("due_date", err)
+	}
+	if p.Priority != nil {
+		_, err := parsePriority(*p.Priority)
+		errs.add("priority", err)
+	}
+	if p.Recurrence.Value != nil {
+		errs.add("recurrence", p.Recurrence.Value.Validate())
+	}
+	if p.Tags != nil {
+		errs.add("tags", validateTags(*p.Tags))
+	}
+	return errs.err()
+}
+
+// ToGRPCTodoPatch return gRPC DTO for the upstream todo-manager service
+func (p *TodoPatch) ToGRPCTodoPatch(id uint64, owner string) *todomgrpb.TodoPatch {
+	grpcPatch := &todomgrpb.TodoPatch{
+		Id:    id,
+		Owner: owner,
+	}
+	if p.Text != nil {
+		grpcPatch.Text = &wrappers.StringValue{Value: *p.Text}
+	}
+	if p.Done != nil {
+		grpcPatch.Done = &wrappers.BoolValue{Value: *p.Done}
+	}
+	if p.Priority != nil {
+		grpcPatch.Priority, _ = parsePriority(*p.Priority)
+	}
+	if p.Tags != nil {
+		grpcPatch.Tags = &todomgrpb.TagList{Tags: *p.Tags}
+	}
+	if p.Recurrence.Set && p.Recurrence.Value == nil {
+		grpcPatch.ClearRecurrence = true
+	} else {
+		grpcPatch.Recurrence = p.Recurrence.Value.ToGRPCRecurrence()
+	}
+	if p.DueDate.Set && p.DueDate.Value == nil {
+		grpcPatch.ClearDueDate = true
+	} else if p.DueDate.Value != nil {
+		if dueDate, err := time.Parse(time.RFC3339, *p.DueDate.Value); err == nil {
+			grpcPatch.DueDate, _ = ptypes.TimestampProto(dueDate)
+		}
+	}
+	return grpcPatch
+}
+
 // DeleteRes data model.
 type DeleteRes struct {
 	Success bool `json:"success"`
@@ -68,3 +423,96 @@ func FromGRPCDeleteRes(grpcRes *todomgrpb.DeleteTodoRes) *DeleteRes {
 		Success: grpcRes.GetSuccess(),
 	}
 }
+
+// BatchDeleteReq data model.
+type BatchDeleteReq struct {
+	IDs []string `json:"ids"`
+}
+
+// Bind allows to set additional properties on BatchDeleteReq object; not used here
+func (b *BatchDeleteReq) Bind(r *http.Request) error {
+	return nil
+}
+
+// BatchDeleteRes data model.
+type BatchDeleteRes struct {
+	Deleted  []string `json:"deleted"`
+	NotFound []string `json:"not_found"`
+	Failed   []string `json:"failed,omitempty"`
+}
+
+// Render allows to modify the way BatchDeleteRes object is rendered to text; not used here
+func (b *BatchDeleteRes) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// BatchGetItem is a todo requested by ID in a batch get; Todo is not set if it's not found
+type BatchGetItem struct {
+	ID       string `json:"id"`
+	Todo     *Todo  `json:"todo,omitempty"`
+	NotFound bool   `json:"not_found,omitempty"`
+}
+
+// BatchGetRes data model.
+type BatchGetRes struct {
+	Todos []BatchGetItem `json:"todos"`
+}
+
+// Render allows to modify the way BatchGetRes object is rendered to text; not used here
+func (b *BatchGetRes) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// CountRes data model.
+type CountRes struct {
+	Count uint64 `json:"count"`
+}
+
+// Render allows to modify the way CountRes object is rendered to text; not used here
+func (c *CountRes) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// FromGRPCCountRes returns new CountRes object based on gRPC DTO from the
+// upstream todo-manager service
+func FromGRPCCountRes(grpcRes *todomgrpb.CountTodosRes) *CountRes {
+	return &CountRes{
+		Count: grpcRes.GetCount(),
+	}
+}
+
+// PriorityCounts data model.
+type PriorityCounts struct {
+	Low    uint64 `json:"low" xml:"low"`
+	Medium uint64 `json:"medium" xml:"medium"`
+	High   uint64 `json:"high" xml:"high"`
+}
+
+// StatsRes data model.
+type StatsRes struct {
+	Total     uint64 `json:"total" xml:"total"`
+	Completed uint64 `json:"completed" xml:"completed"`
+	// Overdue counts the todos not done with a due date in the past
+	Overdue    uint64         `json:"overdue" xml:"overdue"`
+	ByPriority PriorityCounts `json:"by_priority" xml:"by_priority"`
+}
+
+// Render allows to modify the way StatsRes object is rendered to text; not used here
+func (s *StatsRes) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// FromGRPCStatsRes returns new StatsRes object based on gRPC DTO from the
+// upstream todo-manager service
+func FromGRPCStatsRes(grpcRes *todomgrpb.TodoStatsRes) *StatsRes {
+	return &StatsRes{
+		Total:     grpcRes.GetTotal(),
+		Completed: grpcRes.GetDone(),
+		Overdue:   grpcRes.GetOverdue(),
+		ByPriority: PriorityCounts{
+			Low:    grpcRes.GetLowPriority(),
+			Medium: grpcRes.GetMediumPriority(),
+			High:   grpcRes.GetHighPriority(),
+		},
+	}
+}
@@ -0,0 +1,231 @@
+package todo
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+func TestTodoDone(t *testing.T) {
+	client := newFakeClient()
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodPost, "/", `{"text": "buy milk"}`)
+	expectStatus(t, res, http.StatusCreated)
+	created := &Todo{}
+	decodeJSONRes(t, res, created)
+	if created.Done {
+		t.Errorf("expected new todo not to be done")
+	}
+
+	res = serve(handler, http.MethodPut, "/"+created.ID, `{"text": "buy milk", "done": true}`)
+	expectStatus(t, res, http.StatusOK)
+	updated := &Todo{}
+	decodeJSONRes(t, res, updated)
+	if !updated.Done || !client.todo(1).Done {
+		t.Errorf("expected update to mark the todo done")
+	}
+
+	res = serve(handler, http.MethodPut, "/"+created.ID, `{"text": "buy milk", "done": false}`)
+	expectStatus(t, res, http.StatusOK)
+	if client.todo(1).Done {
+		t.Errorf("expected update to mark the todo not done")
+	}
+}
+
+func TestTodoDoneConversion(t *testing.T) {
+	grpcTodo := (&Todo{ID: "7", Text: "done", Done: true}).ToGRPCTodo("alice")
+	if !grpcTodo.Done || grpcTodo.Id != 7 || grpcTodo.Owner != "alice" {
+		t.Errorf("unexpected gRPC todo %v", grpcTodo)
+	}
+	todo, owner := FromGRPCTodo(&todomgrpb.Todo{Id: 7, Text: "done", Done: true, Owner: "alice"})
+	if !todo.Done || todo.ID != "7" || owner != "alice" {
+		t.Errorf("unexpected todo %+v of %q", todo, owner)
+	}
+}
+
+func TestTodoTimestamps(t *testing.T) {
+	createdAt, _ := ptypes.TimestampProto(time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC))
+	client := newFakeClient(&todomgrpb.Todo{Text: "buy milk", Owner: Username, CreatedAt: createdAt})
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodGet, "/1", "")
+	expectStatus(t, res, http.StatusOK)
+	before := &Todo{}
+	decodeJSONRes(t, res, before)
+	if before.CreatedAt != "2020-03-01T10:00:00Z" || before.UpdatedAt != before.CreatedAt {
+		t.Fatalf("expected timestamps of the stored todo, got %q and %q", before.CreatedAt, before.UpdatedAt)
+	}
+
+	res = serve(handler, http.MethodPut, "/1", `{"text": "buy oat milk", "created_at": "2010-01-01T00:00:00Z"}`)
+	expectStatus(t, res, http.StatusOK)
+	after := &Todo{}
+	decodeJSONRes(t, res, after)
+	if after.CreatedAt != before.CreatedAt {
+		t.Errorf("expected update not to change created_at %q, got %q", before.CreatedAt, after.CreatedAt)
+	}
+	if after.UpdatedAt == before.UpdatedAt {
+		t.Errorf("expected update to change updated_at %q", before.UpdatedAt)
+	}
+	if _, err := time.Parse(time.RFC3339, after.UpdatedAt); err != nil {
+		t.Errorf("expected RFC3339 updated_at, got %q", after.UpdatedAt)
+	}
+}
+
+func TestToGRPCTodoIgnoresTimestamps(t *testing.T) {
+	todo := &Todo{Text: "spoofed", CreatedAt: "2010-01-01T00:00:00Z", UpdatedAt: "2010-01-01T00:00:00Z"}
+	if grpcTodo := todo.ToGRPCTodo(Username); grpcTodo.CreatedAt != nil || grpcTodo.UpdatedAt != nil {
+		t.Errorf("expected timestamps sent by clients to be dropped, got %v", grpcTodo)
+	}
+}
+
+func TestTodoDueDate(t *testing.T) {
+	client := newFakeClient()
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	invalid := []struct {
+		name string
+		body string
+	}{
+		{name: "not a date", body: `{"text": "todo", "due_date": "tomorrow"}`},
+		{name: "date without time", body: `{"text": "todo", "due_date": "2030-01-01"}`},
+		{name: "before 2000", body: `{"text": "todo", "due_date": "1999-12-31T23:59:59Z"}`},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serve(handler, http.MethodPost, "/", tt.body)
+			expectStatus(t, res, http.StatusUnprocessableEntity)
+			body := &ErrorRes{}
+			decodeJSONRes(t, res, body)
+			if len(body.Errors) != 1 || body.Errors[0].Field != "due_date" {
+				t.Errorf("expected an error of due_date, got %+v", body.Errors)
+			}
+		})
+	}
+
+	res := serve(handler, http.MethodPost, "/", `{"text": "todo", "due_date": "2030-01-01T12:00:00+02:00"}`)
+	expectStatus(t, res, http.StatusCreated)
+	created := &Todo{}
+	decodeJSONRes(t, res, created)
+	if created.DueDate != "2030-01-01T10:00:00Z" {
+		t.Errorf("expected due date in UTC, got %q", created.DueDate)
+	}
+
+	res = serve(handler, http.MethodPatch, "/"+created.ID, `{"due_date": "not a date"}`)
+	expectStatus(t, res, http.StatusUnprocessableEntity)
+	res = serve(handler, http.MethodPatch, "/"+created.ID, `{"due_date": null}`)
+	expectStatus(t, res, http.StatusOK)
+	if client.todo(1).DueDate != nil {
+		t.Errorf("expected null due date to clear it")
+	}
+}
+
+func TestListTodosDueBefore(t *testing.T) {
+	client := newFakeClient()
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodGet, "/?due_before=2030-01-01T00:00:00Z", "")
+	expectStatus(t, res, http.StatusOK)
+	if dueBefore, _ := ptypes.Timestamp(client.listReq.DueBefore); !dueBefore.Equal(time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected due_before to be sent to todo-manager, got %v", client.listReq.DueBefore)
+	}
+	res = serve(handler, http.MethodGet, "/?due_before=yesterday", "")
+	expectStatus(t, res, http.StatusBadRequest)
+}
+
+func TestTodoPriority(t *testing.T) {
+	client := newFakeClient()
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodPost, "/", `{"text": "todo"}`)
+	expectStatus(t, res, http.StatusCreated)
+	created := &Todo{}
+	decodeJSONRes(t, res, created)
+	if created.Priority != PriorityMedium || client.todo(1).Priority != todomgrpb.Priority_MEDIUM {
+		t.Errorf("expected default priority medium, got %q", created.Priority)
+	}
+
+	res = serve(handler, http.MethodPost, "/", `{"text": "todo", "priority": "high"}`)
+	expectStatus(t, res, http.StatusCreated)
+	if client.todo(2).Priority != todomgrpb.Priority_HIGH {
+		t.Errorf("expected priority high, got %v", client.todo(2).Priority)
+	}
+
+	for _, priority := range []string{"urgent", "HIGH", "0"} {
+		res = serve(handler, http.MethodPost, "/", `{"text": "todo", "priority": "`+priority+`"}`)
+		expectStatus(t, res, http.StatusUnprocessableEntity)
+		res = serve(handler, http.MethodPut, "/1", `{"text": "todo", "priority": "`+priority+`"}`)
+		expectStatus(t, res, http.StatusUnprocessableEntity)
+	}
+	if client.callCount("CreateTodo") != 2 || client.callCount("UpdateTodo") != 0 {
+		t.Errorf("expected invalid priorities not to reach todo-manager")
+	}
+}
+
+func TestListTodosPriorityFilter(t *testing.T) {
+	client := newFakeClient(
+		&todomgrpb.Todo{Text: "low", Owner: Username, Priority: todomgrpb.Priority_LOW},
+		&todomgrpb.Todo{Text: "high", Owner: Username, Priority: todomgrpb.Priority_HIGH},
+		&todomgrpb.Todo{Text: "medium", Owner: Username},
+	)
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	if ids := listedIDs(t, handler, "/?priority=high"); len(ids) != 1 || ids[0] != "2" {
+		t.Errorf("expected only the high priority todo, got %v", ids)
+	}
+	res := serve(handler, http.MethodGet, "/?priority=urgent", "")
+	expectStatus(t, res, http.StatusBadRequest)
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags := normalizeTags([]string{" Work", "urgent ", "WORK", "", "  ", "home"})
+	if strings.Join(tags, ",") != "work,urgent,home" {
+		t.Errorf("expected trimmed, lowercase and deduplicated tags, got %q", tags)
+	}
+}
+
+func TestTodoTags(t *testing.T) {
+	client := newFakeClient(
+		&todomgrpb.Todo{Text: "work", Owner: Username, Tags: []string{"work"}},
+		&todomgrpb.Todo{Text: "urgent work", Owner: Username, Tags: []string{"work", "urgent"}},
+		&todomgrpb.Todo{Text: "urgent", Owner: Username, Tags: []string{"urgent"}},
+	)
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodPost, "/", `{"text": "todo", "tags": [" Home ", "home", "ERRANDS"]}`)
+	expectStatus(t, res, http.StatusCreated)
+	if tags := client.todo(4).Tags; strings.Join(tags, ",") != "home,errands" {
+		t.Errorf("expected normalized tags, got %q", tags)
+	}
+	res = serve(handler, http.MethodPost, "/", `{"text": "todo", "tags": ["`+strings.Repeat("a", maxTagLength+1)+`"]}`)
+	expectStatus(t, res, http.StatusUnprocessableEntity)
+
+	if ids := listedIDs(t, handler, "/?tag=Work&tag=urgent"); strings.Join(ids, ",") != "2" {
+		t.Errorf("expected only the todo with both tags, got %v", ids)
+	}
+	if tags := client.listReq.Tags; strings.Join(tags, ",") != "work,urgent" {
+		t.Errorf("expected normalized tag filters, got %q", tags)
+	}
+	if ids := listedIDs(t, handler, "/?tag=urgent"); strings.Join(ids, ",") != "2,3" {
+		t.Errorf("expected the todos tagged urgent, got %v", ids)
+	}
+}
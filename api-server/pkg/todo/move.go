@@ -0,0 +1,92 @@
+package todo
+
+import (
+	"errors"
+	"math"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// MoveReq data model.
+type MoveReq struct {
+	// Position is the index the todo is moved to in the list of the active todos, 0 for the
+	// first one; larger values than the number of todos move it last
+	Position *int `json:"position"`
+}
+
+// Bind allows to set additional properties on MoveReq object; not used here
+func (m *MoveReq) Bind(r *http.Request) error {
+	return nil
+}
+
+// Validate checks if MoveReq has a valid position
+func (m *MoveReq) Validate() error {
+	if m.Position == nil {
+		return errors.New("Position can't be empty")
+	}
+	if *m.Position < 0 {
+		return errors.New("Position can't be negative")
+	}
+	return nil
+}
+
+// MoveTodo moves a todo owned by the user to another position of their list of todos, which is
+// sorted by position by default
+func (t *Router) MoveTodo(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	id, err := parseTodoID(chi.URLParam(r, "todoID"))
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	data := &MoveReq{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if err := data.Validate(); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	// larger positions move the todo last anyway
+	position := uint32(math.MaxUint32)
+	if uint64(*data.Position) < math.MaxUint32 {
+		position = uint32(*data.Position)
+	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		render.Render(w, r, errPreconditionFailed(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	var trailer metadata.MD
+	grpcTodo, err := t.grpcClient.MoveTodo(ctx, &todomgrpb.MoveTodoReq{
+		Id:              id,
+		Owner:           owner,
+		Position:        position,
+		ExpectedVersion: version,
+	}, grpc.Trailer(&trailer))
+	if err != nil {
+		setVersionETag(w, trailer)
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	w.Header().Set("ETag", versionETag(todo.version))
+	if err := render.Render(w, r, todo); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	t.webhooks.dispatch(WebhookEventUpdated, owner, todo)
+}
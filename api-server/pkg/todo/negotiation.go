@@ -0,0 +1,88 @@
+package todo
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+)
+
+// ndjsonContentType is the media type of newline-delimited JSON, offered by lists of todos
+const ndjsonContentType = "application/x-ndjson"
+
+func init() {
+	render.Respond = respond
+}
+
+// xmlList is the root element of lists rendered as XML
+type xmlList struct {
+	XMLName xml.Name `xml:"list"`
+	Items   []render.Renderer
+}
+
+// NegotiateContentType is a middleware making responses XML encoded for clients preferring
+// application/xml or text/xml in their Accept header; JSON is used otherwise
+func NegotiateContentType(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if prefersXML(r.Header.Get("Accept")) {
+			r = r.WithContext(context.WithValue(r.Context(), render.ContentTypeCtxKey, render.ContentTypeXML))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// prefersXML checks if an XML media type is listed before JSON and wildcards in an Accept header
+func prefersXML(accept string) bool {
+	return acceptsFirst(accept, func(mediaType string) bool {
+		return render.GetContentType(mediaType) == render.ContentTypeXML
+	})
+}
+
+// prefersNDJSON checks if NDJSON is listed before JSON, XML and wildcards in an Accept header
+func prefersNDJSON(accept string) bool {
+	return acceptsFirst(accept, func(mediaType string) bool {
+		return mediaType == ndjsonContentType
+	})
+}
+
+// acceptsFirst checks if a media type matching want is listed in an Accept header before any JSON,
+// XML or wildcard media type; quality values are ignored, as clients list their preferred type first
+func acceptsFirst(accept string, want func(mediaType string) bool) bool {
+	for _, mediaType := range strings.Split(accept, ",") {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0]))
+		if want(mediaType) {
+			return true
+		}
+		switch render.GetContentType(mediaType) {
+		case render.ContentTypeJSON, render.ContentTypeXML:
+			return false
+		}
+		if strings.HasSuffix(mediaType, "/*") {
+			return false
+		}
+	}
+	return false
+}
+
+// respond is the render responder of the todo API; it renders error responses with their error
+// code. When XML is negotiated, it wraps lists in a root element and falls back to JSON for values
+// that can't be encoded as XML.
+func respond(w http.ResponseWriter, r *http.Request, v interface{}) {
+	if body, ok := errorResBody(v); ok {
+		v = body
+	}
+	if render.GetAcceptedContentType(r) != render.ContentTypeXML {
+		render.DefaultResponder(w, r, v)
+		return
+	}
+	if list, ok := v.([]render.Renderer); ok {
+		v = &xmlList{Items: list}
+	}
+	if _, err := xml.Marshal(v); err != nil {
+		render.JSON(w, r, v)
+		return
+	}
+	render.XML(w, r, v)
+}
@@ -0,0 +1,585 @@
+package todo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+// openAPIVersion is the version of the OpenAPI specification followed by the spec of the API
+const openAPIVersion = "3.0.3"
+
+// apiOperation documents the handler of a route of GetRouter; the paths and methods of the spec
+// are taken from the route tree, so routes can't be missing from it
+type apiOperation struct {
+	id      string
+	summary string
+	// params are keys of apiParameters
+	params []string
+	// body is the model of the JSON request body, if there's one
+	body interface{}
+	// bodyMedia lists the media types of the request body accepted besides JSON, with the same schema
+	bodyMedia []string
+	// bodyOptional marks operations whose request body can be omitted
+	bodyOptional bool
+	// upload marks operations taking a multipart form with a file field instead of a JSON body
+	upload bool
+	// responses maps the success status codes to the models of their JSON bodies; slices are
+	// documented as arrays and nil means no body
+	responses map[int]interface{}
+	// media overrides the media types of success responses, which are then documented as strings
+	media []string
+	// errors are the status codes of errors specific to the operation; the others are covered
+	// by the default response
+	errors []int
+}
+
+// apiOperations documents the routes of GetRouter, keyed by method and chi route pattern
+var apiOperations = map[string]apiOperation{
+	"GET /": {
+		id:        "listTodos",
+		summary:   "List todos",
+		params:    []string{"limit", "offset", "cursor", "sort", "order", "done", "due_before", "created_after", "created_before", "priority", "tag", "archived", "deleted", "fields"},
+		responses: map[int]interface{}{http.StatusOK: []Todo{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"POST /": {
+		id:        "createTodo",
+		summary:   "Create a todo, or validate it without storing it with dry_run",
+		params:    []string{IdempotencyKeyHeader, "create_only", "dry_run"},
+		body:      Todo{},
+		responses: map[int]interface{}{http.StatusCreated: Todo{}, http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge},
+	},
+	"PUT /": {
+		id:        "replaceTodos",
+		summary:   "Make the active todos match the request, deleting the missing ones",
+		params:    []string{"replace"},
+		body:      []Todo{},
+		responses: map[int]interface{}{http.StatusOK: ReplaceRes{}},
+		errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusRequestEntityTooLarge},
+	},
+	"GET /count": {
+		id:        "countTodos",
+		summary:   "Count todos matching the filters",
+		params:    []string{"done", "due_before", "created_after", "created_before", "priority", "tag", "archived", "deleted"},
+		responses: map[int]interface{}{http.StatusOK: CountRes{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"GET /stats": {
+		id:        "todoStats",
+		summary:   "Summarize todos matching the filters: total, completed, overdue and by priority",
+		params:    []string{"done", "due_before", "created_after", "created_before", "priority", "tag", "archived", "deleted"},
+		responses: map[int]interface{}{http.StatusOK: StatsRes{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"GET /search": {
+		id:        "searchTodos",
+		summary:   "List todos with text containing a query, ignoring case",
+		params:    []string{"q", "limit", "offset", "cursor", "sort", "order", "done", "due_before", "created_after", "created_before", "priority", "tag", "archived", "deleted", "fields"},
+		responses: map[int]interface{}{http.StatusOK: []Todo{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"GET /due-soon": {
+		id:        "listDueSoonTodos",
+		summary:   "List todos not done and due within a duration from now, sorted by due date",
+		params:    []string{"within", "limit", "offset", "cursor", "priority", "tag", "fields"},
+		responses: map[int]interface{}{http.StatusOK: []Todo{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"GET /stream": {
+		id:        "streamTodos",
+		summary:   "Stream changes of todos as Server-Sent Events",
+		responses: map[int]interface{}{http.StatusOK: nil},
+		media:     []string{"text/event-stream"},
+	},
+	"GET /ws": {
+		id:        "syncTodos",
+		summary:   "Sync todos both ways over a WebSocket connection",
+		responses: map[int]interface{}{http.StatusSwitchingProtocols: nil},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"GET /export": {
+		id:        "exportTodos",
+		summary:   "Export todos as an attachment",
+		params:    []string{"format", "done", "due_before", "created_after", "created_before", "priority", "tag", "archived", "deleted"},
+		responses: map[int]interface{}{http.StatusOK: nil},
+		media:     []string{"text/csv", "application/json"},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"POST /import": {
+		id:        "importTodos",
+		summary:   "Import todos from a CSV or JSON file",
+		upload:    true,
+		responses: map[int]interface{}{http.StatusOK: ImportRes{}},
+		errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusRequestEntityTooLarge},
+	},
+	"POST /import/stream": {
+		id:        "streamImportTodos",
+		summary:   "Import todos from NDJSON, one per line, streaming back the result of every line and a summary",
+		body:      Todo{},
+		bodyMedia: []string{ndjsonContentType},
+		responses: map[int]interface{}{http.StatusOK: nil},
+		media:     []string{ndjsonContentType},
+	},
+	"GET /batch": {
+		id:        "batchGetTodos",
+		summary:   "Get todos by ID, in the order of the IDs; IDs that aren't found are marked as not found",
+		params:    []string{"ids"},
+		responses: map[int]interface{}{http.StatusOK: BatchGetRes{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"POST /batch": {
+		id:        "batchCreateTodos",
+		summary:   "Create todos, all or none of them",
+		body:      []Todo{},
+		responses: map[int]interface{}{http.StatusOK: []Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusRequestEntityTooLarge},
+	},
+	"DELETE /batch": {
+		id:        "batchDeleteTodos",
+		summary:   "Delete todos",
+		body:      BatchDeleteReq{},
+		responses: map[int]interface{}{http.StatusOK: BatchDeleteRes{}},
+		errors:    []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge},
+	},
+	"POST /batch/complete": {
+		id:        "batchCompleteTodos",
+		summary:   "Mark done all the todos matching the filters",
+		params:    []string{"done", "due_before", "created_after", "created_before", "priority", "tag", "archived", "deleted"},
+		responses: map[int]interface{}{http.StatusOK: CountRes{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"POST /batch/uncomplete": {
+		id:        "batchUncompleteTodos",
+		summary:   "Mark not done all the todos matching the filters",
+		params:    []string{"done", "due_before", "created_after", "created_before", "priority", "tag", "archived", "deleted"},
+		responses: map[int]interface{}{http.StatusOK: CountRes{}},
+		errors:    []int{http.StatusBadRequest},
+	},
+	"GET /{todoID}/": {
+		id:        "getTodo",
+		summary:   "Get a todo",
+		params:    []string{"If-None-Match", "If-Modified-Since", "fields"},
+		responses: map[int]interface{}{http.StatusOK: Todo{}, http.StatusNotModified: nil},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	"HEAD /{todoID}/": {
+		id:        "headTodo",
+		summary:   "Check a todo exists, getting the headers of getTodo",
+		params:    []string{"If-None-Match", "If-Modified-Since"},
+		responses: map[int]interface{}{http.StatusOK: nil, http.StatusNotModified: nil},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	"PUT /{todoID}/": {
+		id:        "updateTodo",
+		summary:   "Replace a todo, or validate the replacement without storing it with dry_run",
+		params:    []string{"If-Match", "dry_run"},
+		body:      Todo{},
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge},
+	},
+	"PATCH /{todoID}/": {
+		id:        "patchTodo",
+		summary:   "Update the fields of a todo present in the request, with JSON Merge Patch semantics",
+		params:    []string{"If-Match"},
+		body:      TodoPatch{},
+		bodyMedia: []string{mergePatchContentType},
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge},
+	},
+	"DELETE /{todoID}/": {
+		id:        "deleteTodo",
+		summary:   "Move a todo to the trash or delete it permanently; DeleteRes is returned only to clients accepting application/json",
+		params:    []string{"hard"},
+		responses: map[int]interface{}{http.StatusNoContent: nil, http.StatusOK: DeleteRes{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	"POST /{todoID}/restore": {
+		id:        "restoreTodo",
+		summary:   "Move a todo out of the trash",
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	"POST /{todoID}/archive": {
+		id:        "archiveTodo",
+		summary:   "Archive a todo, hiding it from lists",
+		params:    []string{"If-Match"},
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed},
+	},
+	"POST /{todoID}/unarchive": {
+		id:        "unarchiveTodo",
+		summary:   "Unarchive a todo",
+		params:    []string{"If-Match"},
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed},
+	},
+	"POST /{todoID}/subtasks": {
+		id:        "addSubtask",
+		summary:   "Append a subtask to a todo",
+		params:    []string{"If-Match"},
+		body:      Subtask{},
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusRequestEntityTooLarge},
+	},
+	"PATCH /{todoID}/subtasks/{index}": {
+		id:        "updateSubtask",
+		summary:   "Update the fields of a subtask present in the request",
+		params:    []string{"If-Match", "complete_parent"},
+		body:      SubtaskPatch{},
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusRequestEntityTooLarge},
+	},
+	"DELETE /{todoID}/subtasks/{index}": {
+		id:        "removeSubtask",
+		summary:   "Remove a subtask from a todo",
+		params:    []string{"If-Match"},
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed},
+	},
+	"GET /{todoID}/comments": {
+		id:        "listComments",
+		summary:   "List the comments of a todo",
+		params:    []string{"limit", "offset", "comment_order"},
+		responses: map[int]interface{}{http.StatusOK: []Comment{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	"POST /{todoID}/comments": {
+		id:        "addComment",
+		summary:   "Comment a todo",
+		body:      Comment{},
+		responses: map[int]interface{}{http.StatusCreated: Comment{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusRequestEntityTooLarge},
+	},
+	"POST /{todoID}/move": {
+		id:        "moveTodo",
+		summary:   "Move a todo to another position of the list",
+		params:    []string{"If-Match"},
+		body:      MoveReq{},
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusRequestEntityTooLarge},
+	},
+	"POST /{todoID}/duplicate": {
+		id:           "duplicateTodo",
+		summary:      "Create a copy of a todo, not done and without due date, optionally overriding its fields",
+		body:         DuplicateReq{},
+		bodyOptional: true,
+		responses:    map[int]interface{}{http.StatusCreated: Todo{}},
+		errors:       []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge},
+	},
+	"POST /{todoID}/share": {
+		id:        "shareTodo",
+		summary:   "Share a todo with another user",
+		body:      Share{},
+		responses: map[int]interface{}{http.StatusOK: Todo{}},
+		errors:    []int{http.StatusBadRequest, http.StatusNotFound, http.StatusRequestEntityTooLarge},
+	},
+}
+
+// apiParameters are the query and header params of the API, keyed by the names used in apiOperations
+var apiParameters = map[string]map[string]interface{}{
+	"limit":             apiParam("query", "limit", "Max number of results; defaults to "+strconv.Itoa(DefaultPageSize)+" and is clamped to "+strconv.Itoa(DefaultMaxPageSize)+", reported in the "+limitClampedHeader+" header, unless configured otherwise", apiType("integer")),
+	"offset":            apiParam("query", "offset", "Number of results to skip", apiType("integer")),
+	"cursor":            apiParam("query", "cursor", "Opaque cursor of the next page, from the "+NextCursorHeader+" header of the previous one; it can't be used with offset and is valid only with the same sort and order", apiType("string")),
+	"sort":              apiParam("query", "sort", "Field to sort by; defaults to position", apiEnum("id", "text", "done", "created_at", "updated_at", "position")),
+	"order":             apiParam("query", "order", "Sort order; defaults to asc", apiEnum("asc", "desc")),
+	"comment_order":     apiParam("query", "order", "Sort order by creation time; defaults to desc, newest first", apiEnum("asc", "desc")),
+	"done":              apiParam("query", "done", "Match only todos done or not", apiType("boolean")),
+	"within":            apiParam("query", "within", "Duration from now the todos are due within, like 90m or 24h; defaults to 24h", apiType("string")),
+	"due_before":        apiParam("query", "due_before", "Match only todos due before an RFC3339 date-time", apiFormat("string", "date-time")),
+	"created_after":     apiParam("query", "created_after", "Match only todos created at or after an RFC3339 date-time", apiFormat("string", "date-time")),
+	"created_before":    apiParam("query", "created_before", "Match only todos created before an RFC3339 date-time", apiFormat("string", "date-time")),
+	"priority":          apiParam("query", "priority", "Match only todos with a priority", apiEnum(PriorityLow, PriorityMedium, PriorityHigh)),
+	"tag":               apiParam("query", "tag", "Match only todos with all of the tags; can be repeated", map[string]interface{}{"type": "array", "items": apiType("string")}),
+	"archived":          apiParam("query", "archived", "List archived todos instead of the other ones", apiType("boolean")),
+	"deleted":           apiParam("query", "deleted", "List todos in the trash instead of the other ones", apiType("boolean")),
+	"fields":            apiParam("query", "fields", "Comma separated list of the fields to return, like id,text,done; unknown fields are rejected and XML responses always have all of them", apiType("string")),
+	"ids":               apiParam("query", "ids", "Comma separated list of up to "+strconv.Itoa(maxBatchGetIDs)+" todo IDs", apiType("string")),
+	"q":                 apiParam("query", "q", "Text to search for", apiType("string")),
+	"format":            apiParam("query", "format", "Format of the export; defaults to csv", apiEnum("csv", "json")),
+	"replace":           apiParam("query", "replace", "Confirm that the todos missing from the request are deleted; required", apiType("boolean")),
+	"hard":              apiParam("query", "hard", "Delete the todo permanently instead of moving it to the trash", apiType("boolean")),
+	"dry_run":           apiParam("query", "dry_run", "Validate the request and respond with the todo that would be stored, without storing it; the response has the "+DryRunHeader+" header", apiType("boolean")),
+	"complete_parent":   apiParam("query", "complete_parent", "Mark the todo done when all of its subtasks are done", apiType("boolean")),
+	"If-Match":          apiParam("header", "If-Match", "ETag of the todo version the change is based on", apiType("string")),
+	"If-None-Match":     apiParam("header", "If-None-Match", "ETag of a cached todo version", apiType("string")),
+	"create_only":       apiParam("header", "If-None-Match", "* to create the todo only if no todo with its id exists, failing with 412 otherwise; requires the id", apiEnum("*")),
+	"If-Modified-Since": apiParam("header", "If-Modified-Since", "Last-Modified date of a cached todo version; ignored with If-None-Match", apiType("string")),
+	IdempotencyKeyHeader: apiParam("header", IdempotencyKeyHeader, "Key making retries of the request safe",
+		apiType("string")),
+}
+
+// apiPathParameters are the schemas of the URL params of routes
+var apiPathParameters = map[string]map[string]interface{}{
+	"todoID": {"type": "integer", "format": "int64", "minimum": 1},
+	"index":  apiType("integer"),
+}
+
+// apiRequired lists the required fields of models in request bodies
+var apiRequired = map[string][]string{
+	"Todo":           {"text"},
+	"Subtask":        {"text"},
+	"Comment":        {"text"},
+	"Share":          {"user"},
+	"MoveReq":        {"position"},
+	"Recurrence":     {"frequency"},
+	"BatchDeleteReq": {"ids"},
+}
+
+// apiEnums lists the allowed values of model fields, keyed by model and JSON field name
+var apiEnums = map[string][]string{
+	"Todo.priority":        {PriorityLow, PriorityMedium, PriorityHigh},
+	"TodoPatch.priority":   {PriorityLow, PriorityMedium, PriorityHigh},
+	"Recurrence.frequency": {"daily", "weekly", "monthly"},
+	"Share.permission":     {PermissionView, PermissionEdit},
+	"ErrorRes.code":        errorCodeNames(),
+}
+
+// errorCodeNames returns the names of errorCodes
+func errorCodeNames() []string {
+	names := make([]string, len(errorCodes))
+	for i, code := range errorCodes {
+		names[i] = string(code)
+	}
+	return names
+}
+
+// urlParamRegexp matches chi URL params, optionally with a regexp they must match
+var urlParamRegexp = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// NewOpenAPISpec returns the OpenAPI 3 document of the todo routes served under basePath, built by
+// walking the chi route tree; the schemas of models are derived from their JSON encoding
+func NewOpenAPISpec(apiVersion, basePath string, routes chi.Routes) (map[string]interface{}, error) {
+	schemas := map[string]interface{}{}
+	errorSchema := apiSchema(reflect.TypeOf(ErrorRes{}), schemas)
+	errorResponse := func(status int) map[string]interface{} {
+		description := "Error"
+		if status > 0 {
+			description = http.StatusText(status)
+		}
+		return map[string]interface{}{
+			"description": description,
+			"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": errorSchema}},
+		}
+	}
+
+	paths := map[string]map[string]interface{}{}
+	err := chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		// OPTIONS handlers are generated from the other routes
+		if method == http.MethodOptions {
+			return nil
+		}
+		route = walkedPattern(route)
+		path := urlParamRegexp.ReplaceAllString(route, "{$1}")
+		if path != "/" {
+			path = strings.TrimSuffix(path, "/")
+		}
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+
+		op := apiOperations[method+" "+route]
+		responses := map[string]interface{}{"default": errorResponse(0)}
+		for status, model := range op.responses {
+			res := map[string]interface{}{"description": http.StatusText(status)}
+			if len(op.media) > 0 {
+				content := map[string]interface{}{}
+				for _, mediaType := range op.media {
+					content[mediaType] = map[string]interface{}{"schema": apiType("string")}
+				}
+				res["content"] = content
+			} else if model != nil {
+				res["content"] = apiJSONContent(apiSchema(reflect.TypeOf(model), schemas))
+			}
+			responses[strconv.Itoa(status)] = res
+		}
+		for _, status := range op.errors {
+			responses[strconv.Itoa(status)] = errorResponse(status)
+		}
+		operation := map[string]interface{}{"responses": responses}
+		if op.id != "" {
+			operation["operationId"] = op.id
+			operation["summary"] = op.summary
+		}
+
+		var params []interface{}
+		for _, match := range urlParamRegexp.FindAllStringSubmatch(route, -1) {
+			schema, found := apiPathParameters[match[1]]
+			if !found {
+				schema = apiType("string")
+			}
+			params = append(params, map[string]interface{}{"name": match[1], "in": "path", "required": true, "schema": schema})
+		}
+		for _, name := range op.params {
+			param, found := apiParameters[name]
+			if !found {
+				return fmt.Errorf("operation %s %s has undefined param %q", method, route, name)
+			}
+			params = append(params, param)
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+
+		if op.body != nil {
+			schema := apiSchema(reflect.TypeOf(op.body), schemas)
+			content := apiJSONContent(schema)
+			for _, mediaType := range op.bodyMedia {
+				content[mediaType] = map[string]interface{}{"schema": schema}
+			}
+			operation["requestBody"] = map[string]interface{}{
+				"required": !op.bodyOptional,
+				"content":  content,
+			}
+		} else if op.upload {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{"multipart/form-data": map[string]interface{}{"schema": map[string]interface{}{
+					"type":       "object",
+					"required":   []string{importFileField},
+					"properties": map[string]interface{}{importFileField: apiFormat("string", "binary")},
+				}}},
+			}
+		}
+		paths[path][strings.ToLower(method)] = operation
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   "Todo API",
+			"version": apiVersion,
+		},
+		"servers":    []interface{}{map[string]interface{}{"url": basePath}},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}, nil
+}
+
+// NewOpenAPIHandler returns a handler serving the document built by NewOpenAPISpec as JSON
+func NewOpenAPIHandler(apiVersion, basePath string, routes chi.Routes) (http.HandlerFunc, error) {
+	spec, err := NewOpenAPISpec(apiVersion, basePath, routes)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(data)
+	}, nil
+}
+
+// apiSchema returns the schema of the JSON encoding of a Go type; structs are added to schemas,
+// named after their type, and referenced
+func apiSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// optional values of patches wrap a nullable Value
+	if t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		if value, found := t.FieldByName("Value"); found {
+			schema := apiSchema(value.Type, schemas)
+			if _, isRef := schema["$ref"]; isRef {
+				return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+			}
+			schema["nullable"] = true
+			return schema
+		}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return apiType("boolean")
+	case reflect.String:
+		return apiType("string")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return apiFormat("integer", "int32")
+	case reflect.Int64, reflect.Uint64:
+		return apiFormat("integer", "int64")
+	case reflect.Float32, reflect.Float64:
+		return apiType("number")
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": apiSchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if _, found := schemas[t.Name()]; found {
+			return ref
+		}
+		// the name is reserved before adding the fields, so recursive models terminate
+		schemas[t.Name()] = nil
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if field.PkgPath != "" || name == "-" {
+				continue
+			}
+			if name == "" && field.Anonymous {
+				// the fields of embedded structs are encoded inline
+				embedded := field.Type
+				if embedded.Kind() == reflect.Ptr {
+					embedded = embedded.Elem()
+				}
+				apiSchema(embedded, schemas)
+				if schema, ok := schemas[embedded.Name()].(map[string]interface{}); ok {
+					for name, property := range schema["properties"].(map[string]interface{}) {
+						properties[name] = property
+					}
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			property := apiSchema(field.Type, schemas)
+			if enum, found := apiEnums[t.Name()+"."+name]; found {
+				property["enum"] = enum
+			}
+			properties[name] = property
+		}
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if required, found := apiRequired[t.Name()]; found {
+			schema["required"] = required
+		}
+		schemas[t.Name()] = schema
+		return ref
+	}
+	return map[string]interface{}{}
+}
+
+// apiJSONContent returns the content of a JSON request or response body
+func apiJSONContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// apiParam returns a query or header param
+func apiParam(in, name, description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"name": name, "in": in, "description": description, "schema": schema}
+}
+
+func apiType(typ string) map[string]interface{} {
+	return map[string]interface{}{"type": typ}
+}
+
+func apiFormat(typ, format string) map[string]interface{} {
+	return map[string]interface{}{"type": typ, "format": format}
+}
+
+func apiEnum(values ...string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "enum": values}
+}
@@ -0,0 +1,211 @@
+package todo
+
+import (
+	"crypto/tls"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// DefaultCallTimeout is the default timeout of a single gRPC call to todo-manager
+	DefaultCallTimeout = 5 * time.Second
+	// DefaultRetryMaxAttempts is the default max number of attempts of idempotent gRPC calls
+	DefaultRetryMaxAttempts = 3
+	// DefaultRetryBackoff is the default base wait time between retries, growing exponentially
+	DefaultRetryBackoff = 100 * time.Millisecond
+	// DefaultCircuitBreakerThreshold is the default number of consecutive failed gRPC calls opening
+	// the circuit breaker
+	DefaultCircuitBreakerThreshold = 5
+	// DefaultCircuitBreakerCooldown is the default time the circuit breaker stays open before letting
+	// a call probe todo-manager
+	DefaultCircuitBreakerCooldown = 10 * time.Second
+	// DefaultKeepaliveTime is the default interval of keepalive pings sent to todo-manager; it has
+	// to be longer than the MinTime of todo-manager's keepalive enforcement policy
+	DefaultKeepaliveTime = 30 * time.Second
+	// DefaultKeepaliveTimeout is the default time to wait for a keepalive ping ack before the
+	// connection is considered dead and re-established
+	DefaultKeepaliveTimeout = 10 * time.Second
+	// DefaultMaxRecvMsgSize is the default max size of a gRPC message received from todo-manager,
+	// the default of gRPC
+	DefaultMaxRecvMsgSize = 4 << 20
+	// DefaultMaxSendMsgSize is the default max size of a gRPC message sent to todo-manager, the
+	// default of gRPC
+	DefaultMaxSendMsgSize = math.MaxInt32
+	// DefaultWebhookTimeout is the default timeout of a single webhook delivery attempt
+	DefaultWebhookTimeout = 5 * time.Second
+	// DefaultWebhookMaxAttempts is the default max number of attempts of a webhook delivery
+	DefaultWebhookMaxAttempts = 3
+	// DefaultWebhookBackoff is the default base wait time between webhook delivery attempts, growing exponentially
+	DefaultWebhookBackoff = time.Second
+)
+
+// RouterOptions allows to override default Router options
+type RouterOptions struct {
+	// TLSConfig configures TLS of the gRPC connection to todo-manager; takes precedence over TLSCAFile
+	TLSConfig *tls.Config
+	// TLSCAFile is a path to the PEM encoded CA certificate used to verify todo-manager's certificate
+	TLSCAFile string
+	// Insecure allows a plaintext gRPC connection to todo-manager; it has to be set explicitly
+	// when no TLS is configured
+	Insecure bool
+	// CORSAllowedOrigins lists origins allowed to make cross-origin requests; all origins
+	// are allowed by default
+	CORSAllowedOrigins []string
+	// CORSAllowedMethods lists methods allowed in cross-origin requests; defaults to
+	// the methods used by the todo API
+	CORSAllowedMethods []string
+	// CORSAllowedHeaders lists headers allowed in cross-origin requests
+	CORSAllowedHeaders []string
+	// RateLimit is the number of requests per second allowed for each owner; rate limiting
+	// is disabled when 0
+	RateLimit float64
+	// RateLimitBurst is the max number of requests allowed at once for each owner; defaults
+	// to RateLimit rounded up
+	RateLimitBurst int
+	// TraceStartOptions are applied to the client spans of gRPC calls to todo-manager; the
+	// global trace config is used by default
+	TraceStartOptions trace.StartOptions
+	// CallTimeout limits the duration of every gRPC call to todo-manager; defaults to DefaultCallTimeout
+	CallTimeout time.Duration
+	// HandlerTimeout limits the time handlers of requests that aren't streamed can take to respond,
+	// they're answered with 503 Service Unavailable after it; defaults to DefaultHandlerTimeout,
+	// a negative value disables it
+	HandlerTimeout time.Duration
+	// RetryMaxAttempts is the max number of attempts of idempotent gRPC calls failing with transient
+	// errors; defaults to DefaultRetryMaxAttempts, 1 disables retries
+	RetryMaxAttempts uint
+	// RetryBackoff is the base wait time between retries; defaults to DefaultRetryBackoff
+	RetryBackoff time.Duration
+	// CircuitBreakerThreshold is the number of consecutive gRPC calls failing because todo-manager is
+	// unavailable or too slow that open the circuit breaker, rejecting calls with 503 Service Unavailable
+	// right away; defaults to DefaultCircuitBreakerThreshold, a negative value disables it
+	CircuitBreakerThreshold int
+	// CircuitBreakerCooldown is the time the circuit breaker stays open before a call is let through to
+	// check if todo-manager recovered; defaults to DefaultCircuitBreakerCooldown
+	CircuitBreakerCooldown time.Duration
+	// KeepaliveTime is the interval of keepalive pings sent to todo-manager, also while there's no active
+	// call; defaults to DefaultKeepaliveTime
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is the time to wait for a keepalive ping ack; defaults to DefaultKeepaliveTimeout
+	KeepaliveTimeout time.Duration
+	// MaxRecvMsgSize is the max size in bytes of a gRPC message received from todo-manager, like a
+	// todo with many subtasks; defaults to DefaultMaxRecvMsgSize
+	MaxRecvMsgSize int
+	// MaxSendMsgSize is the max size in bytes of a gRPC message sent to todo-manager; defaults to
+	// DefaultMaxSendMsgSize. todo-manager has its own limit for the messages it receives.
+	MaxSendMsgSize int
+	// TodoCacheTTL is the time for which the todos got by GetTodo are cached, invalidated by the
+	// changes made through the router; todos are always got from todo-manager when 0, the default
+	TodoCacheTTL time.Duration
+	// IdempotencyKeyTTL is the time for which the result of a create request with an Idempotency-Key
+	// is remembered; defaults to DefaultIdempotencyKeyTTL
+	IdempotencyKeyTTL time.Duration
+	// WebhookURLs lists the URLs todo events are POSTed to; webhooks are disabled when empty
+	WebhookURLs []string
+	// WebhookSecret is the key used to sign webhook payloads
+	WebhookSecret string
+	// WebhookTimeout limits every webhook delivery attempt; defaults to DefaultWebhookTimeout
+	WebhookTimeout time.Duration
+	// WebhookMaxAttempts is the max number of attempts of a webhook delivery failing with transient
+	// errors; defaults to DefaultWebhookMaxAttempts
+	WebhookMaxAttempts uint
+	// WebhookBackoff is the base wait time between webhook delivery attempts; defaults to DefaultWebhookBackoff
+	WebhookBackoff time.Duration
+	// ImportMaxBytes is the max size of an import request; defaults to DefaultImportMaxBytes
+	ImportMaxBytes int64
+	// MaxBodyBytes is the max size of the JSON body of requests; defaults to DefaultMaxBodyBytes
+	MaxBodyBytes int64
+	// LogPayloads logs the request and response bodies of every request with Logger, for debugging;
+	// it shouldn't be enabled in production
+	LogPayloads bool
+	// PayloadLogMaxBytes is the number of bytes of every body logged with LogPayloads; defaults to
+	// DefaultPayloadLogMaxBytes
+	PayloadLogMaxBytes int
+	// PayloadLogRedactedFields lists the JSON fields whose values are redacted from bodies logged
+	// with LogPayloads; defaults to DefaultPayloadLogRedactedFields
+	PayloadLogRedactedFields []string
+	// Registerer registers the metrics of the router; defaults to prometheus.DefaultRegisterer
+	Registerer prometheus.Registerer
+	// Logger is used to log errors returned by todo-manager and panics of handlers; defaults to the logrus standard logger
+	Logger logrus.FieldLogger
+}
+
+func (o *RouterOptions) fillDefaults() {
+	if len(o.CORSAllowedOrigins) == 0 {
+		o.CORSAllowedOrigins = []string{"*"}
+	}
+	if len(o.CORSAllowedMethods) == 0 {
+		o.CORSAllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+	}
+	if len(o.CORSAllowedHeaders) == 0 {
+		o.CORSAllowedHeaders = []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match", IdempotencyKeyHeader, RequestTimeoutHeader, APIKeyHeader}
+	}
+	if o.CallTimeout == 0 {
+		o.CallTimeout = DefaultCallTimeout
+	}
+	if o.HandlerTimeout == 0 {
+		o.HandlerTimeout = DefaultHandlerTimeout
+	}
+	if o.RetryMaxAttempts == 0 {
+		o.RetryMaxAttempts = DefaultRetryMaxAttempts
+	}
+	if o.RetryBackoff == 0 {
+		o.RetryBackoff = DefaultRetryBackoff
+	}
+	if o.CircuitBreakerThreshold == 0 {
+		o.CircuitBreakerThreshold = DefaultCircuitBreakerThreshold
+	}
+	if o.CircuitBreakerCooldown == 0 {
+		o.CircuitBreakerCooldown = DefaultCircuitBreakerCooldown
+	}
+	if o.KeepaliveTime == 0 {
+		o.KeepaliveTime = DefaultKeepaliveTime
+	}
+	if o.KeepaliveTimeout == 0 {
+		o.KeepaliveTimeout = DefaultKeepaliveTimeout
+	}
+	if o.MaxRecvMsgSize == 0 {
+		o.MaxRecvMsgSize = DefaultMaxRecvMsgSize
+	}
+	if o.MaxSendMsgSize == 0 {
+		o.MaxSendMsgSize = DefaultMaxSendMsgSize
+	}
+	if o.IdempotencyKeyTTL == 0 {
+		o.IdempotencyKeyTTL = DefaultIdempotencyKeyTTL
+	}
+	if o.WebhookTimeout == 0 {
+		o.WebhookTimeout = DefaultWebhookTimeout
+	}
+	if o.WebhookMaxAttempts == 0 {
+		o.WebhookMaxAttempts = DefaultWebhookMaxAttempts
+	}
+	if o.WebhookBackoff == 0 {
+		o.WebhookBackoff = DefaultWebhookBackoff
+	}
+	if o.ImportMaxBytes == 0 {
+		o.ImportMaxBytes = DefaultImportMaxBytes
+	}
+	if o.MaxBodyBytes == 0 {
+		o.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if o.PayloadLogMaxBytes == 0 {
+		o.PayloadLogMaxBytes = DefaultPayloadLogMaxBytes
+	}
+	if o.PayloadLogRedactedFields == nil {
+		o.PayloadLogRedactedFields = DefaultPayloadLogRedactedFields
+	}
+	if o.Registerer == nil {
+		o.Registerer = prometheus.DefaultRegisterer
+	}
+	if o.Logger == nil {
+		o.Logger = logrus.StandardLogger()
+	}
+	if o.RateLimit > 0 && o.RateLimitBurst == 0 {
+		o.RateLimitBurst = int(math.Ceil(o.RateLimit))
+	}
+}
@@ -0,0 +1,151 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultPayloadLogMaxBytes is the default number of bytes of request and response bodies logged
+const DefaultPayloadLogMaxBytes = 4 << 10
+
+// DefaultPayloadLogRedactedFields lists the JSON fields redacted from logged bodies by default
+var DefaultPayloadLogRedactedFields = []string{"password", "secret", "token", "api_key", "authorization"}
+
+// redactedValue replaces the values of redacted fields in logged bodies
+const redactedValue = "[REDACTED]"
+
+// NewPayloadLoggingMiddleware returns a middleware logging the request and response bodies of every
+// request, up to maxBytes each, at info level; it's meant for debugging clients and shouldn't be
+// used in production. Only the first maxBytes of the request body are buffered and then replayed
+// to the handler, so large uploads aren't held in memory. The values of the JSON fields named in
+// redactedFields, at any depth and ignoring case, are replaced with [REDACTED]; in truncated bodies
+// only string values can be found.
+func NewPayloadLoggingMiddleware(logger logrus.FieldLogger, maxBytes int, redactedFields []string) func(http.Handler) http.Handler {
+	redactor := newPayloadRedactor(redactedFields)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var reqBody []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				// errors are left to the handler, which gets them reading the rest of the body
+				reqBody, _ = ioutil.ReadAll(io.LimitReader(r.Body, int64(maxBytes)+1))
+				r.Body = &replayedBody{Reader: io.MultiReader(bytes.NewReader(reqBody), r.Body), Closer: r.Body}
+			}
+			resBody := &truncatingBuffer{max: maxBytes}
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(resBody)
+			next.ServeHTTP(ww, r)
+
+			reqTruncated := len(reqBody) > maxBytes
+			if reqTruncated {
+				reqBody = reqBody[:maxBytes]
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			logger.WithFields(logrus.Fields{
+				"req_id":                  chimiddleware.GetReqID(r.Context()),
+				"method":                  r.Method,
+				"uri":                     r.RequestURI,
+				"status":                  status,
+				"request_body":            redactor.redact(reqBody, reqTruncated),
+				"request_body_truncated":  reqTruncated,
+				"response_body":           redactor.redact(resBody.Bytes(), resBody.truncated),
+				"response_body_truncated": resBody.truncated,
+			}).Info("HTTP payloads")
+		})
+	}
+}
+
+// replayedBody is a request body whose beginning was already read by the payload logging
+// middleware and is read again before the rest of it
+type replayedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// truncatingBuffer keeps the first max bytes written to it and drops the rest
+type truncatingBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// Write implements io.Writer; it never fails, so that it doesn't break the response it copies
+func (b *truncatingBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); len(p) > room {
+		b.Buffer.Write(p[:room])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// payloadRedactor replaces the values of sensitive fields in logged JSON bodies
+type payloadRedactor struct {
+	fields map[string]bool
+	// strings matches string values of the fields, also cut off at the end of truncated bodies
+	strings *regexp.Regexp
+}
+
+// newPayloadRedactor returns a payloadRedactor of fields, matched ignoring case
+func newPayloadRedactor(fields []string) *payloadRedactor {
+	p := &payloadRedactor{fields: map[string]bool{}}
+	quoted := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			p.fields[strings.ToLower(field)] = true
+			quoted = append(quoted, regexp.QuoteMeta(field))
+		}
+	}
+	if len(quoted) > 0 {
+		p.strings = regexp.MustCompile(`(?i)"(` + strings.Join(quoted, "|") + `)"\s*:\s*"(?:[^"\\]|\\.)*"?`)
+	}
+	return p
+}
+
+// redact returns body with the values of the redacted fields replaced. Complete JSON bodies are
+// decoded to find fields with any value; truncated or invalid ones are searched for string values.
+func (p *payloadRedactor) redact(body []byte, truncated bool) string {
+	if len(p.fields) == 0 || len(body) == 0 {
+		return string(body)
+	}
+	if !truncated {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err == nil && !decoder.More() {
+			if redacted, err := json.Marshal(p.redactValue(value)); err == nil {
+				return string(redacted)
+			}
+		}
+	}
+	return p.strings.ReplaceAllString(string(body), `"$1":"`+redactedValue+`"`)
+}
+
+// redactValue replaces the values of the redacted fields in a decoded JSON value
+func (p *payloadRedactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if p.fields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = p.redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = p.redactValue(item)
+		}
+	}
+	return value
+}
@@ -7,6 +7,8 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -24,21 +26,226 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type Priority int32
+
+const (
+	Priority_PRIORITY_UNSPECIFIED Priority = 0
+	Priority_LOW                  Priority = 1
+	Priority_MEDIUM               Priority = 2
+	Priority_HIGH                 Priority = 3
+)
+
+var Priority_name = map[int32]string{
+	0: "PRIORITY_UNSPECIFIED",
+	1: "LOW",
+	2: "MEDIUM",
+	3: "HIGH",
+}
+
+var Priority_value = map[string]int32{
+	"PRIORITY_UNSPECIFIED": 0,
+	"LOW":                  1,
+	"MEDIUM":               2,
+	"HIGH":                 3,
+}
+
+func (x Priority) String() string {
+	return proto.EnumName(Priority_name, int32(x))
+}
+
+func (Priority) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{0}
+}
+
+type Frequency int32
+
+const (
+	Frequency_FREQUENCY_UNSPECIFIED Frequency = 0
+	Frequency_DAILY                 Frequency = 1
+	Frequency_WEEKLY                Frequency = 2
+	Frequency_MONTHLY               Frequency = 3
+)
+
+var Frequency_name = map[int32]string{
+	0: "FREQUENCY_UNSPECIFIED",
+	1: "DAILY",
+	2: "WEEKLY",
+	3: "MONTHLY",
+}
+
+var Frequency_value = map[string]int32{
+	"FREQUENCY_UNSPECIFIED": 0,
+	"DAILY":                 1,
+	"WEEKLY":                2,
+	"MONTHLY":               3,
+}
+
+func (x Frequency) String() string {
+	return proto.EnumName(Frequency_name, int32(x))
+}
+
+func (Frequency) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{1}
+}
+
+type Permission int32
+
+const (
+	Permission_PERMISSION_UNSPECIFIED Permission = 0
+	Permission_VIEW                   Permission = 1
+	Permission_EDIT                   Permission = 2
+)
+
+var Permission_name = map[int32]string{
+	0: "PERMISSION_UNSPECIFIED",
+	1: "VIEW",
+	2: "EDIT",
+}
+
+var Permission_value = map[string]int32{
+	"PERMISSION_UNSPECIFIED": 0,
+	"VIEW":                   1,
+	"EDIT":                   2,
+}
+
+func (x Permission) String() string {
+	return proto.EnumName(Permission_name, int32(x))
+}
+
+func (Permission) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{2}
+}
+
+type ListTodosReq_Order int32
+
+const (
+	ListTodosReq_ASC  ListTodosReq_Order = 0
+	ListTodosReq_DESC ListTodosReq_Order = 1
+)
+
+var ListTodosReq_Order_name = map[int32]string{
+	0: "ASC",
+	1: "DESC",
+}
+
+var ListTodosReq_Order_value = map[string]int32{
+	"ASC":  0,
+	"DESC": 1,
+}
+
+func (x ListTodosReq_Order) String() string {
+	return proto.EnumName(ListTodosReq_Order_name, int32(x))
+}
+
+func (ListTodosReq_Order) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{15, 0}
+}
+
+type TodoEvent_Type int32
+
+const (
+	TodoEvent_TYPE_UNSPECIFIED TodoEvent_Type = 0
+	TodoEvent_CREATED          TodoEvent_Type = 1
+	TodoEvent_UPDATED          TodoEvent_Type = 2
+	TodoEvent_DELETED          TodoEvent_Type = 3
+)
+
+var TodoEvent_Type_name = map[int32]string{
+	0: "TYPE_UNSPECIFIED",
+	1: "CREATED",
+	2: "UPDATED",
+	3: "DELETED",
+}
+
+var TodoEvent_Type_value = map[string]int32{
+	"TYPE_UNSPECIFIED": 0,
+	"CREATED":          1,
+	"UPDATED":          2,
+	"DELETED":          3,
+}
+
+func (x TodoEvent_Type) String() string {
+	return proto.EnumName(TodoEvent_Type_name, int32(x))
+}
+
+func (TodoEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{21, 0}
+}
+
+type Recurrence struct {
+	Frequency            Frequency `protobuf:"varint,1,opt,name=frequency,proto3,enum=todo_mgr.Frequency" json:"frequency,omitempty"`
+	Interval             uint32    `protobuf:"varint,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Recurrence) Reset()         { *m = Recurrence{} }
+func (m *Recurrence) String() string { return proto.CompactTextString(m) }
+func (*Recurrence) ProtoMessage()    {}
+func (*Recurrence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{0}
+}
+
+func (m *Recurrence) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Recurrence.Unmarshal(m, b)
+}
+func (m *Recurrence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Recurrence.Marshal(b, m, deterministic)
+}
+func (m *Recurrence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Recurrence.Merge(m, src)
+}
+func (m *Recurrence) XXX_Size() int {
+	return xxx_messageInfo_Recurrence.Size(m)
+}
+func (m *Recurrence) XXX_DiscardUnknown() {
+	xxx_messageInfo_Recurrence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Recurrence proto.InternalMessageInfo
+
+func (m *Recurrence) GetFrequency() Frequency {
+	if m != nil {
+		return m.Frequency
+	}
+	return Frequency_FREQUENCY_UNSPECIFIED
+}
+
+func (m *Recurrence) GetInterval() uint32 {
+	if m != nil {
+		return m.Interval
+	}
+	return 0
+}
+
 type Todo struct {
-	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Text                 string   `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
-	Done                 bool     `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
-	Owner                string   `protobuf:"bytes,4,opt,name=owner,proto3" json:"owner,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Id                   uint64               `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Text                 string               `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Done                 bool                 `protobuf:"varint,3,opt,name=done,proto3" json:"done,omitempty"`
+	Owner                string               `protobuf:"bytes,4,opt,name=owner,proto3" json:"owner,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt            *timestamp.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DueDate              *timestamp.Timestamp `protobuf:"bytes,7,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	Priority             Priority             `protobuf:"varint,8,opt,name=priority,proto3,enum=todo_mgr.Priority" json:"priority,omitempty"`
+	Tags                 []string             `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	Version              uint64               `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"`
+	DeletedAt            *timestamp.Timestamp `protobuf:"bytes,11,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	Archived             bool                 `protobuf:"varint,12,opt,name=archived,proto3" json:"archived,omitempty"`
+	Subtasks             []*Subtask           `protobuf:"bytes,13,rep,name=subtasks,proto3" json:"subtasks,omitempty"`
+	Recurrence           *Recurrence          `protobuf:"bytes,14,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	SharedWith           []*Share             `protobuf:"bytes,15,rep,name=shared_with,json=sharedWith,proto3" json:"shared_with,omitempty"`
+	Position             float64              `protobuf:"fixed64,16,opt,name=position,proto3" json:"position,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *Todo) Reset()         { *m = Todo{} }
 func (m *Todo) String() string { return proto.CompactTextString(m) }
 func (*Todo) ProtoMessage()    {}
 func (*Todo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{0}
+	return fileDescriptor_0e4b95d0c4e09639, []int{1}
 }
 
 func (m *Todo) XXX_Unmarshal(b []byte) error {
@@ -59,188 +266,1762 @@ func (m *Todo) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Todo proto.InternalMessageInfo
 
-func (m *Todo) GetId() uint64 {
+func (m *Todo) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Todo) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+func (m *Todo) GetDone() bool {
+	if m != nil {
+		return m.Done
+	}
+	return false
+}
+
+func (m *Todo) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Todo) GetCreatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return nil
+}
+
+func (m *Todo) GetUpdatedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return nil
+}
+
+func (m *Todo) GetDueDate() *timestamp.Timestamp {
+	if m != nil {
+		return m.DueDate
+	}
+	return nil
+}
+
+func (m *Todo) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_PRIORITY_UNSPECIFIED
+}
+
+func (m *Todo) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Todo) GetVersion() uint64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Todo) GetDeletedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.DeletedAt
+	}
+	return nil
+}
+
+func (m *Todo) GetArchived() bool {
+	if m != nil {
+		return m.Archived
+	}
+	return false
+}
+
+func (m *Todo) GetSubtasks() []*Subtask {
+	if m != nil {
+		return m.Subtasks
+	}
+	return nil
+}
+
+func (m *Todo) GetRecurrence() *Recurrence {
+	if m != nil {
+		return m.Recurrence
+	}
+	return nil
+}
+
+func (m *Todo) GetSharedWith() []*Share {
+	if m != nil {
+		return m.SharedWith
+	}
+	return nil
+}
+
+func (m *Todo) GetPosition() float64 {
+	if m != nil {
+		return m.Position
+	}
+	return 0
+}
+
+type Share struct {
+	User                 string     `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Permission           Permission `protobuf:"varint,2,opt,name=permission,proto3,enum=todo_mgr.Permission" json:"permission,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *Share) Reset()         { *m = Share{} }
+func (m *Share) String() string { return proto.CompactTextString(m) }
+func (*Share) ProtoMessage()    {}
+func (*Share) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{2}
+}
+
+func (m *Share) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Share.Unmarshal(m, b)
+}
+func (m *Share) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Share.Marshal(b, m, deterministic)
+}
+func (m *Share) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Share.Merge(m, src)
+}
+func (m *Share) XXX_Size() int {
+	return xxx_messageInfo_Share.Size(m)
+}
+func (m *Share) XXX_DiscardUnknown() {
+	xxx_messageInfo_Share.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Share proto.InternalMessageInfo
+
+func (m *Share) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *Share) GetPermission() Permission {
+	if m != nil {
+		return m.Permission
+	}
+	return Permission_PERMISSION_UNSPECIFIED
+}
+
+type ShareTodoReq struct {
+	Id                   uint64     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner                string     `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	User                 string     `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Permission           Permission `protobuf:"varint,4,opt,name=permission,proto3,enum=todo_mgr.Permission" json:"permission,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ShareTodoReq) Reset()         { *m = ShareTodoReq{} }
+func (m *ShareTodoReq) String() string { return proto.CompactTextString(m) }
+func (*ShareTodoReq) ProtoMessage()    {}
+func (*ShareTodoReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{3}
+}
+
+func (m *ShareTodoReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ShareTodoReq.Unmarshal(m, b)
+}
+func (m *ShareTodoReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ShareTodoReq.Marshal(b, m, deterministic)
+}
+func (m *ShareTodoReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ShareTodoReq.Merge(m, src)
+}
+func (m *ShareTodoReq) XXX_Size() int {
+	return xxx_messageInfo_ShareTodoReq.Size(m)
+}
+func (m *ShareTodoReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ShareTodoReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ShareTodoReq proto.InternalMessageInfo
+
+func (m *ShareTodoReq) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *ShareTodoReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ShareTodoReq) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *ShareTodoReq) GetPermission() Permission {
+	if m != nil {
+		return m.Permission
+	}
+	return Permission_PERMISSION_UNSPECIFIED
+}
+
+type Subtask struct {
+	Text                 string   `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Done                 bool     `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Subtask) Reset()         { *m = Subtask{} }
+func (m *Subtask) String() string { return proto.CompactTextString(m) }
+func (*Subtask) ProtoMessage()    {}
+func (*Subtask) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{4}
+}
+
+func (m *Subtask) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Subtask.Unmarshal(m, b)
+}
+func (m *Subtask) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Subtask.Marshal(b, m, deterministic)
+}
+func (m *Subtask) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Subtask.Merge(m, src)
+}
+func (m *Subtask) XXX_Size() int {
+	return xxx_messageInfo_Subtask.Size(m)
+}
+func (m *Subtask) XXX_DiscardUnknown() {
+	xxx_messageInfo_Subtask.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Subtask proto.InternalMessageInfo
+
+func (m *Subtask) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+func (m *Subtask) GetDone() bool {
+	if m != nil {
+		return m.Done
+	}
+	return false
+}
+
+type TodoList struct {
+	Todos                []*Todo  `protobuf:"bytes,1,rep,name=todos,proto3" json:"todos,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TodoList) Reset()         { *m = TodoList{} }
+func (m *TodoList) String() string { return proto.CompactTextString(m) }
+func (*TodoList) ProtoMessage()    {}
+func (*TodoList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{5}
+}
+
+func (m *TodoList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TodoList.Unmarshal(m, b)
+}
+func (m *TodoList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TodoList.Marshal(b, m, deterministic)
+}
+func (m *TodoList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TodoList.Merge(m, src)
+}
+func (m *TodoList) XXX_Size() int {
+	return xxx_messageInfo_TodoList.Size(m)
+}
+func (m *TodoList) XXX_DiscardUnknown() {
+	xxx_messageInfo_TodoList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TodoList proto.InternalMessageInfo
+
+func (m *TodoList) GetTodos() []*Todo {
+	if m != nil {
+		return m.Todos
+	}
+	return nil
+}
+
+type CreateTodoRes struct {
+	Todo                 *Todo    `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	Code                 uint32   `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateTodoRes) Reset()         { *m = CreateTodoRes{} }
+func (m *CreateTodoRes) String() string { return proto.CompactTextString(m) }
+func (*CreateTodoRes) ProtoMessage()    {}
+func (*CreateTodoRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{6}
+}
+
+func (m *CreateTodoRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateTodoRes.Unmarshal(m, b)
+}
+func (m *CreateTodoRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateTodoRes.Marshal(b, m, deterministic)
+}
+func (m *CreateTodoRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateTodoRes.Merge(m, src)
+}
+func (m *CreateTodoRes) XXX_Size() int {
+	return xxx_messageInfo_CreateTodoRes.Size(m)
+}
+func (m *CreateTodoRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateTodoRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateTodoRes proto.InternalMessageInfo
+
+func (m *CreateTodoRes) GetTodo() *Todo {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
+func (m *CreateTodoRes) GetCode() uint32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *CreateTodoRes) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type TagList struct {
+	Tags                 []string `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TagList) Reset()         { *m = TagList{} }
+func (m *TagList) String() string { return proto.CompactTextString(m) }
+func (*TagList) ProtoMessage()    {}
+func (*TagList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{7}
+}
+
+func (m *TagList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TagList.Unmarshal(m, b)
+}
+func (m *TagList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TagList.Marshal(b, m, deterministic)
+}
+func (m *TagList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TagList.Merge(m, src)
+}
+func (m *TagList) XXX_Size() int {
+	return xxx_messageInfo_TagList.Size(m)
+}
+func (m *TagList) XXX_DiscardUnknown() {
+	xxx_messageInfo_TagList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TagList proto.InternalMessageInfo
+
+func (m *TagList) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+type TodoPatch struct {
+	Id                   uint64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner                string                `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Text                 *wrappers.StringValue `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	Done                 *wrappers.BoolValue   `protobuf:"bytes,4,opt,name=done,proto3" json:"done,omitempty"`
+	DueDate              *timestamp.Timestamp  `protobuf:"bytes,5,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	ClearDueDate         bool                  `protobuf:"varint,6,opt,name=clear_due_date,json=clearDueDate,proto3" json:"clear_due_date,omitempty"`
+	Priority             Priority              `protobuf:"varint,7,opt,name=priority,proto3,enum=todo_mgr.Priority" json:"priority,omitempty"`
+	Tags                 *TagList              `protobuf:"bytes,8,opt,name=tags,proto3" json:"tags,omitempty"`
+	ExpectedVersion      uint64                `protobuf:"varint,9,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	Archived             *wrappers.BoolValue   `protobuf:"bytes,10,opt,name=archived,proto3" json:"archived,omitempty"`
+	Recurrence           *Recurrence           `protobuf:"bytes,11,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	ClearRecurrence      bool                  `protobuf:"varint,12,opt,name=clear_recurrence,json=clearRecurrence,proto3" json:"clear_recurrence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *TodoPatch) Reset()         { *m = TodoPatch{} }
+func (m *TodoPatch) String() string { return proto.CompactTextString(m) }
+func (*TodoPatch) ProtoMessage()    {}
+func (*TodoPatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{8}
+}
+
+func (m *TodoPatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TodoPatch.Unmarshal(m, b)
+}
+func (m *TodoPatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TodoPatch.Marshal(b, m, deterministic)
+}
+func (m *TodoPatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TodoPatch.Merge(m, src)
+}
+func (m *TodoPatch) XXX_Size() int {
+	return xxx_messageInfo_TodoPatch.Size(m)
+}
+func (m *TodoPatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_TodoPatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TodoPatch proto.InternalMessageInfo
+
+func (m *TodoPatch) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *TodoPatch) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *TodoPatch) GetText() *wrappers.StringValue {
+	if m != nil {
+		return m.Text
+	}
+	return nil
+}
+
+func (m *TodoPatch) GetDone() *wrappers.BoolValue {
+	if m != nil {
+		return m.Done
+	}
+	return nil
+}
+
+func (m *TodoPatch) GetDueDate() *timestamp.Timestamp {
+	if m != nil {
+		return m.DueDate
+	}
+	return nil
+}
+
+func (m *TodoPatch) GetClearDueDate() bool {
+	if m != nil {
+		return m.ClearDueDate
+	}
+	return false
+}
+
+func (m *TodoPatch) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_PRIORITY_UNSPECIFIED
+}
+
+func (m *TodoPatch) GetTags() *TagList {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *TodoPatch) GetExpectedVersion() uint64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+func (m *TodoPatch) GetArchived() *wrappers.BoolValue {
+	if m != nil {
+		return m.Archived
+	}
+	return nil
+}
+
+func (m *TodoPatch) GetRecurrence() *Recurrence {
+	if m != nil {
+		return m.Recurrence
+	}
+	return nil
+}
+
+func (m *TodoPatch) GetClearRecurrence() bool {
+	if m != nil {
+		return m.ClearRecurrence
+	}
+	return false
+}
+
+type MoveTodoReq struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Position             uint32   `protobuf:"varint,3,opt,name=position,proto3" json:"position,omitempty"`
+	ExpectedVersion      uint64   `protobuf:"varint,4,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MoveTodoReq) Reset()         { *m = MoveTodoReq{} }
+func (m *MoveTodoReq) String() string { return proto.CompactTextString(m) }
+func (*MoveTodoReq) ProtoMessage()    {}
+func (*MoveTodoReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{9}
+}
+
+func (m *MoveTodoReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MoveTodoReq.Unmarshal(m, b)
+}
+func (m *MoveTodoReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MoveTodoReq.Marshal(b, m, deterministic)
+}
+func (m *MoveTodoReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MoveTodoReq.Merge(m, src)
+}
+func (m *MoveTodoReq) XXX_Size() int {
+	return xxx_messageInfo_MoveTodoReq.Size(m)
+}
+func (m *MoveTodoReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_MoveTodoReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MoveTodoReq proto.InternalMessageInfo
+
+func (m *MoveTodoReq) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *MoveTodoReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *MoveTodoReq) GetPosition() uint32 {
+	if m != nil {
+		return m.Position
+	}
+	return 0
+}
+
+func (m *MoveTodoReq) GetExpectedVersion() uint64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+type TodoIdReq struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TodoIdReq) Reset()         { *m = TodoIdReq{} }
+func (m *TodoIdReq) String() string { return proto.CompactTextString(m) }
+func (*TodoIdReq) ProtoMessage()    {}
+func (*TodoIdReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{10}
+}
+
+func (m *TodoIdReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TodoIdReq.Unmarshal(m, b)
+}
+func (m *TodoIdReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TodoIdReq.Marshal(b, m, deterministic)
+}
+func (m *TodoIdReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TodoIdReq.Merge(m, src)
+}
+func (m *TodoIdReq) XXX_Size() int {
+	return xxx_messageInfo_TodoIdReq.Size(m)
+}
+func (m *TodoIdReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_TodoIdReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TodoIdReq proto.InternalMessageInfo
+
+func (m *TodoIdReq) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *TodoIdReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+type DeleteTodoReq struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Hard                 bool     `protobuf:"varint,3,opt,name=hard,proto3" json:"hard,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteTodoReq) Reset()         { *m = DeleteTodoReq{} }
+func (m *DeleteTodoReq) String() string { return proto.CompactTextString(m) }
+func (*DeleteTodoReq) ProtoMessage()    {}
+func (*DeleteTodoReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{11}
+}
+
+func (m *DeleteTodoReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteTodoReq.Unmarshal(m, b)
+}
+func (m *DeleteTodoReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteTodoReq.Marshal(b, m, deterministic)
+}
+func (m *DeleteTodoReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteTodoReq.Merge(m, src)
+}
+func (m *DeleteTodoReq) XXX_Size() int {
+	return xxx_messageInfo_DeleteTodoReq.Size(m)
+}
+func (m *DeleteTodoReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteTodoReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteTodoReq proto.InternalMessageInfo
+
+func (m *DeleteTodoReq) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *DeleteTodoReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *DeleteTodoReq) GetHard() bool {
+	if m != nil {
+		return m.Hard
+	}
+	return false
+}
+
+type AddSubtaskReq struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Subtask              *Subtask `protobuf:"bytes,3,opt,name=subtask,proto3" json:"subtask,omitempty"`
+	ExpectedVersion      uint64   `protobuf:"varint,4,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddSubtaskReq) Reset()         { *m = AddSubtaskReq{} }
+func (m *AddSubtaskReq) String() string { return proto.CompactTextString(m) }
+func (*AddSubtaskReq) ProtoMessage()    {}
+func (*AddSubtaskReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{12}
+}
+
+func (m *AddSubtaskReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddSubtaskReq.Unmarshal(m, b)
+}
+func (m *AddSubtaskReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddSubtaskReq.Marshal(b, m, deterministic)
+}
+func (m *AddSubtaskReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddSubtaskReq.Merge(m, src)
+}
+func (m *AddSubtaskReq) XXX_Size() int {
+	return xxx_messageInfo_AddSubtaskReq.Size(m)
+}
+func (m *AddSubtaskReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddSubtaskReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddSubtaskReq proto.InternalMessageInfo
+
+func (m *AddSubtaskReq) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *AddSubtaskReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *AddSubtaskReq) GetSubtask() *Subtask {
+	if m != nil {
+		return m.Subtask
+	}
+	return nil
+}
+
+func (m *AddSubtaskReq) GetExpectedVersion() uint64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+type UpdateSubtaskReq struct {
+	Id                   uint64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner                string                `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Index                uint32                `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	Text                 *wrappers.StringValue `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	Done                 *wrappers.BoolValue   `protobuf:"bytes,5,opt,name=done,proto3" json:"done,omitempty"`
+	CompleteParent       bool                  `protobuf:"varint,6,opt,name=complete_parent,json=completeParent,proto3" json:"complete_parent,omitempty"`
+	ExpectedVersion      uint64                `protobuf:"varint,7,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *UpdateSubtaskReq) Reset()         { *m = UpdateSubtaskReq{} }
+func (m *UpdateSubtaskReq) String() string { return proto.CompactTextString(m) }
+func (*UpdateSubtaskReq) ProtoMessage()    {}
+func (*UpdateSubtaskReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{13}
+}
+
+func (m *UpdateSubtaskReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateSubtaskReq.Unmarshal(m, b)
+}
+func (m *UpdateSubtaskReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateSubtaskReq.Marshal(b, m, deterministic)
+}
+func (m *UpdateSubtaskReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateSubtaskReq.Merge(m, src)
+}
+func (m *UpdateSubtaskReq) XXX_Size() int {
+	return xxx_messageInfo_UpdateSubtaskReq.Size(m)
+}
+func (m *UpdateSubtaskReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateSubtaskReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateSubtaskReq proto.InternalMessageInfo
+
+func (m *UpdateSubtaskReq) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *UpdateSubtaskReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *UpdateSubtaskReq) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *UpdateSubtaskReq) GetText() *wrappers.StringValue {
+	if m != nil {
+		return m.Text
+	}
+	return nil
+}
+
+func (m *UpdateSubtaskReq) GetDone() *wrappers.BoolValue {
+	if m != nil {
+		return m.Done
+	}
+	return nil
+}
+
+func (m *UpdateSubtaskReq) GetCompleteParent() bool {
+	if m != nil {
+		return m.CompleteParent
+	}
+	return false
+}
+
+func (m *UpdateSubtaskReq) GetExpectedVersion() uint64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+type SubtaskIdReq struct {
+	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Index                uint32   `protobuf:"varint,3,opt,name=index,proto3" json:"index,omitempty"`
+	ExpectedVersion      uint64   `protobuf:"varint,4,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubtaskIdReq) Reset()         { *m = SubtaskIdReq{} }
+func (m *SubtaskIdReq) String() string { return proto.CompactTextString(m) }
+func (*SubtaskIdReq) ProtoMessage()    {}
+func (*SubtaskIdReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{14}
+}
+
+func (m *SubtaskIdReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubtaskIdReq.Unmarshal(m, b)
+}
+func (m *SubtaskIdReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubtaskIdReq.Marshal(b, m, deterministic)
+}
+func (m *SubtaskIdReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubtaskIdReq.Merge(m, src)
+}
+func (m *SubtaskIdReq) XXX_Size() int {
+	return xxx_messageInfo_SubtaskIdReq.Size(m)
+}
+func (m *SubtaskIdReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubtaskIdReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubtaskIdReq proto.InternalMessageInfo
+
+func (m *SubtaskIdReq) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *SubtaskIdReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *SubtaskIdReq) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *SubtaskIdReq) GetExpectedVersion() uint64 {
+	if m != nil {
+		return m.ExpectedVersion
+	}
+	return 0
+}
+
+type ListTodosReq struct {
+	Owner                string               `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Limit                uint32               `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset               uint32               `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Sort                 string               `protobuf:"bytes,4,opt,name=sort,proto3" json:"sort,omitempty"`
+	Order                ListTodosReq_Order   `protobuf:"varint,5,opt,name=order,proto3,enum=todo_mgr.ListTodosReq_Order" json:"order,omitempty"`
+	Done                 *wrappers.BoolValue  `protobuf:"bytes,6,opt,name=done,proto3" json:"done,omitempty"`
+	DueBefore            *timestamp.Timestamp `protobuf:"bytes,7,opt,name=due_before,json=dueBefore,proto3" json:"due_before,omitempty"`
+	Priority             Priority             `protobuf:"varint,8,opt,name=priority,proto3,enum=todo_mgr.Priority" json:"priority,omitempty"`
+	Tags                 []string             `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	TextQuery            string               `protobuf:"bytes,10,opt,name=text_query,json=textQuery,proto3" json:"text_query,omitempty"`
+	Deleted              bool                 `protobuf:"varint,11,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Archived             bool                 `protobuf:"varint,12,opt,name=archived,proto3" json:"archived,omitempty"`
+	CreatedAfter         *timestamp.Timestamp `protobuf:"bytes,13,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore        *timestamp.Timestamp `protobuf:"bytes,14,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	Cursor               string               `protobuf:"bytes,15,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	DueAfter             *timestamp.Timestamp `protobuf:"bytes,16,opt,name=due_after,json=dueAfter,proto3" json:"due_after,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ListTodosReq) Reset()         { *m = ListTodosReq{} }
+func (m *ListTodosReq) String() string { return proto.CompactTextString(m) }
+func (*ListTodosReq) ProtoMessage()    {}
+func (*ListTodosReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{15}
+}
+
+func (m *ListTodosReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListTodosReq.Unmarshal(m, b)
+}
+func (m *ListTodosReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListTodosReq.Marshal(b, m, deterministic)
+}
+func (m *ListTodosReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListTodosReq.Merge(m, src)
+}
+func (m *ListTodosReq) XXX_Size() int {
+	return xxx_messageInfo_ListTodosReq.Size(m)
+}
+func (m *ListTodosReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListTodosReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListTodosReq proto.InternalMessageInfo
+
+func (m *ListTodosReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *ListTodosReq) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ListTodosReq) GetOffset() uint32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *ListTodosReq) GetSort() string {
+	if m != nil {
+		return m.Sort
+	}
+	return ""
+}
+
+func (m *ListTodosReq) GetOrder() ListTodosReq_Order {
+	if m != nil {
+		return m.Order
+	}
+	return ListTodosReq_ASC
+}
+
+func (m *ListTodosReq) GetDone() *wrappers.BoolValue {
+	if m != nil {
+		return m.Done
+	}
+	return nil
+}
+
+func (m *ListTodosReq) GetDueBefore() *timestamp.Timestamp {
+	if m != nil {
+		return m.DueBefore
+	}
+	return nil
+}
+
+func (m *ListTodosReq) GetPriority() Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return Priority_PRIORITY_UNSPECIFIED
+}
+
+func (m *ListTodosReq) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *ListTodosReq) GetTextQuery() string {
+	if m != nil {
+		return m.TextQuery
+	}
+	return ""
+}
+
+func (m *ListTodosReq) GetDeleted() bool {
+	if m != nil {
+		return m.Deleted
+	}
+	return false
+}
+
+func (m *ListTodosReq) GetArchived() bool {
+	if m != nil {
+		return m.Archived
+	}
+	return false
+}
+
+func (m *ListTodosReq) GetCreatedAfter() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedAfter
+	}
+	return nil
+}
+
+func (m *ListTodosReq) GetCreatedBefore() *timestamp.Timestamp {
+	if m != nil {
+		return m.CreatedBefore
+	}
+	return nil
+}
+
+func (m *ListTodosReq) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *ListTodosReq) GetDueAfter() *timestamp.Timestamp {
+	if m != nil {
+		return m.DueAfter
+	}
+	return nil
+}
+
+type SetTodosDoneReq struct {
+	Filter               *ListTodosReq `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Done                 bool          `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *SetTodosDoneReq) Reset()         { *m = SetTodosDoneReq{} }
+func (m *SetTodosDoneReq) String() string { return proto.CompactTextString(m) }
+func (*SetTodosDoneReq) ProtoMessage()    {}
+func (*SetTodosDoneReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{16}
+}
+
+func (m *SetTodosDoneReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetTodosDoneReq.Unmarshal(m, b)
+}
+func (m *SetTodosDoneReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetTodosDoneReq.Marshal(b, m, deterministic)
+}
+func (m *SetTodosDoneReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetTodosDoneReq.Merge(m, src)
+}
+func (m *SetTodosDoneReq) XXX_Size() int {
+	return xxx_messageInfo_SetTodosDoneReq.Size(m)
+}
+func (m *SetTodosDoneReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetTodosDoneReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetTodosDoneReq proto.InternalMessageInfo
+
+func (m *SetTodosDoneReq) GetFilter() *ListTodosReq {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func (m *SetTodosDoneReq) GetDone() bool {
+	if m != nil {
+		return m.Done
+	}
+	return false
+}
+
+type CountTodosRes struct {
+	Count                uint64   `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CountTodosRes) Reset()         { *m = CountTodosRes{} }
+func (m *CountTodosRes) String() string { return proto.CompactTextString(m) }
+func (*CountTodosRes) ProtoMessage()    {}
+func (*CountTodosRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{17}
+}
+
+func (m *CountTodosRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CountTodosRes.Unmarshal(m, b)
+}
+func (m *CountTodosRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CountTodosRes.Marshal(b, m, deterministic)
+}
+func (m *CountTodosRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CountTodosRes.Merge(m, src)
+}
+func (m *CountTodosRes) XXX_Size() int {
+	return xxx_messageInfo_CountTodosRes.Size(m)
+}
+func (m *CountTodosRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_CountTodosRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CountTodosRes proto.InternalMessageInfo
+
+func (m *CountTodosRes) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type TodoStatsRes struct {
+	Total                uint64   `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Done                 uint64   `protobuf:"varint,2,opt,name=done,proto3" json:"done,omitempty"`
+	Overdue              uint64   `protobuf:"varint,3,opt,name=overdue,proto3" json:"overdue,omitempty"`
+	LowPriority          uint64   `protobuf:"varint,4,opt,name=low_priority,json=lowPriority,proto3" json:"low_priority,omitempty"`
+	MediumPriority       uint64   `protobuf:"varint,5,opt,name=medium_priority,json=mediumPriority,proto3" json:"medium_priority,omitempty"`
+	HighPriority         uint64   `protobuf:"varint,6,opt,name=high_priority,json=highPriority,proto3" json:"high_priority,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TodoStatsRes) Reset()         { *m = TodoStatsRes{} }
+func (m *TodoStatsRes) String() string { return proto.CompactTextString(m) }
+func (*TodoStatsRes) ProtoMessage()    {}
+func (*TodoStatsRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{18}
+}
+
+func (m *TodoStatsRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TodoStatsRes.Unmarshal(m, b)
+}
+func (m *TodoStatsRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TodoStatsRes.Marshal(b, m, deterministic)
+}
+func (m *TodoStatsRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TodoStatsRes.Merge(m, src)
+}
+func (m *TodoStatsRes) XXX_Size() int {
+	return xxx_messageInfo_TodoStatsRes.Size(m)
+}
+func (m *TodoStatsRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_TodoStatsRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TodoStatsRes proto.InternalMessageInfo
+
+func (m *TodoStatsRes) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *TodoStatsRes) GetDone() uint64 {
+	if m != nil {
+		return m.Done
+	}
+	return 0
+}
+
+func (m *TodoStatsRes) GetOverdue() uint64 {
+	if m != nil {
+		return m.Overdue
+	}
+	return 0
+}
+
+func (m *TodoStatsRes) GetLowPriority() uint64 {
+	if m != nil {
+		return m.LowPriority
+	}
+	return 0
+}
+
+func (m *TodoStatsRes) GetMediumPriority() uint64 {
+	if m != nil {
+		return m.MediumPriority
+	}
+	return 0
+}
+
+func (m *TodoStatsRes) GetHighPriority() uint64 {
+	if m != nil {
+		return m.HighPriority
+	}
+	return 0
+}
+
+type DeleteTodoRes struct {
+	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteTodoRes) Reset()         { *m = DeleteTodoRes{} }
+func (m *DeleteTodoRes) String() string { return proto.CompactTextString(m) }
+func (*DeleteTodoRes) ProtoMessage()    {}
+func (*DeleteTodoRes) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{19}
+}
+
+func (m *DeleteTodoRes) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteTodoRes.Unmarshal(m, b)
+}
+func (m *DeleteTodoRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteTodoRes.Marshal(b, m, deterministic)
+}
+func (m *DeleteTodoRes) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteTodoRes.Merge(m, src)
+}
+func (m *DeleteTodoRes) XXX_Size() int {
+	return xxx_messageInfo_DeleteTodoRes.Size(m)
+}
+func (m *DeleteTodoRes) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteTodoRes.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteTodoRes proto.InternalMessageInfo
+
+func (m *DeleteTodoRes) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+type WatchTodosReq struct {
+	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchTodosReq) Reset()         { *m = WatchTodosReq{} }
+func (m *WatchTodosReq) String() string { return proto.CompactTextString(m) }
+func (*WatchTodosReq) ProtoMessage()    {}
+func (*WatchTodosReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{20}
+}
+
+func (m *WatchTodosReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchTodosReq.Unmarshal(m, b)
+}
+func (m *WatchTodosReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchTodosReq.Marshal(b, m, deterministic)
+}
+func (m *WatchTodosReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchTodosReq.Merge(m, src)
+}
+func (m *WatchTodosReq) XXX_Size() int {
+	return xxx_messageInfo_WatchTodosReq.Size(m)
+}
+func (m *WatchTodosReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchTodosReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchTodosReq proto.InternalMessageInfo
+
+func (m *WatchTodosReq) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+type TodoEvent struct {
+	Type                 TodoEvent_Type `protobuf:"varint,1,opt,name=type,proto3,enum=todo_mgr.TodoEvent_Type" json:"type,omitempty"`
+	Todo                 *Todo          `protobuf:"bytes,2,opt,name=todo,proto3" json:"todo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *TodoEvent) Reset()         { *m = TodoEvent{} }
+func (m *TodoEvent) String() string { return proto.CompactTextString(m) }
+func (*TodoEvent) ProtoMessage()    {}
+func (*TodoEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{21}
+}
+
+func (m *TodoEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TodoEvent.Unmarshal(m, b)
+}
+func (m *TodoEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TodoEvent.Marshal(b, m, deterministic)
+}
+func (m *TodoEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TodoEvent.Merge(m, src)
+}
+func (m *TodoEvent) XXX_Size() int {
+	return xxx_messageInfo_TodoEvent.Size(m)
+}
+func (m *TodoEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_TodoEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TodoEvent proto.InternalMessageInfo
+
+func (m *TodoEvent) GetType() TodoEvent_Type {
+	if m != nil {
+		return m.Type
+	}
+	return TodoEvent_TYPE_UNSPECIFIED
+}
+
+func (m *TodoEvent) GetTodo() *Todo {
+	if m != nil {
+		return m.Todo
+	}
+	return nil
+}
+
+type Comment struct {
+	Id                   uint64               `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TodoId               uint64               `protobuf:"varint,2,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	Author               string               `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Text                 string               `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	CreatedAt            *timestamp.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Comment) Reset()         { *m = Comment{} }
+func (m *Comment) String() string { return proto.CompactTextString(m) }
+func (*Comment) ProtoMessage()    {}
+func (*Comment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{22}
+}
+
+func (m *Comment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Comment.Unmarshal(m, b)
+}
+func (m *Comment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Comment.Marshal(b, m, deterministic)
+}
+func (m *Comment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Comment.Merge(m, src)
+}
+func (m *Comment) XXX_Size() int {
+	return xxx_messageInfo_Comment.Size(m)
+}
+func (m *Comment) XXX_DiscardUnknown() {
+	xxx_messageInfo_Comment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Comment proto.InternalMessageInfo
+
+func (m *Comment) GetId() uint64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Comment) GetTodoId() uint64 {
 	if m != nil {
-		return m.Id
+		return m.TodoId
 	}
 	return 0
 }
 
-func (m *Todo) GetText() string {
+func (m *Comment) GetAuthor() string {
 	if m != nil {
-		return m.Text
+		return m.Author
 	}
 	return ""
 }
 
-func (m *Todo) GetDone() bool {
+func (m *Comment) GetText() string {
 	if m != nil {
-		return m.Done
+		return m.Text
 	}
-	return false
+	return ""
 }
 
-func (m *Todo) GetOwner() string {
+func (m *Comment) GetCreatedAt() *timestamp.Timestamp {
 	if m != nil {
-		return m.Owner
+		return m.CreatedAt
 	}
-	return ""
+	return nil
 }
 
-type TodoIdReq struct {
-	Id                   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+type AddCommentReq struct {
+	TodoId               uint64   `protobuf:"varint,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
 	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Text                 string   `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *TodoIdReq) Reset()         { *m = TodoIdReq{} }
-func (m *TodoIdReq) String() string { return proto.CompactTextString(m) }
-func (*TodoIdReq) ProtoMessage()    {}
-func (*TodoIdReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{1}
+func (m *AddCommentReq) Reset()         { *m = AddCommentReq{} }
+func (m *AddCommentReq) String() string { return proto.CompactTextString(m) }
+func (*AddCommentReq) ProtoMessage()    {}
+func (*AddCommentReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{23}
 }
 
-func (m *TodoIdReq) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_TodoIdReq.Unmarshal(m, b)
+func (m *AddCommentReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AddCommentReq.Unmarshal(m, b)
 }
-func (m *TodoIdReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_TodoIdReq.Marshal(b, m, deterministic)
+func (m *AddCommentReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AddCommentReq.Marshal(b, m, deterministic)
 }
-func (m *TodoIdReq) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_TodoIdReq.Merge(m, src)
+func (m *AddCommentReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddCommentReq.Merge(m, src)
 }
-func (m *TodoIdReq) XXX_Size() int {
-	return xxx_messageInfo_TodoIdReq.Size(m)
+func (m *AddCommentReq) XXX_Size() int {
+	return xxx_messageInfo_AddCommentReq.Size(m)
 }
-func (m *TodoIdReq) XXX_DiscardUnknown() {
-	xxx_messageInfo_TodoIdReq.DiscardUnknown(m)
+func (m *AddCommentReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddCommentReq.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_TodoIdReq proto.InternalMessageInfo
+var xxx_messageInfo_AddCommentReq proto.InternalMessageInfo
 
-func (m *TodoIdReq) GetId() uint64 {
+func (m *AddCommentReq) GetTodoId() uint64 {
 	if m != nil {
-		return m.Id
+		return m.TodoId
 	}
 	return 0
 }
 
-func (m *TodoIdReq) GetOwner() string {
+func (m *AddCommentReq) GetOwner() string {
 	if m != nil {
 		return m.Owner
 	}
 	return ""
 }
 
-type ListTodosReq struct {
-	Owner                string   `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+func (m *AddCommentReq) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+type ListCommentsReq struct {
+	TodoId               uint64   `protobuf:"varint,1,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	Owner                string   `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Limit                uint32   `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset               uint32   `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	OldestFirst          bool     `protobuf:"varint,5,opt,name=oldest_first,json=oldestFirst,proto3" json:"oldest_first,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *ListTodosReq) Reset()         { *m = ListTodosReq{} }
-func (m *ListTodosReq) String() string { return proto.CompactTextString(m) }
-func (*ListTodosReq) ProtoMessage()    {}
-func (*ListTodosReq) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{2}
+func (m *ListCommentsReq) Reset()         { *m = ListCommentsReq{} }
+func (m *ListCommentsReq) String() string { return proto.CompactTextString(m) }
+func (*ListCommentsReq) ProtoMessage()    {}
+func (*ListCommentsReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{24}
 }
 
-func (m *ListTodosReq) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_ListTodosReq.Unmarshal(m, b)
+func (m *ListCommentsReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListCommentsReq.Unmarshal(m, b)
 }
-func (m *ListTodosReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_ListTodosReq.Marshal(b, m, deterministic)
+func (m *ListCommentsReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListCommentsReq.Marshal(b, m, deterministic)
 }
-func (m *ListTodosReq) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_ListTodosReq.Merge(m, src)
+func (m *ListCommentsReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListCommentsReq.Merge(m, src)
 }
-func (m *ListTodosReq) XXX_Size() int {
-	return xxx_messageInfo_ListTodosReq.Size(m)
+func (m *ListCommentsReq) XXX_Size() int {
+	return xxx_messageInfo_ListCommentsReq.Size(m)
 }
-func (m *ListTodosReq) XXX_DiscardUnknown() {
-	xxx_messageInfo_ListTodosReq.DiscardUnknown(m)
+func (m *ListCommentsReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListCommentsReq.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_ListTodosReq proto.InternalMessageInfo
+var xxx_messageInfo_ListCommentsReq proto.InternalMessageInfo
 
-func (m *ListTodosReq) GetOwner() string {
+func (m *ListCommentsReq) GetTodoId() uint64 {
+	if m != nil {
+		return m.TodoId
+	}
+	return 0
+}
+
+func (m *ListCommentsReq) GetOwner() string {
 	if m != nil {
 		return m.Owner
 	}
 	return ""
 }
 
-type DeleteTodoRes struct {
-	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *ListCommentsReq) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
 }
 
-func (m *DeleteTodoRes) Reset()         { *m = DeleteTodoRes{} }
-func (m *DeleteTodoRes) String() string { return proto.CompactTextString(m) }
-func (*DeleteTodoRes) ProtoMessage()    {}
-func (*DeleteTodoRes) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0e4b95d0c4e09639, []int{3}
+func (m *ListCommentsReq) GetOffset() uint32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
 }
 
-func (m *DeleteTodoRes) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DeleteTodoRes.Unmarshal(m, b)
+func (m *ListCommentsReq) GetOldestFirst() bool {
+	if m != nil {
+		return m.OldestFirst
+	}
+	return false
 }
-func (m *DeleteTodoRes) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DeleteTodoRes.Marshal(b, m, deterministic)
+
+type CommentList struct {
+	Comments             []*Comment `protobuf:"bytes,1,rep,name=comments,proto3" json:"comments,omitempty"`
+	Total                uint64     `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
 }
-func (m *DeleteTodoRes) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DeleteTodoRes.Merge(m, src)
+
+func (m *CommentList) Reset()         { *m = CommentList{} }
+func (m *CommentList) String() string { return proto.CompactTextString(m) }
+func (*CommentList) ProtoMessage()    {}
+func (*CommentList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0e4b95d0c4e09639, []int{25}
 }
-func (m *DeleteTodoRes) XXX_Size() int {
-	return xxx_messageInfo_DeleteTodoRes.Size(m)
+
+func (m *CommentList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommentList.Unmarshal(m, b)
 }
-func (m *DeleteTodoRes) XXX_DiscardUnknown() {
-	xxx_messageInfo_DeleteTodoRes.DiscardUnknown(m)
+func (m *CommentList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommentList.Marshal(b, m, deterministic)
+}
+func (m *CommentList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommentList.Merge(m, src)
+}
+func (m *CommentList) XXX_Size() int {
+	return xxx_messageInfo_CommentList.Size(m)
+}
+func (m *CommentList) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommentList.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DeleteTodoRes proto.InternalMessageInfo
+var xxx_messageInfo_CommentList proto.InternalMessageInfo
 
-func (m *DeleteTodoRes) GetSuccess() bool {
+func (m *CommentList) GetComments() []*Comment {
 	if m != nil {
-		return m.Success
+		return m.Comments
 	}
-	return false
+	return nil
+}
+
+func (m *CommentList) GetTotal() uint64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
 }
 
 func init() {
+	proto.RegisterEnum("todo_mgr.Priority", Priority_name, Priority_value)
+	proto.RegisterEnum("todo_mgr.Frequency", Frequency_name, Frequency_value)
+	proto.RegisterEnum("todo_mgr.Permission", Permission_name, Permission_value)
+	proto.RegisterEnum("todo_mgr.ListTodosReq_Order", ListTodosReq_Order_name, ListTodosReq_Order_value)
+	proto.RegisterEnum("todo_mgr.TodoEvent_Type", TodoEvent_Type_name, TodoEvent_Type_value)
+	proto.RegisterType((*Recurrence)(nil), "todo_mgr.Recurrence")
 	proto.RegisterType((*Todo)(nil), "todo_mgr.Todo")
+	proto.RegisterType((*Share)(nil), "todo_mgr.Share")
+	proto.RegisterType((*ShareTodoReq)(nil), "todo_mgr.ShareTodoReq")
+	proto.RegisterType((*Subtask)(nil), "todo_mgr.Subtask")
+	proto.RegisterType((*TodoList)(nil), "todo_mgr.TodoList")
+	proto.RegisterType((*CreateTodoRes)(nil), "todo_mgr.CreateTodoRes")
+	proto.RegisterType((*TagList)(nil), "todo_mgr.TagList")
+	proto.RegisterType((*TodoPatch)(nil), "todo_mgr.TodoPatch")
+	proto.RegisterType((*MoveTodoReq)(nil), "todo_mgr.MoveTodoReq")
 	proto.RegisterType((*TodoIdReq)(nil), "todo_mgr.TodoIdReq")
+	proto.RegisterType((*DeleteTodoReq)(nil), "todo_mgr.DeleteTodoReq")
+	proto.RegisterType((*AddSubtaskReq)(nil), "todo_mgr.AddSubtaskReq")
+	proto.RegisterType((*UpdateSubtaskReq)(nil), "todo_mgr.UpdateSubtaskReq")
+	proto.RegisterType((*SubtaskIdReq)(nil), "todo_mgr.SubtaskIdReq")
 	proto.RegisterType((*ListTodosReq)(nil), "todo_mgr.ListTodosReq")
+	proto.RegisterType((*SetTodosDoneReq)(nil), "todo_mgr.SetTodosDoneReq")
+	proto.RegisterType((*CountTodosRes)(nil), "todo_mgr.CountTodosRes")
+	proto.RegisterType((*TodoStatsRes)(nil), "todo_mgr.TodoStatsRes")
 	proto.RegisterType((*DeleteTodoRes)(nil), "todo_mgr.DeleteTodoRes")
+	proto.RegisterType((*WatchTodosReq)(nil), "todo_mgr.WatchTodosReq")
+	proto.RegisterType((*TodoEvent)(nil), "todo_mgr.TodoEvent")
+	proto.RegisterType((*Comment)(nil), "todo_mgr.Comment")
+	proto.RegisterType((*AddCommentReq)(nil), "todo_mgr.AddCommentReq")
+	proto.RegisterType((*ListCommentsReq)(nil), "todo_mgr.ListCommentsReq")
+	proto.RegisterType((*CommentList)(nil), "todo_mgr.CommentList")
 }
 
 func init() { proto.RegisterFile("todo.proto", fileDescriptor_0e4b95d0c4e09639) }
 
 var fileDescriptor_0e4b95d0c4e09639 = []byte{
-	// 282 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0x4f, 0x4b, 0xf3, 0x40,
-	0x10, 0xc6, 0xd9, 0x7d, 0xf3, 0xda, 0x64, 0xaa, 0x3d, 0x8c, 0xa2, 0x8b, 0xa7, 0x50, 0x3c, 0x44,
-	0x28, 0xc1, 0x3f, 0x78, 0xf1, 0xa8, 0x82, 0x08, 0x7a, 0x59, 0xea, 0xc5, 0x8b, 0xa4, 0xdd, 0x21,
-	0x04, 0x6c, 0x36, 0xee, 0xae, 0xe8, 0x87, 0xf0, 0x43, 0xcb, 0x6e, 0x69, 0x92, 0xb6, 0x1e, 0xbc,
-	0xcd, 0x33, 0xf3, 0x7b, 0x66, 0x66, 0x87, 0x05, 0x70, 0x5a, 0xe9, 0xbc, 0x31, 0xda, 0x69, 0x8c,
-	0x7d, 0xfc, 0xba, 0x28, 0xcd, 0x78, 0x0a, 0xd1, 0x54, 0x2b, 0x8d, 0x23, 0xe0, 0x95, 0x12, 0x2c,
-	0x65, 0x59, 0x24, 0x79, 0xa5, 0x10, 0x21, 0x72, 0xf4, 0xe5, 0x04, 0x4f, 0x59, 0x96, 0xc8, 0x10,
-	0xfb, 0x9c, 0xd2, 0x35, 0x89, 0x7f, 0x29, 0xcb, 0x62, 0x19, 0x62, 0x3c, 0x80, 0xff, 0xfa, 0xb3,
-	0x26, 0x23, 0xa2, 0x00, 0x2e, 0xc5, 0xf8, 0x1c, 0x12, 0xdf, 0xf5, 0x41, 0x49, 0x7a, 0xdf, 0x6a,
-	0xdd, 0x5a, 0x78, 0xdf, 0x72, 0x02, 0xbb, 0x8f, 0x95, 0x75, 0xde, 0x66, 0xbd, 0xab, 0xa5, 0x58,
-	0x9f, 0x3a, 0x85, 0xbd, 0x3b, 0x7a, 0x23, 0x47, 0x9e, 0x93, 0x64, 0x51, 0xc0, 0xc0, 0x7e, 0xcc,
-	0xe7, 0x64, 0x6d, 0x00, 0x63, 0xb9, 0x92, 0x17, 0xdf, 0x1c, 0x86, 0x9e, 0x7a, 0x2a, 0xea, 0xa2,
-	0x24, 0x83, 0x13, 0x80, 0x5b, 0x43, 0xc5, 0xd2, 0x8a, 0xa3, 0x7c, 0x75, 0x82, 0xdc, 0xeb, 0xe3,
-	0x0d, 0x8d, 0x57, 0x90, 0xb4, 0xeb, 0xe0, 0x61, 0x57, 0xec, 0xef, 0xb8, 0x69, 0x3a, 0x63, 0x98,
-	0xc3, 0xe0, 0x9e, 0x02, 0x80, 0xfb, 0xeb, 0xc5, 0x70, 0x8b, 0xad, 0x31, 0x13, 0x80, 0xe7, 0x46,
-	0xfd, 0x75, 0xa9, 0x6b, 0x80, 0xee, 0xf5, 0xbf, 0x0f, 0x38, 0xea, 0x92, 0x6b, 0x87, 0xba, 0x19,
-	0xbe, 0x24, 0xbe, 0xb2, 0x28, 0x4d, 0x33, 0x9b, 0xed, 0x84, 0x6f, 0x70, 0xf9, 0x13, 0x00, 0x00,
-	0xff, 0xff, 0xbc, 0x93, 0x4f, 0x16, 0x14, 0x02, 0x00, 0x00,
+	// 1878 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0x5f, 0x73, 0x23, 0x47,
+	0x11, 0xcf, 0x4a, 0x2b, 0x69, 0xb7, 0xf5, 0xc7, 0xca, 0x9c, 0xe3, 0x6c, 0x54, 0x09, 0x38, 0x4b,
+	0x52, 0xf8, 0x8e, 0x44, 0xf1, 0x39, 0x84, 0x84, 0x4a, 0x80, 0xd8, 0x92, 0x2e, 0x27, 0xb0, 0xcf,
+	0xba, 0x95, 0x7d, 0x57, 0x86, 0xa2, 0x54, 0x6b, 0xed, 0x58, 0xda, 0x42, 0xd2, 0xe8, 0x66, 0x67,
+	0xed, 0x33, 0x0f, 0x54, 0x51, 0xc5, 0x1b, 0x45, 0x51, 0x3c, 0xf2, 0xca, 0x57, 0xe1, 0xd3, 0xf0,
+	0x2d, 0xa8, 0x99, 0x9d, 0xd9, 0x3f, 0xfa, 0x73, 0x96, 0x03, 0x6f, 0xdb, 0x33, 0xdd, 0xd3, 0x3d,
+	0xbf, 0xe9, 0xfe, 0x4d, 0xcf, 0x02, 0x30, 0xe2, 0x91, 0xe6, 0x9c, 0x12, 0x46, 0x90, 0xc1, 0xbf,
+	0x07, 0xd3, 0x11, 0x6d, 0xfc, 0x70, 0x44, 0xc8, 0x68, 0x82, 0x3f, 0x13, 0xe3, 0x97, 0xe1, 0xd5,
+	0x67, 0xcc, 0x9f, 0xe2, 0x80, 0xb9, 0xd3, 0x79, 0xa4, 0xda, 0xf8, 0xc1, 0xa2, 0xc2, 0x0d, 0x75,
+	0xe7, 0x73, 0x4c, 0x83, 0x68, 0xde, 0xfe, 0x1d, 0x80, 0x83, 0x87, 0x21, 0xa5, 0x78, 0x36, 0xc4,
+	0xe8, 0x31, 0x98, 0x57, 0x14, 0xbf, 0x0a, 0xf1, 0x6c, 0x78, 0x6b, 0x69, 0xbb, 0xda, 0x5e, 0xed,
+	0xe0, 0x41, 0x53, 0x39, 0x6b, 0x3e, 0x51, 0x53, 0x4e, 0xa2, 0x85, 0x1a, 0x60, 0xf8, 0x33, 0x86,
+	0xe9, 0xb5, 0x3b, 0xb1, 0x72, 0xbb, 0xda, 0x5e, 0xd5, 0x89, 0x65, 0xfb, 0x3f, 0x3a, 0xe8, 0x67,
+	0xc4, 0x23, 0xa8, 0x06, 0x39, 0xdf, 0x13, 0x0b, 0xea, 0x4e, 0xce, 0xf7, 0x10, 0x02, 0x9d, 0xe1,
+	0xd7, 0x4c, 0x18, 0x98, 0x8e, 0xf8, 0xe6, 0x63, 0x1e, 0x99, 0x61, 0x2b, 0xbf, 0xab, 0xed, 0x19,
+	0x8e, 0xf8, 0x46, 0xdb, 0x50, 0x20, 0x37, 0x33, 0x4c, 0x2d, 0x5d, 0x28, 0x46, 0x02, 0xfa, 0x39,
+	0xc0, 0x90, 0x62, 0x97, 0x61, 0x6f, 0xe0, 0x32, 0xab, 0xb0, 0xab, 0xed, 0x95, 0x0f, 0x1a, 0xcd,
+	0x68, 0xa3, 0x4d, 0xb5, 0xd1, 0xe6, 0x99, 0x42, 0xc2, 0x31, 0xa5, 0xf6, 0x21, 0xe3, 0xa6, 0xe1,
+	0xdc, 0x53, 0xa6, 0xc5, 0xbb, 0x4d, 0xa5, 0xf6, 0x21, 0x43, 0x5f, 0x80, 0xe1, 0x85, 0x78, 0xc0,
+	0x45, 0xab, 0x74, 0xa7, 0x61, 0xc9, 0x0b, 0x71, 0xdb, 0x65, 0x18, 0x35, 0xc1, 0x98, 0x53, 0x9f,
+	0x50, 0x9f, 0xdd, 0x5a, 0x86, 0x40, 0x14, 0x25, 0x88, 0xf6, 0xe4, 0x8c, 0x13, 0xeb, 0x08, 0x68,
+	0xdc, 0x51, 0x60, 0x99, 0xbb, 0x79, 0x01, 0x8d, 0x3b, 0x0a, 0x90, 0x05, 0xa5, 0x6b, 0x4c, 0x03,
+	0x9f, 0xcc, 0x2c, 0x10, 0x18, 0x2a, 0x91, 0xef, 0xc7, 0xc3, 0x13, 0x2c, 0xf7, 0x53, 0xbe, 0x7b,
+	0x3f, 0x52, 0xfb, 0x90, 0xf1, 0x83, 0x73, 0xe9, 0x70, 0xec, 0x5f, 0x63, 0xcf, 0xaa, 0x08, 0xcc,
+	0x63, 0x19, 0x7d, 0x0a, 0x46, 0x10, 0x5e, 0x32, 0x37, 0xf8, 0x43, 0x60, 0x55, 0x77, 0xf3, 0x7b,
+	0xe5, 0x83, 0xb7, 0x93, 0xa0, 0xfb, 0xd1, 0x8c, 0x13, 0xab, 0xa0, 0x9f, 0x02, 0xd0, 0x38, 0x89,
+	0xac, 0x9a, 0x88, 0x62, 0x3b, 0x31, 0x48, 0x12, 0xcc, 0x49, 0xe9, 0xa1, 0x7d, 0x28, 0x07, 0x63,
+	0x97, 0x62, 0x6f, 0x70, 0xe3, 0xb3, 0xb1, 0xb5, 0x25, 0xfc, 0x6c, 0xa5, 0xfc, 0xf0, 0x49, 0x07,
+	0x22, 0x9d, 0x97, 0x3e, 0x1b, 0xf3, 0x90, 0xe7, 0x24, 0xf0, 0x19, 0x07, 0xa2, 0xbe, 0xab, 0xed,
+	0x69, 0x4e, 0x2c, 0xdb, 0xcf, 0xa1, 0x20, 0x0c, 0x38, 0x80, 0x61, 0x80, 0xa9, 0xc8, 0x36, 0xd3,
+	0x11, 0xdf, 0x3c, 0xc0, 0x39, 0xa6, 0x53, 0x3f, 0x10, 0x18, 0xe6, 0xc4, 0x31, 0xa4, 0x02, 0xec,
+	0xc5, 0x73, 0x4e, 0x4a, 0xcf, 0xfe, 0x13, 0x54, 0xc4, 0x92, 0x3c, 0x85, 0x1d, 0xfc, 0x6a, 0x29,
+	0x8b, 0xe3, 0xec, 0xcc, 0xa5, 0xb3, 0x53, 0xf9, 0xcf, 0xaf, 0xf5, 0xaf, 0x6f, 0xe8, 0xff, 0x31,
+	0x94, 0x24, 0xd6, 0x71, 0xc1, 0x68, 0x2b, 0x0a, 0x26, 0x97, 0x14, 0x8c, 0xbd, 0x0f, 0x06, 0x8f,
+	0xf6, 0xd8, 0x0f, 0x18, 0xfa, 0x08, 0x0a, 0xdc, 0x43, 0x60, 0x69, 0x02, 0xd9, 0x5a, 0xe2, 0x4f,
+	0x6c, 0x28, 0x9a, 0xb4, 0x7f, 0x0f, 0xd5, 0x96, 0x28, 0x8f, 0x68, 0x97, 0x01, 0xb2, 0x41, 0xe7,
+	0x33, 0xc2, 0xd5, 0xb2, 0x95, 0x98, 0xe3, 0xae, 0x87, 0xc4, 0xc3, 0xb2, 0xe0, 0xc5, 0x37, 0x47,
+	0x03, 0x53, 0x4a, 0xd4, 0xc6, 0x23, 0xc1, 0xfe, 0x00, 0x4a, 0x67, 0xee, 0x48, 0xc4, 0xa3, 0x32,
+	0x5b, 0x4b, 0x32, 0xdb, 0xfe, 0xab, 0x0e, 0x26, 0x5f, 0xb7, 0xe7, 0xb2, 0xe1, 0x78, 0x43, 0x80,
+	0xf7, 0x25, 0x16, 0x79, 0x11, 0xe0, 0xfb, 0x4b, 0xd9, 0xde, 0x67, 0xd4, 0x9f, 0x8d, 0x5e, 0xb8,
+	0x93, 0x10, 0x4b, 0xa4, 0x9a, 0x12, 0x29, 0x7d, 0x4d, 0x7d, 0x1c, 0x11, 0x32, 0x91, 0xfa, 0x5c,
+	0x2f, 0x53, 0xea, 0x85, 0xcd, 0x4b, 0xfd, 0x23, 0xa8, 0x0d, 0x27, 0xd8, 0xa5, 0x83, 0xd8, 0xb8,
+	0x28, 0x8e, 0xa6, 0x22, 0x46, 0xdb, 0x2b, 0x08, 0xa1, 0xb4, 0x01, 0x21, 0x7c, 0x2c, 0x61, 0x33,
+	0x76, 0xb5, 0x6c, 0x1d, 0x4a, 0x5c, 0x25, 0x47, 0x3c, 0x84, 0x3a, 0x7e, 0x3d, 0xc7, 0x43, 0x4e,
+	0x05, 0x8a, 0x2c, 0x4c, 0x81, 0xe4, 0x96, 0x1a, 0x7f, 0x11, 0x0d, 0xa3, 0x9f, 0xa5, 0x2a, 0x1f,
+	0xee, 0x84, 0x24, 0xd6, 0x5d, 0x28, 0xf3, 0xf2, 0x86, 0x65, 0xfe, 0x10, 0xea, 0x11, 0x2a, 0x29,
+	0xdb, 0x88, 0x6f, 0xb6, 0xc4, 0x78, 0x62, 0x66, 0xff, 0x11, 0xca, 0x27, 0xe4, 0xfa, 0x9e, 0xf5,
+	0x96, 0x26, 0x85, 0x7c, 0x74, 0x01, 0x29, 0x79, 0x25, 0x28, 0xfa, 0x4a, 0x50, 0xec, 0xc7, 0x51,
+	0x22, 0x76, 0xbd, 0x8d, 0x3d, 0xdb, 0x5d, 0xa8, 0xb6, 0x05, 0x9d, 0xde, 0x9b, 0x20, 0xc6, 0x2e,
+	0xf5, 0xd4, 0x45, 0xc7, 0xbf, 0xed, 0xbf, 0x69, 0x50, 0x3d, 0xf4, 0x3c, 0x45, 0xad, 0x1b, 0xaf,
+	0xf5, 0x13, 0x28, 0x49, 0x16, 0xb6, 0xf2, 0x8b, 0xf9, 0xa1, 0x16, 0x53, 0x1a, 0xf7, 0x41, 0xe3,
+	0x2f, 0x39, 0xa8, 0x9f, 0x8b, 0xab, 0xef, 0xde, 0x21, 0x6d, 0x43, 0xc1, 0x9f, 0x79, 0xf8, 0xb5,
+	0x3c, 0x8c, 0x48, 0x88, 0x8b, 0x56, 0xbf, 0x77, 0xd1, 0x16, 0x36, 0x2c, 0xda, 0x1f, 0xc3, 0xd6,
+	0x90, 0x4c, 0xe7, 0xfc, 0x3c, 0x06, 0x73, 0x97, 0xe2, 0x19, 0x93, 0xe5, 0x57, 0x53, 0xc3, 0x3d,
+	0x31, 0xba, 0x12, 0x86, 0xd2, 0x6a, 0x18, 0x42, 0xa8, 0xc8, 0xfd, 0x77, 0xbd, 0xff, 0x15, 0x81,
+	0x7b, 0xa0, 0xff, 0xf7, 0x02, 0x54, 0x78, 0x69, 0xf3, 0xbc, 0x0a, 0xb8, 0xdf, 0xd8, 0x8f, 0xb6,
+	0xe0, 0x67, 0xe2, 0x4f, 0x7d, 0x26, 0x69, 0x38, 0x12, 0xd0, 0x0e, 0x14, 0xc9, 0xd5, 0x55, 0x80,
+	0x99, 0x74, 0x2f, 0x25, 0x9e, 0x76, 0x01, 0xa1, 0x4c, 0xb6, 0x52, 0xe2, 0x1b, 0x1d, 0x40, 0x81,
+	0x50, 0x0f, 0x53, 0x01, 0x72, 0xed, 0xe0, 0xfd, 0x24, 0x79, 0xd2, 0xee, 0x9b, 0xa7, 0x5c, 0xc7,
+	0x89, 0x54, 0xe3, 0x73, 0x29, 0x6e, 0x78, 0x2e, 0xbc, 0x45, 0x09, 0xf1, 0xe0, 0x12, 0x5f, 0x11,
+	0xba, 0x49, 0xe7, 0x64, 0x7a, 0x21, 0x3e, 0x12, 0xca, 0xff, 0x97, 0xde, 0xe9, 0x03, 0x00, 0x9e,
+	0x4e, 0x83, 0x57, 0x21, 0xa6, 0xb7, 0x82, 0xee, 0x4c, 0xc7, 0xe4, 0x23, 0xcf, 0xf9, 0x00, 0x6f,
+	0xad, 0x64, 0x4b, 0x24, 0x08, 0xcd, 0x70, 0x94, 0xf8, 0xc6, 0xfe, 0xe8, 0x57, 0x50, 0x8d, 0x3b,
+	0xd0, 0x2b, 0x86, 0xa9, 0x55, 0xbd, 0x73, 0x5b, 0x15, 0xd5, 0x84, 0x72, 0x7d, 0x74, 0x08, 0x35,
+	0xb5, 0x80, 0x04, 0xa6, 0x76, 0xe7, 0x0a, 0xca, 0xa5, 0x04, 0x67, 0x07, 0x8a, 0xc3, 0x90, 0x06,
+	0x84, 0x5a, 0x5b, 0x62, 0x53, 0x52, 0x42, 0x5f, 0x02, 0x47, 0x50, 0xc6, 0x55, 0xbf, 0x73, 0x55,
+	0x7e, 0xd3, 0x89, 0x98, 0xec, 0x06, 0x14, 0xc4, 0x41, 0xa3, 0x12, 0xe4, 0x0f, 0xfb, 0xad, 0xfa,
+	0x5b, 0xc8, 0x00, 0xbd, 0xdd, 0xe9, 0xb7, 0xea, 0x9a, 0x7d, 0x0e, 0x5b, 0x7d, 0x1c, 0x25, 0x44,
+	0x9b, 0xcc, 0x30, 0xcf, 0xc9, 0x26, 0x14, 0xaf, 0xfc, 0x09, 0x93, 0x49, 0x59, 0x3e, 0xd8, 0x59,
+	0x9d, 0x3c, 0x8e, 0xd4, 0x5a, 0xd9, 0xae, 0x7c, 0x0c, 0xd5, 0x16, 0x09, 0x67, 0x4a, 0x39, 0xe0,
+	0x29, 0x3d, 0xe4, 0x03, 0xb2, 0xc6, 0x22, 0xc1, 0xfe, 0xb7, 0x06, 0x15, 0xae, 0xd2, 0x67, 0x2e,
+	0x53, 0x6a, 0x8c, 0x30, 0x77, 0xa2, 0xd4, 0x84, 0x90, 0xf1, 0xa0, 0xcb, 0xec, 0xb3, 0xa0, 0x44,
+	0xae, 0x31, 0xf5, 0xc2, 0xe8, 0x61, 0xa1, 0x3b, 0x4a, 0x44, 0x1f, 0x42, 0x65, 0x42, 0x6e, 0x06,
+	0x71, 0x82, 0x45, 0xb5, 0x58, 0x9e, 0x90, 0x1b, 0x95, 0x59, 0x9c, 0x52, 0xa6, 0xd8, 0xf3, 0xc3,
+	0x69, 0xa2, 0x55, 0x10, 0x5a, 0xb5, 0x68, 0x38, 0x56, 0xfc, 0x11, 0x54, 0xc7, 0xfe, 0x68, 0x9c,
+	0xa8, 0x15, 0x85, 0x5a, 0x85, 0x0f, 0x2a, 0x25, 0xfb, 0x61, 0xf6, 0xba, 0x10, 0x6d, 0x7d, 0x10,
+	0x0e, 0x87, 0x38, 0x08, 0xc4, 0x3e, 0x0c, 0x47, 0x89, 0x1c, 0x97, 0x97, 0xbc, 0x23, 0x7a, 0x33,
+	0x01, 0xd8, 0xff, 0xd2, 0xa2, 0x4b, 0xab, 0x73, 0xcd, 0x79, 0xed, 0x13, 0xd0, 0xd9, 0xed, 0x1c,
+	0xcb, 0x77, 0x9b, 0x95, 0x6d, 0xdc, 0x84, 0x4a, 0xf3, 0xec, 0x76, 0xce, 0xe9, 0xf5, 0x76, 0x8e,
+	0xe3, 0x36, 0x2f, 0xb7, 0xbe, 0xcd, 0xb3, 0x5b, 0xa0, 0x73, 0x0b, 0xb4, 0x0d, 0xf5, 0xb3, 0x8b,
+	0x5e, 0x67, 0x70, 0xfe, 0xac, 0xdf, 0xeb, 0xb4, 0xba, 0x4f, 0xba, 0x9d, 0x76, 0xfd, 0x2d, 0x54,
+	0x86, 0x52, 0xcb, 0xe9, 0x1c, 0x9e, 0x75, 0xda, 0x75, 0x8d, 0x0b, 0xe7, 0xbd, 0xb6, 0x10, 0x72,
+	0x5c, 0x68, 0x77, 0x8e, 0x3b, 0x5c, 0xc8, 0xdb, 0xff, 0xd4, 0xa0, 0xd4, 0x22, 0xd3, 0x29, 0x0f,
+	0x71, 0x91, 0x3f, 0xdf, 0x85, 0x92, 0xf0, 0xeb, 0x7b, 0xf2, 0xd0, 0x8a, 0x4c, 0xdc, 0xc1, 0x3c,
+	0xb9, 0xdd, 0x90, 0x8d, 0xe3, 0x6e, 0x52, 0x4a, 0x71, 0x1f, 0xac, 0xa7, 0xfa, 0xe0, 0xef, 0xff,
+	0x1c, 0xb4, 0x1d, 0x71, 0xeb, 0xca, 0xe8, 0x38, 0xce, 0xa9, 0x80, 0xb4, 0x4c, 0x40, 0x6b, 0xaf,
+	0xf2, 0xb8, 0x15, 0x95, 0xe1, 0xd8, 0xff, 0xd0, 0x60, 0x8b, 0x17, 0x80, 0x5c, 0x35, 0xf8, 0x1e,
+	0xcb, 0xc6, 0xc4, 0x9e, 0x5f, 0x4d, 0xec, 0x7a, 0x86, 0xd8, 0x3f, 0x84, 0x0a, 0x99, 0x78, 0x38,
+	0x60, 0x83, 0x2b, 0x9f, 0x06, 0x11, 0x02, 0x86, 0x53, 0x8e, 0xc6, 0x9e, 0xf0, 0x21, 0xdb, 0x81,
+	0xb2, 0x0c, 0x47, 0x74, 0xe2, 0x9f, 0x82, 0x31, 0x94, 0xd1, 0xc9, 0xc7, 0x41, 0xaa, 0x6d, 0x50,
+	0x68, 0xc4, 0x2a, 0x49, 0xb5, 0xe5, 0x52, 0xd5, 0xf6, 0xa8, 0x05, 0x46, 0x9c, 0xff, 0x16, 0x6c,
+	0xf7, 0x9c, 0xee, 0xa9, 0xd3, 0x3d, 0xbb, 0x58, 0x48, 0x92, 0x12, 0xe4, 0x8f, 0x4f, 0x5f, 0xd6,
+	0x35, 0x04, 0x50, 0x3c, 0xe9, 0xb4, 0xbb, 0xe7, 0x27, 0xf5, 0x1c, 0xe7, 0x95, 0xa7, 0xdd, 0xef,
+	0x9e, 0xd6, 0xf3, 0x8f, 0x7e, 0x0d, 0x66, 0xfc, 0x57, 0x01, 0xbd, 0x07, 0xef, 0x3c, 0x71, 0x3a,
+	0xcf, 0xcf, 0x3b, 0xcf, 0x5a, 0x8b, 0xcb, 0x98, 0x50, 0x68, 0x1f, 0x76, 0x8f, 0x2f, 0xa2, 0x85,
+	0x5e, 0x76, 0x3a, 0xbf, 0x39, 0xbe, 0x88, 0x12, 0xed, 0xe4, 0xf4, 0xd9, 0xd9, 0xd3, 0xe3, 0x8b,
+	0x7a, 0xfe, 0xd1, 0x37, 0x00, 0xc9, 0x43, 0x0a, 0x35, 0x60, 0xa7, 0xd7, 0x71, 0x4e, 0xba, 0xfd,
+	0x7e, 0xf7, 0xf4, 0xd9, 0xc2, 0x6a, 0x06, 0xe8, 0x2f, 0xba, 0x1d, 0x1e, 0x95, 0x01, 0x7a, 0xa7,
+	0xdd, 0x3d, 0xab, 0xe7, 0x0e, 0xfe, 0x6c, 0x42, 0x99, 0xa7, 0xfe, 0x89, 0x3b, 0x73, 0x47, 0x98,
+	0xa2, 0x4f, 0x00, 0x92, 0x77, 0x11, 0x5a, 0xa8, 0x8f, 0xc6, 0x82, 0x8c, 0xbe, 0x82, 0xfa, 0x11,
+	0x2f, 0xd8, 0xc4, 0x24, 0x58, 0xb2, 0x41, 0x59, 0x99, 0x9f, 0xc4, 0x9e, 0x86, 0xbe, 0x85, 0xb7,
+	0xfb, 0x8c, 0x62, 0x77, 0xfa, 0x26, 0xd3, 0x77, 0x53, 0xc7, 0x93, 0x7e, 0xac, 0xed, 0x69, 0xfb,
+	0x1a, 0xfa, 0x02, 0xcc, 0x98, 0x70, 0xd1, 0x1a, 0x16, 0x5e, 0x0c, 0x78, 0x5f, 0x43, 0xbf, 0x00,
+	0x48, 0xb8, 0x77, 0xad, 0x5d, 0xda, 0x73, 0x86, 0xa9, 0x9b, 0x50, 0xfa, 0x2e, 0xba, 0x11, 0xd0,
+	0x83, 0xec, 0xda, 0x5d, 0x6f, 0x85, 0x43, 0x8e, 0x67, 0xd4, 0x50, 0x6e, 0x84, 0xe7, 0x3e, 0x98,
+	0x3d, 0x45, 0x80, 0x8b, 0xeb, 0x8b, 0x89, 0x25, 0x8b, 0x5f, 0x02, 0x24, 0xec, 0x8a, 0x52, 0x61,
+	0x67, 0x5a, 0xf4, 0xc6, 0x9a, 0x89, 0x00, 0x1d, 0x40, 0xd9, 0xc1, 0x01, 0x23, 0x14, 0xaf, 0xf2,
+	0xb9, 0x7a, 0x4f, 0xdf, 0x00, 0x24, 0x34, 0x9d, 0xf6, 0x99, 0x21, 0xef, 0xc6, 0x83, 0x15, 0x54,
+	0x2c, 0xce, 0x0d, 0x92, 0x96, 0x3f, 0x6d, 0x9d, 0x79, 0x08, 0x2c, 0x39, 0xfd, 0x1a, 0xaa, 0x99,
+	0xce, 0x1c, 0x35, 0x12, 0x85, 0xc5, 0x96, 0x7d, 0xc9, 0xf8, 0x4b, 0xa8, 0x3a, 0x78, 0x4a, 0xae,
+	0x63, 0xe3, 0x9d, 0xa5, 0xf7, 0xc2, 0xea, 0xad, 0x7e, 0x25, 0x82, 0x55, 0x3c, 0x9e, 0x0d, 0x36,
+	0xe1, 0xcf, 0xc6, 0x32, 0x8f, 0xa0, 0x6f, 0xa3, 0x5e, 0xb6, 0xa5, 0xd8, 0xe4, 0xbd, 0x6c, 0xa6,
+	0xa5, 0x68, 0xb2, 0xf1, 0xce, 0x92, 0x35, 0xd7, 0x40, 0x9f, 0x83, 0x19, 0xff, 0x87, 0xc9, 0x04,
+	0x9c, 0xfa, 0x39, 0xb3, 0x14, 0xf0, 0x63, 0x30, 0xd4, 0x5b, 0x12, 0xa5, 0xd6, 0x4d, 0xbd, 0x2f,
+	0x97, 0x4c, 0x8e, 0xa0, 0x92, 0x6e, 0x72, 0xd2, 0x91, 0x2e, 0x34, 0x3f, 0xeb, 0xcb, 0xe2, 0x6b,
+	0x30, 0xe3, 0x4e, 0x65, 0x6d, 0x51, 0xed, 0x64, 0x1d, 0xab, 0xb6, 0xe6, 0xa8, 0xfc, 0x5b, 0x93,
+	0x4f, 0x4c, 0x47, 0x74, 0x7e, 0x79, 0x59, 0x14, 0x57, 0xd7, 0xe7, 0xff, 0x1d, 0x00, 0xee, 0x51,
+	0x72, 0x47, 0xf9, 0x15, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -256,10 +2037,25 @@ const _ = grpc.SupportPackageIsVersion4
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type TodoManagerClient interface {
 	CreateTodo(ctx context.Context, in *Todo, opts ...grpc.CallOption) (*Todo, error)
+	BatchCreateTodos(ctx context.Context, opts ...grpc.CallOption) (TodoManager_BatchCreateTodosClient, error)
+	StreamCreateTodos(ctx context.Context, opts ...grpc.CallOption) (TodoManager_StreamCreateTodosClient, error)
 	ListTodos(ctx context.Context, in *ListTodosReq, opts ...grpc.CallOption) (TodoManager_ListTodosClient, error)
+	CountTodos(ctx context.Context, in *ListTodosReq, opts ...grpc.CallOption) (*CountTodosRes, error)
 	GetTodo(ctx context.Context, in *TodoIdReq, opts ...grpc.CallOption) (*Todo, error)
 	UpdateTodo(ctx context.Context, in *Todo, opts ...grpc.CallOption) (*Todo, error)
-	DeleteTodo(ctx context.Context, in *TodoIdReq, opts ...grpc.CallOption) (*DeleteTodoRes, error)
+	PatchTodo(ctx context.Context, in *TodoPatch, opts ...grpc.CallOption) (*Todo, error)
+	DeleteTodo(ctx context.Context, in *DeleteTodoReq, opts ...grpc.CallOption) (*DeleteTodoRes, error)
+	RestoreTodo(ctx context.Context, in *TodoIdReq, opts ...grpc.CallOption) (*Todo, error)
+	WatchTodos(ctx context.Context, in *WatchTodosReq, opts ...grpc.CallOption) (TodoManager_WatchTodosClient, error)
+	AddSubtask(ctx context.Context, in *AddSubtaskReq, opts ...grpc.CallOption) (*Todo, error)
+	UpdateSubtask(ctx context.Context, in *UpdateSubtaskReq, opts ...grpc.CallOption) (*Todo, error)
+	RemoveSubtask(ctx context.Context, in *SubtaskIdReq, opts ...grpc.CallOption) (*Todo, error)
+	AddComment(ctx context.Context, in *AddCommentReq, opts ...grpc.CallOption) (*Comment, error)
+	ListComments(ctx context.Context, in *ListCommentsReq, opts ...grpc.CallOption) (*CommentList, error)
+	ShareTodo(ctx context.Context, in *ShareTodoReq, opts ...grpc.CallOption) (*Todo, error)
+	MoveTodo(ctx context.Context, in *MoveTodoReq, opts ...grpc.CallOption) (*Todo, error)
+	SetTodosDone(ctx context.Context, in *SetTodosDoneReq, opts ...grpc.CallOption) (*CountTodosRes, error)
+	TodoStats(ctx context.Context, in *ListTodosReq, opts ...grpc.CallOption) (*TodoStatsRes, error)
 }
 
 type todoManagerClient struct {
@@ -279,8 +2075,73 @@ func (c *todoManagerClient) CreateTodo(ctx context.Context, in *Todo, opts ...gr
 	return out, nil
 }
 
+func (c *todoManagerClient) BatchCreateTodos(ctx context.Context, opts ...grpc.CallOption) (TodoManager_BatchCreateTodosClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TodoManager_serviceDesc.Streams[0], "/todo_mgr.TodoManager/BatchCreateTodos", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoManagerBatchCreateTodosClient{stream}
+	return x, nil
+}
+
+type TodoManager_BatchCreateTodosClient interface {
+	Send(*Todo) error
+	CloseAndRecv() (*TodoList, error)
+	grpc.ClientStream
+}
+
+type todoManagerBatchCreateTodosClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoManagerBatchCreateTodosClient) Send(m *Todo) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *todoManagerBatchCreateTodosClient) CloseAndRecv() (*TodoList, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(TodoList)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *todoManagerClient) StreamCreateTodos(ctx context.Context, opts ...grpc.CallOption) (TodoManager_StreamCreateTodosClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TodoManager_serviceDesc.Streams[1], "/todo_mgr.TodoManager/StreamCreateTodos", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoManagerStreamCreateTodosClient{stream}
+	return x, nil
+}
+
+type TodoManager_StreamCreateTodosClient interface {
+	Send(*Todo) error
+	Recv() (*CreateTodoRes, error)
+	grpc.ClientStream
+}
+
+type todoManagerStreamCreateTodosClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoManagerStreamCreateTodosClient) Send(m *Todo) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *todoManagerStreamCreateTodosClient) Recv() (*CreateTodoRes, error) {
+	m := new(CreateTodoRes)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *todoManagerClient) ListTodos(ctx context.Context, in *ListTodosReq, opts ...grpc.CallOption) (TodoManager_ListTodosClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_TodoManager_serviceDesc.Streams[0], "/todo_mgr.TodoManager/ListTodos", opts...)
+	stream, err := c.cc.NewStream(ctx, &_TodoManager_serviceDesc.Streams[2], "/todo_mgr.TodoManager/ListTodos", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -311,6 +2172,15 @@ func (x *todoManagerListTodosClient) Recv() (*Todo, error) {
 	return m, nil
 }
 
+func (c *todoManagerClient) CountTodos(ctx context.Context, in *ListTodosReq, opts ...grpc.CallOption) (*CountTodosRes, error) {
+	out := new(CountTodosRes)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/CountTodos", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *todoManagerClient) GetTodo(ctx context.Context, in *TodoIdReq, opts ...grpc.CallOption) (*Todo, error) {
 	out := new(Todo)
 	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/GetTodo", in, out, opts...)
@@ -329,7 +2199,16 @@ func (c *todoManagerClient) UpdateTodo(ctx context.Context, in *Todo, opts ...gr
 	return out, nil
 }
 
-func (c *todoManagerClient) DeleteTodo(ctx context.Context, in *TodoIdReq, opts ...grpc.CallOption) (*DeleteTodoRes, error) {
+func (c *todoManagerClient) PatchTodo(ctx context.Context, in *TodoPatch, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/PatchTodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) DeleteTodo(ctx context.Context, in *DeleteTodoReq, opts ...grpc.CallOption) (*DeleteTodoRes, error) {
 	out := new(DeleteTodoRes)
 	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/DeleteTodo", in, out, opts...)
 	if err != nil {
@@ -338,13 +2217,150 @@ func (c *todoManagerClient) DeleteTodo(ctx context.Context, in *TodoIdReq, opts
 	return out, nil
 }
 
+func (c *todoManagerClient) RestoreTodo(ctx context.Context, in *TodoIdReq, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/RestoreTodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) WatchTodos(ctx context.Context, in *WatchTodosReq, opts ...grpc.CallOption) (TodoManager_WatchTodosClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TodoManager_serviceDesc.Streams[3], "/todo_mgr.TodoManager/WatchTodos", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &todoManagerWatchTodosClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TodoManager_WatchTodosClient interface {
+	Recv() (*TodoEvent, error)
+	grpc.ClientStream
+}
+
+type todoManagerWatchTodosClient struct {
+	grpc.ClientStream
+}
+
+func (x *todoManagerWatchTodosClient) Recv() (*TodoEvent, error) {
+	m := new(TodoEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *todoManagerClient) AddSubtask(ctx context.Context, in *AddSubtaskReq, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/AddSubtask", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) UpdateSubtask(ctx context.Context, in *UpdateSubtaskReq, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/UpdateSubtask", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) RemoveSubtask(ctx context.Context, in *SubtaskIdReq, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/RemoveSubtask", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) AddComment(ctx context.Context, in *AddCommentReq, opts ...grpc.CallOption) (*Comment, error) {
+	out := new(Comment)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/AddComment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) ListComments(ctx context.Context, in *ListCommentsReq, opts ...grpc.CallOption) (*CommentList, error) {
+	out := new(CommentList)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/ListComments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) ShareTodo(ctx context.Context, in *ShareTodoReq, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/ShareTodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) MoveTodo(ctx context.Context, in *MoveTodoReq, opts ...grpc.CallOption) (*Todo, error) {
+	out := new(Todo)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/MoveTodo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) SetTodosDone(ctx context.Context, in *SetTodosDoneReq, opts ...grpc.CallOption) (*CountTodosRes, error) {
+	out := new(CountTodosRes)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/SetTodosDone", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoManagerClient) TodoStats(ctx context.Context, in *ListTodosReq, opts ...grpc.CallOption) (*TodoStatsRes, error) {
+	out := new(TodoStatsRes)
+	err := c.cc.Invoke(ctx, "/todo_mgr.TodoManager/TodoStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TodoManagerServer is the server API for TodoManager service.
 type TodoManagerServer interface {
 	CreateTodo(context.Context, *Todo) (*Todo, error)
+	BatchCreateTodos(TodoManager_BatchCreateTodosServer) error
+	StreamCreateTodos(TodoManager_StreamCreateTodosServer) error
 	ListTodos(*ListTodosReq, TodoManager_ListTodosServer) error
+	CountTodos(context.Context, *ListTodosReq) (*CountTodosRes, error)
 	GetTodo(context.Context, *TodoIdReq) (*Todo, error)
 	UpdateTodo(context.Context, *Todo) (*Todo, error)
-	DeleteTodo(context.Context, *TodoIdReq) (*DeleteTodoRes, error)
+	PatchTodo(context.Context, *TodoPatch) (*Todo, error)
+	DeleteTodo(context.Context, *DeleteTodoReq) (*DeleteTodoRes, error)
+	RestoreTodo(context.Context, *TodoIdReq) (*Todo, error)
+	WatchTodos(*WatchTodosReq, TodoManager_WatchTodosServer) error
+	AddSubtask(context.Context, *AddSubtaskReq) (*Todo, error)
+	UpdateSubtask(context.Context, *UpdateSubtaskReq) (*Todo, error)
+	RemoveSubtask(context.Context, *SubtaskIdReq) (*Todo, error)
+	AddComment(context.Context, *AddCommentReq) (*Comment, error)
+	ListComments(context.Context, *ListCommentsReq) (*CommentList, error)
+	ShareTodo(context.Context, *ShareTodoReq) (*Todo, error)
+	MoveTodo(context.Context, *MoveTodoReq) (*Todo, error)
+	SetTodosDone(context.Context, *SetTodosDoneReq) (*CountTodosRes, error)
+	TodoStats(context.Context, *ListTodosReq) (*TodoStatsRes, error)
 }
 
 // UnimplementedTodoManagerServer can be embedded to have forward compatible implementations.
@@ -354,18 +2370,63 @@ type UnimplementedTodoManagerServer struct {
 func (*UnimplementedTodoManagerServer) CreateTodo(ctx context.Context, req *Todo) (*Todo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateTodo not implemented")
 }
+func (*UnimplementedTodoManagerServer) BatchCreateTodos(srv TodoManager_BatchCreateTodosServer) error {
+	return status.Errorf(codes.Unimplemented, "method BatchCreateTodos not implemented")
+}
+func (*UnimplementedTodoManagerServer) StreamCreateTodos(srv TodoManager_StreamCreateTodosServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamCreateTodos not implemented")
+}
 func (*UnimplementedTodoManagerServer) ListTodos(req *ListTodosReq, srv TodoManager_ListTodosServer) error {
 	return status.Errorf(codes.Unimplemented, "method ListTodos not implemented")
 }
+func (*UnimplementedTodoManagerServer) CountTodos(ctx context.Context, req *ListTodosReq) (*CountTodosRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountTodos not implemented")
+}
 func (*UnimplementedTodoManagerServer) GetTodo(ctx context.Context, req *TodoIdReq) (*Todo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTodo not implemented")
 }
 func (*UnimplementedTodoManagerServer) UpdateTodo(ctx context.Context, req *Todo) (*Todo, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateTodo not implemented")
 }
-func (*UnimplementedTodoManagerServer) DeleteTodo(ctx context.Context, req *TodoIdReq) (*DeleteTodoRes, error) {
+func (*UnimplementedTodoManagerServer) PatchTodo(ctx context.Context, req *TodoPatch) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchTodo not implemented")
+}
+func (*UnimplementedTodoManagerServer) DeleteTodo(ctx context.Context, req *DeleteTodoReq) (*DeleteTodoRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteTodo not implemented")
 }
+func (*UnimplementedTodoManagerServer) RestoreTodo(ctx context.Context, req *TodoIdReq) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreTodo not implemented")
+}
+func (*UnimplementedTodoManagerServer) WatchTodos(req *WatchTodosReq, srv TodoManager_WatchTodosServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTodos not implemented")
+}
+func (*UnimplementedTodoManagerServer) AddSubtask(ctx context.Context, req *AddSubtaskReq) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddSubtask not implemented")
+}
+func (*UnimplementedTodoManagerServer) UpdateSubtask(ctx context.Context, req *UpdateSubtaskReq) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSubtask not implemented")
+}
+func (*UnimplementedTodoManagerServer) RemoveSubtask(ctx context.Context, req *SubtaskIdReq) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveSubtask not implemented")
+}
+func (*UnimplementedTodoManagerServer) AddComment(ctx context.Context, req *AddCommentReq) (*Comment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddComment not implemented")
+}
+func (*UnimplementedTodoManagerServer) ListComments(ctx context.Context, req *ListCommentsReq) (*CommentList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListComments not implemented")
+}
+func (*UnimplementedTodoManagerServer) ShareTodo(ctx context.Context, req *ShareTodoReq) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareTodo not implemented")
+}
+func (*UnimplementedTodoManagerServer) MoveTodo(ctx context.Context, req *MoveTodoReq) (*Todo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveTodo not implemented")
+}
+func (*UnimplementedTodoManagerServer) SetTodosDone(ctx context.Context, req *SetTodosDoneReq) (*CountTodosRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTodosDone not implemented")
+}
+func (*UnimplementedTodoManagerServer) TodoStats(ctx context.Context, req *ListTodosReq) (*TodoStatsRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TodoStats not implemented")
+}
 
 func RegisterTodoManagerServer(s *grpc.Server, srv TodoManagerServer) {
 	s.RegisterService(&_TodoManager_serviceDesc, srv)
@@ -389,6 +2450,58 @@ func _TodoManager_CreateTodo_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TodoManager_BatchCreateTodos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TodoManagerServer).BatchCreateTodos(&todoManagerBatchCreateTodosServer{stream})
+}
+
+type TodoManager_BatchCreateTodosServer interface {
+	SendAndClose(*TodoList) error
+	Recv() (*Todo, error)
+	grpc.ServerStream
+}
+
+type todoManagerBatchCreateTodosServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoManagerBatchCreateTodosServer) SendAndClose(m *TodoList) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *todoManagerBatchCreateTodosServer) Recv() (*Todo, error) {
+	m := new(Todo)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _TodoManager_StreamCreateTodos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TodoManagerServer).StreamCreateTodos(&todoManagerStreamCreateTodosServer{stream})
+}
+
+type TodoManager_StreamCreateTodosServer interface {
+	Send(*CreateTodoRes) error
+	Recv() (*Todo, error)
+	grpc.ServerStream
+}
+
+type todoManagerStreamCreateTodosServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoManagerStreamCreateTodosServer) Send(m *CreateTodoRes) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *todoManagerStreamCreateTodosServer) Recv() (*Todo, error) {
+	m := new(Todo)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _TodoManager_ListTodos_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(ListTodosReq)
 	if err := stream.RecvMsg(m); err != nil {
@@ -410,6 +2523,24 @@ func (x *todoManagerListTodosServer) Send(m *Todo) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _TodoManager_CountTodos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTodosReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).CountTodos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/CountTodos",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).CountTodos(ctx, req.(*ListTodosReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TodoManager_GetTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TodoIdReq)
 	if err := dec(in); err != nil {
@@ -446,8 +2577,26 @@ func _TodoManager_UpdateTodo_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TodoManager_PatchTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TodoPatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).PatchTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/PatchTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).PatchTodo(ctx, req.(*TodoPatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TodoManager_DeleteTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(TodoIdReq)
+	in := new(DeleteTodoReq)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -459,7 +2608,208 @@ func _TodoManager_DeleteTodo_Handler(srv interface{}, ctx context.Context, dec f
 		FullMethod: "/todo_mgr.TodoManager/DeleteTodo",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TodoManagerServer).DeleteTodo(ctx, req.(*TodoIdReq))
+		return srv.(TodoManagerServer).DeleteTodo(ctx, req.(*DeleteTodoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_RestoreTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TodoIdReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).RestoreTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/RestoreTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).RestoreTodo(ctx, req.(*TodoIdReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_WatchTodos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTodosReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TodoManagerServer).WatchTodos(m, &todoManagerWatchTodosServer{stream})
+}
+
+type TodoManager_WatchTodosServer interface {
+	Send(*TodoEvent) error
+	grpc.ServerStream
+}
+
+type todoManagerWatchTodosServer struct {
+	grpc.ServerStream
+}
+
+func (x *todoManagerWatchTodosServer) Send(m *TodoEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TodoManager_AddSubtask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddSubtaskReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).AddSubtask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/AddSubtask",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).AddSubtask(ctx, req.(*AddSubtaskReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_UpdateSubtask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSubtaskReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).UpdateSubtask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/UpdateSubtask",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).UpdateSubtask(ctx, req.(*UpdateSubtaskReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_RemoveSubtask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubtaskIdReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).RemoveSubtask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/RemoveSubtask",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).RemoveSubtask(ctx, req.(*SubtaskIdReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_AddComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddCommentReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).AddComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/AddComment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).AddComment(ctx, req.(*AddCommentReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_ListComments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCommentsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).ListComments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/ListComments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).ListComments(ctx, req.(*ListCommentsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_ShareTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareTodoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).ShareTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/ShareTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).ShareTodo(ctx, req.(*ShareTodoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_MoveTodo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveTodoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).MoveTodo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/MoveTodo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).MoveTodo(ctx, req.(*MoveTodoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_SetTodosDone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTodosDoneReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).SetTodosDone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/SetTodosDone",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).SetTodosDone(ctx, req.(*SetTodosDoneReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoManager_TodoStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTodosReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoManagerServer).TodoStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/todo_mgr.TodoManager/TodoStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoManagerServer).TodoStats(ctx, req.(*ListTodosReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -472,6 +2822,10 @@ var _TodoManager_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CreateTodo",
 			Handler:    _TodoManager_CreateTodo_Handler,
 		},
+		{
+			MethodName: "CountTodos",
+			Handler:    _TodoManager_CountTodos_Handler,
+		},
 		{
 			MethodName: "GetTodo",
 			Handler:    _TodoManager_GetTodo_Handler,
@@ -480,17 +2834,77 @@ var _TodoManager_serviceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateTodo",
 			Handler:    _TodoManager_UpdateTodo_Handler,
 		},
+		{
+			MethodName: "PatchTodo",
+			Handler:    _TodoManager_PatchTodo_Handler,
+		},
 		{
 			MethodName: "DeleteTodo",
 			Handler:    _TodoManager_DeleteTodo_Handler,
 		},
+		{
+			MethodName: "RestoreTodo",
+			Handler:    _TodoManager_RestoreTodo_Handler,
+		},
+		{
+			MethodName: "AddSubtask",
+			Handler:    _TodoManager_AddSubtask_Handler,
+		},
+		{
+			MethodName: "UpdateSubtask",
+			Handler:    _TodoManager_UpdateSubtask_Handler,
+		},
+		{
+			MethodName: "RemoveSubtask",
+			Handler:    _TodoManager_RemoveSubtask_Handler,
+		},
+		{
+			MethodName: "AddComment",
+			Handler:    _TodoManager_AddComment_Handler,
+		},
+		{
+			MethodName: "ListComments",
+			Handler:    _TodoManager_ListComments_Handler,
+		},
+		{
+			MethodName: "ShareTodo",
+			Handler:    _TodoManager_ShareTodo_Handler,
+		},
+		{
+			MethodName: "MoveTodo",
+			Handler:    _TodoManager_MoveTodo_Handler,
+		},
+		{
+			MethodName: "SetTodosDone",
+			Handler:    _TodoManager_SetTodosDone_Handler,
+		},
+		{
+			MethodName: "TodoStats",
+			Handler:    _TodoManager_TodoStats_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchCreateTodos",
+			Handler:       _TodoManager_BatchCreateTodos_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamCreateTodos",
+			Handler:       _TodoManager_StreamCreateTodos_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "ListTodos",
 			Handler:       _TodoManager_ListTodos_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchTodos",
+			Handler:       _TodoManager_WatchTodos_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "todo.proto",
 }
@@ -0,0 +1,236 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+const (
+	// DefaultPageSize is the default number of todos returned by ListTodos when no limit is requested
+	DefaultPageSize = 50
+	// DefaultMaxPageSize is the default max number of todos returned by ListTodos; larger limits are clamped
+	DefaultMaxPageSize = 1000
+)
+
+// NextCursorHeader is set to the cursor of the next page of todos, if the listed page is full
+const NextCursorHeader = "X-Next-Cursor"
+
+// limitClampedHeader is set to the applied limit when the requested one is larger than MaxPageSize
+const limitClampedHeader = "X-Limit-Clamped"
+
+// sortFields is the allow-list of the fields ListTodos can sort by with the 'sort' param
+var sortFields = map[string]bool{
+	"id":         true,
+	"text":       true,
+	"done":       true,
+	"created_at": true,
+	"updated_at": true,
+	"position":   true,
+}
+
+// newListTodosReq builds the gRPC request listing the todos of owner based on query params
+func (t *Router) newListTodosReq(w http.ResponseWriter, r *http.Request, owner string) (*todomgrpb.ListTodosReq, error) {
+	req := &todomgrpb.ListTodosReq{Owner: owner}
+	var err error
+	if req.Limit, req.Offset, err = t.parsePagination(w, r); err != nil {
+		return nil, err
+	}
+	if req.Cursor = r.URL.Query().Get("cursor"); req.Cursor != "" && req.Offset > 0 {
+		return nil, errors.New("cursor and offset can't be used together")
+	}
+	if req.Sort, req.Order, err = parseSort(r); err != nil {
+		return nil, err
+	}
+	if err := parseFilters(r, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// parseFilters sets the filters of req based on query params
+func parseFilters(r *http.Request, req *todomgrpb.ListTodosReq) error {
+	var err error
+	if req.Done, err = parseBoolFilter(r, "done"); err != nil {
+		return err
+	}
+	if req.DueBefore, err = parseTimeFilter(r, "due_before"); err != nil {
+		return err
+	}
+	if req.CreatedAfter, err = parseTimeFilter(r, "created_after"); err != nil {
+		return err
+	}
+	if req.CreatedBefore, err = parseTimeFilter(r, "created_before"); err != nil {
+		return err
+	}
+	if req.CreatedAfter != nil && req.CreatedBefore != nil && isAfter(req.CreatedAfter, req.CreatedBefore) {
+		return errors.New("created_after can't be later than created_before")
+	}
+	deleted, err := parseBoolFilter(r, "deleted")
+	if err != nil {
+		return err
+	}
+	req.Deleted = deleted.GetValue()
+	archived, err := parseBoolFilter(r, "archived")
+	if err != nil {
+		return err
+	}
+	req.Archived = archived.GetValue()
+	req.Tags = normalizeTags(r.URL.Query()["tag"])
+	if priority := r.URL.Query().Get("priority"); priority != "" {
+		if req.Priority, err = parsePriority(priority); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parsePagination reads 'limit' and 'offset' query params; a missing or zero limit
+// means DefaultPageSize, a limit larger than MaxPageSize is clamped and reported in the
+// X-Limit-Clamped header
+func (t *Router) parsePagination(w http.ResponseWriter, r *http.Request) (limit, offset uint32, err error) {
+	limit = uint32(t.DefaultPageSize)
+	query := r.URL.Query()
+	if v := query.Get("limit"); v != "" {
+		l, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("limit must be a non-negative integer, got %q", v)
+		}
+		if l > 0 {
+			limit = uint32(l)
+		}
+	}
+	if t.MaxPageSize > 0 && limit > uint32(t.MaxPageSize) {
+		limit = uint32(t.MaxPageSize)
+		w.Header().Set(limitClampedHeader, strconv.Itoa(t.MaxPageSize))
+	}
+	if v := query.Get("offset"); v != "" {
+		o, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer, got %q", v)
+		}
+		offset = uint32(o)
+	}
+	return limit, offset, nil
+}
+
+// paginationLinks returns the value of the Link header (RFC 8288) of a page of limit todos
+// starting at offset out of total todos, with the first, last, prev and next pages; prev is
+// omitted on the first page and next on the last one. The links are the URL of r with the
+// other query params unchanged, so that they keep filters and sorting.
+func paginationLinks(r *http.Request, limit, offset uint32, total uint64) string {
+	if limit == 0 {
+		return ""
+	}
+	link := func(rel string, offset uint64) string {
+		query := r.URL.Query()
+		query.Set("limit", strconv.FormatUint(uint64(limit), 10))
+		query.Set("offset", strconv.FormatUint(offset, 10))
+		u := *r.URL
+		u.Scheme, u.Host, u.RawQuery = "", "", query.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+	}
+	size, start := uint64(limit), uint64(offset)
+	var last uint64
+	if total > 0 {
+		last = (total - 1) / size * size
+	}
+	links := []string{link("first", 0)}
+	if start > 0 {
+		prev := uint64(0)
+		if start > size {
+			prev = start - size
+		}
+		links = append(links, link("prev", prev))
+	}
+	if start+size < total {
+		links = append(links, link("next", start+size))
+	}
+	links = append(links, link("last", last))
+	return strings.Join(links, ", ")
+}
+
+// cursorLinks returns the value of the Link header of a page of todos listed with a cursor, with
+// the first page and the next one, if next isn't empty
+func cursorLinks(r *http.Request, next string) string {
+	link := func(rel, cursor string) string {
+		query := r.URL.Query()
+		query.Del("cursor")
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		u := *r.URL
+		u.Scheme, u.Host, u.RawQuery = "", "", query.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+	}
+	links := []string{link("first", "")}
+	if next != "" {
+		links = append(links, link("next", next))
+	}
+	return strings.Join(links, ", ")
+}
+
+// parseSort reads 'sort' and 'order' query params; unknown sort fields are an error,
+// while any order other than "desc" means ascending
+func parseSort(r *http.Request) (string, todomgrpb.ListTodosReq_Order, error) {
+	query := r.URL.Query()
+	sort := query.Get("sort")
+	if sort != "" && !sortFields[sort] {
+		return "", 0, fmt.Errorf("can't sort by %q, allowed fields are: id, text, done, created_at, updated_at, position", sort)
+	}
+	order := todomgrpb.ListTodosReq_ASC
+	if strings.ToLower(query.Get("order")) == "desc" {
+		order = todomgrpb.ListTodosReq_DESC
+	}
+	return sort, order, nil
+}
+
+// parseBoolFilter reads an optional boolean query param; nil is returned if it's not present
+func parseBoolFilter(r *http.Request, name string) (*wrappers.BoolValue, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a boolean, got %q", name, v)
+	}
+	return &wrappers.BoolValue{Value: b}, nil
+}
+
+// parseTimeFilter reads an optional RFC3339 time query param; nil is returned if it's not present
+func parseTimeFilter(r *http.Request, name string) (*timestamp.Timestamp, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 date-time, got %q", name, v)
+	}
+	return ptypes.TimestampProto(t)
+}
+
+// isAfter reports whether the timestamp a is later than b
+func isAfter(a, b *timestamp.Timestamp) bool {
+	return a.GetSeconds() > b.GetSeconds() || a.GetSeconds() == b.GetSeconds() && a.GetNanos() > b.GetNanos()
+}
+
+// parseTodoID parses the ID of a todo sent by a client; IDs are positive and fit in an int64
+func parseTodoID(todoID string) (uint64, error) {
+	id, err := strconv.ParseInt(todoID, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("todo ID must be a positive integer up to %d, got %q", int64(math.MaxInt64), todoID)
+	}
+	return uint64(id), nil
+}
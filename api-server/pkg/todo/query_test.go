@@ -0,0 +1,241 @@
+package todo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// fakeTodos returns n todos of owner with texts "todo 1" to "todo n"
+func fakeTodos(owner string, n int) []*todomgrpb.Todo {
+	var todos []*todomgrpb.Todo
+	for i := 1; i <= n; i++ {
+		todos = append(todos, &todomgrpb.Todo{Text: fmt.Sprintf("todo %d", i), Owner: owner})
+	}
+	return todos
+}
+
+func TestListTodosPagination(t *testing.T) {
+	client := newFakeClient(fakeTodos(Username, 5)...)
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	router.DefaultPageSize = 2
+	router.MaxPageSize = 3
+	handler := router.GetRouter()
+
+	tests := []struct {
+		name    string
+		query   string
+		status  int
+		limit   uint32
+		offset  uint32
+		todos   []string
+		clamped string
+		link    string
+	}{
+		{
+			name:   "defaults",
+			status: http.StatusOK,
+			limit:  2,
+			todos:  []string{"1", "2"},
+			link:   `</?limit=2&offset=0>; rel="first", </?limit=2&offset=2>; rel="next", </?limit=2&offset=4>; rel="last"`,
+		},
+		{
+			name:   "limit and offset",
+			query:  "?limit=2&offset=1",
+			status: http.StatusOK,
+			limit:  2,
+			offset: 1,
+			todos:  []string{"2", "3"},
+			link:   `</?limit=2&offset=0>; rel="first", </?limit=2&offset=0>; rel="prev", </?limit=2&offset=3>; rel="next", </?limit=2&offset=4>; rel="last"`,
+		},
+		{
+			name:   "zero limit is the default",
+			query:  "?limit=0",
+			status: http.StatusOK,
+			limit:  2,
+			todos:  []string{"1", "2"},
+		},
+		{
+			name:    "limit is clamped",
+			query:   "?limit=100000",
+			status:  http.StatusOK,
+			limit:   3,
+			todos:   []string{"1", "2", "3"},
+			clamped: "3",
+		},
+		{
+			name:   "last page",
+			query:  "?limit=3&offset=3",
+			status: http.StatusOK,
+			limit:  3,
+			offset: 3,
+			todos:  []string{"4", "5"},
+			link:   `</?limit=3&offset=0>; rel="first", </?limit=3&offset=0>; rel="prev", </?limit=3&offset=3>; rel="last"`,
+		},
+		{
+			name:   "offset past the end",
+			query:  "?offset=10",
+			status: http.StatusOK,
+			limit:  2,
+			offset: 10,
+			todos:  []string{},
+		},
+		{
+			name:   "negative limit",
+			query:  "?limit=-1",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "limit isn't a number",
+			query:  "?limit=ten",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "limit overflows",
+			query:  "?limit=4294967296",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "negative offset",
+			query:  "?offset=-3",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "offset with cursor",
+			query:  "?offset=1&cursor=abc",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.listReq = nil
+			res := serve(handler, http.MethodGet, "/"+tt.query, "")
+			expectStatus(t, res, tt.status)
+			if tt.status != http.StatusOK {
+				if client.listReq != nil {
+					t.Errorf("expected invalid request not to reach todo-manager, got %v", client.listReq)
+				}
+				return
+			}
+			if client.listReq.Limit != tt.limit || client.listReq.Offset != tt.offset {
+				t.Errorf("expected limit %d and offset %d, got %d and %d", tt.limit, tt.offset, client.listReq.Limit, client.listReq.Offset)
+			}
+			var todos []Todo
+			decodeJSONRes(t, res, &todos)
+			ids := []string{}
+			for _, todo := range todos {
+				ids = append(ids, todo.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.todos, ",") {
+				t.Errorf("expected todos %v, got %v", tt.todos, ids)
+			}
+			if total := res.Header().Get("X-Total-Count"); total != "5" {
+				t.Errorf("expected X-Total-Count 5, got %q", total)
+			}
+			if clamped := res.Header().Get(limitClampedHeader); clamped != tt.clamped {
+				t.Errorf("expected %s %q, got %q", limitClampedHeader, tt.clamped, clamped)
+			}
+			if link := res.Header().Get("Link"); tt.link != "" && link != tt.link {
+				t.Errorf("expected Link %s, got %s", tt.link, link)
+			}
+		})
+	}
+}
+
+func TestParsePaginationDefaults(t *testing.T) {
+	router := newTestRouter(newFakeClient(), nil)
+	defer router.Close()
+	client := router.grpcClient.(*fakeClient)
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodGet, "/", "")
+	expectStatus(t, res, http.StatusOK)
+	if client.listReq.Limit != DefaultPageSize {
+		t.Errorf("expected default limit %d, got %d", DefaultPageSize, client.listReq.Limit)
+	}
+	res = serve(handler, http.MethodGet, "/?limit=100000", "")
+	expectStatus(t, res, http.StatusOK)
+	if client.listReq.Limit != DefaultMaxPageSize {
+		t.Errorf("expected limit clamped to %d, got %d", DefaultMaxPageSize, client.listReq.Limit)
+	}
+}
+
+// listedIDs lists the todos of the JSON response to a GET request of target, failing the test
+// if it doesn't succeed
+func listedIDs(t *testing.T, handler http.Handler, target string) []string {
+	t.Helper()
+	res := serve(handler, http.MethodGet, target, "")
+	expectStatus(t, res, http.StatusOK)
+	var todos []Todo
+	decodeJSONRes(t, res, &todos)
+	ids := []string{}
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	return ids
+}
+
+func TestListTodosDoneFilter(t *testing.T) {
+	client := newFakeClient(
+		&todomgrpb.Todo{Text: "open", Owner: Username},
+		&todomgrpb.Todo{Text: "done", Owner: Username, Done: true},
+		&todomgrpb.Todo{Text: "also open", Owner: Username},
+	)
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	tests := []struct {
+		query string
+		todos []string
+	}{
+		{query: "", todos: []string{"1", "2", "3"}},
+		{query: "?done=true", todos: []string{"2"}},
+		{query: "?done=false", todos: []string{"1", "3"}},
+	}
+	for _, tt := range tests {
+		if ids := listedIDs(t, handler, "/"+tt.query); strings.Join(ids, ",") != strings.Join(tt.todos, ",") {
+			t.Errorf("expected todos %v for %q, got %v", tt.todos, tt.query, ids)
+		}
+	}
+	if res := serve(handler, http.MethodGet, "/?done=maybe", ""); res.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid done filter, got %d", res.Code)
+	}
+}
+
+func TestSearchTodos(t *testing.T) {
+	client := newFakeClient(
+		&todomgrpb.Todo{Text: "Buy MILK", Owner: Username},
+		&todomgrpb.Todo{Text: "call mom", Owner: Username},
+		&todomgrpb.Todo{Text: "milk the cow", Owner: Username},
+		&todomgrpb.Todo{Text: "milk of alice", Owner: "alice"},
+	)
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	if ids := listedIDs(t, handler, "/search?q=Milk"); strings.Join(ids, ",") != "1,3" {
+		t.Errorf("expected todos matching milk ignoring case, got %v", ids)
+	}
+	if client.listReq.TextQuery != "Milk" || client.listReq.Owner != Username {
+		t.Errorf("expected the query of the owner to be sent to todo-manager, got %v", client.listReq)
+	}
+	if ids := listedIDs(t, handler, "/search?q=milk&limit=1&offset=1"); strings.Join(ids, ",") != "3" {
+		t.Errorf("expected the second page of matching todos, got %v", ids)
+	}
+	if ids := listedIDs(t, handler, "/search?q=bread"); len(ids) != 0 {
+		t.Errorf("expected no match, got %v", ids)
+	}
+	res := serve(handler, http.MethodGet, "/search?q=bread", "")
+	if body := strings.TrimSpace(res.Body.String()); body != "[]" {
+		t.Errorf("expected an empty JSON array, got %q", body)
+	}
+	for _, query := range []string{"", "?q=", "?limit=10"} {
+		res := serve(handler, http.MethodGet, "/search"+query, "")
+		expectStatus(t, res, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,126 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// rateLimiterIdleTimeout is the time after which a token bucket that wasn't used is dropped
+const rateLimiterIdleTimeout = 10 * time.Minute
+
+// The headers telling clients how close they are to the rate limit, set on every response
+const (
+	// RateLimitLimitHeader holds the max number of requests allowed at once, the burst
+	RateLimitLimitHeader = "X-RateLimit-Limit"
+	// RateLimitRemainingHeader holds the number of requests allowed right now, including this one
+	// if it's rejected
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	// RateLimitResetHeader holds the Unix time in seconds when all the requests are allowed again
+	RateLimitResetHeader = "X-RateLimit-Reset"
+)
+
+// rateLimiterEntry is the token bucket of a client
+type rateLimiterEntry struct {
+	// tokens is the number of requests the bucket allowed at lastSeen
+	tokens   float64
+	lastSeen time.Time
+}
+
+// ownerRateLimiter keeps a token bucket for each owner
+type ownerRateLimiter struct {
+	rps       float64
+	burst     int
+	lock      sync.Mutex
+	limiters  map[string]*rateLimiterEntry
+	lastSweep time.Time
+}
+
+// NewRateLimitMiddleware returns a middleware limiting requests to rps per second with burst
+// per owner; requests without an owner set by one of the auth middlewares are limited per
+// client IP address. The X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers
+// are set on every response, so that clients can slow down before they're limited.
+func NewRateLimitMiddleware(rps float64, burst int) func(http.Handler) http.Handler {
+	l := &ownerRateLimiter{
+		rps:       rps,
+		burst:     burst,
+		limiters:  map[string]*rateLimiterEntry{},
+		lastSweep: time.Now(),
+	}
+	return l.handler
+}
+
+// take takes a token from the bucket of key at now, refilled at rps since it was last used. It
+// returns the tokens left and, if there was none to take, the time to wait for one.
+func (l *ownerRateLimiter) take(key string, now time.Time) (float64, time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	// drop buckets of clients we haven't seen in a while, so the map doesn't grow forever
+	if now.Sub(l.lastSweep) > rateLimiterIdleTimeout {
+		for k, e := range l.limiters {
+			if now.Sub(e.lastSeen) > rateLimiterIdleTimeout {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	e, found := l.limiters[key]
+	if !found {
+		e = &rateLimiterEntry{tokens: float64(l.burst), lastSeen: now}
+		l.limiters[key] = e
+	}
+	e.tokens = math.Min(float64(l.burst), e.tokens+now.Sub(e.lastSeen).Seconds()*l.rps)
+	e.lastSeen = now
+	if e.tokens < 1 {
+		return e.tokens, time.Duration((1 - e.tokens) / l.rps * float64(time.Second))
+	}
+	e.tokens--
+	return e.tokens, 0
+}
+
+// setRateLimitHeaders sets the headers reporting the tokens left in the bucket of the client
+func (l *ownerRateLimiter) setRateLimitHeaders(w http.ResponseWriter, tokens float64, now time.Time) {
+	full := now.Add(time.Duration((float64(l.burst) - tokens) / l.rps * float64(time.Second)))
+	w.Header().Set(RateLimitLimitHeader, strconv.Itoa(l.burst))
+	w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(int(math.Max(0, math.Floor(tokens)))))
+	w.Header().Set(RateLimitResetHeader, strconv.FormatInt(int64(math.Ceil(float64(full.UnixNano())/float64(time.Second))), 10))
+}
+
+func (l *ownerRateLimiter) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l.burst < 1 || l.rps <= 0 {
+			// no request can ever be allowed
+			render.Render(w, r, errTooManyRequests(errors.New("rate limit exceeded"), defaultRetryAfter))
+			return
+		}
+		key, ok := OwnerFromContext(r.Context())
+		if !ok {
+			key = clientIP(r)
+		}
+		now := time.Now()
+		tokens, delay := l.take(key, now)
+		l.setRateLimitHeaders(w, tokens, now)
+		if delay > 0 {
+			retryAfter := int(math.Ceil(delay.Seconds()))
+			render.Render(w, r, errTooManyRequests(fmt.Errorf("rate limit exceeded, retry in %d seconds", retryAfter), retryAfter))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP returns the IP address of the client sending the request
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,69 @@
+package todo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	handler := NewRateLimitMiddleware(0.5, 2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	request := func(owner string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if owner != "" {
+			r = r.WithContext(ContextWithOwner(r.Context(), owner))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		res := request("alice")
+		expectStatus(t, res, http.StatusNoContent)
+		if limit := res.Header().Get(RateLimitLimitHeader); limit != "2" {
+			t.Errorf("expected limit 2, got %q", limit)
+		}
+	}
+	res := request("alice")
+	expectStatus(t, res, http.StatusTooManyRequests)
+	if retryAfter := res.Header().Get("Retry-After"); retryAfter != "2" {
+		t.Errorf("expected Retry-After 2 at 0.5 requests per second, got %q", retryAfter)
+	}
+	if remaining := res.Header().Get(RateLimitRemainingHeader); remaining != "0" {
+		t.Errorf("expected no request remaining, got %q", remaining)
+	}
+	body := &ErrorRes{}
+	decodeJSONRes(t, res, body)
+	if body.Code != CodeRateLimited {
+		t.Errorf("expected code %s, got %s", CodeRateLimited, body.Code)
+	}
+
+	// the buckets are per owner, and per client IP without one
+	expectStatus(t, request("bob"), http.StatusNoContent)
+	expectStatus(t, request(""), http.StatusNoContent)
+	expectStatus(t, request(""), http.StatusNoContent)
+	expectStatus(t, request(""), http.StatusTooManyRequests)
+}
+
+func TestOwnerRateLimiterRefill(t *testing.T) {
+	l := &ownerRateLimiter{rps: 2, burst: 2, limiters: map[string]*rateLimiterEntry{}, lastSweep: time.Now()}
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, delay := l.take("alice", now); delay != 0 {
+			t.Fatalf("expected request %d within the burst to be allowed", i)
+		}
+	}
+	if _, delay := l.take("alice", now); delay != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms for a token, got %v", delay)
+	}
+	if _, delay := l.take("alice", now.Add(500*time.Millisecond)); delay != 0 {
+		t.Errorf("expected the bucket to be refilled after 500ms")
+	}
+	if tokens, _ := l.take("alice", now.Add(time.Hour)); tokens != 1 {
+		t.Errorf("expected the bucket to be refilled up to the burst, got %v tokens left", tokens)
+	}
+}
@@ -0,0 +1,107 @@
+package todo
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+// errReadOnly is returned for requests changing todos while the router is in read-only mode
+var errReadOnly = errors.New("the API is in read-only mode for maintenance, todos can't be changed at the moment")
+
+// ReadOnly checks if the router is in read-only mode
+func (t *Router) ReadOnly() bool {
+	return atomic.LoadInt32(&t.readOnly) == 1
+}
+
+// SetReadOnly switches the read-only mode of the router: while it's on, requests changing todos
+// are rejected with 503 Service Unavailable and only reads are served. It's safe to call while
+// serving requests; the mode is kept in memory, so every replica has to be switched.
+func (t *Router) SetReadOnly(readOnly bool) {
+	var value int32
+	if readOnly {
+		value = 1
+	}
+	if atomic.SwapInt32(&t.readOnly, value) != value {
+		t.options.Logger.WithField("read_only", readOnly).Warn("Read-only mode switched")
+	}
+}
+
+// rejectWritesWhenReadOnly rejects requests with methods other than GET, HEAD and OPTIONS while
+// the router is in read-only mode
+func (t *Router) rejectWritesWhenReadOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if t.ReadOnly() {
+				render.Render(w, r, errServiceUnavailable(withCode(CodeReadOnly, errReadOnly)))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ReadOnlyRes is the request and response of the read-only mode admin endpoint
+type ReadOnlyRes struct {
+	ReadOnly *bool `json:"read_only"`
+}
+
+// Bind checks the ReadOnlyRes object decoded from the request
+func (res *ReadOnlyRes) Bind(r *http.Request) error {
+	if res.ReadOnly == nil {
+		return errors.New("read_only is required")
+	}
+	return nil
+}
+
+// Render allows to modify the way ReadOnlyRes object is rendered to text; not used here
+func (res *ReadOnlyRes) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// GetReadOnly reports if the router is in read-only mode
+func (t *Router) GetReadOnly(w http.ResponseWriter, r *http.Request) {
+	readOnly := t.ReadOnly()
+	render.Render(w, r, &ReadOnlyRes{ReadOnly: &readOnly})
+}
+
+// PutReadOnly switches the read-only mode of the router on or off
+func (t *Router) PutReadOnly(w http.ResponseWriter, r *http.Request) {
+	data := &ReadOnlyRes{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	t.SetReadOnly(*data.ReadOnly)
+	t.GetReadOnly(w, r)
+}
+
+// AdminTokenMiddleware allows only requests with token as their bearer token in the Authorization
+// header; the token is compared in constant time. It guards the admin endpoints, which aren't
+// scoped to an owner.
+func AdminTokenMiddleware(token string) func(http.Handler) http.Handler {
+	expected := sha256.Sum256([]byte(token))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if !strings.HasPrefix(header, "Bearer ") {
+				render.Render(w, r, middleware.ErrAuth(errors.New("Authorization header is missing or not a bearer token")))
+				return
+			}
+			hash := sha256.Sum256([]byte(strings.TrimPrefix(header, "Bearer ")))
+			if subtle.ConstantTimeCompare(hash[:], expected[:]) != 1 {
+				render.Render(w, r, middleware.ErrAuth(errors.New("invalid admin token")))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
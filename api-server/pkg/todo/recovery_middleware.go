@@ -0,0 +1,51 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/sirupsen/logrus"
+)
+
+// errHandlerPanicked is the error reported to clients when a handler panics; the panic itself
+// is only logged
+var errHandlerPanicked = errors.New("the request handler panicked")
+
+// NewRecoveryMiddleware returns a middleware recovering from panics of handlers: the panic and its
+// stack trace are logged at error level and a 500 Internal Server Error is rendered. If the handler
+// already started the response, it's ended where it stopped, as its status can't be changed.
+func NewRecoveryMiddleware(logger logrus.FieldLogger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					return
+				}
+				// aborting a response is a deliberate panic handled by net/http
+				if rvr == http.ErrAbortHandler {
+					panic(rvr)
+				}
+				stack := debug.Stack()
+				if p, ok := rvr.(*handlerPanic); ok {
+					rvr, stack = p.value, p.stack
+				}
+				logger.WithFields(logrus.Fields{
+					"req_id": chimiddleware.GetReqID(r.Context()),
+					"uri":    r.RequestURI,
+					"panic":  fmt.Sprintf("%+v", rvr),
+					"stack":  string(stack),
+				}).Error("Request handler panicked")
+				if ww.Status() == 0 {
+					render.Render(ww, r, errInternal(errHandlerPanicked))
+				}
+			}()
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package todo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// panickingClient is a fakeClient panicking when getting a todo, like with a bug in a conversion helper
+type panickingClient struct {
+	*fakeClient
+}
+
+func (c *panickingClient) GetTodo(ctx context.Context, req *todomgrpb.TodoIdReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	var todo *todomgrpb.Todo
+	return &todomgrpb.Todo{Text: todo.Text}, nil
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.Out = buf
+	logger.Formatter = &logrus.JSONFormatter{}
+	router := newTestRouter(&panickingClient{newFakeClient()}, &RouterOptions{Logger: logger})
+	defer router.Close()
+
+	res := serve(router.GetRouter(), http.MethodGet, "/1", "", RequestIDHeader, "req-1")
+	expectStatus(t, res, http.StatusInternalServerError)
+	if contentType := res.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("expected a JSON error, got Content-Type %q", contentType)
+	}
+	body := &ErrorRes{}
+	decodeJSONRes(t, res, body)
+	if body.Code != CodeInternalError || body.Error != errHandlerPanicked.Error() {
+		t.Errorf("expected an internal error, got %+v", body)
+	}
+
+	line := logLine(t, logLines(t, buf), "Request handler panicked")
+	if line["level"] != "error" || line["req_id"] != "req-1" || !strings.Contains(line["panic"].(string), "nil pointer dereference") {
+		t.Errorf("expected the panic to be logged at error level, got %v", line)
+	}
+	// the handler runs in its own goroutine with the timeout middleware, the stack is still the one of the panic
+	if stack, _ := line["stack"].(string); !strings.Contains(stack, "panickingClient") {
+		t.Errorf("expected the stack trace of the panic to be logged, got %q", stack)
+	}
+}
+
+func TestRecoveryMiddlewareStack(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.Out = buf
+	logger.Formatter = &logrus.JSONFormatter{}
+	client := &panickingClient{newFakeClient()}
+	handler := NewRecoveryMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client.GetTodo(r.Context(), &todomgrpb.TodoIdReq{Id: 1})
+	}))
+
+	expectStatus(t, serve(handler, http.MethodGet, "/", ""), http.StatusInternalServerError)
+	line := logLine(t, logLines(t, buf), "Request handler panicked")
+	if stack, _ := line["stack"].(string); !strings.Contains(stack, "panickingClient") {
+		t.Errorf("expected the stack trace of the panic to be logged, got %q", stack)
+	}
+}
+
+func TestRecoveryMiddlewareStartedResponse(t *testing.T) {
+	logger := logrus.New()
+	logger.Out = &bytes.Buffer{}
+	handler := NewRecoveryMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("[{"))
+		panic("oops")
+	}))
+
+	res := serve(handler, http.MethodGet, "/", "")
+	expectStatus(t, res, http.StatusOK)
+	if res.Body.String() != "[{" {
+		t.Errorf("expected the response to end where it stopped, got %q", res.Body.String())
+	}
+	if !strings.Contains(logger.Out.(*bytes.Buffer).String(), "oops") {
+		t.Errorf("expected the panic to be logged")
+	}
+
+	// aborting a response is left to net/http, even from a handler run by the timeout middleware
+	handler = NewRecoveryMiddleware(logger)(NewTimeoutMiddleware(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})))
+	defer func() {
+		if rvr := recover(); rvr != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to be panicked again, got %v", rvr)
+		}
+	}()
+	serve(handler, http.MethodGet, "/", "")
+}
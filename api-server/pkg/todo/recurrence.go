@@ -0,0 +1,79 @@
+package todo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// maxRecurrenceInterval is the max number of periods between occurrences of a recurring todo
+const maxRecurrenceInterval = 365
+
+// frequencies maps the allowed recurrence frequencies to their gRPC enum values
+var frequencies = map[string]todomgrpb.Frequency{
+	"daily":   todomgrpb.Frequency_DAILY,
+	"weekly":  todomgrpb.Frequency_WEEKLY,
+	"monthly": todomgrpb.Frequency_MONTHLY,
+}
+
+// Recurrence makes todo-manager create the next occurrence of a todo when it's completed,
+// with the due date advanced by Interval periods of Frequency
+type Recurrence struct {
+	// Frequency is one of: daily, weekly, monthly
+	Frequency string `json:"frequency" xml:"frequency"`
+	// Interval defaults to 1
+	Interval uint32 `json:"interval,omitempty" xml:"interval,omitempty"`
+}
+
+// Validate checks if Recurrence has a known frequency and an interval in range
+func (rec *Recurrence) Validate() error {
+	if _, found := frequencies[rec.Frequency]; !found {
+		return fmt.Errorf("recurrence frequency must be one of: daily, weekly, monthly, got %q", rec.Frequency)
+	}
+	if rec.Interval > maxRecurrenceInterval {
+		return fmt.Errorf("recurrence interval can't be more than %d", maxRecurrenceInterval)
+	}
+	return nil
+}
+
+// ToGRPCRecurrence return gRPC DTO for the upstream todo-manager service
+func (rec *Recurrence) ToGRPCRecurrence() *todomgrpb.Recurrence {
+	if rec == nil {
+		return nil
+	}
+	return &todomgrpb.Recurrence{
+		Frequency: frequencies[rec.Frequency],
+		Interval:  rec.Interval,
+	}
+}
+
+// FromGRPCRecurrence returns new Recurrence object based on an optional gRPC DTO
+func FromGRPCRecurrence(grpcRecurrence *todomgrpb.Recurrence) *Recurrence {
+	if grpcRecurrence == nil {
+		return nil
+	}
+	return &Recurrence{
+		Frequency: strings.ToLower(grpcRecurrence.GetFrequency().String()),
+		Interval:  grpcRecurrence.GetInterval(),
+	}
+}
+
+// optionalRecurrence is a JSON recurrence field that tells apart a missing key from an explicit null
+type optionalRecurrence struct {
+	// Set is true if the key was present in JSON
+	Set bool
+	// Value is nil if the key was set to null
+	Value *Recurrence
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (o *optionalRecurrence) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Value = nil
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
@@ -0,0 +1,169 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// errReplaceNotConfirmed is returned when replacing all the todos is not confirmed with ?replace=true
+var errReplaceNotConfirmed = errors.New("replacing all the todos deletes the ones missing from the request, confirm it with ?replace=true")
+
+// ReplaceRes data model.
+type ReplaceRes struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Deleted []string `json:"deleted"`
+}
+
+// Render allows to modify the way ReplaceRes object is rendered to text; not used here
+func (res *ReplaceRes) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// ReplaceTodos makes the active todos owned by a user match a JSON array: todos without an ID are
+// created, the ones with an ID are replaced and the ones missing from the array are moved to the
+// trash. Archived todos, todos in the trash and todos shared with the user are left untouched.
+// The request is rejected before changing anything if any todo is invalid or its ID doesn't
+// match an active todo of the user, but it's not atomic: when a change fails, the ones before
+// it are kept.
+func (t *Router) ReplaceTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	if r.URL.Query().Get("replace") != "true" {
+		render.Render(w, r, middleware.ErrInvalidRequest(errReplaceNotConfirmed))
+		return
+	}
+	var data []*Todo
+	if err := render.DecodeJSON(r.Body, &data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+
+	existing, err := t.ownedTodoIDs(r, owner)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	kept := map[uint64]bool{}
+	ids := make([]uint64, len(data))
+	for i, todo := range data {
+		if todo == nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: can't be null", i)))
+			return
+		}
+		if err := todo.Bind(r); err != nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: %v", i, err)))
+			return
+		}
+		if err := t.validateTodo(todo); err != nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: %v", i, err)))
+			return
+		}
+		if todo.Priority == "" {
+			todo.Priority = PriorityMedium
+		}
+		if todo.ID == "" || todo.ID == "0" {
+			continue
+		}
+		id, err := parseTodoID(todo.ID)
+		if err != nil {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: %v", i, err)))
+			return
+		}
+		if !existing[id] {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: there's no active todo with ID %d", i, id)))
+			return
+		}
+		if kept[id] {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("todo at index %d: ID %d is listed more than once", i, id)))
+			return
+		}
+		kept[id] = true
+		ids[i] = id
+	}
+
+	res := &ReplaceRes{Created: []string{}, Updated: []string{}, Deleted: []string{}}
+	for i, item := range data {
+		req := item.ToGRPCTodo(owner)
+		ctx, cancel := t.callContext(r)
+		var grpcTodo *todomgrpb.Todo
+		if ids[i] == 0 {
+			req.Id = 0
+			grpcTodo, err = t.grpcClient.CreateTodo(ctx, req)
+		} else {
+			req.Id = ids[i]
+			grpcTodo, err = t.grpcClient.UpdateTodo(ctx, req)
+		}
+		cancel()
+		if err != nil {
+			t.renderGRPCError(w, r, err)
+			return
+		}
+		todo, _ := FromGRPCTodo(grpcTodo)
+		if ids[i] == 0 {
+			res.Created = append(res.Created, todo.ID)
+			t.createOneCounter.WithLabelValues(owner).Inc()
+			t.webhooks.dispatch(WebhookEventCreated, owner, todo)
+		} else {
+			res.Updated = append(res.Updated, todo.ID)
+			t.updateOneCounter.WithLabelValues(owner).Inc()
+			t.webhooks.dispatch(WebhookEventUpdated, owner, todo)
+		}
+	}
+	for id := range existing {
+		if kept[id] {
+			continue
+		}
+		ctx, cancel := t.callContext(r)
+		_, err := t.grpcClient.DeleteTodo(ctx, &todomgrpb.DeleteTodoReq{
+			Id:    id,
+			Owner: owner,
+		})
+		cancel()
+		if err != nil {
+			t.renderGRPCError(w, r, err)
+			return
+		}
+		todo := &Todo{ID: strconv.FormatUint(id, 10)}
+		res.Deleted = append(res.Deleted, todo.ID)
+		t.deleteOneCounter.WithLabelValues(owner).Inc()
+		t.webhooks.dispatch(WebhookEventDeleted, owner, todo)
+	}
+	if err := render.Render(w, r, res); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+}
+
+// ownedTodoIDs returns the IDs of all the active todos owned by owner, without the shared ones
+func (t *Router) ownedTodoIDs(r *http.Request, owner string) (map[uint64]bool, error) {
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	stream, err := t.grpcClient.ListTodos(ctx, &todomgrpb.ListTodosReq{Owner: owner})
+	if err != nil {
+		return nil, err
+	}
+	ids := map[uint64]bool{}
+	for {
+		todo, err := stream.Recv()
+		if err == io.EOF {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if todo.GetOwner() == owner {
+			ids[todo.GetId()] = true
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// RequestIDHeader is the HTTP header carrying the request ID
+	RequestIDHeader = "X-Request-Id"
+	// RequestIDMetadataKey is the gRPC metadata key carrying the request ID to todo-manager
+	RequestIDMetadataKey = "x-request-id"
+)
+
+// RequestIDMiddleware echoes the request ID back in the X-Request-Id response header. The ID
+// is taken from the context when chi's RequestID middleware already ran, otherwise that
+// middleware is used to read it from the request header or generate a new one.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, chimiddleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r)
+	})
+	withID := chimiddleware.RequestID(echo)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if chimiddleware.GetReqID(r.Context()) == "" {
+			withID.ServeHTTP(w, r)
+			return
+		}
+		echo.ServeHTTP(w, r)
+	})
+}
+
+// withRequestID attaches the request ID stored in ctx to the outgoing gRPC metadata
+func withRequestID(ctx context.Context) context.Context {
+	if reqID := chimiddleware.GetReqID(ctx); reqID != "" {
+		return metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, reqID)
+	}
+	return ctx
+}
+
+// unaryRequestIDInterceptor passes the request ID to todo-manager with every unary call
+func unaryRequestIDInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+// streamRequestIDInterceptor passes the request ID to todo-manager with every streaming call
+func streamRequestIDInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withRequestID(ctx), desc, cc, method, opts...)
+}
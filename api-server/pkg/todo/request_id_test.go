@@ -0,0 +1,88 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	// the request IDs todo-manager gets in the metadata of the calls
+	var lock sync.Mutex
+	var received []string
+	addr, server := startTestServer(t, &testServer{
+		getTodo: func(ctx context.Context, req *todomgrpb.TodoIdReq) (*todomgrpb.Todo, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			lock.Lock()
+			received = append(received, md.Get(RequestIDMetadataKey)...)
+			lock.Unlock()
+			return &todomgrpb.Todo{Id: req.Id, Text: "todo", Owner: req.Owner}, nil
+		},
+	})
+	defer server.Stop()
+	router, err := NewRouter(addr, testOptions(&RouterOptions{Insecure: true}))
+	if err != nil {
+		t.Fatalf("can't create the router: %v", err)
+	}
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodGet, "/1", "", RequestIDHeader, "req-1")
+	expectStatus(t, res, http.StatusOK)
+	if reqID := res.Header().Get(RequestIDHeader); reqID != "req-1" {
+		t.Errorf("expected the request ID to be echoed, got %q", reqID)
+	}
+	res = serve(handler, http.MethodGet, "/1", "")
+	expectStatus(t, res, http.StatusOK)
+	generated := res.Header().Get(RequestIDHeader)
+	if generated == "" {
+		t.Errorf("expected a request ID to be generated")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(received) != 2 || received[0] != "req-1" || received[1] != generated {
+		t.Errorf("expected todo-manager to get the request IDs [req-1 %s], got %v", generated, received)
+	}
+}
+
+func TestRequestIDInterceptors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), chimiddleware.RequestIDKey, "req-1")
+	var md metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	if err := unaryRequestIDInterceptor(ctx, "/todo_mgr.TodoManager/GetTodo", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reqID := md.Get(RequestIDMetadataKey); len(reqID) != 1 || reqID[0] != "req-1" {
+		t.Errorf("expected the request ID in the metadata of unary calls, got %v", reqID)
+	}
+
+	md = nil
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	}
+	if _, err := streamRequestIDInterceptor(ctx, &grpc.StreamDesc{}, nil, "/todo_mgr.TodoManager/ListTodos", streamer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reqID := md.Get(RequestIDMetadataKey); len(reqID) != 1 || reqID[0] != "req-1" {
+		t.Errorf("expected the request ID in the metadata of streaming calls, got %v", reqID)
+	}
+
+	// without a request ID, no metadata is added
+	md = nil
+	unaryRequestIDInterceptor(context.Background(), "/todo_mgr.TodoManager/GetTodo", nil, nil, nil, invoker)
+	if reqID := md.Get(RequestIDMetadataKey); len(reqID) != 0 {
+		t.Errorf("expected no request ID in the metadata, got %v", reqID)
+	}
+}
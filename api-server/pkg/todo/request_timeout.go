@@ -0,0 +1,47 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+// RequestTimeoutHeader is the header clients use to ask for a shorter timeout of the gRPC calls
+// made while serving their request, as a Go duration like "3s"
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+type callTimeoutCtxKey struct{}
+
+// requestTimeout sets the timeout of the gRPC calls of requests with the X-Request-Timeout header,
+// clamped to CallTimeout; requests with a malformed or non-positive duration are rejected
+func (t *Router) requestTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(RequestTimeoutHeader)
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		timeout, err := time.ParseDuration(header)
+		if err != nil || timeout <= 0 {
+			render.Render(w, r, middleware.ErrInvalidRequest(fmt.Errorf("%s must be a positive duration like 3s, got %q", RequestTimeoutHeader, header)))
+			return
+		}
+		if timeout > t.options.CallTimeout {
+			timeout = t.options.CallTimeout
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), callTimeoutCtxKey{}, timeout)))
+	})
+}
+
+// callTimeout returns the timeout of the gRPC calls made with ctx: the one requested with
+// X-Request-Timeout, or CallTimeout
+func (t *Router) callTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(callTimeoutCtxKey{}).(time.Duration); ok {
+		return timeout
+	}
+	return t.options.CallTimeout
+}
@@ -0,0 +1,50 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// slowClient is a fakeClient taking delay to get a todo, unless the context of the call is done first
+type slowClient struct {
+	*fakeClient
+	delay time.Duration
+}
+
+func (c *slowClient) GetTodo(ctx context.Context, req *todomgrpb.TodoIdReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	select {
+	case <-time.After(c.delay):
+		return c.fakeClient.GetTodo(ctx, req, opts...)
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	client := &slowClient{fakeClient: newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: Username}), delay: time.Second}
+	router := newTestRouter(client, &RouterOptions{CallTimeout: 20 * time.Millisecond})
+	defer router.Close()
+	handler := router.GetRouter()
+
+	start := time.Now()
+	res := serve(handler, http.MethodGet, "/1", "")
+	expectStatus(t, res, http.StatusGatewayTimeout)
+	if elapsed := time.Since(start); elapsed > client.delay/2 {
+		t.Errorf("expected the call to be cancelled after the timeout, took %v", elapsed)
+	}
+	body := &ErrorRes{}
+	decodeJSONRes(t, res, body)
+	if body.Code != CodeBackendTimeout {
+		t.Errorf("expected code %s, got %s", CodeBackendTimeout, body.Code)
+	}
+
+	client.delay = time.Millisecond
+	expectStatus(t, serve(handler, http.MethodGet, "/1", ""), http.StatusOK)
+}
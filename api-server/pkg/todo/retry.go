@@ -0,0 +1,62 @@
+package todo
+
+import (
+	"context"
+
+	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// idempotentMethods lists the todo-manager methods that are safe to retry
+var idempotentMethods = map[string]bool{
+	"/todo_mgr.TodoManager/ListTodos":  true,
+	"/todo_mgr.TodoManager/CountTodos": true,
+	"/todo_mgr.TodoManager/GetTodo":    true,
+}
+
+// retryableCodes lists the gRPC codes of transient failures that are retried
+var retryableCodes = []codes.Code{codes.Unavailable, codes.ResourceExhausted}
+
+// retryAllowed checks if a call to method can be safely retried; creating todos is retried
+// only for requests with an idempotency key
+func retryAllowed(ctx context.Context, method string) bool {
+	if method == "/todo_mgr.TodoManager/CreateTodo" {
+		return hasIdempotencyKey(ctx)
+	}
+	return idempotentMethods[method]
+}
+
+// retryCallOptions returns the grpc_retry options of the retry policy configured in options
+func retryCallOptions(options *RouterOptions) []grpc_retry.CallOption {
+	return []grpc_retry.CallOption{
+		grpc_retry.WithMax(options.RetryMaxAttempts),
+		grpc_retry.WithBackoff(grpc_retry.BackoffExponentialWithJitter(options.RetryBackoff, 0.1)),
+		grpc_retry.WithCodes(retryableCodes...),
+	}
+}
+
+// newUnaryRetryInterceptor returns an interceptor retrying unary calls that are allowed to be retried
+func newUnaryRetryInterceptor(options *RouterOptions) grpc.UnaryClientInterceptor {
+	retry := grpc_retry.UnaryClientInterceptor(retryCallOptions(options)...)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !retryAllowed(ctx, method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return retry(ctx, method, req, reply, cc, invoker, opts...)
+	}
+}
+
+// newStreamRetryInterceptor returns an interceptor retrying server streaming calls that are allowed
+// to be retried; calls are only retried until the first message is received
+func newStreamRetryInterceptor(options *RouterOptions) grpc.StreamClientInterceptor {
+	retry := grpc_retry.StreamClientInterceptor(retryCallOptions(options)...)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if desc.ClientStreams || !retryAllowed(ctx, method) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		return retry(ctx, desc, cc, method, streamer, opts...)
+	}
+}
@@ -0,0 +1,87 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failingInvoker returns an invoker failing with the errors of errs in turn, then succeeding; attempts
+// counts its calls
+func failingInvoker(attempts *int, errs ...error) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		*attempts++
+		if *attempts <= len(errs) {
+			return errs[*attempts-1]
+		}
+		return nil
+	}
+}
+
+func TestUnaryRetryInterceptor(t *testing.T) {
+	options := &RouterOptions{RetryBackoff: time.Millisecond}
+	options.fillDefaults()
+	retry := newUnaryRetryInterceptor(options)
+	unavailable := status.Error(codes.Unavailable, "todo-manager is down")
+
+	for _, tc := range []struct {
+		name         string
+		method       string
+		errs         []error
+		wantAttempts int
+		wantCode     codes.Code
+	}{
+		{"retried until success", "/todo_mgr.TodoManager/GetTodo", []error{unavailable, unavailable}, 3, codes.OK},
+		{"resource exhausted", "/todo_mgr.TodoManager/CountTodos", []error{status.Error(codes.ResourceExhausted, "busy")}, 2, codes.OK},
+		{"up to the max attempts", "/todo_mgr.TodoManager/GetTodo", []error{unavailable, unavailable, unavailable, unavailable}, 3, codes.Unavailable},
+		{"not retryable code", "/todo_mgr.TodoManager/GetTodo", []error{status.Error(codes.NotFound, "no todo")}, 1, codes.NotFound},
+		{"internal error", "/todo_mgr.TodoManager/GetTodo", []error{status.Error(codes.Internal, "oops")}, 1, codes.Internal},
+		{"update", "/todo_mgr.TodoManager/UpdateTodo", []error{unavailable}, 1, codes.Unavailable},
+		{"create", "/todo_mgr.TodoManager/CreateTodo", []error{unavailable}, 1, codes.Unavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			err := retry(context.Background(), tc.method, nil, nil, nil, failingInvoker(&attempts, tc.errs...))
+			if code := status.Code(err); code != tc.wantCode {
+				t.Errorf("expected code %s, got %v", tc.wantCode, err)
+			}
+			if attempts != tc.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.wantAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestStreamRetryInterceptor(t *testing.T) {
+	options := &RouterOptions{RetryBackoff: time.Millisecond}
+	options.fillDefaults()
+	retry := newStreamRetryInterceptor(options)
+	attempts := 0
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		attempts++
+		if attempts <= 2 {
+			return nil, status.Error(codes.Unavailable, "todo-manager is down")
+		}
+		return &fakeStream{ctx: ctx}, nil
+	}
+
+	if _, err := retry(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/todo_mgr.TodoManager/ListTodos", streamer); err != nil {
+		t.Fatalf("expected the stream to be opened after retries, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	// client streams can't be replayed
+	attempts = 0
+	if _, err := retry(context.Background(), &grpc.StreamDesc{ClientStreams: true}, nil, "/todo_mgr.TodoManager/BatchCreateTodos", streamer); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the error of the first attempt, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
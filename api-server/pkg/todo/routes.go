@@ -1,207 +1,764 @@
 package todo
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi"
+	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/go-chi/cors"
 	"github.com/go-chi/render"
 	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	log "github.com/sirupsen/logrus"
 	"go.opencensus.io/plugin/ocgrpc"
 
 	// "go.opencensus.io/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
 )
 
+// DefaultMaxTextLength is the default max number of characters of the text of todos
+const DefaultMaxTextLength = 1000
+
 // Username is a temporary value for all user name fields until we get proper authentication in place
 const Username = "anonymous"
 
-var ()
+// DefaultOwnerFromRequest is the default owner resolver; it returns the owner set in the
+// request context by an auth middleware and falls back to Username if there's none
+func DefaultOwnerFromRequest(r *http.Request) (string, error) {
+	if owner, ok := OwnerFromContext(r.Context()); ok {
+		return owner, nil
+	}
+	return Username, nil
+}
+
+// totalCountMetadataKey is the gRPC header metadata key todo-manager uses to report the
+// total number of todos matching a ListTodos request
+const totalCountMetadataKey = "x-total-count"
+
+// nextCursorMetadataKey is the gRPC header metadata key todo-manager uses to report the cursor
+// of the page after the listed todos
+const nextCursorMetadataKey = "x-next-cursor"
+
+// ErrRouterClosed is returned when Close is called on an already closed Router
+var ErrRouterClosed = errors.New("todo router is already closed")
 
 // Router is a registry of go-chi routes supported by Todo
 type Router struct {
+	// OwnerFromRequest returns the owner of the todos the request operates on;
+	// if it returns an error, the request is rejected as unauthenticated
+	OwnerFromRequest func(*http.Request) (string, error)
+	// MaxTextLength is the max number of characters of the text of todos; there's no limit when 0
+	MaxTextLength int
+	// DefaultPageSize is the number of todos listed when no limit is requested; all of them,
+	// up to MaxPageSize, when 0
+	DefaultPageSize int
+	// MaxPageSize is the max number of todos listed at once, larger limits are clamped; there's
+	// no limit when 0
+	MaxPageSize int
+	// DedupeWindow makes CreateTodo return an existing todo of the owner with the same text created
+	// within the window, instead of creating a duplicate; duplicates are not checked when 0
+	DedupeWindow time.Duration
+
+	options          *RouterOptions
+	conn             *grpc.ClientConn
+	closeLock        sync.Mutex
+	closed           bool
+	drainLock        sync.Mutex
+	draining         bool
+	shutdown         chan struct{}
+	inFlight         sync.WaitGroup
+	readOnly         int32
 	grpcClient       todomgrpb.TodoManagerClient
+	healthClient     healthpb.HealthClient
+	breaker          *circuitBreaker
+	idempotency      *idempotencyStore
+	todoCache        *todoCache
+	webhooks         *webhookDispatcher
+	rateLimit        func(http.Handler) http.Handler
 	getAllCounter    *prometheus.CounterVec
 	getOneCounter    *prometheus.CounterVec
 	deleteOneCounter *prometheus.CounterVec
 	updateOneCounter *prometheus.CounterVec
+	patchOneCounter  *prometheus.CounterVec
 	createOneCounter *prometheus.CounterVec
+	connStateGauge   prometheus.GaugeFunc
+	todoCacheCounter *prometheus.CounterVec
 }
 
-// NewRouter returns new go-chi router with initialized gRPC client
-func NewRouter(todoManagerAddr string) *Router {
+// NewRouter returns new go-chi router with initialized gRPC client, optionally configured with RouterOptions
+func NewRouter(todoManagerAddr string, options *RouterOptions) (*Router, error) {
+	// if we didn't get any options, initialize with default struct
+	if options == nil {
+		options = &RouterOptions{}
+	}
+	options.fillDefaults()
+
+	creds, err := transportCredentials(options)
+	if err != nil {
+		return nil, err
+	}
+	unaryInterceptors := []grpc.UnaryClientInterceptor{unaryRequestIDInterceptor}
+	streamInterceptors := []grpc.StreamClientInterceptor{streamRequestIDInterceptor}
+	breaker := newCircuitBreaker(options)
+	if breaker != nil {
+		unaryInterceptors = append(unaryInterceptors, breaker.unaryInterceptor)
+		streamInterceptors = append(streamInterceptors, breaker.streamInterceptor)
+	}
+	unaryInterceptors = append(unaryInterceptors, newUnaryRetryInterceptor(options))
+	streamInterceptors = append(streamInterceptors, newStreamRetryInterceptor(options))
 	// Dial the server, returns a client connection
-	conn, err := grpc.Dial(todoManagerAddr, grpc.WithInsecure(), grpc.WithStatsHandler(new(ocgrpc.ClientHandler)))
+	conn, err := grpc.Dial(todoManagerAddr,
+		creds,
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                options.KeepaliveTime,
+			Timeout:             options.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithStatsHandler(&ocgrpc.ClientHandler{
+			StartOptions: options.TraceStartOptions,
+		}),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(options.MaxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(options.MaxSendMsgSize),
+		),
+		grpc.WithChainUnaryInterceptor(unaryInterceptors...),
+		grpc.WithChainStreamInterceptor(streamInterceptors...),
+	)
 	if err != nil {
-		log.Fatalf("Unable to establish client connection to %s: %v", todoManagerAddr, err)
+		return nil, fmt.Errorf("unable to establish client connection to %s: %v", todoManagerAddr, err)
 	}
 	// Instantiate the TodoManagerClient with our client connection to the server
-	client := todomgrpb.NewTodoManagerClient(conn)
+	t := NewRouterWithClient(todomgrpb.NewTodoManagerClient(conn), options)
+	t.conn = conn
+	t.breaker = breaker
+	t.healthClient = healthpb.NewHealthClient(conn)
+	t.connStateGauge = promauto.With(options.Registerer).NewGaugeFunc(prometheus.GaugeOpts{
+		Subsystem: "todo",
+		Name:      "grpc_connection_state",
+		Help:      "The state of the gRPC connection to todo-manager: 0 idle, 1 connecting, 2 ready, 3 transient failure, 4 shutdown",
+	}, func() float64 {
+		return float64(conn.GetState())
+	})
+	go monitorConnState(conn, options.Logger)
+	return t, nil
+}
+
+// NewRouterWithClient returns new go-chi router using a pre-built todo-manager client, like a fake one
+// in tests, optionally configured with RouterOptions. The router doesn't own any connection, so its
+// readiness checks always succeed; the options configuring the connection are ignored.
+func NewRouterWithClient(client todomgrpb.TodoManagerClient, options *RouterOptions) *Router {
+	// if we didn't get any options, initialize with default struct
+	if options == nil {
+		options = &RouterOptions{}
+	}
+	options.fillDefaults()
+
+	factory := promauto.With(options.Registerer)
+	var cache *todoCache
+	var cacheCounter *prometheus.CounterVec
+	if options.TodoCacheTTL > 0 {
+		cache = newTodoCache(options.TodoCacheTTL)
+		client = &invalidatingClient{TodoManagerClient: client, cache: cache}
+		cacheCounter = factory.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: "todo",
+			Name:      "cache_lookups_total",
+			Help:      "The total number of lookups of single todos in the GetTodo cache, by result: hit or miss",
+		}, []string{"result"})
+	}
+	// the todo routes and the GraphQL endpoint share the rate limits of owners
+	var rateLimit func(http.Handler) http.Handler
+	if options.RateLimit > 0 {
+		rateLimit = NewRateLimitMiddleware(options.RateLimit, options.RateLimitBurst)
+	}
 	return &Router{
-		grpcClient: client,
-		getAllCounter: promauto.NewCounterVec(prometheus.CounterOpts{
+		OwnerFromRequest: DefaultOwnerFromRequest,
+		MaxTextLength:    DefaultMaxTextLength,
+		DefaultPageSize:  DefaultPageSize,
+		MaxPageSize:      DefaultMaxPageSize,
+		options:          options,
+		grpcClient:       client,
+		idempotency:      newIdempotencyStore(options.IdempotencyKeyTTL),
+		todoCache:        cache,
+		todoCacheCounter: cacheCounter,
+		webhooks:         newWebhookDispatcher(options),
+		rateLimit:        rateLimit,
+		shutdown:         make(chan struct{}),
+		getAllCounter: factory.NewCounterVec(prometheus.CounterOpts{
 			Subsystem: "todo",
 			Name:      "get_all_count_total",
 			Help:      "The total number of successful GETs for all the todos of an user",
 		}, []string{"user"}),
-		getOneCounter: promauto.NewCounterVec(prometheus.CounterOpts{
+		getOneCounter: factory.NewCounterVec(prometheus.CounterOpts{
 			Subsystem: "todo",
 			Name:      "get_one_count_total",
 			Help:      "The total number of successful GETs for a single todo of an user",
 		}, []string{"user"}),
-		deleteOneCounter: promauto.NewCounterVec(prometheus.CounterOpts{
+		deleteOneCounter: factory.NewCounterVec(prometheus.CounterOpts{
 			Subsystem: "todo",
 			Name:      "delete_one_count_total",
 			Help:      "The total number of successful DELETEs for a single todo of an user",
 		}, []string{"user"}),
-		createOneCounter: promauto.NewCounterVec(prometheus.CounterOpts{
+		createOneCounter: factory.NewCounterVec(prometheus.CounterOpts{
 			Subsystem: "todo",
 			Name:      "create_one_count_total",
 			Help:      "The total number of successful POSTs for a single todo of an user",
 		}, []string{"user"}),
-		updateOneCounter: promauto.NewCounterVec(prometheus.CounterOpts{
+		updateOneCounter: factory.NewCounterVec(prometheus.CounterOpts{
 			Subsystem: "todo",
 			Name:      "update_one_count_total",
 			Help:      "The total number of successful PUTs for a single todo of an user",
 		}, []string{"user"}),
+		patchOneCounter: factory.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: "todo",
+			Name:      "patch_one_count_total",
+			Help:      "The total number of successful PATCHes for a single todo of an user",
+		}, []string{"user"}),
 	}
 }
 
+// Close releases the gRPC connection to the todo-manager service, if the router owns one
+func (t *Router) Close() error {
+	t.closeLock.Lock()
+	defer t.closeLock.Unlock()
+	if t.closed {
+		return ErrRouterClosed
+	}
+	t.closed = true
+	t.webhooks.close()
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.Close()
+}
+
+// callContext returns the context for a gRPC call made while serving r, limited by CallTimeout
+// or the shorter timeout requested with X-Request-Timeout
+func (t *Router) callContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), t.callTimeout(r.Context()))
+}
+
+// checkTextLength checks if the text of a todo is at most MaxTextLength characters long
+func (t *Router) checkTextLength(text string) error {
+	if t.MaxTextLength > 0 && utf8.RuneCountInString(text) > t.MaxTextLength {
+		return fmt.Errorf("Text can't be longer than %d characters", t.MaxTextLength)
+	}
+	return nil
+}
+
+// owner resolves the owner of the request and renders an auth error if that's not possible
+func (t *Router) owner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	owner, err := t.OwnerFromRequest(r)
+	if err != nil {
+		render.Render(w, r, middleware.ErrAuth(err))
+		return "", false
+	}
+	middleware.LogEntrySetField(r, "owner", owner)
+	return owner, true
+}
+
+// logPayloads logs the request and response bodies of requests, as configured by the options
+func (t *Router) logPayloads(next http.Handler) http.Handler {
+	return NewPayloadLoggingMiddleware(t.options.Logger, t.options.PayloadLogMaxBytes, t.options.PayloadLogRedactedFields)(next)
+}
+
+// middlewares returns the middlewares shared by the todo routes and the GraphQL endpoint
+func (t *Router) middlewares() chi.Middlewares {
+	middlewares := chi.Chain(t.trackInFlight, RequestIDMiddleware)
+	if t.options.LogPayloads {
+		middlewares = append(middlewares, t.logPayloads)
+	}
+	middlewares = append(middlewares, NegotiateContentType, t.requestTimeout, NewRecoveryMiddleware(t.options.Logger))
+	middlewares = append(middlewares, cors.Handler(cors.Options{
+		AllowedOrigins: t.options.CORSAllowedOrigins,
+		AllowedMethods: t.options.CORSAllowedMethods,
+		AllowedHeaders: t.options.CORSAllowedHeaders,
+		ExposedHeaders: []string{"X-Total-Count", "Link", NextCursorHeader, limitClampedHeader, RequestIDHeader, "ETag", "Idempotent-Replayed", deduplicatedHeader, DryRunHeader, "Location", "Content-Disposition", "Accept-Patch", RateLimitLimitHeader, RateLimitRemainingHeader, RateLimitResetHeader},
+	}))
+	if t.rateLimit != nil {
+		middlewares = append(middlewares, t.rateLimit)
+	}
+	return middlewares
+}
+
 // GetRouter returns configuredsub-router for Todo resources
 func (t *Router) GetRouter() chi.Router {
 	r := chi.NewRouter()
+	r.Use(t.middlewares()...)
+	r.Use(t.rejectWritesWhenReadOnly)
+
+	// routes decoding a JSON body are limited to MaxBodyBytes, imports have their own limit
+	limitBody := NewBodyLimitMiddleware(t.options.MaxBodyBytes)
+
+	// streamed responses and uploads can legitimately take long, so they're not limited by HandlerTimeout
+	r.Get("/", t.ListTodos)                       // GET /
+	r.Get("/search", t.SearchTodos)               // GET /search
+	r.Get("/due-soon", t.DueSoonTodos)            // GET /due-soon?within=24h
+	r.Get("/stream", t.StreamTodos)               // GET /stream
+	r.Get("/ws", t.SyncTodos)                     // GET /ws
+	r.Get("/export", t.ExportTodos)               // GET /export
+	r.Post("/import", t.ImportTodos)              // POST /import
+	r.Post("/import/stream", t.StreamImportTodos) // POST /import/stream
 
-	r.Get("/", t.ListTodos)
-	r.Post("/", t.CreateTodo) // POST /
+	r.Group(func(r chi.Router) {
+		r.Use(NewTimeoutMiddleware(t.options.HandlerTimeout))
 
-	r.Route("/{todoID}", func(r chi.Router) {
-		r.Get("/", t.GetTodo)       // GET /123
-		r.Put("/", t.UpdateTodo)    // PUT /123
-		r.Delete("/", t.DeleteTodo) // DELETE /123
+		r.With(limitBody).Post("/", t.CreateTodo)  // POST /
+		r.With(limitBody).Put("/", t.ReplaceTodos) // PUT /?replace=true
+
+		r.Get("/count", t.CountTodos)                          // GET /count
+		r.Get("/stats", t.TodoStats)                           // GET /stats
+		r.Get("/batch", t.BatchGetTodos)                       // GET /batch?ids=1,2
+		r.With(limitBody).Post("/batch", t.BatchCreateTodos)   // POST /batch
+		r.With(limitBody).Delete("/batch", t.BatchDeleteTodos) // DELETE /batch
+		r.Post("/batch/complete", t.BatchCompleteTodos)        // POST /batch/complete
+		r.Post("/batch/uncomplete", t.BatchUncompleteTodos)    // POST /batch/uncomplete
+
+		r.Route("/{todoID}", func(r chi.Router) {
+			r.Get("/", t.GetTodo)                     // GET /123
+			r.Head("/", t.HeadTodo)                   // HEAD /123
+			r.With(limitBody).Put("/", t.UpdateTodo)  // PUT /123
+			r.With(limitBody).Patch("/", t.PatchTodo) // PATCH /123
+			r.Delete("/", t.DeleteTodo)               // DELETE /123
+			r.Post("/restore", t.RestoreTodo)         // POST /123/restore
+			r.Post("/archive", t.ArchiveTodo)         // POST /123/archive
+			r.Post("/unarchive", t.UnarchiveTodo)     // POST /123/unarchive
+
+			r.With(limitBody).Post("/subtasks", t.AddSubtask)             // POST /123/subtasks
+			r.With(limitBody).Patch("/subtasks/{index}", t.UpdateSubtask) // PATCH /123/subtasks/0
+			r.Delete("/subtasks/{index}", t.RemoveSubtask)                // DELETE /123/subtasks/0
+
+			r.Get("/comments", t.ListComments)                // GET /123/comments
+			r.With(limitBody).Post("/comments", t.AddComment) // POST /123/comments
+
+			r.With(limitBody).Post("/share", t.ShareTodo)         // POST /123/share
+			r.With(limitBody).Post("/move", t.MoveTodo)           // POST /123/move
+			r.With(limitBody).Post("/duplicate", t.DuplicateTodo) // POST /123/duplicate
+
+			handleMethods(r) // OPTIONS and 405 of all the routes above
+		})
 	})
 
+	handleMethods(r) // OPTIONS and 405 of all the routes above
+	r.NotFound(notFound)
+
 	return r
 }
 
 // ListTodos lists all todos owned by a user
 func (t *Router) ListTodos(w http.ResponseWriter, r *http.Request) {
-	stream, err := t.grpcClient.ListTodos(r.Context(), &todomgrpb.ListTodosReq{
-		Owner: Username,
-	})
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	req, err := t.newListTodosReq(w, r, owner)
 	if err != nil {
-		render.Render(w, r, middleware.ErrRender(err))
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
 		return
 	}
-	var todoList []render.Renderer
-	for {
-		res, err := stream.Recv()
-		// If end of stream, break the loop
-		if err == io.EOF {
-			break
+	t.listTodos(w, r, req)
+}
+
+// SearchTodos lists all todos owned by a user with text containing the 'q' query param, ignoring case
+func (t *Router) SearchTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		render.Render(w, r, middleware.ErrInvalidRequest(errors.New("Search query 'q' can't be empty")))
+		return
+	}
+	req, err := t.newListTodosReq(w, r, owner)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	req.TextQuery = q
+	t.listTodos(w, r, req)
+}
+
+// listTodos renders all todos returned by todo-manager for the request
+func (t *Router) listTodos(w http.ResponseWriter, r *http.Request, req *todomgrpb.ListTodosReq) {
+	fields, err := parseFields(r)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	stream, err := t.grpcClient.ListTodos(ctx, req)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	header, err := stream.Header()
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	var nextCursor string
+	if cursor := header.Get(nextCursorMetadataKey); len(cursor) > 0 {
+		nextCursor = cursor[0]
+		w.Header().Set(NextCursorHeader, nextCursor)
+	}
+	if total := header.Get(totalCountMetadataKey); len(total) > 0 {
+		w.Header().Set("X-Total-Count", total[0])
+		if count, err := strconv.ParseUint(total[0], 10, 64); err == nil && req.Cursor == "" {
+			if links := paginationLinks(r, req.Limit, req.Offset, count); links != "" {
+				w.Header().Set("Link", links)
+			}
+		}
+	}
+	if req.Cursor != "" {
+		// offsets are meaningless when paging with cursors
+		w.Header().Set("Link", cursorLinks(r, nextCursor))
+	}
+	// the first todo is received before starting the response, so that errors of the query
+	// are rendered with their status
+	res, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	list := newListWriter(w, r, fields)
+	// the status is already sent, so errors can only end the list early
+	for ; err != io.EOF; res, err = stream.Recv() {
+		if err == nil {
+			// stop as soon as the client is gone, even if todo-manager keeps sending
+			err = r.Context().Err()
+		}
+		if err == nil {
+			todo, _ := FromGRPCTodo(res)
+			err = list.write(todo)
 		}
-		// if err, return an error
 		if err != nil {
-			render.Render(w, r, middleware.ErrRender(err))
+			t.logListError(r, err)
 			return
 		}
-		todo, _ := FromGRPCTodo(res)
-		todoList = append(todoList, todo)
 	}
-	if err := render.RenderList(w, r, todoList); err != nil {
+	if err := list.close(); err != nil {
+		t.logListError(r, err)
+		return
+	}
+	t.getAllCounter.WithLabelValues(req.Owner).Inc()
+}
+
+// logListError logs an error ending a list of todos early; it's not logged at error level
+// when the client disconnected
+func (t *Router) logListError(r *http.Request, err error) {
+	entry := t.options.Logger.WithField("req_id", chimiddleware.GetReqID(r.Context())).WithError(err)
+	if r.Context().Err() != nil {
+		entry.Info("client disconnected while listing todos")
+	} else {
+		entry.Error("todo list failed")
+	}
+}
+
+// CountTodos returns the number of todos owned by a user, matching the same filters as ListTodos
+func (t *Router) CountTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	req := &todomgrpb.ListTodosReq{Owner: owner}
+	if err := parseFilters(r, req); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	res, err := t.grpcClient.CountTodos(ctx, req)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	if err := render.Render(w, r, FromGRPCCountRes(res)); err != nil {
 		render.Render(w, r, middleware.ErrRender(err))
 		return
 	}
-	t.getAllCounter.WithLabelValues(Username).Inc()
 }
 
-// CreateTodo creates a new todo for a given user
+// TodoStats summarizes the todos owned by a user, matching the same filters as ListTodos: the
+// total, the completed and the overdue ones and the counts by priority
+func (t *Router) TodoStats(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	req := &todomgrpb.ListTodosReq{Owner: owner}
+	if err := parseFilters(r, req); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	res, err := t.grpcClient.TodoStats(ctx, req)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	if err := render.Render(w, r, FromGRPCStatsRes(res)); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+}
+
+// CreateTodo creates a new todo for a given user; with ?dry_run=true it responds with the todo
+// that would be created without creating it. Clients generating IDs offline can set the ID of the
+// todo, which fails with 409 Conflict if it's already taken; otherwise it's generated.
 func (t *Router) CreateTodo(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
 	// bind JSON from request to go object
 	data := &Todo{}
 	if err := render.Bind(r, data); err != nil {
 		render.Render(w, r, middleware.ErrInvalidRequest(err))
 		return
 	}
-	// validate - todo text can't be empty
-	if data.Text == "" {
-		render.Render(w, r, middleware.ErrInvalidRequest(errors.New("Text can't be empty")))
+	if err := t.validateNewTodo(data); err != nil {
+		render.Render(w, r, errValidation(err))
 		return
 	}
-	// we don't have any real auth, let's pretend we always serve the user with ID 0
-	data.ID = "0"
-	// run request
-	newGrpcTodo, err := t.grpcClient.CreateTodo(r.Context(), data.ToGRPCTodo(Username))
+	dryRun, err := isDryRun(r)
 	if err != nil {
-		render.Render(w, r, middleware.ErrRender(err))
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	onlyIfNew, err := createOnly(r)
+	if err == nil && onlyIfNew && data.ID == "" {
+		err = errCreateOnlyWithoutID
+	}
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
 		return
 	}
+	if data.Priority == "" {
+		data.Priority = PriorityMedium
+	}
+	if data.ID == "" {
+		// todo-manager generates the ID
+		data.ID = "0"
+	}
+	req := data.ToGRPCTodo(owner)
+	if dryRun {
+		renderDryRun(w, r, previewCreate(req))
+		return
+	}
+	// run request, only once for requests with the same idempotency key
+	deduplicated := false
+	create := func(ctx context.Context) (*todomgrpb.Todo, error) {
+		// todos with an ID set by the client are never replaced by another one
+		if t.DedupeWindow > 0 && req.Id == 0 {
+			duplicate, err := t.findDuplicate(ctx, owner, req.Text)
+			if err != nil || duplicate != nil {
+				deduplicated = duplicate != nil
+				return duplicate, err
+			}
+		}
+		return t.grpcClient.CreateTodo(ctx, req)
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	var newGrpcTodo *todomgrpb.Todo
+	replayed := false
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		newGrpcTodo, replayed, err = t.idempotency.do(ctx, owner, key, req, create)
+	} else {
+		newGrpcTodo, err = create(ctx)
+	}
+	switch {
+	case err == errIdempotencyKeyTooLong:
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	case err == errIdempotencyKeyReused:
+		render.Render(w, r, errUnprocessableEntity(err))
+		return
+	case onlyIfNew && status.Code(err) == codes.AlreadyExists:
+		render.Render(w, r, errPreconditionFailed(errTodoExists))
+		return
+	case err != nil:
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
 	// convert to JSON object and send response
 	todo, _ := FromGRPCTodo(newGrpcTodo)
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+todo.ID)
+	w.Header().Set("ETag", versionETag(todo.version))
+	if deduplicated {
+		w.Header().Set(deduplicatedHeader, "true")
+		render.Status(r, http.StatusOK)
+	} else {
+		render.Status(r, http.StatusCreated)
+	}
 	if err := render.Render(w, r, todo); err != nil {
 		render.Render(w, r, middleware.ErrRender(err))
 		return
 	}
-	t.createOneCounter.WithLabelValues(Username).Inc()
+	if !replayed && !deduplicated {
+		t.createOneCounter.WithLabelValues(owner).Inc()
+		t.webhooks.dispatch(WebhookEventCreated, owner, todo)
+	}
 }
 
-// GetTodo gets a todo with specified user and todo ID
+// GetTodo gets a todo with specified user and todo ID, with only the fields selected with the
+// fields query param, if it's present
 func (t *Router) GetTodo(w http.ResponseWriter, r *http.Request) {
-	todoID := chi.URLParam(r, "todoID")
-	id, err := strconv.ParseUint(todoID, 10, 64)
+	fields, err := parseFields(r)
 	if err != nil {
 		render.Render(w, r, middleware.ErrInvalidRequest(err))
 		return
 	}
-	grpcTodo, err := t.grpcClient.GetTodo(r.Context(), &todomgrpb.TodoIdReq{
-		Id:    uint64(id),
-		Owner: Username,
-	})
+	todo, owner, ok := t.lookupTodo(w, r)
+	if !ok {
+		return
+	}
+	res, err := fields.project(r, todo)
 	if err != nil {
 		render.Render(w, r, middleware.ErrRender(err))
 		return
 	}
-	todo, _ := FromGRPCTodo(grpcTodo)
-	if err := render.Render(w, r, todo); err != nil {
+	if err := render.Render(w, r, res); err != nil {
 		render.Render(w, r, middleware.ErrRender(err))
 		return
 	}
-	t.getOneCounter.WithLabelValues(Username).Inc()
+	t.getOneCounter.WithLabelValues(owner).Inc()
+}
+
+// lookupTodo gets the todo with specified user and todo ID for GetTodo and HeadTodo and sets
+// its ETag and Last-Modified headers; false is returned if the response is already rendered, as
+// an error or as 304 Not Modified when the conditional headers of the request match
+func (t *Router) lookupTodo(w http.ResponseWriter, r *http.Request) (*Todo, string, bool) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return nil, "", false
+	}
+	todoID := chi.URLParam(r, "todoID")
+	id, err := parseTodoID(todoID)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return nil, "", false
+	}
+	grpcTodo, err := t.getTodo(r, owner, id)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return nil, "", false
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	etag := versionETag(todo.version)
+	w.Header().Set("ETag", etag)
+	modified := setLastModified(w, grpcTodo.GetUpdatedAt())
+	if notModified(r, etag, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		t.getOneCounter.WithLabelValues(owner).Inc()
+		return nil, "", false
+	}
+	return todo, owner, true
 }
 
-// DeleteTodo deletes a todo with specified user and todo ID
+// DeleteTodo moves a todo with specified user and todo ID to the trash or deletes it permanently
+// when the hard query param is true; it responds 204 No Content unless the client accepts
+// application/json explicitly
 func (t *Router) DeleteTodo(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
 	todoID := chi.URLParam(r, "todoID")
-	id, err := strconv.ParseUint(todoID, 10, 64)
+	id, err := parseTodoID(todoID)
 	if err != nil {
 		render.Render(w, r, middleware.ErrInvalidRequest(err))
 		return
 	}
-	deleteRes, err := t.grpcClient.DeleteTodo(r.Context(), &todomgrpb.TodoIdReq{
-		Id:    uint64(id),
-		Owner: Username,
+	hard, err := parseBoolFilter(r, "hard")
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	deleteRes, err := t.grpcClient.DeleteTodo(ctx, &todomgrpb.DeleteTodoReq{
+		Id:    id,
+		Owner: owner,
+		Hard:  hard.GetValue(),
 	})
 	if err != nil {
-		render.Render(w, r, middleware.ErrRender(err))
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	t.deleteOneCounter.WithLabelValues(owner).Inc()
+	t.webhooks.dispatch(WebhookEventDeleted, owner, &Todo{ID: todoID})
+	// clients explicitly asking for JSON get the delete result, others an empty response
+	if !strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 	if err := render.Render(w, r, FromGRPCDeleteRes(deleteRes)); err != nil {
 		render.Render(w, r, middleware.ErrRender(err))
 		return
 	}
-	t.deleteOneCounter.WithLabelValues(Username).Inc()
 }
 
-// UpdateTodo updates a todo with specified user and todo ID
+// RestoreTodo moves a todo with specified user and todo ID out of the trash
+func (t *Router) RestoreTodo(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	todoID := chi.URLParam(r, "todoID")
+	id, err := parseTodoID(todoID)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	grpcTodo, err := t.grpcClient.RestoreTodo(ctx, &todomgrpb.TodoIdReq{
+		Id:    id,
+		Owner: owner,
+	})
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	w.Header().Set("ETag", versionETag(todo.version))
+	if err := render.Render(w, r, todo); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+}
+
+// UpdateTodo updates a todo with specified user and todo ID; with ?dry_run=true it responds with
+// the todo that would be stored without updating it
 func (t *Router) UpdateTodo(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
 	todoID := chi.URLParam(r, "todoID")
-	_, err := strconv.ParseUint(todoID, 10, 64)
+	id, err := parseTodoID(todoID)
 	if err != nil {
 		render.Render(w, r, middleware.ErrInvalidRequest(err))
 		return
@@ -211,20 +768,104 @@ func (t *Router) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 		render.Render(w, r, middleware.ErrInvalidRequest(err))
 		return
 	}
-	data.ID = todoID
 	if data.ID != "" && data.ID != todoID {
 		render.Render(w, r, middleware.ErrInvalidRequest(errors.New("ID from JSON is not empty and doesn't match URL ID")))
 		return
 	}
-	grpcTodo, err := t.grpcClient.UpdateTodo(r.Context(), data.ToGRPCTodo(Username))
+	data.ID = todoID
+	if err := t.validateTodo(data); err != nil {
+		render.Render(w, r, errValidation(err))
+		return
+	}
+	dryRun, err := isDryRun(r)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	version, err := expectedVersion(r)
 	if err != nil {
+		render.Render(w, r, errPreconditionFailed(err))
+		return
+	}
+	req := data.ToGRPCTodo(owner)
+	req.Id = id
+	req.Version = version
+	if dryRun {
+		t.dryRunUpdate(w, r, req)
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	var trailer metadata.MD
+	grpcTodo, err := t.grpcClient.UpdateTodo(ctx, req, grpc.Trailer(&trailer))
+	if err != nil {
+		setVersionETag(w, trailer)
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	w.Header().Set("ETag", versionETag(todo.version))
+	if err := render.Render(w, r, todo); err != nil {
 		render.Render(w, r, middleware.ErrRender(err))
 		return
 	}
+	t.updateOneCounter.WithLabelValues(owner).Inc()
+	t.webhooks.dispatch(WebhookEventUpdated, grpcTodo.GetOwner(), todo)
+}
+
+// PatchTodo updates only the fields present in the request of a todo with specified user and todo ID;
+// application/merge-patch+json bodies are applied with JSON Merge Patch semantics
+func (t *Router) PatchTodo(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	todoID := chi.URLParam(r, "todoID")
+	id, err := parseTodoID(todoID)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	w.Header().Set("Accept-Patch", mergePatchContentType)
+	if isMergePatch(r) {
+		t.mergePatchTodo(w, r, owner, id)
+		return
+	}
+	data := &TodoPatch{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if data.ID != nil && *data.ID != "" && *data.ID != todoID {
+		render.Render(w, r, middleware.ErrInvalidRequest(errors.New("ID from JSON is not empty and doesn't match URL ID")))
+		return
+	}
+	if err := t.validateTodoPatch(data); err != nil {
+		render.Render(w, r, errValidation(err))
+		return
+	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		render.Render(w, r, errPreconditionFailed(err))
+		return
+	}
+	patch := data.ToGRPCTodoPatch(id, owner)
+	patch.ExpectedVersion = version
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	var trailer metadata.MD
+	grpcTodo, err := t.grpcClient.PatchTodo(ctx, patch, grpc.Trailer(&trailer))
+	if err != nil {
+		setVersionETag(w, trailer)
+		t.renderGRPCError(w, r, err)
+		return
+	}
 	todo, _ := FromGRPCTodo(grpcTodo)
+	w.Header().Set("ETag", versionETag(todo.version))
 	if err := render.Render(w, r, todo); err != nil {
 		render.Render(w, r, middleware.ErrRender(err))
 		return
 	}
-	t.updateOneCounter.WithLabelValues(Username).Inc()
+	t.patchOneCounter.WithLabelValues(owner).Inc()
+	t.webhooks.dispatch(WebhookEventUpdated, grpcTodo.GetOwner(), todo)
 }
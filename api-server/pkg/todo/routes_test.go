@@ -0,0 +1,293 @@
+package todo
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	router := newTestRouter(newFakeClient(), nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		res := serve(handler, http.MethodOptions, "/1", "",
+			"Origin", "https://app.example.com",
+			"Access-Control-Request-Method", method,
+			"Access-Control-Request-Headers", "Content-Type, If-Match")
+		expectStatus(t, res, http.StatusOK)
+		if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "*" {
+			t.Errorf("expected any origin to be allowed, got %q", origin)
+		}
+		if methods := res.Header().Get("Access-Control-Allow-Methods"); methods != method {
+			t.Errorf("expected %s to be allowed, got %q", method, methods)
+		}
+		if headers := res.Header().Get("Access-Control-Allow-Headers"); headers != "Content-Type, If-Match" {
+			t.Errorf("expected the requested headers to be allowed, got %q", headers)
+		}
+	}
+}
+
+func TestCORSAllowedOrigins(t *testing.T) {
+	router := newTestRouter(newFakeClient(), &RouterOptions{CORSAllowedOrigins: []string{"https://app.example.com"}})
+	defer router.Close()
+	handler := router.GetRouter()
+
+	res := serve(handler, http.MethodOptions, "/", "", "Origin", "https://app.example.com", "Access-Control-Request-Method", http.MethodPost)
+	if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "https://app.example.com" {
+		t.Errorf("expected the allowed origin, got %q", origin)
+	}
+	res = serve(handler, http.MethodOptions, "/", "", "Origin", "https://evil.example.com", "Access-Control-Request-Method", http.MethodPost)
+	if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+		t.Errorf("expected other origins not to be allowed, got %q", origin)
+	}
+
+	res = serve(handler, http.MethodGet, "/", "", "Origin", "https://app.example.com")
+	expectStatus(t, res, http.StatusOK)
+	if exposed := res.Header().Get("Access-Control-Expose-Headers"); !strings.Contains(exposed, "X-Total-Count") {
+		t.Errorf("expected X-Total-Count to be exposed, got %q", exposed)
+	}
+}
+
+// multipartFile returns a multipart/form-data body with content as the import file and its Content-Type
+func multipartFile(filename, content string) (string, string) {
+	boundary := "todo-boundary"
+	body := "--" + boundary + "\r\n" +
+		`Content-Disposition: form-data; name="` + importFileField + `"; filename="` + filename + `"` + "\r\n" +
+		"Content-Type: application/json\r\n\r\n" +
+		content + "\r\n--" + boundary + "--\r\n"
+	return body, "multipart/form-data; boundary=" + boundary
+}
+
+func TestRoutes(t *testing.T) {
+	importBody, importContentType := multipartFile("todos.json", `[{"text": "imported"}]`)
+	deletedAt := ptypes.TimestampNow()
+
+	for _, tc := range []struct {
+		method, target, body string
+		headers              []string
+		wantStatus           int
+		// partial is set for routes reporting the todos that failed in a successful response
+		partial bool
+	}{
+		{http.MethodGet, "/", "", nil, http.StatusOK, false},
+		{http.MethodGet, "/search?q=todo", "", nil, http.StatusOK, false},
+		{http.MethodGet, "/due-soon?within=24h", "", nil, http.StatusOK, false},
+		{http.MethodGet, "/export", "", nil, http.StatusOK, false},
+		{http.MethodPost, "/import", importBody, []string{"Content-Type", importContentType}, http.StatusOK, false},
+		{http.MethodPost, "/import/stream", `{"text": "imported"}` + "\n", []string{"Content-Type", ndjsonContentType}, http.StatusOK, false},
+		{http.MethodPost, "/", `{"text": "new"}`, nil, http.StatusCreated, false},
+		{http.MethodPut, "/?replace=true", `[{"text": "replaced"}]`, nil, http.StatusOK, false},
+		{http.MethodGet, "/count", "", nil, http.StatusOK, false},
+		{http.MethodGet, "/stats", "", nil, http.StatusOK, false},
+		{http.MethodGet, "/batch?ids=1", "", nil, http.StatusOK, false},
+		{http.MethodPost, "/batch", `[{"text": "new"}]`, nil, http.StatusOK, false},
+		{http.MethodDelete, "/batch", `{"ids": ["1"]}`, nil, http.StatusOK, true},
+		{http.MethodPost, "/batch/complete", "", nil, http.StatusOK, false},
+		{http.MethodPost, "/batch/uncomplete", "", nil, http.StatusOK, false},
+		{http.MethodGet, "/1", "", nil, http.StatusOK, false},
+		{http.MethodHead, "/1", "", nil, http.StatusOK, false},
+		{http.MethodPut, "/1", `{"text": "updated"}`, nil, http.StatusOK, false},
+		{http.MethodPatch, "/1", `{"done": true}`, nil, http.StatusOK, false},
+		{http.MethodDelete, "/1", "", nil, http.StatusNoContent, false},
+		{http.MethodPost, "/2/restore", "", nil, http.StatusOK, false},
+		{http.MethodPost, "/1/archive", "", nil, http.StatusOK, false},
+		{http.MethodPost, "/1/unarchive", "", nil, http.StatusOK, false},
+		{http.MethodPost, "/1/subtasks", `{"text": "step"}`, nil, http.StatusOK, false},
+		{http.MethodPatch, "/1/subtasks/0", `{"done": true}`, nil, http.StatusOK, false},
+		{http.MethodDelete, "/1/subtasks/0", "", nil, http.StatusOK, false},
+		{http.MethodGet, "/1/comments", "", nil, http.StatusOK, false},
+		{http.MethodPost, "/1/comments", `{"text": "comment"}`, nil, http.StatusCreated, false},
+		{http.MethodPost, "/1/share", `{"user": "bob"}`, nil, http.StatusOK, false},
+		{http.MethodPost, "/1/move", `{"position": 0}`, nil, http.StatusOK, false},
+		{http.MethodPost, "/1/duplicate", `{}`, nil, http.StatusCreated, false},
+	} {
+		t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+			client := newFakeClient(
+				&todomgrpb.Todo{Text: "todo", Owner: Username, Subtasks: []*todomgrpb.Subtask{{Text: "step"}}},
+				&todomgrpb.Todo{Text: "deleted", Owner: Username, DeletedAt: deletedAt},
+			)
+			router := newTestRouter(client, nil)
+			defer router.Close()
+			handler := router.GetRouter()
+
+			expectStatus(t, serve(handler, tc.method, tc.target, tc.body, tc.headers...), tc.wantStatus)
+
+			client.err = status.Error(codes.Unavailable, "todo-manager is down")
+			res := serve(handler, tc.method, tc.target, tc.body, tc.headers...)
+			if tc.partial {
+				expectStatus(t, res, tc.wantStatus)
+				if !strings.Contains(res.Body.String(), `"failed":["1"]`) {
+					t.Errorf("expected the todo to be reported as failed, got %s", res.Body.String())
+				}
+				return
+			}
+			expectStatus(t, res, http.StatusServiceUnavailable)
+			if tc.method == http.MethodHead {
+				return
+			}
+			body := &ErrorRes{}
+			decodeJSONRes(t, res, body)
+			if body.Code != CodeBackendUnavailable {
+				t.Errorf("expected code %s, got %s", CodeBackendUnavailable, body.Code)
+			}
+
+			// the routes of a single todo report it as not found
+			if !strings.HasPrefix(tc.target, "/1") && !strings.HasPrefix(tc.target, "/2") {
+				return
+			}
+			client.err = status.Error(codes.NotFound, "todo not found")
+			res = serve(handler, tc.method, tc.target, tc.body, tc.headers...)
+			expectStatus(t, res, http.StatusNotFound)
+			if tc.method == http.MethodHead {
+				return
+			}
+			body = &ErrorRes{}
+			decodeJSONRes(t, res, body)
+			if body.Code != CodeTodoNotFound {
+				t.Errorf("expected code %s, got %s", CodeTodoNotFound, body.Code)
+			}
+		})
+	}
+}
+
+func TestSyncTodosRoute(t *testing.T) {
+	client := newFakeClient()
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	server := httptest.NewServer(router.GetRouter())
+	defer server.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", server.URL)
+	if err != nil {
+		t.Fatalf("can't connect: %v", err)
+	}
+	defer conn.Close()
+	if err := websocket.JSON.Send(conn, &WSMessage{Type: wsCreate, Ref: "r1", Todo: &Todo{Text: "new"}}); err != nil {
+		t.Fatalf("can't send the edit: %v", err)
+	}
+	res := &WSMessage{}
+	if err := websocket.JSON.Receive(conn, res); err != nil {
+		t.Fatalf("can't receive the result: %v", err)
+	}
+	if res.Type != wsResult || res.Ref != "r1" || res.Todo == nil || res.Todo.Text != "new" {
+		t.Errorf("expected the created todo as result, got %+v", res)
+	}
+
+	client.err = status.Error(codes.Unavailable, "todo-manager is down")
+	websocket.JSON.Send(conn, &WSMessage{Type: wsCreate, Ref: "r2", Todo: &Todo{Text: "new"}})
+	res = &WSMessage{}
+	if err := websocket.JSON.Receive(conn, res); err != nil {
+		t.Fatalf("can't receive the result: %v", err)
+	}
+	if res.Type != wsError || res.Ref != "r2" || res.Code != CodeBackendUnavailable {
+		t.Errorf("expected the error of the edit, got %+v", res)
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	client := newFakeClient(
+		&todomgrpb.Todo{Text: "first", Owner: Username},
+		&todomgrpb.Todo{Text: "second", Owner: Username},
+	)
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+	listed := func(target string) string {
+		return strings.Join(listedIDs(t, handler, target), ",")
+	}
+
+	expectStatus(t, serve(handler, http.MethodDelete, "/1", ""), http.StatusNoContent)
+	if ids := listed("/"); ids != "2" {
+		t.Errorf("expected the deleted todo to be hidden, got todos %s", ids)
+	}
+	expectStatus(t, serve(handler, http.MethodGet, "/1", ""), http.StatusNotFound)
+	if ids := listed("/?deleted=true"); ids != "1" {
+		t.Errorf("expected the deleted todo in the trash, got todos %s", ids)
+	}
+
+	res := serve(handler, http.MethodPost, "/1/restore", "")
+	expectStatus(t, res, http.StatusOK)
+	todo := &Todo{}
+	decodeJSONRes(t, res, todo)
+	if todo.ID != "1" || todo.Text != "first" {
+		t.Errorf("expected the restored todo, got %+v", todo)
+	}
+	if ids := listed("/"); ids != "1,2" {
+		t.Errorf("expected the restored todo to be listed again, got todos %s", ids)
+	}
+	if ids := listed("/?deleted=true"); ids != "" {
+		t.Errorf("expected an empty trash, got todos %s", ids)
+	}
+	expectStatus(t, serve(handler, http.MethodPost, "/2/restore", ""), http.StatusNotFound)
+
+	// hard deletes can't be restored
+	expectStatus(t, serve(handler, http.MethodDelete, "/1?hard=true", ""), http.StatusNoContent)
+	if ids := listed("/?deleted=true"); ids != "" {
+		t.Errorf("expected hard deleted todos not to be in the trash, got todos %s", ids)
+	}
+	expectStatus(t, serve(handler, http.MethodPost, "/1/restore", ""), http.StatusNotFound)
+}
+
+// endlessClient is a fakeClient listing todos forever, ignoring cancellation like a hung todo-manager
+type endlessClient struct {
+	*fakeClient
+}
+
+func (c *endlessClient) ListTodos(ctx context.Context, in *todomgrpb.ListTodosReq, opts ...grpc.CallOption) (todomgrpb.TodoManager_ListTodosClient, error) {
+	return &endlessListStream{fakeStream{ctx: ctx}}, nil
+}
+
+type endlessListStream struct {
+	fakeStream
+}
+
+func (s *endlessListStream) Recv() (*todomgrpb.Todo, error) {
+	time.Sleep(time.Millisecond)
+	return &todomgrpb.Todo{Id: 1, Text: "todo", Owner: Username}, nil
+}
+
+func TestListTodosClientGone(t *testing.T) {
+	router := newTestRouter(&endlessClient{newFakeClient()}, nil)
+	defer router.Close()
+	// done is closed when the handler returned
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		router.GetRouter().ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/", nil)
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("can't list todos: %v", err)
+	}
+	defer res.Body.Close()
+	// todos are flushed as they're received
+	if _, err := bufio.NewReader(res.Body).ReadString('}'); err != nil {
+		t.Fatalf("expected the first todo before the end of the list: %v", err)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the handler to return when the client disconnected")
+	}
+}
@@ -0,0 +1,107 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+const (
+	// PermissionView allows a user a todo is shared with to get it, list and add its comments
+	PermissionView = "view"
+	// PermissionEdit additionally allows to update the todo and its subtasks
+	PermissionEdit = "edit"
+)
+
+// permissions maps the allowed permission values to their gRPC enum values
+var permissions = map[string]todomgrpb.Permission{
+	PermissionView: todomgrpb.Permission_VIEW,
+	PermissionEdit: todomgrpb.Permission_EDIT,
+}
+
+// Share grants a user access to a todo they don't own
+type Share struct {
+	User string `json:"user" xml:"user"`
+	// Permission is one of: view, edit; defaults to view
+	Permission string `json:"permission,omitempty" xml:"permission,omitempty"`
+}
+
+// Bind normalizes the Share object decoded from the request
+func (s *Share) Bind(r *http.Request) error {
+	s.User = strings.TrimSpace(s.User)
+	if s.Permission == "" {
+		s.Permission = PermissionView
+	}
+	return nil
+}
+
+// Validate checks if Share names a user and a known permission
+func (s *Share) Validate() error {
+	if s.User == "" {
+		return errors.New("User to share with can't be empty")
+	}
+	if _, found := permissions[s.Permission]; !found {
+		return fmt.Errorf("permission must be one of: view, edit, got %q", s.Permission)
+	}
+	return nil
+}
+
+// sharesFromGRPC returns shares based on gRPC DTOs
+func sharesFromGRPC(grpcShares []*todomgrpb.Share) []Share {
+	var shares []Share
+	for _, share := range grpcShares {
+		shares = append(shares, Share{
+			User:       share.GetUser(),
+			Permission: strings.ToLower(share.GetPermission().String()),
+		})
+	}
+	return shares
+}
+
+// ShareTodo shares a todo owned by the user with another user, who then sees it in their list
+// and can access it with the requested permission
+func (t *Router) ShareTodo(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	id, err := parseTodoID(chi.URLParam(r, "todoID"))
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	data := &Share{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if err := data.Validate(); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	grpcTodo, err := t.grpcClient.ShareTodo(ctx, &todomgrpb.ShareTodoReq{
+		Id:         id,
+		Owner:      owner,
+		User:       data.User,
+		Permission: permissions[data.Permission],
+	})
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	todo, _ := FromGRPCTodo(grpcTodo)
+	w.Header().Set("ETag", versionETag(todo.version))
+	if err := render.Render(w, r, todo); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+}
@@ -0,0 +1,129 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+func TestShareTodo(t *testing.T) {
+	client := newFakeClient(
+		&todomgrpb.Todo{Text: "shared", Owner: "alice"},
+		&todomgrpb.Todo{Text: "private", Owner: "alice"},
+	)
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+	alice, bob, carol := asOwner("alice", handler), asOwner("bob", handler), asOwner("carol", handler)
+
+	expectStatus(t, serve(bob, http.MethodGet, "/1", ""), http.StatusNotFound)
+	res := serve(alice, http.MethodPost, "/1/share", `{"user": "bob"}`)
+	expectStatus(t, res, http.StatusOK)
+	todo := &Todo{}
+	decodeJSONRes(t, res, todo)
+	if len(todo.SharedWith) != 1 || todo.SharedWith[0].User != "bob" || todo.SharedWith[0].Permission != "view" {
+		t.Errorf("expected the todo to be shared with bob with view permission, got %+v", todo.SharedWith)
+	}
+
+	res = serve(bob, http.MethodGet, "/1", "")
+	expectStatus(t, res, http.StatusOK)
+	if ids := strings.Join(listedIDs(t, bob, "/"), ","); ids != "1" {
+		t.Errorf("expected bob to list the todo shared with him, got todos %s", ids)
+	}
+	expectStatus(t, serve(bob, http.MethodGet, "/2", ""), http.StatusNotFound)
+	expectStatus(t, serve(carol, http.MethodGet, "/1", ""), http.StatusNotFound)
+	if ids := listedIDs(t, carol, "/"); len(ids) != 0 {
+		t.Errorf("expected carol not to list any todo, got todos %v", ids)
+	}
+
+	// only the owner can share a todo, and users with edit permission can update it
+	expectStatus(t, serve(bob, http.MethodPost, "/1/share", `{"user": "carol"}`), http.StatusNotFound)
+	expectStatus(t, serve(alice, http.MethodPost, "/1/share", `{"user": "carol", "permission": "edit"}`), http.StatusOK)
+	expectStatus(t, serve(carol, http.MethodPut, "/1", `{"text": "edited"}`), http.StatusOK)
+	if text := client.todo(1).Text; text != "edited" {
+		t.Errorf("expected the todo to be edited by carol, got text %q", text)
+	}
+	expectStatus(t, serve(alice, http.MethodPost, "/1/share", `{"user": "bob", "permission": "own"}`), http.StatusBadRequest)
+}
+
+// ownerCheckingClient is a fakeClient denying access to the todos of other owners than owner, like
+// todo-manager does
+type ownerCheckingClient struct {
+	*fakeClient
+	owner string
+}
+
+func (c *ownerCheckingClient) check(owner string) error {
+	if owner != c.owner {
+		return status.Error(codes.PermissionDenied, "Todo is owned by another user")
+	}
+	return nil
+}
+
+func (c *ownerCheckingClient) GetTodo(ctx context.Context, in *todomgrpb.TodoIdReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	if err := c.check(in.Owner); err != nil {
+		return nil, err
+	}
+	return c.fakeClient.GetTodo(ctx, in, opts...)
+}
+
+func (c *ownerCheckingClient) UpdateTodo(ctx context.Context, in *todomgrpb.Todo, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	if err := c.check(in.Owner); err != nil {
+		return nil, err
+	}
+	return c.fakeClient.UpdateTodo(ctx, in, opts...)
+}
+
+func (c *ownerCheckingClient) DeleteTodo(ctx context.Context, in *todomgrpb.DeleteTodoReq, opts ...grpc.CallOption) (*todomgrpb.DeleteTodoRes, error) {
+	if err := c.check(in.Owner); err != nil {
+		return nil, err
+	}
+	return c.fakeClient.DeleteTodo(ctx, in, opts...)
+}
+
+func TestOtherOwnersTodo(t *testing.T) {
+	client := &ownerCheckingClient{fakeClient: newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: "alice"}), owner: "alice"}
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+
+	for _, tc := range []struct {
+		method, body string
+	}{
+		{http.MethodGet, ""},
+		{http.MethodPut, `{"text": "edited"}`},
+		{http.MethodDelete, ""},
+	} {
+		// access is denied without revealing the todo exists
+		res := serve(asOwner("bob", handler), tc.method, "/1", tc.body)
+		expectStatus(t, res, http.StatusNotFound)
+		body := &ErrorRes{}
+		decodeJSONRes(t, res, body)
+		if body.Code != CodeTodoNotFound {
+			t.Errorf("expected %s of another owner's todo to be %s, got %s", tc.method, CodeTodoNotFound, body.Code)
+		}
+		if res := serve(asOwner("alice", handler), tc.method, "/1", tc.body); res.Code >= 300 {
+			t.Errorf("expected %s by the owner to succeed, got %d", tc.method, res.Code)
+		}
+	}
+}
+
+func TestUpdateViewSharedTodo(t *testing.T) {
+	client := newFakeClient(&todomgrpb.Todo{Text: "shared", Owner: "alice", SharedWith: []*todomgrpb.Share{{User: "bob", Permission: todomgrpb.Permission_VIEW}}})
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	bob := asOwner("bob", router.GetRouter())
+
+	expectStatus(t, serve(bob, http.MethodGet, "/1", ""), http.StatusOK)
+	expectStatus(t, serve(bob, http.MethodPut, "/1", `{"text": "edited"}`), http.StatusNotFound)
+	if text := client.todo(1).Text; text != "shared" {
+		t.Errorf("expected the todo not to be edited, got text %q", text)
+	}
+}
@@ -0,0 +1,63 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// DefaultShutdownTimeout is the default time to wait for in-flight requests when shutting down
+const DefaultShutdownTimeout = 25 * time.Second
+
+// errShuttingDown is returned for requests received while the router is shutting down
+var errShuttingDown = errors.New("server is shutting down")
+
+// trackInFlight counts the requests being served, so that Shutdown can wait for them; requests
+// received after Shutdown was called are rejected with 503 Service Unavailable
+func (t *Router) trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.drainLock.Lock()
+		if t.draining {
+			t.drainLock.Unlock()
+			w.Header().Set("Connection", "close")
+			render.Render(w, r, errServiceUnavailable(errShuttingDown))
+			return
+		}
+		t.inFlight.Add(1)
+		t.drainLock.Unlock()
+		defer t.inFlight.Done()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Shutdown gracefully shuts down the router: new requests are rejected, event streams are
+// ended and in-flight requests are waited for until ctx is done, then the router is closed.
+// It's meant to be called before shutting down the http.Server, which waits for the
+// connections to become idle; ctx.Err() is returned if in-flight requests didn't finish in time.
+func (t *Router) Shutdown(ctx context.Context) error {
+	t.drainLock.Lock()
+	if !t.draining {
+		t.draining = true
+		close(t.shutdown)
+	}
+	t.drainLock.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		t.inFlight.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if closeErr := t.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// streamHeartbeatInterval is the interval of comments sent on idle event streams, so proxies
+// don't close the connection
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamTodos streams changes of the todos owned by a user as Server-Sent Events; the event type
+// is one of: created, updated, deleted and the data is the JSON encoded todo
+func (t *Router) StreamTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		render.Render(w, r, middleware.ErrRender(errors.New("streaming is not supported")))
+		return
+	}
+	// the stream is cancelled together with the request when the client disconnects
+	stream, err := t.grpcClient.WatchTodos(r.Context(), &todomgrpb.WatchTodosReq{Owner: owner})
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	if _, err := stream.Header(); err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := make(chan *todomgrpb.TodoEvent)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event := <-events:
+			if err := writeTodoEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case err := <-errs:
+			if r.Context().Err() == nil && err != io.EOF {
+				t.options.Logger.WithError(err).Error("todo-manager event stream failed")
+				fmt.Fprintf(w, "event: error\ndata: %q\n\n", err.Error())
+				flusher.Flush()
+			}
+			return
+		case <-r.Context().Done():
+			return
+		case <-t.shutdown:
+			// clients reconnect to another instance
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeTodoEvent writes a todo event in Server-Sent Events format
+func writeTodoEvent(w io.Writer, event *todomgrpb.TodoEvent) error {
+	todo, _ := FromGRPCTodo(event.GetTodo())
+	data, err := json.Marshal(todo)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", strings.ToLower(event.GetType().String()), data)
+	return err
+}
@@ -0,0 +1,230 @@
+package todo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// streamImportMaxPending is the max number of lines of a streamed import sent to todo-manager and
+// waiting for their result; reading the request body pauses when it's reached
+const streamImportMaxPending = 64
+
+// ImportLineRes is the result of a line of a streamed import: the ID of the created todo, or the
+// code and message of the error the line failed with
+type ImportLineRes struct {
+	Line  int       `json:"line"`
+	ID    string    `json:"id,omitempty"`
+	Code  ErrorCode `json:"code,omitempty"`
+	Error string    `json:"error,omitempty"`
+	// Errors lists the errors of all the invalid fields of lines failing validation
+	Errors ValidationErrors `json:"errors,omitempty"`
+}
+
+// StreamImportRes summarizes a streamed import, as the last line of the response; Code and Error
+// are set when the import was stopped before the end of the request body
+type StreamImportRes struct {
+	Imported int       `json:"imported"`
+	Failed   int       `json:"failed"`
+	Code     ErrorCode `json:"code,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// StreamImportTodos creates todos for a user from a request body of newline-delimited JSON, one todo
+// per line. Every line is validated and sent to todo-manager as soon as it's read, and its result is
+// streamed back as a line of NDJSON, so imports of any size are neither buffered nor limited; invalid
+// lines and lines with the ID of a todo that already exists are reported and skipped. The last line
+// of the response summarizes the import. Errors like the owner reaching the max number of todos stop
+// the import, keeping the todos created so far.
+func (t *Router) StreamImportTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	// the import takes as long as the client needs to upload the body, so it's not limited by CallTimeout
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	stream, err := t.grpcClient.StreamCreateTodos(ctx)
+	if err != nil {
+		t.renderGRPCError(w, r, err)
+		return
+	}
+
+	if r.ProtoMajor < 2 {
+		// the HTTP/1 server stops reading the request body when the response starts, unless the
+		// connection is closed after the response
+		w.Header().Set("Connection", "close")
+	}
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	flush(flusher)
+
+	lines := make(chan *ImportLineRes, streamImportMaxPending)
+	summary := make(chan *StreamImportRes, 1)
+	go func() {
+		summary <- t.writeStreamImportResults(ctx, w, r, flusher, stream, lines, cancel)
+	}()
+	err = parseNDJSONImport(r.Body, int(t.options.MaxBodyBytes), func(n int, todo *Todo, err error) error {
+		line := &ImportLineRes{Line: n}
+		if err == nil {
+			err = todo.Bind(r)
+		}
+		if err == nil {
+			err = t.validateNewTodo(todo)
+		}
+		if err != nil {
+			line.Code = CodeInvalidRequest
+			line.Error = err.Error()
+			if fieldErrs, ok := err.(ValidationErrors); ok {
+				line.Code = CodeValidationFailed
+				line.Errors = fieldErrs
+			}
+		} else {
+			if todo.ID == "" {
+				todo.ID = "0"
+			}
+			if todo.Priority == "" {
+				todo.Priority = PriorityMedium
+			}
+			if err := stream.Send(todo.ToGRPCTodo(owner)); err != nil {
+				return err
+			}
+		}
+		select {
+		case lines <- line:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	if ctx.Err() != nil || err == io.EOF {
+		// the stream was stopped by the results writer or by the client, which has the reason
+		err = nil
+	}
+	if err == nil {
+		stream.CloseSend()
+	} else {
+		// makes todo-manager stop too; the todos created so far are kept
+		cancel()
+	}
+	close(lines)
+	res := <-summary
+	if err != nil && res.Error == "" {
+		res.Code = CodeInvalidRequest
+		res.Error = err.Error()
+	}
+	if r.Context().Err() == nil {
+		json.NewEncoder(w).Encode(res)
+	}
+	t.createOneCounter.WithLabelValues(owner).Add(float64(res.Imported))
+}
+
+// writeStreamImportResults writes the result of every line of a streamed import, in the order they're
+// read from lines; lines without an error wait for the result of todo-manager, received from stream.
+// The summary of the import is returned; if the stream fails, the import is stopped with cancel, the
+// function cancelling ctx.
+func (t *Router) writeStreamImportResults(ctx context.Context, w io.Writer, r *http.Request, flusher http.Flusher,
+	stream todomgrpb.TodoManager_StreamCreateTodosClient, lines <-chan *ImportLineRes, cancel func()) *StreamImportRes {
+	res := &StreamImportRes{}
+	stop := func(err error) {
+		t.logGRPCError(r, err)
+		if body, ok := errorResBody(errFromGRPC(err)); ok {
+			res.Code, res.Error = body.Code, body.Error
+		}
+		cancel()
+	}
+	encoder := json.NewEncoder(w)
+	for line := range lines {
+		if ctx.Err() != nil {
+			// the import was stopped, the lines still pending are dropped
+			continue
+		}
+		if line.Error == "" {
+			created, err := stream.Recv()
+			if err != nil {
+				stop(err)
+				continue
+			}
+			if created.GetError() != "" {
+				line.Code = CodeInvalidRequest
+				if code, found := grpcErrorCodes[codes.Code(created.GetCode())]; found {
+					line.Code = code
+				}
+				line.Error = created.GetError()
+			} else {
+				todo, _ := FromGRPCTodo(created.GetTodo())
+				line.ID = todo.ID
+			}
+		}
+		if line.Error == "" {
+			res.Imported++
+		} else {
+			res.Failed++
+		}
+		if err := encoder.Encode(line); err != nil {
+			// the client is gone
+			cancel()
+			continue
+		}
+		flush(flusher)
+	}
+	if ctx.Err() == nil {
+		// todo-manager ends the stream once it got all the lines, possibly with an error
+		if _, err := stream.Recv(); err != io.EOF {
+			stop(err)
+		}
+	}
+	return res
+}
+
+// parseNDJSONImport calls add for every non-empty line of newline-delimited JSON, with its 1-based line
+// number; lines that aren't a todo or are longer than maxLineBytes are passed to add with an error,
+// while errors returned by add and errors reading body stop the parsing
+func parseNDJSONImport(body io.Reader, maxLineBytes int, add func(int, *Todo, error) error) error {
+	reader := bufio.NewReader(body)
+	var line []byte
+	for n := 1; ; n++ {
+		line = line[:0]
+		tooLong := false
+		var err error
+		for {
+			var chunk []byte
+			chunk, err = reader.ReadSlice('\n')
+			if tooLong || len(line)+len(bytes.TrimRight(chunk, "\r\n")) > maxLineBytes {
+				// the rest of the line is skipped
+				tooLong = true
+			} else {
+				line = append(line, chunk...)
+			}
+			if err != bufio.ErrBufferFull {
+				break
+			}
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		var addErr error
+		switch {
+		case tooLong:
+			addErr = add(n, nil, fmt.Errorf("line is longer than %d bytes", maxLineBytes))
+		case len(bytes.TrimSpace(line)) > 0:
+			todo := &Todo{}
+			addErr = add(n, todo, json.Unmarshal(line, todo))
+		}
+		if addErr != nil {
+			return addErr
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
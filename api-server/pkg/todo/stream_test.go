@@ -0,0 +1,77 @@
+package todo
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// readEvent reads the next Server-Sent Event from reader, skipping comments
+func readEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+	var event, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("can't read event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event != "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStreamTodos(t *testing.T) {
+	client := newFakeClient()
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	// done is closed when the handler returned
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		router.GetRouter().ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatalf("can't open the stream: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got status %d and Content-Type %q", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	client.events <- &todomgrpb.TodoEvent{Type: todomgrpb.TodoEvent_CREATED, Todo: &todomgrpb.Todo{Id: 1, Text: "new", Owner: Username}}
+	client.events <- &todomgrpb.TodoEvent{Type: todomgrpb.TodoEvent_DELETED, Todo: &todomgrpb.Todo{Id: 1, Text: "new", Owner: Username}}
+	reader := bufio.NewReader(res.Body)
+	for _, want := range []string{"created", "deleted"} {
+		event, data := readEvent(t, reader)
+		if event != want {
+			t.Errorf("expected a %s event, got %s", want, event)
+		}
+		todo := &Todo{}
+		if err := json.Unmarshal([]byte(data), todo); err != nil || todo.ID != "1" || todo.Text != "new" {
+			t.Errorf("expected the JSON encoded todo as data, got %q", data)
+		}
+	}
+
+	// disconnecting cancels the stream of todo-manager
+	res.Body.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the stream to end when the client disconnected")
+	}
+}
@@ -0,0 +1,162 @@
+package todo
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// AddSubtask appends a subtask to a todo with specified user and todo ID
+func (t *Router) AddSubtask(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	id, err := parseTodoID(chi.URLParam(r, "todoID"))
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	data := &Subtask{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if err := data.Validate(); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		render.Render(w, r, errPreconditionFailed(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	var trailer metadata.MD
+	grpcTodo, err := t.grpcClient.AddSubtask(ctx, &todomgrpb.AddSubtaskReq{
+		Id:              id,
+		Owner:           owner,
+		Subtask:         &todomgrpb.Subtask{Text: data.Text, Done: data.Done},
+		ExpectedVersion: version,
+	}, grpc.Trailer(&trailer))
+	t.renderSubtaskResult(w, r, grpcTodo, trailer, err)
+}
+
+// UpdateSubtask updates the fields present in the request of a subtask with specified user, todo ID
+// and index; the todo is marked done when all of its subtasks are done, if the complete_parent
+// query param is true
+func (t *Router) UpdateSubtask(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	id, index, err := subtaskParams(r)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	completeParent, err := parseBoolFilter(r, "complete_parent")
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	data := &SubtaskPatch{}
+	if err := render.Bind(r, data); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	if err := data.Validate(); err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		render.Render(w, r, errPreconditionFailed(err))
+		return
+	}
+	req := &todomgrpb.UpdateSubtaskReq{
+		Id:              id,
+		Owner:           owner,
+		Index:           index,
+		CompleteParent:  completeParent.GetValue(),
+		ExpectedVersion: version,
+	}
+	if data.Text != nil {
+		req.Text = &wrappers.StringValue{Value: *data.Text}
+	}
+	if data.Done != nil {
+		req.Done = &wrappers.BoolValue{Value: *data.Done}
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	var trailer metadata.MD
+	grpcTodo, err := t.grpcClient.UpdateSubtask(ctx, req, grpc.Trailer(&trailer))
+	t.renderSubtaskResult(w, r, grpcTodo, trailer, err)
+}
+
+// RemoveSubtask removes a subtask with specified user, todo ID and index; the following subtasks
+// move one index down
+func (t *Router) RemoveSubtask(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	id, index, err := subtaskParams(r)
+	if err != nil {
+		render.Render(w, r, middleware.ErrInvalidRequest(err))
+		return
+	}
+	version, err := expectedVersion(r)
+	if err != nil {
+		render.Render(w, r, errPreconditionFailed(err))
+		return
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	var trailer metadata.MD
+	grpcTodo, err := t.grpcClient.RemoveSubtask(ctx, &todomgrpb.SubtaskIdReq{
+		Id:              id,
+		Owner:           owner,
+		Index:           index,
+		ExpectedVersion: version,
+	}, grpc.Trailer(&trailer))
+	t.renderSubtaskResult(w, r, grpcTodo, trailer, err)
+}
+
+// subtaskParams returns the todo ID and the subtask index from the URL of r
+func subtaskParams(r *http.Request) (uint64, uint32, error) {
+	id, err := parseTodoID(chi.URLParam(r, "todoID"))
+	if err != nil {
+		return 0, 0, err
+	}
+	index, err := strconv.ParseUint(chi.URLParam(r, "index"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return id, uint32(index), nil
+}
+
+// renderSubtaskResult renders the todo returned by a subtask change or its error
+func (t *Router) renderSubtaskResult(w http.ResponseWriter, r *http.Request, grpcTodo *todomgrpb.Todo, trailer metadata.MD, err error) {
+	if err != nil {
+		setVersionETag(w, trailer)
+		t.renderGRPCError(w, r, err)
+		return
+	}
+	todo, owner := FromGRPCTodo(grpcTodo)
+	w.Header().Set("ETag", versionETag(todo.version))
+	if err := render.Render(w, r, todo); err != nil {
+		render.Render(w, r, middleware.ErrRender(err))
+		return
+	}
+	t.patchOneCounter.WithLabelValues(owner).Inc()
+}
@@ -0,0 +1,45 @@
+package todo
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// errInvalidUTF8 is returned for texts that aren't valid UTF-8
+	errInvalidUTF8 = errors.New("Text must be valid UTF-8")
+	// errInvalidUTF8JSON is returned for JSON documents that aren't valid UTF-8
+	errInvalidUTF8JSON = errors.New("JSON must be valid UTF-8")
+)
+
+// normalizeText trims the whitespace around the text of a todo and converts it to the NFC form,
+// so that texts written with precomposed or combining characters compare equal. Texts that
+// aren't valid UTF-8 are only trimmed, validateText rejects them.
+func normalizeText(text string) string {
+	text = strings.TrimSpace(text)
+	if !utf8.ValidString(text) {
+		return text
+	}
+	return norm.NFC.String(text)
+}
+
+// validateText checks if the text of a todo is valid UTF-8
+func validateText(text string) error {
+	if !utf8.ValidString(text) {
+		return errInvalidUTF8
+	}
+	return nil
+}
+
+// checkUTF8JSON checks if a JSON document decoded from a request is valid UTF-8. The JSON decoder
+// replaces invalid byte sequences with U+FFFD instead of failing, so the UnmarshalJSON methods of
+// the todos, patches and GraphQL requests check their raw JSON with it first.
+func checkUTF8JSON(data []byte) error {
+	if !utf8.Valid(data) {
+		return errInvalidUTF8JSON
+	}
+	return nil
+}
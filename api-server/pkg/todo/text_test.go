@@ -0,0 +1,114 @@
+package todo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// invalidUTF8 is a byte sequence that isn't valid UTF-8
+const invalidUTF8 = "bad \xff\xfe text"
+
+func TestNormalizeText(t *testing.T) {
+	for text, want := range map[string]string{
+		"  todo \n":  "todo",
+		"cafe\u0301": "caf\u00e9",
+		"caf\u00e9":  "caf\u00e9",
+		invalidUTF8:  invalidUTF8,
+	} {
+		if got := normalizeText(text); got != want {
+			t.Errorf("expected %q to be normalized to %q, got %q", text, want, got)
+		}
+	}
+}
+
+func TestCombiningCharacters(t *testing.T) {
+	client := newFakeClient()
+	router := newTestRouter(client, nil)
+	defer router.Close()
+
+	expectStatus(t, serve(router.GetRouter(), http.MethodPost, "/", `{"text": " cafe\u0301 "}`), http.StatusCreated)
+	if text := client.todo(1).Text; text != "caf\u00e9" {
+		t.Errorf("expected the text to be stored in the NFC form, got %q", text)
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	client := newFakeClient(&todomgrpb.Todo{Text: "todo", Owner: Username})
+	router := newTestRouter(client, nil)
+	defer router.Close()
+	handler := router.GetRouter()
+	graphQLHandler, err := router.NewGraphQLHandler()
+	if err != nil {
+		t.Fatalf("can't build the GraphQL handler: %v", err)
+	}
+	importBody, importContentType := multipartFile("todos.json", `[{"text": "`+invalidUTF8+`"}]`)
+
+	for _, tc := range []struct {
+		handler              http.Handler
+		method, target, body string
+		headers              []string
+		// failed is true for imports, which report invalid todos in a 200 response
+		failed bool
+	}{
+		{handler, http.MethodPost, "/", `{"text": "` + invalidUTF8 + `"}`, nil, false},
+		{handler, http.MethodPut, "/1", `{"text": "` + invalidUTF8 + `"}`, nil, false},
+		{handler, http.MethodPatch, "/1", `{"text": "` + invalidUTF8 + `"}`, nil, false},
+		{handler, http.MethodPatch, "/1", `{"tags": ["` + invalidUTF8 + `"]}`, []string{"Content-Type", mergePatchContentType}, false},
+		{handler, http.MethodPost, "/batch", `[{"text": "` + invalidUTF8 + `"}]`, nil, false},
+		{handler, http.MethodPut, "/?replace=true", `[{"text": "` + invalidUTF8 + `"}]`, nil, false},
+		{handler, http.MethodPost, "/import", importBody, []string{"Content-Type", importContentType}, true},
+		{handler, http.MethodPost, "/import/stream", `{"text": "` + invalidUTF8 + `"}` + "\n", []string{"Content-Type", ndjsonContentType}, true},
+		{graphQLHandler, http.MethodPost, "/graphql", `{"query": "mutation { createTodo(todo: {text: \"` + invalidUTF8 + `\"}) { id } }"}`, nil, false},
+	} {
+		res := serve(tc.handler, tc.method, tc.target, tc.body, tc.headers...)
+		want := http.StatusBadRequest
+		if tc.failed {
+			want = http.StatusOK
+		}
+		if res.Code != want || !strings.Contains(res.Body.String(), errInvalidUTF8JSON.Error()) {
+			t.Errorf("expected %s %s with invalid UTF-8 to be rejected, got %d: %s", tc.method, tc.target, res.Code, res.Body.String())
+		}
+	}
+	if todo := client.todo(2); todo != nil {
+		t.Errorf("expected no todo to be created, got %v", todo)
+	}
+	if text := client.todo(1).Text; text != "todo" {
+		t.Errorf("expected the todo not to be changed, got text %q", text)
+	}
+}
+
+func TestInvalidUTF8WebSocket(t *testing.T) {
+	router := newTestRouter(newFakeClient(), nil)
+	defer router.Close()
+	server := httptest.NewServer(router.GetRouter())
+	defer server.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", server.URL)
+	if err != nil {
+		t.Fatalf("can't connect: %v", err)
+	}
+	defer conn.Close()
+	if err := websocket.Message.Send(conn, `{"type": "create", "ref": "r1", "todo": {"text": "`+invalidUTF8+`"}}`); err != nil {
+		t.Fatalf("can't send the edit: %v", err)
+	}
+	res := &WSMessage{}
+	if err := websocket.JSON.Receive(conn, res); err != nil {
+		t.Fatalf("can't receive the result: %v", err)
+	}
+	if res.Type != wsError || res.Ref != "r1" || res.Error != errInvalidUTF8JSON.Error() {
+		t.Errorf("expected the edit to be rejected, got %+v", res)
+	}
+
+	// the connection stays open
+	websocket.JSON.Send(conn, &WSMessage{Type: wsCreate, Ref: "r2", Todo: &Todo{Text: "new"}})
+	res = &WSMessage{}
+	if err := websocket.JSON.Receive(conn, res); err != nil || res.Type != wsResult || res.Ref != "r2" {
+		t.Errorf("expected the next edit to succeed, got %+v, %v", res, err)
+	}
+}
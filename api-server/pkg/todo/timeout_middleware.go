@@ -0,0 +1,116 @@
+package todo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// DefaultHandlerTimeout is the default max time a handler can take to respond
+const DefaultHandlerTimeout = 30 * time.Second
+
+// errHandlerTimeout is returned for requests whose handler didn't respond in time
+var errHandlerTimeout = errors.New("the request took too long to be served")
+
+// NewTimeoutMiddleware returns a middleware cancelling the context of requests after timeout and
+// responding 503 Service Unavailable if the handler didn't respond by then. Responses are buffered
+// until the handler returns, so it can't be used for streamed responses; it's disabled when
+// timeout isn't positive.
+func NewTimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			// the request of the handler is copied here, since rendering the 503 response changes r
+			req := r.WithContext(ctx)
+			tw := &timeoutResponseWriter{header: http.Header{}, status: http.StatusOK}
+			done := make(chan struct{})
+			panicked := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						if p != http.ErrAbortHandler {
+							p = &handlerPanic{value: p, stack: debug.Stack()}
+						}
+						panicked <- p
+					}
+				}()
+				next.ServeHTTP(tw, req)
+				close(done)
+			}()
+			select {
+			case p := <-panicked:
+				// re-panicked in the goroutine of the request, so that the recovery middleware handles it
+				// with the stack trace of the handler
+				panic(p)
+			case <-done:
+				tw.lock.Lock()
+				defer tw.lock.Unlock()
+				for key, values := range tw.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tw.status)
+				w.Write(tw.body.Bytes())
+			case <-ctx.Done():
+				tw.lock.Lock()
+				defer tw.lock.Unlock()
+				tw.timedOut = true
+				render.Render(w, r, errServiceUnavailable(errHandlerTimeout))
+			}
+		})
+	}
+}
+
+// handlerPanic is a panic of a handler run by the timeout middleware, panicked again in the
+// goroutine of the request with the stack trace where it happened
+type handlerPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// timeoutResponseWriter buffers the response of a handler until it returns; writes after the
+// timeout fail with http.ErrHandlerTimeout
+type timeoutResponseWriter struct {
+	lock        sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+// Header returns the headers of the buffered response
+func (w *timeoutResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the status code of the response
+func (w *timeoutResponseWriter) WriteHeader(code int) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.timedOut || w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+}
+
+// Write buffers the body of the response
+func (w *timeoutResponseWriter) Write(b []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
@@ -0,0 +1,38 @@
+package todo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// errNoTransportSecurity is returned when neither TLS nor an insecure connection was configured
+var errNoTransportSecurity = errors.New("no transport security configured for todo-manager connection: " +
+	"set TLSConfig or TLSCAFile, or explicitly request an insecure connection with Insecure")
+
+// transportCredentials returns the dial option securing the gRPC connection to todo-manager
+// as configured in options
+func transportCredentials(options *RouterOptions) (grpc.DialOption, error) {
+	switch {
+	case options.TLSConfig != nil:
+		return grpc.WithTransportCredentials(credentials.NewTLS(options.TLSConfig)), nil
+	case options.TLSCAFile != "":
+		pem, err := ioutil.ReadFile(options.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read todo-manager CA certificate: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in %s", options.TLSCAFile)
+		}
+		return grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool})), nil
+	case options.Insecure:
+		return grpc.WithInsecure(), nil
+	}
+	return nil, errNoTransportSecurity
+}
@@ -0,0 +1,121 @@
+package todo
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// selfSignedCert returns a self-signed certificate for 127.0.0.1 and its PEM encoding
+func selfSignedCert(t *testing.T) (tls.Certificate, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("can't generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "todo-manager"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("can't create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// writeTempFile writes content to a new temporary file and returns its path; the file has to be
+// removed by the test
+func writeTempFile(t *testing.T, content []byte) string {
+	t.Helper()
+	file, err := ioutil.TempFile("", "todo-test")
+	if err != nil {
+		t.Fatalf("can't create temporary file: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(content); err != nil {
+		t.Fatalf("can't write temporary file: %v", err)
+	}
+	return file.Name()
+}
+
+func TestTLS(t *testing.T) {
+	cert, certPEM := selfSignedCert(t)
+	addr, server := startTestServer(t, &testServer{
+		getTodo: func(ctx context.Context, req *todomgrpb.TodoIdReq) (*todomgrpb.Todo, error) {
+			return &todomgrpb.Todo{Id: req.Id, Text: "todo", Owner: req.Owner}, nil
+		},
+	}, grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+	defer server.Stop()
+	caFile := writeTempFile(t, certPEM)
+	defer os.Remove(caFile)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+
+	for _, tc := range []struct {
+		name       string
+		options    *RouterOptions
+		wantStatus int
+	}{
+		{"CA file", &RouterOptions{TLSCAFile: caFile}, http.StatusOK},
+		{"TLS config", &RouterOptions{TLSConfig: &tls.Config{RootCAs: pool}}, http.StatusOK},
+		{"TLS config taking precedence", &RouterOptions{TLSConfig: &tls.Config{RootCAs: pool}, Insecure: true}, http.StatusOK},
+		{"untrusted certificate", &RouterOptions{TLSConfig: &tls.Config{}}, http.StatusServiceUnavailable},
+		{"insecure", &RouterOptions{Insecure: true}, http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.options.RetryMaxAttempts = 1
+			router, err := NewRouter(addr, testOptions(tc.options))
+			if err != nil {
+				t.Fatalf("can't create the router: %v", err)
+			}
+			defer router.Close()
+			expectStatus(t, serve(router.GetRouter(), http.MethodGet, "/1", ""), tc.wantStatus)
+		})
+	}
+}
+
+func TestTLSMisconfigured(t *testing.T) {
+	invalidFile := writeTempFile(t, []byte("not a certificate"))
+	defer os.Remove(invalidFile)
+
+	for _, tc := range []struct {
+		name    string
+		options *RouterOptions
+	}{
+		{"no transport security", &RouterOptions{}},
+		{"missing CA file", &RouterOptions{TLSCAFile: invalidFile + ".missing"}},
+		{"invalid CA file", &RouterOptions{TLSCAFile: invalidFile}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if router, err := NewRouter("127.0.0.1:1", testOptions(tc.options)); err == nil {
+				router.Close()
+				t.Errorf("expected the router not to be created")
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+const (
+	// todoCacheMaxEntries is the max number of todos kept by the GetTodo cache; no more todos are
+	// cached while it's full of entries that didn't expire yet
+	todoCacheMaxEntries = 10000
+	// todoCacheMaxInvalidations is the number of the last invalidations remembered by the GetTodo
+	// cache; todos got by calls started before older ones aren't stored
+	todoCacheMaxInvalidations = 1000
+)
+
+type todoCacheEntry struct {
+	todo    *todomgrpb.Todo
+	expires time.Time
+}
+
+// todoInvalidation describes the todos dropped by an invalidation of the GetTodo cache
+type todoInvalidation struct {
+	// id is the ID of the invalidated todo, or 0 if all the todos of owner were invalidated
+	id uint64
+	// owner is the owner of the invalidated todos; all of them were invalidated if it's empty too
+	owner string
+}
+
+// matches checks if todo was invalidated
+func (inv todoInvalidation) matches(todo *todomgrpb.Todo) bool {
+	if inv.id != 0 {
+		return inv.id == todo.GetId()
+	}
+	return inv.owner == "" || inv.owner == todo.GetOwner()
+}
+
+// todoCache keeps the todos got by GetTodo for a short TTL, by todo ID and owner of the request,
+// so that repeated reads of a todo don't call todo-manager. Entries are invalidated by the calls
+// changing todos made by this process only; changes made through other replicas of the apiserver
+// are seen once the entries expire.
+type todoCache struct {
+	ttl       time.Duration
+	lock      sync.Mutex
+	entries   map[uint64]map[string]*todoCacheEntry
+	size      int
+	lastSweep time.Time
+	// generation is incremented by every invalidation, so that todos it dropped got by calls
+	// started before it aren't stored; invalidations are the last ones, up to generation
+	generation    uint64
+	invalidations []todoInvalidation
+}
+
+func newTodoCache(ttl time.Duration) *todoCache {
+	return &todoCache{
+		ttl:       ttl,
+		entries:   map[uint64]map[string]*todoCacheEntry{},
+		lastSweep: time.Now(),
+	}
+}
+
+// get returns the todo with id cached for owner, or nil if there's none; it must not be modified
+func (c *todoCache) get(owner string, id uint64, now time.Time) *todomgrpb.Todo {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, found := c.entries[id][owner]; found && now.Before(e.expires) {
+		return e.todo
+	}
+	return nil
+}
+
+// currentGeneration returns the generation to pass to put with the result of a call started now
+func (c *todoCache) currentGeneration() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.generation
+}
+
+// put caches todo for owner, unless it was invalidated since generation was returned by
+// currentGeneration; invalidations of other todos don't prevent it
+func (c *todoCache) put(owner string, todo *todomgrpb.Todo, generation uint64, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	since := c.generation - generation
+	if since > uint64(len(c.invalidations)) {
+		// the invalidations since the call started aren't all remembered anymore
+		return
+	}
+	for _, inv := range c.invalidations[uint64(len(c.invalidations))-since:] {
+		if inv.matches(todo) {
+			return
+		}
+	}
+	// drop expired entries, so the map doesn't grow forever
+	if now.Sub(c.lastSweep) > c.ttl {
+		for id, owners := range c.entries {
+			for o, e := range owners {
+				if !now.Before(e.expires) {
+					delete(owners, o)
+					c.size--
+				}
+			}
+			if len(owners) == 0 {
+				delete(c.entries, id)
+			}
+		}
+		c.lastSweep = now
+	}
+	owners := c.entries[todo.GetId()]
+	if owners == nil {
+		owners = map[string]*todoCacheEntry{}
+		c.entries[todo.GetId()] = owners
+	}
+	if _, found := owners[owner]; !found {
+		if c.size >= todoCacheMaxEntries {
+			return
+		}
+		c.size++
+	}
+	owners[owner] = &todoCacheEntry{todo: todo, expires: now.Add(c.ttl)}
+}
+
+// invalidated records an invalidation in a new generation; the lock has to be held
+func (c *todoCache) invalidated(inv todoInvalidation) {
+	c.generation++
+	c.invalidations = append(c.invalidations, inv)
+	if len(c.invalidations) > todoCacheMaxInvalidations {
+		c.invalidations = c.invalidations[len(c.invalidations)-todoCacheMaxInvalidations:]
+	}
+}
+
+// invalidate drops the todo with id, cached for any owner
+func (c *todoCache) invalidate(id uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.invalidated(todoInvalidation{id: id})
+	c.size -= len(c.entries[id])
+	delete(c.entries, id)
+}
+
+// invalidateOwner drops all the todos of owner, cached for any owner; all todos are dropped when
+// owner is empty
+func (c *todoCache) invalidateOwner(owner string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.invalidated(todoInvalidation{owner: owner})
+	for id, owners := range c.entries {
+		for o, e := range owners {
+			if owner == "" || e.todo.GetOwner() == owner {
+				delete(owners, o)
+				c.size--
+			}
+		}
+		if len(owners) == 0 {
+			delete(c.entries, id)
+		}
+	}
+}
+
+// invalidatingClient is a todo-manager client invalidating the todos cached in cache once the
+// calls changing them return, failed ones included since they might have been applied anyway
+type invalidatingClient struct {
+	todomgrpb.TodoManagerClient
+	cache *todoCache
+}
+
+func (c *invalidatingClient) UpdateTodo(ctx context.Context, in *todomgrpb.Todo, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.TodoManagerClient.UpdateTodo(ctx, in, opts...)
+}
+
+func (c *invalidatingClient) PatchTodo(ctx context.Context, in *todomgrpb.TodoPatch, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.TodoManagerClient.PatchTodo(ctx, in, opts...)
+}
+
+func (c *invalidatingClient) DeleteTodo(ctx context.Context, in *todomgrpb.DeleteTodoReq, opts ...grpc.CallOption) (*todomgrpb.DeleteTodoRes, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.TodoManagerClient.DeleteTodo(ctx, in, opts...)
+}
+
+func (c *invalidatingClient) RestoreTodo(ctx context.Context, in *todomgrpb.TodoIdReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.TodoManagerClient.RestoreTodo(ctx, in, opts...)
+}
+
+func (c *invalidatingClient) AddSubtask(ctx context.Context, in *todomgrpb.AddSubtaskReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.TodoManagerClient.AddSubtask(ctx, in, opts...)
+}
+
+func (c *invalidatingClient) UpdateSubtask(ctx context.Context, in *todomgrpb.UpdateSubtaskReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.TodoManagerClient.UpdateSubtask(ctx, in, opts...)
+}
+
+func (c *invalidatingClient) RemoveSubtask(ctx context.Context, in *todomgrpb.SubtaskIdReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.TodoManagerClient.RemoveSubtask(ctx, in, opts...)
+}
+
+func (c *invalidatingClient) ShareTodo(ctx context.Context, in *todomgrpb.ShareTodoReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.TodoManagerClient.ShareTodo(ctx, in, opts...)
+}
+
+// MoveTodo invalidates all the todos of the owner, since moving a todo shifts the position of others
+func (c *invalidatingClient) MoveTodo(ctx context.Context, in *todomgrpb.MoveTodoReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	defer c.cache.invalidateOwner(in.GetOwner())
+	return c.TodoManagerClient.MoveTodo(ctx, in, opts...)
+}
+
+func (c *invalidatingClient) SetTodosDone(ctx context.Context, in *todomgrpb.SetTodosDoneReq, opts ...grpc.CallOption) (*todomgrpb.CountTodosRes, error) {
+	defer c.cache.invalidateOwner(in.GetFilter().GetOwner())
+	return c.TodoManagerClient.SetTodosDone(ctx, in, opts...)
+}
+
+// getTodo gets the todo with id from todo-manager for owner, or from the GetTodo cache if it's
+// enabled and has it
+func (t *Router) getTodo(r *http.Request, owner string, id uint64) (*todomgrpb.Todo, error) {
+	var generation uint64
+	if t.todoCache != nil {
+		if cached := t.todoCache.get(owner, id, time.Now()); cached != nil {
+			t.todoCacheCounter.WithLabelValues("hit").Inc()
+			return cached, nil
+		}
+		t.todoCacheCounter.WithLabelValues("miss").Inc()
+		generation = t.todoCache.currentGeneration()
+	}
+	ctx, cancel := t.callContext(r)
+	defer cancel()
+	grpcTodo, err := t.grpcClient.GetTodo(ctx, &todomgrpb.TodoIdReq{
+		Id:    id,
+		Owner: owner,
+	})
+	if err == nil && t.todoCache != nil {
+		t.todoCache.put(owner, grpcTodo, generation, time.Now())
+	}
+	return grpcTodo, err
+}
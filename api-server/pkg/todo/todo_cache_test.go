@@ -0,0 +1,104 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+func TestTodoCachePut(t *testing.T) {
+	now := time.Now()
+	todo := &todomgrpb.Todo{Id: 1, Owner: "alice"}
+	for _, tc := range []struct {
+		name       string
+		invalidate func(c *todoCache)
+		cached     bool
+	}{
+		{"no invalidation", func(c *todoCache) {}, true},
+		{"other todo", func(c *todoCache) { c.invalidate(2) }, true},
+		{"other owner", func(c *todoCache) { c.invalidateOwner("bob") }, true},
+		{"same todo", func(c *todoCache) { c.invalidate(1) }, false},
+		{"same owner", func(c *todoCache) { c.invalidateOwner("alice") }, false},
+		{"all owners", func(c *todoCache) { c.invalidateOwner("") }, false},
+		{"too many invalidations to tell", func(c *todoCache) {
+			for i := 0; i <= todoCacheMaxInvalidations; i++ {
+				c.invalidate(2)
+			}
+		}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTodoCache(time.Minute)
+			c.invalidate(1)
+			generation := c.currentGeneration()
+			tc.invalidate(c)
+			c.put("alice", todo, generation, now)
+			if cached := c.get("alice", 1, now) != nil; cached != tc.cached {
+				t.Errorf("expected the todo to be cached: %v, got %v", tc.cached, cached)
+			}
+		})
+	}
+}
+
+// blockingGetClient is a fakeClient whose GetTodo signals got once it got the todo and waits for
+// release
+type blockingGetClient struct {
+	*fakeClient
+	got     chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingGetClient) GetTodo(ctx context.Context, in *todomgrpb.TodoIdReq, opts ...grpc.CallOption) (*todomgrpb.Todo, error) {
+	todo, err := c.fakeClient.GetTodo(ctx, in, opts...)
+	select {
+	case c.got <- struct{}{}:
+	default:
+	}
+	<-c.release
+	return todo, err
+}
+
+func TestTodoCacheOtherOwnersWrites(t *testing.T) {
+	client := &blockingGetClient{
+		fakeClient: newFakeClient(
+			&todomgrpb.Todo{Text: "alice's", Owner: "alice"},
+			&todomgrpb.Todo{Text: "bob's", Owner: "bob"},
+		),
+		got:     make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	router := newTestRouter(client, &RouterOptions{TodoCacheTTL: time.Minute})
+	defer router.Close()
+	handler := router.GetRouter()
+	alice, bob := asOwner("alice", handler), asOwner("bob", handler)
+
+	// bob updates his todo while alice's GetTodo is in flight
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		expectStatus(t, serve(alice, http.MethodGet, "/1", ""), http.StatusOK)
+	}()
+	<-client.got
+	expectStatus(t, serve(bob, http.MethodPut, "/2", `{"text": "edited"}`), http.StatusOK)
+	close(client.release)
+	<-done
+
+	expectStatus(t, serve(alice, http.MethodGet, "/1", ""), http.StatusOK)
+	if calls := client.callCount("GetTodo"); calls != 1 {
+		t.Errorf("expected alice's todo to be cached, got %d GetTodo calls", calls)
+	}
+
+	// alice's own writes invalidate it
+	expectStatus(t, serve(alice, http.MethodPut, "/1", `{"text": "edited"}`), http.StatusOK)
+	res := serve(alice, http.MethodGet, "/1", "")
+	expectStatus(t, res, http.StatusOK)
+	todo := &Todo{}
+	decodeJSONRes(t, res, todo)
+	if todo.Text != "edited" {
+		t.Errorf("expected the updated todo, got text %q", todo.Text)
+	}
+}
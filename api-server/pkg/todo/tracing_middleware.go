@@ -0,0 +1,33 @@
+package todo
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"go.opencensus.io/trace"
+)
+
+// ownerAttribute is the span attribute holding the owner of the todos
+const ownerAttribute = "todo.owner"
+
+// TracingMiddleware names the server span started by ochttp after the matched chi route pattern
+// instead of the raw path and adds the authenticated owner as an attribute. It has to be used
+// after the auth middleware and on a server already wrapped with ochttp.Handler.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.FromContext(r.Context())
+		if span == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		owner, ok := OwnerFromContext(r.Context())
+		if !ok {
+			owner = Username
+		}
+		span.AddAttributes(trace.StringAttribute(ownerAttribute, owner))
+		next.ServeHTTP(w, r)
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			span.SetName(r.Method + " " + rctx.RoutePattern())
+		}
+	})
+}
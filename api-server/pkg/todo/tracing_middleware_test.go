@@ -0,0 +1,99 @@
+package todo
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+	"google.golang.org/grpc/metadata"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+// spanRecorder is an in-memory trace exporter
+type spanRecorder struct {
+	lock  sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (e *spanRecorder) ExportSpan(span *trace.SpanData) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// span returns the recorded span named name, or nil
+func (e *spanRecorder) span(name string) *trace.SpanData {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, span := range e.spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := &spanRecorder{}
+	trace.RegisterExporter(recorder)
+	defer trace.UnregisterExporter(recorder)
+	sampled := trace.StartOptions{Sampler: trace.AlwaysSample()}
+
+	// the span context todo-manager gets in the metadata of the call
+	var received trace.SpanContext
+	var propagated bool
+	addr, server := startTestServer(t, &testServer{
+		getTodo: func(ctx context.Context, req *todomgrpb.TodoIdReq) (*todomgrpb.Todo, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			if values := md.Get("grpc-trace-bin"); len(values) > 0 {
+				received, propagated = propagation.FromBinary([]byte(values[0]))
+			}
+			return &todomgrpb.Todo{Id: req.Id, Text: "todo", Owner: req.Owner}, nil
+		},
+	})
+	defer server.Stop()
+	router, err := NewRouter(addr, testOptions(&RouterOptions{Insecure: true, TraceStartOptions: sampled}))
+	if err != nil {
+		t.Fatalf("can't create the router: %v", err)
+	}
+	defer router.Close()
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return &ochttp.Handler{Handler: next, StartOptions: sampled}
+	})
+	r.Route("/v1", func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r.WithContext(ContextWithOwner(r.Context(), "alice")))
+			})
+		})
+		r.Use(TracingMiddleware)
+		r.Mount("/todo", router.GetRouter())
+	})
+	expectStatus(t, serve(r, http.MethodGet, "/v1/todo/1", ""), http.StatusOK)
+
+	span := recorder.span("GET /v1/todo/{todoID}/")
+	if span == nil {
+		t.Fatalf("expected a server span named after the route, got %d other spans", len(recorder.spans))
+	}
+	if owner := span.Attributes[ownerAttribute]; owner != "alice" {
+		t.Errorf("expected the owner attribute alice, got %v", owner)
+	}
+	client := recorder.span("todo_mgr.TodoManager.GetTodo")
+	if client == nil {
+		t.Fatalf("expected a client span of the gRPC call")
+	}
+	if client.TraceID != span.TraceID || client.ParentSpanID != span.SpanID {
+		t.Errorf("expected the client span to be a child of the server span")
+	}
+	if !propagated || received.TraceID != span.TraceID || received.SpanID != client.SpanID {
+		t.Errorf("expected the span context of the client span in the gRPC metadata, got %v", received)
+	}
+}
@@ -0,0 +1,114 @@
+package todo
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+)
+
+// FieldError is the validation error of a single field of a request body
+type FieldError struct {
+	Field   string    `json:"field" xml:"field"`
+	Code    ErrorCode `json:"code" xml:"code"`
+	Message string    `json:"message" xml:"message"`
+}
+
+// ValidationErrors collects the validation errors of all the invalid fields of a request body
+type ValidationErrors []FieldError
+
+// Error joins the messages of all the field errors
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// add records err as the validation error of field, if it's not nil; the field errors of a nested
+// ValidationErrors are added with their field prefixed by field. Field errors get the code of the
+// top level field.
+func (e *ValidationErrors) add(field string, err error) {
+	if err == nil {
+		return
+	}
+	if nested, ok := err.(ValidationErrors); ok {
+		for _, fieldErr := range nested {
+			nestedField := field + "." + fieldErr.Field
+			*e = append(*e, FieldError{Field: nestedField, Code: fieldErrorCode(nestedField), Message: fieldErr.Message})
+		}
+		return
+	}
+	*e = append(*e, FieldError{Field: field, Code: fieldErrorCode(field), Message: err.Error()})
+}
+
+// err returns the collected errors, or nil if there are none
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidationErrRes is the response to requests with invalid fields, listing the errors of all of them
+type ValidationErrRes struct {
+	*middleware.ErrResponse
+	Errors ValidationErrors `json:"errors,omitempty" xml:"error,omitempty"`
+}
+
+// errValidation returns 422 Unprocessable Entity listing the field errors of a ValidationErrors; other
+// errors are returned as invalid requests
+func errValidation(err error) render.Renderer {
+	fieldErrs, ok := err.(ValidationErrors)
+	if !ok {
+		return middleware.ErrInvalidRequest(err)
+	}
+	return &ValidationErrRes{
+		ErrResponse: &middleware.ErrResponse{
+			Err:            withCode(CodeValidationFailed, err),
+			HTTPStatusCode: http.StatusUnprocessableEntity,
+			StatusText:     "Validation failed.",
+			ErrorText:      err.Error(),
+		},
+		Errors: fieldErrs,
+	}
+}
+
+// validateTodo checks all the fields of a todo, including the length of its text
+func (t *Router) validateTodo(data *Todo) error {
+	var errs ValidationErrors
+	if err := data.Validate(); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+	if data.Text != "" {
+		errs.add("text", t.checkTextLength(data.Text))
+	}
+	return errs.err()
+}
+
+// validateNewTodo checks all the fields of a todo to create, including its ID if the client set it
+func (t *Router) validateNewTodo(data *Todo) error {
+	var errs ValidationErrors
+	if err := t.validateTodo(data); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+	if data.ID != "" {
+		_, err := parseTodoID(data.ID)
+		errs.add("id", err)
+	}
+	return errs.err()
+}
+
+// validateTodoPatch checks all the fields present in a todo patch, including the length of its text
+func (t *Router) validateTodoPatch(data *TodoPatch) error {
+	var errs ValidationErrors
+	if err := data.Validate(); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+	if data.Text != nil && *data.Text != "" {
+		errs.add("text", t.checkTextLength(*data.Text))
+	}
+	return errs.err()
+}
@@ -0,0 +1,27 @@
+package todo
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// VersionRes is the response of the version endpoint, describing the running build
+type VersionRes struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+}
+
+// Render allows to modify the way VersionRes object is rendered to text; not used here
+func (v *VersionRes) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// NewVersionHandler returns a handler reporting the version, git commit and build date of the build
+func NewVersionHandler(version, commit, buildDate string) http.HandlerFunc {
+	res := &VersionRes{Version: version, Commit: commit, BuildDate: buildDate}
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.Render(w, r, res)
+	}
+}
@@ -0,0 +1,195 @@
+package todo
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// WebhookSignatureHeader carries the hex encoded HMAC-SHA256 of the webhook payload, computed with
+	// the webhook secret and prefixed with "sha256="
+	WebhookSignatureHeader = "X-Todo-Signature"
+	// WebhookEventHeader carries the type of the webhook event
+	WebhookEventHeader = "X-Todo-Event"
+
+	// webhookQueueSize is the max number of deliveries waiting for a worker; events are dropped
+	// when the queue is full, so that slow subscribers don't block requests
+	webhookQueueSize = 256
+	// webhookWorkers is the number of concurrent webhook deliveries
+	webhookWorkers = 4
+)
+
+// Types of webhook events, the same as the ones of the todo stream
+const (
+	WebhookEventCreated = "created"
+	WebhookEventUpdated = "updated"
+	WebhookEventDeleted = "deleted"
+)
+
+// WebhookEvent is the JSON payload POSTed to webhook subscribers; deleted todos carry only their ID
+type WebhookEvent struct {
+	Type  string `json:"type"`
+	Owner string `json:"owner"`
+	Todo  *Todo  `json:"todo"`
+	// Timestamp is the RFC3339 time the event happened
+	Timestamp string `json:"timestamp"`
+}
+
+// webhookDelivery is a payload waiting to be POSTed to a subscriber
+type webhookDelivery struct {
+	url       string
+	eventType string
+	body      []byte
+}
+
+// webhookDispatcher POSTs todo events to subscriber URLs asynchronously, retrying failed
+// deliveries with exponential backoff
+type webhookDispatcher struct {
+	urls        []string
+	secret      []byte
+	client      *http.Client
+	maxAttempts uint
+	backoff     time.Duration
+	logger      logrus.FieldLogger
+
+	queue     chan webhookDelivery
+	done      chan struct{}
+	closeLock sync.RWMutex
+	closed    bool
+	workers   sync.WaitGroup
+}
+
+// newWebhookDispatcher returns a dispatcher for the webhooks configured in options and starts its
+// workers; nil is returned if there are no subscribers
+func newWebhookDispatcher(options *RouterOptions) *webhookDispatcher {
+	if len(options.WebhookURLs) == 0 {
+		return nil
+	}
+	d := &webhookDispatcher{
+		urls:        options.WebhookURLs,
+		secret:      []byte(options.WebhookSecret),
+		client:      &http.Client{Timeout: options.WebhookTimeout},
+		maxAttempts: options.WebhookMaxAttempts,
+		backoff:     options.WebhookBackoff,
+		logger:      options.Logger,
+		queue:       make(chan webhookDelivery, webhookQueueSize),
+		done:        make(chan struct{}),
+	}
+	for i := 0; i < webhookWorkers; i++ {
+		d.workers.Add(1)
+		go d.run()
+	}
+	return d
+}
+
+// dispatch queues an event for delivery to all the subscribers; it never blocks
+func (d *webhookDispatcher) dispatch(eventType, owner string, todo *Todo) {
+	if d == nil {
+		return
+	}
+	body, err := json.Marshal(&WebhookEvent{
+		Type:      eventType,
+		Owner:     owner,
+		Todo:      todo,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		d.logger.WithError(err).Error("Error encoding webhook event")
+		return
+	}
+	d.closeLock.RLock()
+	defer d.closeLock.RUnlock()
+	if d.closed {
+		return
+	}
+	for _, url := range d.urls {
+		select {
+		case d.queue <- webhookDelivery{url: url, eventType: eventType, body: body}:
+		default:
+			d.logger.WithField("url", url).Warn("Webhook queue is full, dropping event")
+		}
+	}
+}
+
+// run delivers queued payloads until the dispatcher is closed
+func (d *webhookDispatcher) run() {
+	defer d.workers.Done()
+	for delivery := range d.queue {
+		if err := d.deliver(delivery); err != nil {
+			d.logger.WithField("url", delivery.url).WithError(err).Error("Webhook delivery failed")
+		}
+	}
+}
+
+// deliver POSTs a payload to its subscriber, retrying on network errors and 5xx or 429 responses;
+// retries are abandoned when the dispatcher is closed
+func (d *webhookDispatcher) deliver(delivery webhookDelivery) error {
+	var err error
+	for attempt := uint(0); attempt < d.maxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(d.backoff * time.Duration(1<<(attempt-1))):
+			case <-d.done:
+				return fmt.Errorf("dispatcher closed, last error: %v", err)
+			}
+		}
+		var retry bool
+		if retry, err = d.post(delivery); err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// post makes a single delivery attempt and reports if it can be retried when it fails
+func (d *webhookDispatcher) post(delivery webhookDelivery) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.url, bytes.NewReader(delivery.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.eventType)
+	req.Header.Set(WebhookSignatureHeader, signWebhookPayload(d.secret, delivery.body))
+	res, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("subscriber responded %d", res.StatusCode)
+}
+
+// close stops accepting events and waits for the queued ones to be delivered, without retries
+func (d *webhookDispatcher) close() {
+	if d == nil {
+		return
+	}
+	d.closeLock.Lock()
+	d.closed = true
+	close(d.done)
+	close(d.queue)
+	d.closeLock.Unlock()
+	d.workers.Wait()
+}
+
+// signWebhookPayload returns the value of WebhookSignatureHeader for a payload
+func signWebhookPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,104 @@
+package todo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// webhookRequest is a webhook delivery received by a subscriber
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookSubscriber returns a subscriber sending the deliveries it gets to the returned channel,
+// responding with the statuses in turn and then 200
+func webhookSubscriber(statuses ...int) (*httptest.Server, chan webhookRequest) {
+	requests := make(chan webhookRequest, 10)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- webhookRequest{header: r.Header, body: body}
+		if attempts < len(statuses) {
+			w.WriteHeader(statuses[attempts])
+		}
+		attempts++
+	}))
+	return server, requests
+}
+
+// nextWebhook returns the next delivery received by a subscriber, failing the test if there's none
+func nextWebhook(t *testing.T, requests chan webhookRequest) webhookRequest {
+	t.Helper()
+	select {
+	case req := <-requests:
+		return req
+	case <-time.After(time.Second):
+		t.Fatalf("expected a webhook delivery")
+		return webhookRequest{}
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	subscriber, requests := webhookSubscriber(http.StatusServiceUnavailable)
+	defer subscriber.Close()
+	router := newTestRouter(newFakeClient(), &RouterOptions{
+		WebhookURLs:    []string{subscriber.URL},
+		WebhookSecret:  "s3cret",
+		WebhookBackoff: time.Millisecond,
+	})
+	defer router.Close()
+
+	expectStatus(t, serve(router.GetRouter(), http.MethodPost, "/", `{"text": "new"}`), http.StatusCreated)
+
+	// the first delivery fails and is retried with the same payload
+	failed := nextWebhook(t, requests)
+	req := nextWebhook(t, requests)
+	if string(req.body) != string(failed.body) {
+		t.Errorf("expected the retry to deliver the same payload, got %s and %s", failed.body, req.body)
+	}
+	event := &WebhookEvent{}
+	if err := json.Unmarshal(req.body, event); err != nil {
+		t.Fatalf("webhook payload %q isn't valid JSON: %v", req.body, err)
+	}
+	if event.Type != WebhookEventCreated || event.Owner != Username || event.Todo == nil || event.Todo.ID != "1" || event.Todo.Text != "new" {
+		t.Errorf("expected a created event of the new todo, got %s", req.body)
+	}
+	if _, err := time.Parse(time.RFC3339, event.Timestamp); err != nil {
+		t.Errorf("expected an RFC3339 timestamp, got %q", event.Timestamp)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(req.body)
+	if signature := req.header.Get(WebhookSignatureHeader); signature != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("expected the HMAC-SHA256 of the payload as signature, got %q", signature)
+	}
+	if eventType := req.header.Get(WebhookEventHeader); eventType != WebhookEventCreated {
+		t.Errorf("expected event header %s, got %q", WebhookEventCreated, eventType)
+	}
+}
+
+func TestWebhooksNotRetried(t *testing.T) {
+	subscriber, requests := webhookSubscriber(http.StatusBadRequest)
+	defer subscriber.Close()
+	router := newTestRouter(newFakeClient(), &RouterOptions{
+		WebhookURLs:    []string{subscriber.URL},
+		WebhookBackoff: time.Millisecond,
+	})
+	defer router.Close()
+
+	expectStatus(t, serve(router.GetRouter(), http.MethodPost, "/", `{"text": "new"}`), http.StatusCreated)
+	nextWebhook(t, requests)
+	// retries would happen after 1ms
+	select {
+	case req := <-requests:
+		t.Errorf("expected deliveries rejected by the subscriber not to be retried, got %s", req.body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
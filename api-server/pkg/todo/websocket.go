@@ -0,0 +1,332 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/piontec/go-chi-middleware-server/pkg/server/middleware"
+	"golang.org/x/net/websocket"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/api-server/pkg/todo/proto"
+)
+
+const (
+	// wsPingInterval is the interval of the ping messages sent to WebSocket clients
+	wsPingInterval = 30 * time.Second
+	// wsPongWait is how long the server waits for a message from a WebSocket client, like the
+	// pong answering a ping, before closing the connection
+	wsPongWait = 2 * wsPingInterval
+	// wsWriteWait is the max time to send a message to a WebSocket client
+	wsWriteWait = 10 * time.Second
+)
+
+// The types of the messages of the WebSocket sync protocol; the events of the todos have the
+// types of StreamTodos: created, updated and deleted
+const (
+	// wsCreate, wsUpdate and wsDelete are the types of the edits sent by clients
+	wsCreate = "create"
+	wsUpdate = "update"
+	wsDelete = "delete"
+	// wsResult and wsError are the types of the result of an edit
+	wsResult = "result"
+	wsError  = "error"
+	// wsPing and wsPong keep the connection alive; they're sent by both sides
+	wsPing = "ping"
+	wsPong = "pong"
+)
+
+// errWSOwnerMismatch is returned for WebSocket edits of an owner other than the one of the connection
+var errWSOwnerMismatch = errors.New("the owner of the message isn't the one of the connection")
+
+// WSMessage is a message of the WebSocket sync protocol, sent as a JSON text frame
+type WSMessage struct {
+	Type string `json:"type"`
+	// Ref is set by clients in edits to match them with their result, which has the same Ref
+	Ref string `json:"ref,omitempty"`
+	// Owner is optional in edits; if it's set, it must be the owner of the connection
+	Owner string `json:"owner,omitempty"`
+	// ID is the ID of the todo of delete edits and of their results
+	ID string `json:"id,omitempty"`
+	// Todo is the todo of create and update edits, of their results and of events
+	Todo *Todo `json:"todo,omitempty"`
+	// Code, Error and Errors describe the error of an edit in messages of type error
+	Code   ErrorCode        `json:"code,omitempty"`
+	Error  string           `json:"error,omitempty"`
+	Errors ValidationErrors `json:"errors,omitempty"`
+}
+
+// isWebSocketUpgrade checks if r asks to upgrade the connection to WebSocket
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// SyncTodos upgrades the connection to WebSocket and syncs the todos of a user both ways: the events
+// of the todos are pushed like with StreamTodos, while the client sends edits creating, updating and
+// deleting todos, each answered with its result. The owner of every edit is the one of the request
+// upgrading the connection. The server sends a ping every wsPingInterval and closes connections
+// without messages from the client for wsPongWait, so clients have to answer pings with a pong.
+func (t *Router) SyncTodos(w http.ResponseWriter, r *http.Request) {
+	owner, ok := t.owner(w, r)
+	if !ok {
+		return
+	}
+	if !isWebSocketUpgrade(r) {
+		render.Render(w, r, middleware.ErrInvalidRequest(errors.New("WebSocket upgrade is required")))
+		return
+	}
+	if _, ok := w.(http.Hijacker); !ok {
+		render.Render(w, r, middleware.ErrInvalidRequest(errors.New("WebSocket is supported only over HTTP/1.1")))
+		return
+	}
+	server := websocket.Server{
+		Handshake: t.checkWebSocketOrigin,
+		Handler: func(ws *websocket.Conn) {
+			t.syncTodos(ws, r, owner)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// checkWebSocketOrigin rejects WebSocket handshakes from browsers on origins not allowed by
+// CORSAllowedOrigins; clients that aren't browsers don't send an Origin and are allowed
+func (t *Router) checkWebSocketOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	for _, allowed := range t.options.CORSAllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return nil
+		}
+	}
+	return errors.New("origin not allowed")
+}
+
+// wsConn serializes the messages sent over a WebSocket connection
+type wsConn struct {
+	ws   *websocket.Conn
+	lock sync.Mutex
+}
+
+// send sends msg as JSON, failing if the client doesn't receive it in wsWriteWait
+func (c *wsConn) send(msg *WSMessage) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ws.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return websocket.JSON.Send(c.ws, msg)
+}
+
+// sendError sends the error res of the edit with ref
+func (c *wsConn) sendError(ref string, res render.Renderer) error {
+	msg := &WSMessage{Type: wsError, Ref: ref, Code: CodeInternalError}
+	if body, ok := errorResBody(res); ok {
+		msg.Code, msg.Error, msg.Errors = body.Code, body.Error, body.Errors
+	}
+	return c.send(msg)
+}
+
+// syncTodos runs the WebSocket sync protocol on ws until the client disconnects, the event stream
+// of todo-manager fails or the server shuts down
+func (t *Router) syncTodos(ws *websocket.Conn, r *http.Request, owner string) {
+	ws.MaxPayloadBytes = int(t.options.MaxBodyBytes)
+	conn := &wsConn{ws: ws}
+	// the connection isn't watched by the HTTP server anymore, so the context is cancelled only
+	// when one of the goroutines below stops
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	events, err := t.grpcClient.WatchTodos(ctx, &todomgrpb.WatchTodosReq{Owner: owner})
+	if err == nil {
+		_, err = events.Header()
+	}
+	if err != nil {
+		t.logGRPCError(r, err)
+		conn.sendError("", errFromGRPC(err))
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		t.pushWebSocketEvents(ctx, conn, events)
+	}()
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		ping := time.NewTicker(wsPingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case <-ping.C:
+				if err := conn.send(&WSMessage{Type: wsPing}); err != nil {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+		case <-t.shutdown:
+			// clients reconnect to another instance
+		}
+		// sends the close frame and unblocks the reader
+		conn.lock.Lock()
+		ws.Close()
+		conn.lock.Unlock()
+	}()
+
+	for {
+		ws.SetReadDeadline(time.Now().Add(wsPongWait))
+		msg := &WSMessage{}
+		err := websocket.JSON.Receive(ws, msg)
+		switch err.(type) {
+		case nil:
+			err = t.handleWebSocketMessage(ctx, conn, r, owner, msg)
+		case *json.SyntaxError, *json.UnmarshalTypeError:
+			// the frame was read whole, the next one can still be received
+			err = conn.sendError(msg.Ref, middleware.ErrInvalidRequest(err))
+		default:
+			switch err {
+			case errInvalidUTF8JSON:
+				err = conn.sendError(msg.Ref, middleware.ErrInvalidRequest(err))
+			case websocket.ErrFrameTooLarge:
+				// the frame is skipped when receiving the next one
+				err = conn.sendError("", errRequestEntityTooLarge(err))
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	cancel()
+	wg.Wait()
+}
+
+// pushWebSocketEvents sends the events of the todos received from todo-manager until the stream ends
+func (t *Router) pushWebSocketEvents(ctx context.Context, conn *wsConn, events todomgrpb.TodoManager_WatchTodosClient) {
+	for {
+		event, err := events.Recv()
+		if err != nil {
+			if ctx.Err() == nil && err != io.EOF {
+				t.options.Logger.WithError(err).Error("todo-manager event stream failed")
+				conn.sendError("", errFromGRPC(err))
+			}
+			return
+		}
+		todo, _ := FromGRPCTodo(event.GetTodo())
+		if err := conn.send(&WSMessage{Type: strings.ToLower(event.GetType().String()), Todo: todo}); err != nil {
+			return
+		}
+	}
+}
+
+// handleWebSocketMessage answers a message received from a WebSocket client; errors of edits are
+// sent to the client, only errors sending messages are returned
+func (t *Router) handleWebSocketMessage(ctx context.Context, conn *wsConn, r *http.Request, owner string, msg *WSMessage) error {
+	switch msg.Type {
+	case wsPing:
+		return conn.send(&WSMessage{Type: wsPong, Ref: msg.Ref})
+	case wsPong:
+		// receiving it already extended the read deadline
+		return nil
+	case wsCreate, wsUpdate, wsDelete:
+		todo, errRes := t.applyWebSocketEdit(ctx, r, owner, msg)
+		if errRes != nil {
+			return conn.sendError(msg.Ref, errRes)
+		}
+		res := &WSMessage{Type: wsResult, Ref: msg.Ref, Todo: todo}
+		if todo == nil {
+			res.ID = msg.ID
+		}
+		return conn.send(res)
+	default:
+		return conn.sendError(msg.Ref, middleware.ErrInvalidRequest(errors.New("unknown message type "+msg.Type)))
+	}
+}
+
+// applyWebSocketEdit creates, updates or deletes a todo of owner as asked by msg, validated like
+// the requests of the REST endpoints; the changed todo is returned, nil for deletes
+func (t *Router) applyWebSocketEdit(ctx context.Context, r *http.Request, owner string, msg *WSMessage) (*Todo, render.Renderer) {
+	if t.ReadOnly() {
+		return nil, errServiceUnavailable(withCode(CodeReadOnly, errReadOnly))
+	}
+	if msg.Owner != "" && msg.Owner != owner {
+		return nil, errForbidden(errWSOwnerMismatch)
+	}
+	if msg.Type != wsDelete && msg.Todo == nil {
+		return nil, middleware.ErrInvalidRequest(errors.New("todo is required"))
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.callTimeout(ctx))
+	defer cancel()
+	switch msg.Type {
+	case wsCreate:
+		data := msg.Todo
+		data.Bind(r)
+		if err := t.validateNewTodo(data); err != nil {
+			return nil, errValidation(err)
+		}
+		if data.Priority == "" {
+			data.Priority = PriorityMedium
+		}
+		if data.ID == "" {
+			// todo-manager generates the ID
+			data.ID = "0"
+		}
+		grpcTodo, err := t.grpcClient.CreateTodo(ctx, data.ToGRPCTodo(owner))
+		if err != nil {
+			t.logGRPCError(r, err)
+			return nil, errFromGRPC(err)
+		}
+		todo, _ := FromGRPCTodo(grpcTodo)
+		t.createOneCounter.WithLabelValues(owner).Inc()
+		t.webhooks.dispatch(WebhookEventCreated, owner, todo)
+		return todo, nil
+	case wsUpdate:
+		data := msg.Todo
+		id, err := parseTodoID(data.ID)
+		if err != nil {
+			return nil, middleware.ErrInvalidRequest(err)
+		}
+		data.Bind(r)
+		if err := t.validateTodo(data); err != nil {
+			return nil, errValidation(err)
+		}
+		req := data.ToGRPCTodo(owner)
+		req.Id = id
+		grpcTodo, err := t.grpcClient.UpdateTodo(ctx, req)
+		if err != nil {
+			t.logGRPCError(r, err)
+			return nil, errFromGRPC(err)
+		}
+		todo, _ := FromGRPCTodo(grpcTodo)
+		t.updateOneCounter.WithLabelValues(owner).Inc()
+		t.webhooks.dispatch(WebhookEventUpdated, grpcTodo.GetOwner(), todo)
+		return todo, nil
+	default:
+		id, err := parseTodoID(msg.ID)
+		if err != nil {
+			return nil, middleware.ErrInvalidRequest(err)
+		}
+		// deleted todos are moved to the trash, like with DeleteTodo without the hard param
+		if _, err := t.grpcClient.DeleteTodo(ctx, &todomgrpb.DeleteTodoReq{Id: id, Owner: owner}); err != nil {
+			t.logGRPCError(r, err)
+			return nil, errFromGRPC(err)
+		}
+		t.deleteOneCounter.WithLabelValues(owner).Inc()
+		t.webhooks.dispatch(WebhookEventDeleted, owner, &Todo{ID: msg.ID})
+		return nil, nil
+	}
+}
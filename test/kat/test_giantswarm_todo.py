@@ -1,15 +1,29 @@
+import base64
+import csv
+import datetime
+import email.utils
+import io
 import json
 import logging
+import os
+import socket
+import ssl
+import struct
+import threading
+import time
+import urllib.parse
+import xml.etree.ElementTree as ET
 from typing import List, Dict
 
 import pytest
-from pykube import Service, Deployment
+from pykube import Service, Deployment, Pod
 from pytest_helm_charts.clusters import Cluster
 from pytest_helm_charts.utils import (
     wait_for_deployments_to_run,
     proxy_http_get,
     proxy_http_post,
     proxy_http_delete,
+    proxy_http_request,
 )
 from requests import Response
 
@@ -57,7 +71,7 @@ def test_get_todos(kube_cluster: Cluster, cluster_type: str, chart_extra_info: D
     )
     res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
     assert res is not None
-    assert res.content == b"null\n"
+    assert res.content.strip() == b"[]"
     assert res.status_code == 200
     assert "Content-Type" in res.headers
     assert res.headers["Content-Type"] == "application/json; charset=utf-8"
@@ -81,10 +95,2922 @@ def test_create_delete_todo_entry(kube_cluster: Cluster):
         headers=headers,
     )
     assert res is not None
-    assert res.status_code == 200
+    assert res.status_code == 201
     todo_id = json.loads(res.text)["id"]
+    assert res.headers["Location"].endswith(f"/v1/todo/{todo_id}")
     res = proxy_http_delete(
         kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}"
     )
     assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_archived_todo_hidden_from_list(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    body = '{"Text":"testing archive"}'
+    headers = {"Content-Type": "application/json"}
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=body,
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo/{todo_id}/archive",
+        data="",
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text)["archived"] is True
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+    assert todo_id not in [t["id"] for t in json.loads(res.text) or []]
+    res = proxy_http_get(
+        kube_cluster.kube_client, apiserver_service, "v1/todo?archived=true"
+    )
+    assert todo_id in [t["id"] for t in json.loads(res.text) or []]
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_add_toggle_subtask(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    body = '{"Text":"testing subtasks"}'
+    headers = {"Content-Type": "application/json"}
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=body,
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo/{todo_id}/subtasks",
+        data='{"text":"first step"}',
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text)["subtasks"] == [{"text": "first step", "done": False}]
+
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PATCH",
+        f"v1/todo/{todo_id}/subtasks/0?complete_parent=true",
+        data='{"done":true}',
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    todo = json.loads(res.text)
+    assert todo["subtasks"] == [{"text": "first step", "done": True}]
+    assert todo["done"] is True
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_weekly_recurrence_creates_next_occurrence(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    body = json.dumps(
+        {
+            "text": "testing weekly recurrence",
+            "due_date": "2030-01-07T10:00:00Z",
+            "recurrence": {"frequency": "weekly"},
+        }
+    )
+    headers = {"Content-Type": "application/json"}
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=body,
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PATCH",
+        f"v1/todo/{todo_id}",
+        data='{"done":true}',
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo?done=false")
+    next_todos = [
+        t
+        for t in json.loads(res.text) or []
+        if t["text"] == "testing weekly recurrence"
+    ]
+    assert len(next_todos) == 1
+    assert next_todos[0]["id"] != todo_id
+    assert next_todos[0]["due_date"] == "2030-01-14T10:00:00Z"
+    assert next_todos[0]["recurrence"] == {"frequency": "weekly"}
+
+    for id in [todo_id, next_todos[0]["id"]]:
+        res = proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{id}?hard=true"
+        )
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_add_list_comments(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    body = '{"Text":"testing comments"}'
+    headers = {"Content-Type": "application/json"}
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=body,
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    for text in ["first note", "second note"]:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            f"v1/todo/{todo_id}/comments",
+            data=json.dumps({"text": text}),
+            headers=headers,
+        )
+        assert res is not None
+        assert res.status_code == 201
+        assert json.loads(res.text)["text"] == text
+
+    res = proxy_http_get(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}/comments"
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["X-Total-Count"] == "2"
+    assert [c["text"] for c in json.loads(res.text)] == ["second note", "first note"]
+
+    res = proxy_http_get(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo/{todo_id}/comments?limit=1&offset=1",
+    )
+    assert [c["text"] for c in json.loads(res.text)] == ["first note"]
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_export_csv(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    body = '{"Text":"testing, \\"export\\""}'
+    headers = {"Content-Type": "application/json"}
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=body,
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    res = proxy_http_get(
+        kube_cluster.kube_client, apiserver_service, "v1/todo/export?format=csv"
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["Content-Type"].startswith("text/csv")
+    assert res.headers["Content-Disposition"].startswith("attachment;")
+    rows = list(csv.reader(io.StringIO(res.text)))
+    assert rows[0] == ["id", "text", "done", "priority", "due_date", "created_at"]
+    exported = [row for row in rows[1:] if row[0] == todo_id]
+    assert len(exported) == 1
+    assert exported[0][1:4] == ['testing, "export"', "false", "medium"]
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_import_csv(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    content = "text,done,priority\nimported one,false,high\nimported two,true,low\n"
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo/import",
+        files={"file": ("todos.csv", content, "text/csv")},
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text) == {"imported": 2, "failed": []}
+
+    content = "text,done\nimported three,false\n,false\nimported four,maybe\n"
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo/import",
+        files={"file": ("todos.csv", content, "text/csv")},
+    )
+    assert res is not None
+    assert res.status_code == 200
+    summary = json.loads(res.text)
+    assert summary["imported"] == 1
+    assert [f["line"] for f in summary["failed"]] == [3, 4]
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+    imported = [
+        t for t in json.loads(res.text) or [] if t["text"].startswith("imported ")
+    ]
+    assert sorted(t["text"] for t in imported) == [
+        "imported one",
+        "imported three",
+        "imported two",
+    ]
+    for t in imported:
+        res = proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{t['id']}?hard=true"
+        )
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_xml_responses(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    xml_accept = {"Accept": "application/xml"}
+    headers = {"Content-Type": "application/json", **xml_accept}
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": "testing XML", "tags": ["xml"]}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    assert res.headers["Content-Type"].startswith("application/xml")
+    created = ET.fromstring(res.text)
+    assert created.tag == "todo"
+    assert created.findtext("text") == "testing XML"
+    assert created.findtext("done") == "false"
+    assert [t.text for t in created.findall("tag")] == ["xml"]
+    todo_id = created.findtext("id")
+
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "GET",
+        f"v1/todo/{todo_id}",
+        headers=xml_accept,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert ET.fromstring(res.text).findtext("text") == "testing XML"
+
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "GET", "v1/todo", headers=xml_accept
+    )
+    assert res is not None
+    assert res.status_code == 200
+    listed = ET.fromstring(res.text)
+    assert listed.tag == "list"
+    assert todo_id in [t.findtext("id") for t in listed.findall("todo")]
+
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "GET",
+        "v1/todo/abc",
+        headers=xml_accept,
+    )
+    assert res is not None
+    assert res.status_code == 400
+    assert ET.fromstring(res.text).tag == "error"
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_openapi_spec(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "openapi.json")
+    assert res is not None
+    assert res.status_code == 200
+    spec = json.loads(res.text)
+    assert spec["openapi"].startswith("3.")
+    assert spec["servers"] == [{"url": "/v1/todo"}]
+    operations = {
+        (method, path): op["operationId"]
+        for path, ops in spec["paths"].items()
+        for method, op in ops.items()
+    }
+    assert operations[("get", "/")] == "listTodos"
+    assert operations[("post", "/")] == "createTodo"
+    assert operations[("get", "/{todoID}")] == "getTodo"
+    assert operations[("put", "/{todoID}")] == "updateTodo"
+    assert operations[("delete", "/{todoID}")] == "deleteTodo"
+    assert "Todo" in spec["components"]["schemas"]
+    assert "ErrResponse" in spec["components"]["schemas"]
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_head_todo(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data='{"text":"testing HEAD"}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+    assert res is not None
     assert res.status_code == 200
+    etag = res.headers["ETag"]
+    length = len(res.content)
+
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "HEAD", f"v1/todo/{todo_id}"
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert res.content == b""
+    assert res.headers["ETag"] == etag
+    assert res.headers["Content-Length"] == str(length)
+
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "HEAD", "v1/todo/999999999"
+    )
+    assert res is not None
+    assert res.status_code == 404
+    assert res.content == b""
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_max_text_length(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    headers = {"Content-Type": "application/json"}
+    # the default limit is 1000 characters, counted as runes and not bytes
+    at_limit = "ż" * 1000
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": at_limit}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo = json.loads(res.text)
+    assert todo["text"] == at_limit
+
+    for method in ["POST", "PUT", "PATCH"]:
+        path = "v1/todo" if method == "POST" else f"v1/todo/{todo['id']}"
+        res = proxy_http_request(
+            kube_cluster.kube_client,
+            apiserver_service,
+            method,
+            path,
+            data=json.dumps({"text": at_limit + "a"}),
+            headers=headers,
+        )
+        assert res is not None
+        assert res.status_code == 422
+        assert "1000 characters" in json.loads(res.text)["error"]
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo['id']}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_request_body_too_large(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    # the default limit is 1 MiB
+    body = json.dumps({"text": "x" * (2 << 20)})
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=body,
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 413
+    assert res.headers["Content-Type"].startswith("application/json")
+    assert json.loads(res.text)["status"] == "Request entity too large."
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_invalid_todo_ids(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    headers = {"Content-Type": "application/json"}
+    for todo_id in ["0", "-5", "99999999999999999999999"]:
+        for method in ["GET", "PUT", "PATCH", "DELETE"]:
+            res = proxy_http_request(
+                kube_cluster.kube_client,
+                apiserver_service,
+                method,
+                f"v1/todo/{todo_id}",
+                data='{"text":"testing IDs"}' if method in ["PUT", "PATCH"] else None,
+                headers=headers,
+            )
+            assert res is not None
+            assert res.status_code == 400, f"{method} {todo_id}"
+            assert "positive integer" in json.loads(res.text)["error"]
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_list_json_array_and_ndjson(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    headers = {"Content-Type": "application/json"}
+    ids = []
+    for text in ["testing list one", "testing list two"]:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": text}),
+            headers=headers,
+        )
+        assert res is not None
+        assert res.status_code == 201
+        ids.append(json.loads(res.text)["id"])
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+    assert res is not None
+    assert res.status_code == 200
+    todos = json.loads(res.text)
+    assert isinstance(todos, list)
+    assert set(ids) <= {t["id"] for t in todos}
+
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "GET",
+        "v1/todo",
+        headers={"Accept": "application/x-ndjson"},
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["Content-Type"] == "application/x-ndjson"
+    assert res.text.endswith("\n")
+    lines = res.text.splitlines()
+    assert [json.loads(line)["id"] for line in lines] == [t["id"] for t in todos]
+
+    for todo_id in ids:
+        res = proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+        )
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_list_is_json_array(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data='{"text":"testing array"}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    for path, expected in [
+        ("v1/todo/search?q=testing%20array", [todo_id]),
+        ("v1/todo/search?q=no-todo-has-this-text", []),
+    ]:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, path)
+        assert res is not None
+        assert res.status_code == 200
+        todos = json.loads(res.text)
+        assert isinstance(todos, list)
+        assert [t["id"] for t in todos] == expected
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_options_allow(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "OPTIONS", "v1/todo"
+    )
+    assert res is not None
+    assert res.status_code == 204
+    assert res.headers["Allow"] == "GET, POST, OPTIONS"
+
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "OPTIONS", "v1/todo/1"
+    )
+    assert res is not None
+    assert res.status_code == 204
+    allowed = [m.strip() for m in res.headers["Allow"].split(",")]
+    for method in ["GET", "PUT", "PATCH", "DELETE", "OPTIONS"]:
+        assert method in allowed
+    assert "POST" not in allowed
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_method_not_allowed(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo/1",
+        data='{"text":"wrong method"}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 405
+    allowed = [m.strip() for m in res.headers["Allow"].split(",")]
+    for method in ["GET", "PUT", "PATCH", "DELETE"]:
+        assert method in allowed
+    assert "POST" not in allowed
+    assert json.loads(res.text)["status"] == "Method not allowed."
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_unknown_path(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo/1/unknown")
+    assert res is not None
+    assert res.status_code == 404
+    assert json.loads(res.text)["status"] == "Resource not found."
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_page_size_clamped(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo?limit=1")
+    assert res is not None
+    assert res.status_code == 200
+    assert "X-Limit-Clamped" not in res.headers
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo?limit=1000000")
+    assert res is not None
+    assert res.status_code == 200
+    max_page_size = int(res.headers["X-Limit-Clamped"])
+    assert 0 < max_page_size < 1000000
+    assert len(json.loads(res.text)) <= max_page_size
+
+    res = proxy_http_get(
+        kube_cluster.kube_client, apiserver_service, "v1/todo/search?q=x&limit=1000000"
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["X-Limit-Clamped"] == str(max_page_size)
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_graphql(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    mutation = {
+        "query": "mutation($todo: TodoInput!) { createTodo(todo: $todo) { id text done priority } }",
+        "variables": {"todo": {"text": "testing GraphQL", "tags": ["graphql"]}},
+    }
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/graphql",
+        data=json.dumps(mutation),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 200
+    body = json.loads(res.text)
+    assert "errors" not in body
+    todo = body["data"]["createTodo"]
+    assert todo["text"] == "testing GraphQL"
+    assert todo["done"] is False
+    assert todo["priority"] == "medium"
+
+    query = {
+        "query": "query($id: ID!) { todo(id: $id) { id text tags } todos { id } }",
+        "variables": {"id": todo["id"]},
+    }
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/graphql",
+        data=json.dumps(query),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 200
+    body = json.loads(res.text)
+    assert "errors" not in body
+    assert body["data"]["todo"] == {"id": todo["id"], "text": "testing GraphQL", "tags": ["graphql"]}
+    assert todo["id"] in [t["id"] for t in body["data"]["todos"]]
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo['id']}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_debug_routes(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "_routes")
+    assert res is not None
+    if res.status_code == 404:
+        pytest.skip("debug routes are not enabled with apiserverDebugRoutesEnabled")
+    assert res.status_code == 200
+    routes = {(route["method"], route["pattern"]) for route in json.loads(res.text)}
+    for route in [
+        ("GET", "/v1/todo/"),
+        ("POST", "/v1/todo/"),
+        ("GET", "/v1/todo/{todoID}/"),
+        ("PUT", "/v1/todo/{todoID}/"),
+        ("DELETE", "/v1/todo/{todoID}/"),
+    ]:
+        assert route in routes
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_move_todo(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    ids = []
+    for text in ["testing move a", "testing move b", "testing move c"]:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": text}),
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code == 201
+        ids.append(json.loads(res.text)["id"])
+
+    def listed_order():
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo?limit=1000")
+        assert res is not None
+        assert res.status_code == 200
+        return [t["id"] for t in json.loads(res.text) if t["id"] in ids]
+
+    assert listed_order() == ids
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo/{ids[2]}/move",
+        data='{"position":0}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert listed_order() == [ids[2], ids[0], ids[1]]
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo/{ids[0]}/move",
+        data='{"position":1000000}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert listed_order() == [ids[2], ids[1], ids[0]]
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo/{ids[0]}/move",
+        data='{"position":-1}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 400
+
+    for todo_id in ids:
+        res = proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+        )
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_batch_complete(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    tag = "batch-complete"
+    ids = []
+    for text in ["testing complete a", "testing complete b"]:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": text, "tags": [tag]}),
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code == 201
+        ids.append(json.loads(res.text)["id"])
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo/batch/complete?tag={tag}&done=false",
+        data="",
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text)["count"] == 2
+    for todo_id in ids:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+        assert res is not None
+        assert json.loads(res.text)["done"] is True
+
+    # todos already done aren't counted again
+    res = proxy_http_post(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/batch/complete?tag={tag}", data=""
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text)["count"] == 0
+
+    res = proxy_http_post(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/batch/uncomplete?tag={tag}", data=""
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text)["count"] == 2
+    for todo_id in ids:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+        assert res is not None
+        assert json.loads(res.text)["done"] is False
+
+    for todo_id in ids:
+        res = proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+        )
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_graceful_shutdown(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    results: Dict[str, Response] = {}
+
+    def stream():
+        results["stream"] = proxy_http_get(
+            kube_cluster.kube_client, apiserver_service, "v1/todo/stream"
+        )
+
+    # the event stream is in flight until the apiserver shuts down
+    streaming = threading.Thread(target=stream)
+    streaming.start()
+    time.sleep(3)
+    pods = Pod.objects(kube_cluster.kube_client).filter(
+        namespace="default", selector={"app.kubernetes.io/name": "apiserver"}
+    )
+    for pod in pods:
+        pod.delete()
+    streaming.join(timeout=todo_timeout)
+    assert not streaming.is_alive()
+
+    # SIGTERM ends the stream cleanly instead of cutting the connection
+    res = results["stream"]
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["Content-Type"].startswith("text/event-stream")
+
+    wait_for_deployments_to_run(
+        kube_cluster.kube_client, ["apiserver"], "default", todo_timeout, missing_ok=False
+    )
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_created_at_range(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data='{"Text": "created in range"}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    def rfc3339(t: datetime.datetime) -> str:
+        return t.strftime("%Y-%m-%dT%H:%M:%SZ")
+
+    # a day of margin on both sides accounts for clock skew with the cluster
+    now = datetime.datetime.utcnow()
+    day = datetime.timedelta(days=1)
+    res = proxy_http_get(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo?created_after={rfc3339(now - day)}&created_before={rfc3339(now + day)}",
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert todo_id in [t["id"] for t in json.loads(res.text)]
+
+    res = proxy_http_get(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo?created_before={rfc3339(now - day)}"
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert todo_id not in [t["id"] for t in json.loads(res.text)]
+
+    # an inverted range is rejected
+    res = proxy_http_get(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo?created_after={rfc3339(now + day)}&created_before={rfc3339(now - day)}",
+    )
+    assert res is not None
+    assert res.status_code == 400
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_validation_errors(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data='{"text": "", "due_date": "tomorrow"}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 422
+    errors = json.loads(res.text)["errors"]
+    assert [e["field"] for e in errors] == ["text", "due_date"]
+    assert all(e["message"] for e in errors)
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_version(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "version")
+    assert res is not None
+    assert res.status_code == 200
+    # dev builds report the default values, but the fields are always there
+    build = json.loads(res.text)
+    assert set(build.keys()) == {"version", "commit", "build_date"}
+    assert all(isinstance(v, str) and v for v in build.values())
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_if_modified_since(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data='{"text": "cached by date"}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+    assert res is not None
+    assert res.status_code == 200
+    last_modified = res.headers["Last-Modified"]
+    modified = email.utils.parsedate_to_datetime(last_modified)
+
+    # not modified since the Last-Modified date, nor since a later date
+    later = email.utils.format_datetime(modified + datetime.timedelta(hours=1), usegmt=True)
+    for since in [last_modified, later]:
+        res = proxy_http_request(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "GET",
+            f"v1/todo/{todo_id}",
+            headers={"If-Modified-Since": since},
+        )
+        assert res is not None
+        assert res.status_code == 304
+
+    earlier = email.utils.format_datetime(modified - datetime.timedelta(seconds=1), usegmt=True)
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "GET",
+        f"v1/todo/{todo_id}",
+        headers={"If-Modified-Since": earlier},
+    )
+    assert res is not None
+    assert res.status_code == 200
+
+    # If-None-Match takes precedence over If-Modified-Since
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "GET",
+        f"v1/todo/{todo_id}",
+        headers={"If-None-Match": '"999999"', "If-Modified-Since": last_modified},
+    )
+    assert res is not None
+    assert res.status_code == 200
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_readiness_reports_connection_state(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "readyz")
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text)["connection"] == "READY"
+
+    todomanager = Deployment.objects(kube_cluster.kube_client).filter(namespace="default").get(
+        name="todomanager"
+    )
+    replicas = todomanager.replicas
+    todomanager.scale(0)
+    try:
+        # the apiserver reports the failing connection once todo-manager is gone, until its
+        # readiness probe fails and the service proxy answers instead
+        deadline = time.time() + todo_timeout
+        while time.time() < deadline:
+            res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "readyz")
+            if res is not None and res.status_code == 503 and b'"connection"' in res.content:
+                break
+            time.sleep(2)
+        assert res is not None
+        assert res.status_code == 503
+        assert json.loads(res.text)["connection"] != "READY"
+    finally:
+        todomanager.scale(replicas)
+
+    wait_for_deployments_to_run(
+        kube_cluster.kube_client, ["todomanager"], "default", todo_timeout, missing_ok=False
+    )
+    deadline = time.time() + todo_timeout
+    while time.time() < deadline:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "readyz")
+        if res is not None and res.status_code == 200:
+            break
+        time.sleep(2)
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text)["connection"] == "READY"
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_dedupe_create(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    ids = set()
+
+    def create(text: str) -> Response:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": text}),
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code in [200, 201]
+        ids.add(json.loads(res.text)["id"])
+        return res
+
+    try:
+        first = create("dedupe me")
+        assert first.status_code == 201
+        # different texts are never deduplicated
+        other = create("dedupe me too")
+        assert other.status_code == 201
+        assert json.loads(other.text)["id"] != json.loads(first.text)["id"]
+
+        second = create("dedupe me")
+        if second.status_code == 201:
+            pytest.skip("dedupe is disabled, set apiserverDedupeWindow to enable it")
+        assert second.headers["X-Deduplicated"] == "true"
+        assert json.loads(second.text)["id"] == json.loads(first.text)["id"]
+    finally:
+        for todo_id in ids:
+            proxy_http_delete(
+                kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+            )
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_replace_todos(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    headers = {"Content-Type": "application/json"}
+    ids = []
+    for text in ["replace kept", "replace deleted"]:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": text}),
+            headers=headers,
+        )
+        assert res is not None
+        assert res.status_code == 201
+        ids.append(json.loads(res.text)["id"])
+    kept_id, deleted_id = ids
+    payload = json.dumps([{"id": kept_id, "text": "replace updated"}, {"text": "replace created"}])
+
+    # the destructive replace has to be confirmed
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "PUT", "v1/todo", data=payload, headers=headers
+    )
+    assert res is not None
+    assert res.status_code == 400
+
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PUT",
+        "v1/todo?replace=true",
+        data=payload,
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    summary = json.loads(res.text)
+    assert summary["updated"] == [kept_id]
+    assert len(summary["created"]) == 1
+    assert deleted_id in summary["deleted"]
+    created_id = summary["created"][0]
+    ids.append(created_id)
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+    assert res is not None
+    todos = {t["id"]: t["text"] for t in json.loads(res.text)}
+    assert todos == {kept_id: "replace updated", created_id: "replace created"}
+
+    # unknown IDs are rejected without changing anything
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PUT",
+        "v1/todo?replace=true",
+        data=json.dumps([{"id": deleted_id, "text": "replace restored"}]),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 400
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+    assert res is not None
+    assert len(json.loads(res.text)) == 2
+
+    for todo_id in ids:
+        res = proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+        )
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_request_timeout(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    # timeouts are honored when they're shorter than the default call timeout, and clamped to it otherwise
+    for timeout, status in [("3s", 200), ("1h", 200), ("1ns", 504), ("soon", 400), ("-1s", 400)]:
+        res = proxy_http_request(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "GET",
+            "v1/todo",
+            headers={"X-Request-Timeout": timeout},
+        )
+        assert res is not None
+        assert res.status_code == status, timeout
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_merge_patch(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": "merge patch", "due_date": "2030-01-01T00:00:00Z", "tags": ["merge"]}),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+    headers = {"Content-Type": "application/merge-patch+json"}
+
+    # null clears a field
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PATCH",
+        f"v1/todo/{todo_id}",
+        data=json.dumps({"due_date": None}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["Accept-Patch"] == "application/merge-patch+json"
+    todo = json.loads(res.text)
+    assert "due_date" not in todo
+    assert todo["tags"] == ["merge"]
+
+    # absent keys are left untouched and unknown ones are ignored
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PATCH",
+        f"v1/todo/{todo_id}",
+        data=json.dumps({"done": True, "unknown": 1}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    todo = json.loads(res.text)
+    assert todo["done"] is True
+    assert todo["text"] == "merge patch"
+    assert todo["tags"] == ["merge"]
+
+    # the text can't be cleared
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PATCH",
+        f"v1/todo/{todo_id}",
+        data=json.dumps({"text": None}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 422
+
+    res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_retry_after_when_unavailable(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    todomanager = Deployment.objects(kube_cluster.kube_client).filter(namespace="default").get(
+        name="todomanager"
+    )
+    replicas = todomanager.replicas
+    todomanager.scale(0)
+    try:
+        # calls to todo-manager fail with Unavailable once it's gone, until the readiness probe of
+        # the apiserver fails and the service proxy answers instead
+        deadline = time.time() + todo_timeout
+        while time.time() < deadline:
+            res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+            if res is not None and res.status_code == 503 and b'"status"' in res.content:
+                break
+            time.sleep(2)
+        assert res is not None
+        assert res.status_code == 503
+        assert int(res.headers["Retry-After"]) > 0
+    finally:
+        todomanager.scale(replicas)
+
+    wait_for_deployments_to_run(
+        kube_cluster.kube_client, ["todomanager"], "default", todo_timeout, missing_ok=False
+    )
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_retry_after_when_rate_limited(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    for _ in range(50):
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+        assert res is not None
+        if res.status_code == 429:
+            break
+    else:
+        pytest.skip("rate limiting is disabled")
+    assert int(res.headers["Retry-After"]) > 0
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_dry_run(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    headers = {"Content-Type": "application/json"}
+
+    # a dry run of a create shows the server-set defaults without creating the todo
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo?dry_run=true",
+        data=json.dumps({"text": "dry run create", "tags": [" Dry ", "dry"]}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["X-Dry-Run"] == "true"
+    preview = json.loads(res.text)
+    assert preview["priority"] == "medium"
+    assert preview["tags"] == ["dry"]
+    assert preview["created_at"]
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+    assert res is not None
+    assert "dry run create" not in [t["text"] for t in json.loads(res.text)]
+
+    # invalid todos are rejected like without dry_run
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo?dry_run=true",
+        data=json.dumps({"text": ""}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 422
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": "dry run update"}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+    etag = res.headers["ETag"]
+
+    # a dry run of an update doesn't change the todo
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PUT",
+        f"v1/todo/{todo_id}?dry_run=true",
+        data=json.dumps({"text": "dry run updated", "priority": "high"}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["X-Dry-Run"] == "true"
+    preview = json.loads(res.text)
+    assert preview["id"] == todo_id
+    assert preview["text"] == "dry run updated"
+    assert preview["priority"] == "high"
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["ETag"] == etag
+    assert json.loads(res.text)["text"] == "dry run update"
+
+    res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_sparse_fieldsets(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": "sparse fieldsets", "tags": ["sparse"], "subtasks": [{"text": "sub", "done": False}]}),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?fields=id,text,done")
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text) == {"id": todo_id, "text": "sparse fieldsets", "done": False}
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo?fields=id,text,done")
+    assert res is not None
+    assert res.status_code == 200
+    todos = json.loads(res.text)
+    assert todos
+    for todo in todos:
+        assert set(todo.keys()) == {"id", "text", "done"}
+
+    # unknown fields are rejected
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo?fields=id,comments")
+    assert res is not None
+    assert res.status_code == 400
+
+    res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_basic_auth(kube_cluster: Cluster):
+    # the chart has to be deployed with apiserverBasicAuthSecret, listing these credentials
+    credentials = os.environ.get("KAT_BASIC_AUTH_CREDENTIALS", "")
+    if ":" not in credentials:
+        pytest.skip("KAT_BASIC_AUTH_CREDENTIALS isn't set to the username:password of a basic auth user")
+    username, password = credentials.split(":", 1)
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+
+    def get_todos(user: str, pwd: str) -> Response:
+        token = base64.b64encode(f"{user}:{pwd}".encode()).decode()
+        res = proxy_http_request(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "GET",
+            "v1/todo",
+            headers={"Authorization": f"Basic {token}"},
+        )
+        assert res is not None
+        return res
+
+    assert get_todos(username, password).status_code == 200
+    for user, pwd in [(username, password + "x"), (username + "x", password)]:
+        res = get_todos(user, pwd)
+        assert res.status_code == 401
+        assert res.headers["WWW-Authenticate"].startswith("Basic ")
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+    assert res is not None
+    assert res.status_code == 401
+    assert res.headers["WWW-Authenticate"].startswith("Basic ")
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_api_key_auth(kube_cluster: Cluster):
+    # the chart has to be deployed with apiserverAPIKeysSecret, listing this key
+    api_key = os.environ.get("KAT_API_KEY", "")
+    if not api_key:
+        pytest.skip("KAT_API_KEY isn't set to an API key known to the apiserver")
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    for key, status in [(api_key, 200), (api_key + "x", 401)]:
+        res = proxy_http_request(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "GET",
+            "v1/todo",
+            headers={"X-API-Key": key},
+        )
+        assert res is not None
+        assert res.status_code == status
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+    assert res is not None
+    assert res.status_code == 401
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_read_only_mode(kube_cluster: Cluster):
+    # the chart has to be deployed with apiserverAdminTokenSecret, holding this token
+    admin_token = os.environ.get("KAT_ADMIN_TOKEN", "")
+    if not admin_token:
+        pytest.skip("KAT_ADMIN_TOKEN isn't set to the admin token of the apiserver")
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    admin_headers = {"Authorization": f"Bearer {admin_token}", "Content-Type": "application/json"}
+
+    def set_read_only(read_only: bool) -> None:
+        res = proxy_http_request(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "PUT",
+            "admin/read-only",
+            data=json.dumps({"read_only": read_only}),
+            headers=admin_headers,
+        )
+        assert res is not None
+        assert res.status_code == 200
+        assert json.loads(res.text) == {"read_only": read_only}
+
+    # the admin endpoint requires the admin token
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PUT",
+        "admin/read-only",
+        data=json.dumps({"read_only": True}),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 401
+
+    set_read_only(True)
+    try:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+        assert res is not None
+        assert res.status_code == 200
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": "read-only mode"}),
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code == 503
+        assert "read-only" in json.loads(res.text)["error"]
+    finally:
+        set_read_only(False)
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": "read-only mode"}),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+    res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+    assert res is not None
+    assert res.status_code == 204
+
+
+def parse_link_header(header: str) -> Dict[str, Dict[str, List[str]]]:
+    links = {}
+    for link in header.split(","):
+        url, rel = link.split(";")
+        url = url.strip()[1:-1]
+        rel = rel.strip()[len('rel="'):-1]
+        links[rel] = urllib.parse.parse_qs(urllib.parse.urlparse(url).query)
+    return links
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_pagination_link_header(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    ids = []
+    for i in range(5):
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": f"link header {i}", "tags": ["link-header"]}),
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code == 201
+        ids.append(json.loads(res.text)["id"])
+
+    def links(offset: int) -> Dict[str, Dict[str, List[str]]]:
+        res = proxy_http_get(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo?tag=link-header&limit=2&offset={offset}"
+        )
+        assert res is not None
+        assert res.status_code == 200
+        assert res.headers["X-Total-Count"] == "5"
+        parsed = parse_link_header(res.headers["Link"])
+        # the filters are kept in all the links
+        for query in parsed.values():
+            assert query["tag"] == ["link-header"]
+            assert query["limit"] == ["2"]
+        return {rel: query["offset"][0] for rel, query in parsed.items()}
+
+    assert links(0) == {"first": "0", "next": "2", "last": "4"}
+    assert links(2) == {"first": "0", "prev": "0", "next": "4", "last": "4"}
+    assert links(4) == {"first": "0", "prev": "2", "last": "4"}
+
+    for todo_id in ids:
+        res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_handler_timeout(kube_cluster: Cluster):
+    # the chart has to be deployed with apiserverHandlerTimeout set to a duration shorter than any
+    # call to todo-manager, like "1ms", and KAT_HANDLER_TIMEOUT set to it
+    if not os.environ.get("KAT_HANDLER_TIMEOUT", ""):
+        pytest.skip("KAT_HANDLER_TIMEOUT isn't set to the short handler timeout of the apiserver")
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    # handlers that don't stream their response are cut off with 503
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo/count")
+    assert res is not None
+    assert res.status_code == 503
+    assert int(res.headers["Retry-After"]) > 0
+    assert json.loads(res.text)["error"] == "the request took too long to be served"
+
+    # listing todos is streamed, so it's exempt
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo")
+    assert res is not None
+    assert res.status_code == 200
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_text_utf8_normalization(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    headers = {"Content-Type": "application/json"}
+
+    # invalid UTF-8 byte sequences are rejected before they reach todo-manager
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=b'{"text": "broken \xff\xfe text"}',
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 400
+
+    # the text is trimmed and converted to NFC, so a combining acute accent becomes a precomposed é
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": "  Cafe\u0301 visit \n"}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo = json.loads(res.text)
+    assert todo["text"] == "Caf\u00e9 visit"
+
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PATCH",
+        f"v1/todo/{todo['id']}",
+        data=json.dumps({"text": "Re\u0301sume\u0301 "}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text)["text"] == "R\u00e9sum\u00e9"
+
+    res = proxy_http_request(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "PATCH",
+        f"v1/todo/{todo['id']}",
+        data=b'{"text": "\xc3\x28"}',
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 400
+
+    res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo['id']}?hard=true")
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_batch_get(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    ids = []
+    for text in ["pinned one", "pinned two"]:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": text}),
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code == 201
+        ids.append(json.loads(res.text)["id"])
+
+    # missing IDs are marked as not found in place, the order of the IDs is kept
+    missing_id = "999999999"
+    requested = [ids[1], missing_id, ids[0]]
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/batch?ids={','.join(requested)}")
+    assert res is not None
+    assert res.status_code == 200
+    todos = json.loads(res.text)["todos"]
+    assert [todo["id"] for todo in todos] == requested
+    assert todos[0]["todo"]["text"] == "pinned two"
+    assert todos[1] == {"id": missing_id, "not_found": True}
+    assert todos[2]["todo"]["text"] == "pinned one"
+
+    for path in ["v1/todo/batch", "v1/todo/batch?ids=1,abc"]:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, path)
+        assert res is not None
+        assert res.status_code == 400
+
+    for todo_id in ids:
+        res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_cursor_pagination(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    tag = f"cursor-{int(time.time())}"
+
+    def create(text: str) -> str:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": text, "tags": [tag]}),
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code == 201
+        return json.loads(res.text)["id"]
+
+    ids = [create(f"cursor page todo {i}") for i in range(5)]
+    path = f"v1/todo?tag={tag}&sort=id&order=desc&limit=2"
+
+    # newer todos created between pages sort before the cursor, so with cursors, unlike with
+    # offsets, no todo is listed twice or skipped
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, path)
+    assert res is not None
+    assert res.status_code == 200
+    listed = [todo["id"] for todo in json.loads(res.text)]
+    cursor = first_cursor = res.headers["X-Next-Cursor"]
+    ids += [create("cursor inserted todo 1"), create("cursor inserted todo 2")]
+    while cursor:
+        res = proxy_http_get(
+            kube_cluster.kube_client,
+            apiserver_service,
+            f"{path}&cursor={urllib.parse.quote(cursor)}",
+        )
+        assert res is not None
+        assert res.status_code == 200
+        assert 'rel="first"' in res.headers["Link"]
+        listed += [todo["id"] for todo in json.loads(res.text)]
+        cursor = res.headers.get("X-Next-Cursor", "")
+    assert listed == list(reversed(ids[:5]))
+
+    # cursors are opaque, valid only with the sort and order they were created for and not with offsets
+    for bad_query in [
+        "cursor=not-a-cursor",
+        f"sort=text&order=desc&cursor={urllib.parse.quote(first_cursor)}",
+        f"sort=id&order=desc&offset=2&cursor={urllib.parse.quote(first_cursor)}",
+    ]:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo?tag={tag}&limit=2&{bad_query}")
+        assert res is not None
+        assert res.status_code == 400
+
+    for todo_id in ids:
+        res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_error_codes(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo/999999999")
+    assert res is not None
+    assert res.status_code == 404
+    assert json.loads(res.text)["code"] == "TODO_NOT_FOUND"
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo/not-a-number")
+    assert res is not None
+    assert res.status_code == 400
+    assert json.loads(res.text)["code"] == "INVALID_REQUEST"
+
+    # validation errors have a code for the whole request and one for every invalid field
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": "   ", "priority": "urgent"}),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 422
+    body = json.loads(res.text)
+    assert body["code"] == "VALIDATION_FAILED"
+    assert {error["field"]: error["code"] for error in body["errors"]} == {
+        "text": "INVALID_TEXT",
+        "priority": "INVALID_PRIORITY",
+    }
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_todo_stats(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    tag = f"stats-{int(time.time())}"
+    past = "2020-01-01T00:00:00Z"
+    future = "2099-01-01T00:00:00Z"
+    dataset = [
+        {"text": "stats done", "done": True, "priority": "high"},
+        {"text": "stats overdue", "due_date": past, "priority": "low"},
+        {"text": "stats due later", "due_date": future},
+        {"text": "stats done late", "done": True, "due_date": past, "priority": "medium"},
+    ]
+    ids = []
+    for todo in dataset:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({**todo, "tags": [tag]}),
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code == 201
+        ids.append(json.loads(res.text)["id"])
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/stats?tag={tag}")
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text) == {
+        "total": 4,
+        "completed": 2,
+        "overdue": 1,
+        "by_priority": {"low": 1, "medium": 2, "high": 1},
+    }
+
+    for todo_id in ids:
+        res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_client_generated_ids(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    headers = {"Content-Type": "application/json"}
+
+    def create(body: Dict) -> Response:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps(body),
+            headers=headers,
+        )
+        assert res is not None
+        return res
+
+    # without an ID, it's generated
+    res = create({"text": "server generated ID"})
+    assert res.status_code == 201
+    server_id = json.loads(res.text)["id"]
+
+    # a client ID a bit over the generated ones, so that the generated IDs don't jump far ahead
+    client_id = str(int(server_id) + 1000 + int(time.time()) % 1000)
+    res = create({"id": client_id, "text": "client generated ID"})
+    assert res.status_code == 201
+    assert json.loads(res.text)["id"] == client_id
+    assert res.headers["Location"].endswith(f"/{client_id}")
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{client_id}")
+    assert res is not None
+    assert res.status_code == 200
+    assert json.loads(res.text)["text"] == "client generated ID"
+
+    # taken IDs conflict, also if the todo is someone else's or in the trash
+    res = create({"id": client_id, "text": "same client ID"})
+    assert res.status_code == 409
+    assert json.loads(res.text)["code"] == "CONFLICT"
+
+    res = create({"id": "not-an-id", "text": "invalid client ID"})
+    assert res.status_code == 422
+    assert [error["code"] for error in json.loads(res.text)["errors"]] == ["INVALID_ID"]
+
+    for todo_id in [server_id, client_id]:
+        res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_grpc_max_message_size(kube_cluster: Cluster):
+    # the chart has to be deployed with grpcMaxMessageSize set below 2000 bytes, the size of the
+    # longest text of two-byte characters allowed, and KAT_GRPC_MAX_MESSAGE_SIZE set to it
+    limit = int(os.environ.get("KAT_GRPC_MAX_MESSAGE_SIZE", "0") or "0")
+    if limit <= 0:
+        pytest.skip("KAT_GRPC_MAX_MESSAGE_SIZE isn't set to the max gRPC message size")
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    headers = {"Content-Type": "application/json"}
+
+    # a todo whose text alone is larger than the max message size is rejected with 413
+    text = "é" * (limit // 2 + 1)
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": text}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 413
+    body = json.loads(res.text)
+    assert body["code"] == "MESSAGE_TOO_LARGE"
+    assert "max gRPC message size" in body["error"]
+
+    # small todos are still created
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": "small enough"}),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+
+
+def apiserver_logs(kube_cluster: Cluster, since_seconds: int) -> str:
+    pods = Pod.objects(kube_cluster.kube_client).filter(
+        namespace="default", selector={"app.kubernetes.io/name": "apiserver"}
+    )
+    return "\n".join(pod.logs(since_seconds=since_seconds) for pod in pods)
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_payload_logging(kube_cluster: Cluster):
+    # payloads are logged only when the chart is deployed with apiserverPayloadLoggingEnabled and
+    # KAT_PAYLOAD_LOGGING is set; by default they must not be logged
+    enabled = os.environ.get("KAT_PAYLOAD_LOGGING", "").lower() in ("1", "true")
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    marker = f"payload-logging-{time.time_ns()}"
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": marker, "password": f"secret-{marker}"}),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    logs = apiserver_logs(kube_cluster, 60)
+    entries = [
+        json.loads(line)
+        for line in logs.splitlines()
+        if marker in line and '"HTTP payloads"' in line
+    ]
+    if enabled:
+        assert len(entries) > 0
+        entry = entries[0]
+        assert entry["method"] == "POST"
+        assert entry["status"] == 201
+        assert marker in entry["request_body"]
+        assert marker in entry["response_body"]
+        # sensitive fields are redacted
+        assert f"secret-{marker}" not in logs
+        assert "[REDACTED]" in entry["request_body"]
+    else:
+        assert entries == []
+        assert f"secret-{marker}" not in logs
+
+    res = proxy_http_delete(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+    )
+    assert res is not None
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_create_if_none_match(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    create_only = {"Content-Type": "application/json", "If-None-Match": "*"}
+
+    def create(body: Dict, headers: Dict[str, str]) -> Response:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps(body),
+            headers=headers,
+        )
+        assert res is not None
+        return res
+
+    res = create({"text": "server generated ID"}, {"Content-Type": "application/json"})
+    assert res.status_code == 201
+    server_id = json.loads(res.text)["id"]
+
+    # a todo with a new ID is created
+    client_id = str(int(server_id) + 1000 + int(time.time()) % 1000)
+    res = create({"id": client_id, "text": "create only"}, create_only)
+    assert res.status_code == 201
+    assert json.loads(res.text)["id"] == client_id
+
+    # the existing todo isn't overwritten
+    res = create({"id": client_id, "text": "overwrite"}, create_only)
+    assert res.status_code == 412
+    assert json.loads(res.text)["code"] == "PRECONDITION_FAILED"
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{client_id}")
+    assert res is not None
+    assert json.loads(res.text)["text"] == "create only"
+
+    # the precondition needs the ID of the todo and only * can be matched
+    res = create({"text": "no ID"}, create_only)
+    assert res.status_code == 400
+    res = create({"id": client_id, "text": "ETag"}, {"Content-Type": "application/json", "If-None-Match": '"1"'})
+    assert res.status_code == 400
+
+    for todo_id in [server_id, client_id]:
+        res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+        assert res is not None
+        assert res.status_code == 204
+
+
+def jwt_subject(token: str) -> str:
+    payload = token.split(".")[1]
+    return json.loads(base64.urlsafe_b64decode(payload + "=" * (-len(payload) % 4)))["sub"]
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_admin_list_todos(kube_cluster: Cluster):
+    # the chart has to be deployed with apiserverJWTPublicKeySecret, validating these tokens; the
+    # admin one has "admin" in its roles claim and the user one doesn't
+    admin_token = os.environ.get("KAT_JWT_ADMIN_TOKEN", "")
+    user_token = os.environ.get("KAT_JWT_USER_TOKEN", "")
+    if not admin_token or not user_token:
+        pytest.skip("KAT_JWT_ADMIN_TOKEN and KAT_JWT_USER_TOKEN aren't set to JWTs accepted by the apiserver")
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    user_headers = {"Authorization": f"Bearer {user_token}", "Content-Type": "application/json"}
+    admin_headers = {"Authorization": f"Bearer {admin_token}"}
+    owner = urllib.parse.quote(jwt_subject(user_token))
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps({"text": "todo of a user asking for support"}),
+        headers=user_headers,
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+
+    # admins list the todos of any owner
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "GET", f"admin/todos?owner={owner}", headers=admin_headers
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert todo_id in [todo["id"] for todo in json.loads(res.text)]
+
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "GET", "admin/todos", headers=admin_headers
+    )
+    assert res is not None
+    assert res.status_code == 400
+
+    # other users are forbidden, also from listing their own todos there
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "GET", f"admin/todos?owner={owner}", headers=user_headers
+    )
+    assert res is not None
+    assert res.status_code == 403
+    assert json.loads(res.text)["code"] == "FORBIDDEN"
+
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"admin/todos?owner={owner}")
+    assert res is not None
+    assert res.status_code == 401
+
+    res = proxy_http_request(
+        kube_cluster.kube_client, apiserver_service, "DELETE", f"v1/todo/{todo_id}?hard=true", headers=user_headers
+    )
+    assert res is not None
+    assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_todo_quota(kube_cluster: Cluster):
+    # the chart has to be deployed with todomanagerMaxTodosPerOwner set to a small number, like 5,
+    # and KAT_MAX_TODOS_PER_OWNER set to it
+    limit = int(os.environ.get("KAT_MAX_TODOS_PER_OWNER", "0") or "0")
+    if limit <= 0:
+        pytest.skip("KAT_MAX_TODOS_PER_OWNER isn't set to the max number of todos per owner")
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    headers = {"Content-Type": "application/json"}
+
+    def create(text: str) -> Response:
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": text}),
+            headers=headers,
+        )
+        assert res is not None
+        return res
+
+    def hard_delete(todo_id: str) -> None:
+        res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true")
+        assert res is not None
+        assert res.status_code == 204
+
+    # todos are created up to the cap, other tests may have left some behind
+    created = []
+    for i in range(limit + 1):
+        res = create(f"quota {i}")
+        if res.status_code != 201:
+            break
+        created.append(json.loads(res.text)["id"])
+    assert 0 < len(created) <= limit
+
+    # over the cap every way of creating todos is rejected
+    assert res.status_code == 403
+    body = json.loads(res.text)
+    assert body["code"] == "QUOTA_EXCEEDED"
+    assert f"at most {limit} todos" in body["error"]
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo/batch",
+        data=json.dumps([{"text": "quota batch"}]),
+        headers=headers,
+    )
+    assert res is not None
+    assert res.status_code == 403
+    assert json.loads(res.text)["code"] == "QUOTA_EXCEEDED"
+
+    # todos in the trash don't count, but restoring them does
+    res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{created[0]}")
+    assert res is not None
+    assert res.status_code == 204
+    res = create("quota after trash")
+    assert res.status_code == 201
+    created.append(json.loads(res.text)["id"])
+    res = proxy_http_post(kube_cluster.kube_client, apiserver_service, f"v1/todo/{created[0]}/restore")
+    assert res is not None
+    assert res.status_code == 403
+
+    # concurrent creates don't go over the cap
+    for todo_id in created[-2:]:
+        hard_delete(todo_id)
+    del created[-2:]
+    results: List[Response] = []
+
+    def create_concurrently(i: int) -> None:
+        results.append(create(f"quota concurrent {i}"))
+
+    threads = [threading.Thread(target=create_concurrently, args=(i,)) for i in range(6)]
+    for thread in threads:
+        thread.start()
+    for thread in threads:
+        thread.join(timeout=todo_timeout)
+    statuses = sorted(res.status_code for res in results)
+    assert statuses.count(201) == 2
+    assert statuses.count(403) == 4
+    created += [json.loads(res.text)["id"] for res in results if res.status_code == 201]
+
+    for todo_id in created:
+        hard_delete(todo_id)
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_rate_limit_headers(kube_cluster: Cluster):
+    # the rate limit has to be low enough for the bucket not to refill between requests, like
+    # apiserverRateLimitRPS 1 with apiserverRateLimitBurst 10
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo/count")
+    assert res is not None
+    if "X-RateLimit-Limit" not in res.headers:
+        pytest.skip("rate limiting is disabled")
+    limit = int(res.headers["X-RateLimit-Limit"])
+    # wait for the bucket to be full again
+    time.sleep(max(0, int(res.headers["X-RateLimit-Reset"]) - time.time()) + 1)
+
+    remaining = []
+    for _ in range(min(limit, 5)):
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo/count")
+        assert res is not None
+        assert res.status_code == 200
+        assert int(res.headers["X-RateLimit-Limit"]) == limit
+        assert int(res.headers["X-RateLimit-Reset"]) >= int(time.time())
+        remaining.append(int(res.headers["X-RateLimit-Remaining"]))
+    assert remaining[0] == limit - 1
+    assert all(later < earlier for earlier, later in zip(remaining, remaining[1:]))
+
+    # requests over the limit are rejected with the headers too
+    for _ in range(limit + 1):
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo/count")
+        assert res is not None
+        if res.status_code == 429:
+            break
+    assert res.status_code == 429
+    assert res.headers["X-RateLimit-Remaining"] == "0"
+    assert int(res.headers["Retry-After"]) > 0
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_stream_import_ndjson(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    content = "\n".join(
+        [
+            json.dumps({"text": "streamed one", "priority": "high"}),
+            '{"text": "streamed broken"',
+            "",
+            json.dumps({"text": ""}),
+            json.dumps({"text": "streamed two", "done": True}),
+        ]
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo/import/stream",
+        data=content,
+        headers={"Content-Type": "application/x-ndjson"},
+    )
+    assert res is not None
+    assert res.status_code == 200
+    assert res.headers["Content-Type"].startswith("application/x-ndjson")
+    lines = [json.loads(line) for line in res.text.splitlines()]
+    results, summary = lines[:-1], lines[-1]
+    assert [r["line"] for r in results] == [1, 2, 4, 5]
+    assert results[0]["id"] and "error" not in results[0]
+    assert results[1]["code"] == "INVALID_REQUEST"
+    assert results[2]["code"] == "VALIDATION_FAILED"
+    assert results[3]["id"] and "error" not in results[3]
+    assert summary == {"imported": 2, "failed": 2}
+
+    for r in (results[0], results[3]):
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{r['id']}")
+        assert res is not None
+        assert res.status_code == 200
+        assert json.loads(res.text)["text"].startswith("streamed ")
+        res = proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{r['id']}?hard=true"
+        )
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_due_soon(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    now = datetime.datetime.now(datetime.timezone.utc).replace(microsecond=0)
+
+    def due_in(delta: datetime.timedelta) -> str:
+        return (now + delta).strftime("%Y-%m-%dT%H:%M:%SZ")
+
+    todos = {
+        "due soon later": {"due_date": due_in(datetime.timedelta(hours=5))},
+        "due soon first": {"due_date": due_in(datetime.timedelta(hours=1))},
+        "due soon done": {"due_date": due_in(datetime.timedelta(hours=2)), "done": True},
+        "due soon overdue": {"due_date": due_in(-datetime.timedelta(hours=1))},
+        "due soon next week": {"due_date": due_in(datetime.timedelta(days=7))},
+        "due soon never": {},
+    }
+    ids = {}
+    for text, fields in todos.items():
+        res = proxy_http_post(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "v1/todo",
+            data=json.dumps({"text": text, **fields}),
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code == 201
+        ids[text] = json.loads(res.text)["id"]
+
+    def due_soon(query: str) -> List[str]:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo/due-soon" + query)
+        assert res is not None
+        assert res.status_code == 200
+        return [t["text"] for t in json.loads(res.text) or [] if t["text"].startswith("due soon ")]
+
+    # 24h is the default window; the result is sorted by due date
+    assert due_soon("") == ["due soon first", "due soon later"]
+    assert due_soon("?within=24h") == ["due soon first", "due soon later"]
+    assert due_soon("?within=3h") == ["due soon first"]
+    assert due_soon("?within=240h") == ["due soon first", "due soon later", "due soon next week"]
+
+    for within in ["tomorrow", "-1h", "0s"]:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/due-soon?within={within}")
+        assert res is not None
+        assert res.status_code == 400
+
+    for todo_id in ids.values():
+        res = proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+        )
+        assert res is not None
+        assert res.status_code == 204
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_duplicate_todo(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data=json.dumps(
+            {
+                "text": "template todo",
+                "done": True,
+                "priority": "high",
+                "tags": ["work", "weekly"],
+                "due_date": "2030-01-07T10:00:00Z",
+                "subtasks": [{"text": "first step", "done": True}, {"text": "second step"}],
+            }
+        ),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    source = json.loads(res.text)
+
+    res = proxy_http_post(
+        kube_cluster.kube_client, apiserver_service, f"v1/todo/{source['id']}/duplicate"
+    )
+    assert res is not None
+    assert res.status_code == 201
+    copy = json.loads(res.text)
+    assert copy["id"] != source["id"]
+    assert res.headers["Location"].endswith(f"/v1/todo/{copy['id']}")
+    assert copy["text"] == "template todo"
+    assert copy["done"] is False
+    assert copy["priority"] == "high"
+    assert sorted(copy["tags"]) == ["weekly", "work"]
+    assert copy["subtasks"] == [
+        {"text": "first step", "done": False},
+        {"text": "second step", "done": False},
+    ]
+    assert "due_date" not in copy
+    assert copy["created_at"] >= source["created_at"]
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo/{source['id']}/duplicate",
+        data=json.dumps({"text": "next week todo", "due_date": "2030-01-14T10:00:00Z"}),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    override = json.loads(res.text)
+    assert override["text"] == "next week todo"
+    assert override["due_date"] == "2030-01-14T10:00:00Z"
+    assert override["priority"] == "high"
+    assert override["done"] is False
+
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        f"v1/todo/{source['id']}/duplicate",
+        data=json.dumps({"due_date": "tomorrow"}),
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 422
+    assert json.loads(res.text)["code"] == "VALIDATION_FAILED"
+
+    res = proxy_http_post(kube_cluster.kube_client, apiserver_service, "v1/todo/999999999/duplicate")
+    assert res is not None
+    assert res.status_code == 404
+
+    for todo_id in (source["id"], copy["id"], override["id"]):
+        res = proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+        )
+        assert res is not None
+        assert res.status_code == 204
+
+
+def websocket_connect(kube_cluster: Cluster, service: Service, path: str) -> ssl.SSLSocket:
+    # requests can't upgrade connections, so the handshake goes through the service proxy of the
+    # Kubernetes API over a plain TLS socket, authenticated with the kubeconfig credentials
+    config = kube_cluster.kube_client.config
+    server = urllib.parse.urlparse(config.cluster["server"])
+    context = ssl.create_default_context()
+    if "certificate-authority" in config.cluster:
+        context.load_verify_locations(config.cluster["certificate-authority"].filename())
+    if config.cluster.get("insecure-skip-tls-verify"):
+        context.check_hostname = False
+        context.verify_mode = ssl.CERT_NONE
+    headers = ""
+    if "client-certificate" in config.user:
+        context.load_cert_chain(
+            config.user["client-certificate"].filename(), config.user["client-key"].filename()
+        )
+    if "token" in config.user:
+        headers += f"Authorization: Bearer {config.user['token']}\r\n"
+    port = service.obj["spec"]["ports"][0]["port"]
+    sock = context.wrap_socket(
+        socket.create_connection((server.hostname, server.port or 443), timeout=todo_timeout),
+        server_hostname=server.hostname,
+    )
+    key = base64.b64encode(os.urandom(16)).decode()
+    sock.sendall(
+        (
+            f"GET /api/v1/namespaces/{service.namespace}/services/{service.name}:{port}/proxy/{path} HTTP/1.1\r\n"
+            f"Host: {server.netloc}\r\n"
+            "Upgrade: websocket\r\n"
+            "Connection: Upgrade\r\n"
+            f"Sec-WebSocket-Key: {key}\r\n"
+            "Sec-WebSocket-Version: 13\r\n"
+            f"{headers}\r\n"
+        ).encode()
+    )
+    handshake = b""
+    while b"\r\n\r\n" not in handshake:
+        chunk = sock.recv(1)
+        assert chunk, "connection closed during the WebSocket handshake"
+        handshake += chunk
+    assert handshake.split(b" ")[1] == b"101", handshake.decode(errors="replace")
+    return sock
+
+
+def websocket_recv_exact(sock: ssl.SSLSocket, size: int) -> bytes:
+    data = b""
+    while len(data) < size:
+        chunk = sock.recv(size - len(data))
+        assert chunk, "WebSocket connection closed"
+        data += chunk
+    return data
+
+
+def websocket_send(sock: ssl.SSLSocket, message: Dict, opcode: int = 0x1) -> None:
+    # clients have to mask the frames they send
+    payload = json.dumps(message).encode() if opcode == 0x1 else b""
+    mask = os.urandom(4)
+    header = bytes([0x80 | opcode])
+    if len(payload) < 126:
+        header += bytes([0x80 | len(payload)])
+    else:
+        header += bytes([0x80 | 126]) + struct.pack("!H", len(payload))
+    sock.sendall(header + mask + bytes(b ^ mask[i % 4] for i, b in enumerate(payload)))
+
+
+def websocket_recv(sock: ssl.SSLSocket) -> Dict:
+    # returns the next text message, skipping control frames
+    while True:
+        first, second = websocket_recv_exact(sock, 2)
+        size = second & 0x7F
+        if size == 126:
+            size = struct.unpack("!H", websocket_recv_exact(sock, 2))[0]
+        elif size == 127:
+            size = struct.unpack("!Q", websocket_recv_exact(sock, 8))[0]
+        payload = websocket_recv_exact(sock, size)
+        opcode = first & 0x0F
+        assert opcode != 0x8, "WebSocket connection closed by the server"
+        if opcode == 0x1:
+            return json.loads(payload)
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_websocket_sync(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    # plain requests aren't upgraded
+    res = proxy_http_get(kube_cluster.kube_client, apiserver_service, "v1/todo/ws")
+    assert res is not None
+    assert res.status_code == 400
+    assert json.loads(res.text)["code"] == "INVALID_REQUEST"
+
+    sock = websocket_connect(kube_cluster, apiserver_service, "v1/todo/ws")
+    todo_id = None
+    try:
+        # the edit sent by the client creates the todo, which comes back both as the result of the
+        # edit and as an event pushed by the server
+        websocket_send(sock, {"type": "create", "ref": "1", "todo": {"text": "synced todo"}})
+        result, event = None, None
+        while result is None or event is None:
+            msg = websocket_recv(sock)
+            if msg.get("ref") == "1":
+                result = msg
+            elif msg["type"] == "created" and msg["todo"]["text"] == "synced todo":
+                event = msg
+        assert result["type"] == "result"
+        todo_id = result["todo"]["id"]
+        assert result["todo"]["priority"] == "medium"
+        assert event["todo"]["id"] == todo_id
+
+        websocket_send(sock, {"type": "ping", "ref": "2"})
+        msg = websocket_recv(sock)
+        while msg.get("ref") != "2":
+            msg = websocket_recv(sock)
+        assert msg["type"] == "pong"
+
+        # edits are scoped to the owner of the connection
+        websocket_send(
+            sock,
+            {"type": "update", "ref": "3", "owner": "someone-else", "todo": {"id": todo_id, "text": "stolen"}},
+        )
+        msg = websocket_recv(sock)
+        while msg.get("ref") != "3":
+            msg = websocket_recv(sock)
+        assert msg["type"] == "error"
+        assert msg["code"] == "FORBIDDEN"
+
+        websocket_send(sock, {"type": "update", "ref": "4", "todo": {"id": todo_id, "text": "synced todo", "done": True}})
+        msg = websocket_recv(sock)
+        while msg.get("ref") != "4":
+            msg = websocket_recv(sock)
+        assert msg["type"] == "result"
+        assert msg["todo"]["done"] is True
+
+        websocket_send(sock, {"type": "delete", "ref": "5", "id": todo_id})
+        msg = websocket_recv(sock)
+        while msg.get("ref") != "5":
+            msg = websocket_recv(sock)
+        assert msg["type"] == "result"
+        assert msg["id"] == todo_id
+
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+        assert res is not None
+        assert res.status_code == 404
+        websocket_send(sock, {}, opcode=0x8)
+    finally:
+        sock.close()
+        if todo_id is not None:
+            proxy_http_delete(
+                kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+            )
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_circuit_breaker(kube_cluster: Cluster):
+    # requests go to the apiserver pod directly, since its readiness probe fails while todo-manager
+    # is down and the service proxy stops sending requests to it
+    pod = next(
+        iter(
+            Pod.objects(kube_cluster.kube_client).filter(
+                namespace="default", selector={"app.kubernetes.io/name": "apiserver"}
+            )
+        )
+    )
+
+    def pod_get(path: str) -> Response:
+        return kube_cluster.kube_client.get(url=f"pods/{pod.name}:8080/proxy/{path}", namespace="default")
+
+    res = pod_get("readyz")
+    assert res.status_code == 200
+    breaker = json.loads(res.text).get("circuit_breaker")
+    if breaker is None:
+        pytest.skip("the circuit breaker is disabled, set apiserverCircuitBreakerThreshold to enable it")
+    assert breaker == "closed"
+
+    todomanager = Deployment.objects(kube_cluster.kube_client).filter(namespace="default").get(
+        name="todomanager"
+    )
+    replicas = todomanager.replicas
+    todomanager.scale(0)
+    try:
+        # consecutive failures open the breaker
+        deadline = time.time() + todo_timeout
+        while time.time() < deadline:
+            res = pod_get("readyz")
+            if json.loads(res.text).get("circuit_breaker") == "open":
+                break
+            pod_get("v1/todo")
+            time.sleep(0.5)
+        assert json.loads(res.text)["circuit_breaker"] == "open"
+
+        # calls are rejected without waiting for todo-manager
+        res = pod_get("v1/todo")
+        assert res.status_code == 503
+        assert int(res.headers["Retry-After"]) > 0
+        body = json.loads(res.text)
+        assert body["code"] == "BACKEND_UNAVAILABLE"
+        assert "circuit breaker is open" in body["error"]
+        assert res.elapsed.total_seconds() < 1
+    finally:
+        todomanager.scale(replicas)
+
+    wait_for_deployments_to_run(
+        kube_cluster.kube_client, ["todomanager"], "default", todo_timeout, missing_ok=False
+    )
+    # after the cooldown a call probes todo-manager and closes the breaker
+    deadline = time.time() + todo_timeout
+    while time.time() < deadline:
+        res = pod_get("v1/todo")
+        if res.status_code == 200:
+            break
+        time.sleep(2)
+    assert res.status_code == 200
+    res = pod_get("readyz")
+    assert json.loads(res.text)["circuit_breaker"] == "closed"
+
+
+def cache_hits(kube_cluster: Cluster, service: Service) -> float:
+    # returns -1 if the apiserver has no cache lookups to report, like when the cache is disabled
+    res = proxy_http_get(kube_cluster.kube_client, service, "metrics")
+    assert res is not None
+    assert res.status_code == 200
+    hits = -1.0
+    for line in res.text.splitlines():
+        if line.startswith('todo_cache_lookups_total{result="hit"}'):
+            return float(line.split()[-1])
+        if line.startswith("todo_cache_lookups_total"):
+            # there were only misses so far
+            hits = 0.0
+    return hits
+
+
+@pytest.mark.flaky(reruns=10, reruns_delay=3)
+@pytest.mark.usefixtures("deployments")
+def test_get_todo_cache(kube_cluster: Cluster):
+    apiserver_service = (
+        Service.objects(kube_cluster.kube_client)
+        .filter(namespace="default")
+        .get(name="apiserver")
+    )
+    res = proxy_http_post(
+        kube_cluster.kube_client,
+        apiserver_service,
+        "v1/todo",
+        data='{"text":"cached todo"}',
+        headers={"Content-Type": "application/json"},
+    )
+    assert res is not None
+    assert res.status_code == 201
+    todo_id = json.loads(res.text)["id"]
+    try:
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+        assert res is not None
+        assert res.status_code == 200
+        hits = cache_hits(kube_cluster, apiserver_service)
+        if hits < 0:
+            pytest.skip("the GetTodo cache is disabled, set apiserverTodoCacheTTL to enable it")
+
+        # the second read is served from the cache, without calling todo-manager
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+        assert res is not None
+        assert res.status_code == 200
+        assert json.loads(res.text)["text"] == "cached todo"
+        assert cache_hits(kube_cluster, apiserver_service) == hits + 1
+
+        # changes invalidate the cached todo
+        res = proxy_http_request(
+            kube_cluster.kube_client,
+            apiserver_service,
+            "PATCH",
+            f"v1/todo/{todo_id}",
+            data='{"text":"changed todo"}',
+            headers={"Content-Type": "application/json"},
+        )
+        assert res is not None
+        assert res.status_code == 200
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+        assert res is not None
+        assert res.status_code == 200
+        assert json.loads(res.text)["text"] == "changed todo"
+
+        res = proxy_http_delete(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+        assert res is not None
+        assert res.status_code == 204
+        res = proxy_http_get(kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}")
+        assert res is not None
+        assert res.status_code == 404
+    finally:
+        proxy_http_delete(
+            kube_cluster.kube_client, apiserver_service, f"v1/todo/{todo_id}?hard=true"
+        )
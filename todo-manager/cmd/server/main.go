@@ -12,6 +12,8 @@ import (
 	"go.opencensus.io/trace"
 	"go.opencensus.io/zpages"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 
 	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
 	todomgr "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/server"
@@ -52,6 +54,27 @@ func main() {
 		initTracing(config)
 	}
 	todoMgr := todomgr.NewTodoManagerServer(config)
+	serverOptions := []grpc.ServerOption{
+		grpc.StatsHandler(&ocgrpc.ServerHandler{}),
+		// allow keepalive pings sent by apiserver to detect dead connections
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             10 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	}
+	if config.TLSCertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			log.Fatalf("Failed to load TLS certificate: %v", err)
+		}
+		serverOptions = append(serverOptions, grpc.Creds(creds))
+	}
+	if config.GrpcMaxMessageSize > 0 {
+		serverOptions = append(serverOptions,
+			grpc.MaxRecvMsgSize(config.GrpcMaxMessageSize),
+			grpc.MaxSendMsgSize(config.GrpcMaxMessageSize),
+		)
+	}
 
 	server := grpcserver.NewGrpcServer(func(server *grpc.Server) {
 		todomgrpb.RegisterTodoManagerServer(server, todoMgr)
@@ -59,16 +82,22 @@ func main() {
 		LoggerFields: log.Fields{
 			"ver": version,
 		},
-		AdditionalOptions: []grpc.ServerOption{
-			grpc.StatsHandler(&ocgrpc.ServerHandler{}),
-		},
-		MetricsPort: 8080,
+		AdditionalOptions: serverOptions,
+		MetricsPort:       8080,
 	})
 	printVersion(server.GetLogger())
 	if config.EnableFailures {
 		server.GetLogger().Warn("Failures Middleware is enabled")
 	}
 	server.GetLogger().Infof("Tracing instrumentation is %v", config.EnableTracing)
+	server.GetLogger().Infof("TLS is %v", config.TLSCertFile != "")
+	if config.MaxTodosPerOwner > 0 || len(config.MaxTodosPerOwnerOverrides) > 0 {
+		server.GetLogger().Infof("Owners can have at most %d todos, %d owners have their own limit",
+			config.MaxTodosPerOwner, len(config.MaxTodosPerOwnerOverrides))
+	}
+	if config.GrpcMaxMessageSize > 0 {
+		server.GetLogger().Infof("Max gRPC message size is %d bytes", config.GrpcMaxMessageSize)
+	}
 	server.Run()
 	todoMgr.Stop()
 }
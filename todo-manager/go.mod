@@ -12,5 +12,6 @@ require (
 	github.com/piontec/grpc-middleware-server v0.1.2
 	github.com/sirupsen/logrus v1.7.0
 	go.opencensus.io v0.22.3
+	google.golang.org/genproto v0.0.0-20200604104852-0b0486081ffb
 	google.golang.org/grpc v1.29.1
 )
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddComment stores a new comment of a todo with a specified ID, authored by a user who can view it
+func (t *TodoManagerServer) AddComment(ctx context.Context, req *todomgrpb.AddCommentReq) (*todomgrpb.Comment, error) {
+	if req.GetText() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Comment text can't be empty")
+	}
+	if err := t.checkTodoAccess(ctx, req.GetTodoId(), req.GetOwner()); err != nil {
+		return nil, err
+	}
+	comment := &TodoComment{
+		TodoEntryID: uint(req.GetTodoId()),
+		Author:      req.GetOwner(),
+		Text:        req.GetText(),
+	}
+	_, span := trace.StartSpan(ctx, "db-comment-create")
+	err := t.db.Create(comment).Error
+	span.End()
+	if err != nil {
+		return nil, errors.New("Error inserting to database")
+	}
+	return comment.ToGrpc(), nil
+}
+
+// ListComments lists the comments of a todo with a specified ID for a user who can view it, newest first by default
+func (t *TodoManagerServer) ListComments(ctx context.Context, req *todomgrpb.ListCommentsReq) (*todomgrpb.CommentList, error) {
+	if err := t.checkTodoAccess(ctx, req.GetTodoId(), req.GetOwner()); err != nil {
+		return nil, err
+	}
+	query := t.db.Model(&TodoComment{}).Where("todo_entry_id = ?", req.GetTodoId())
+	res := &todomgrpb.CommentList{}
+	_, span := trace.StartSpan(ctx, "db-comment-count")
+	err := query.Count(&res.Total).Error
+	span.End()
+	if err != nil {
+		return nil, errors.New("Error counting records in DB")
+	}
+
+	direction := "desc"
+	if req.GetOldestFirst() {
+		direction = "asc"
+	}
+	query = query.Order("created_at " + direction).Order("id " + direction)
+	if req.GetOffset() > 0 {
+		query = query.Offset(req.GetOffset())
+	}
+	if req.GetLimit() > 0 {
+		query = query.Limit(req.GetLimit())
+	}
+	var comments []TodoComment
+	_, span = trace.StartSpan(ctx, "db-comment-list")
+	err = query.Find(&comments).Error
+	span.End()
+	if err != nil {
+		return nil, errors.New("Error listing records in DB")
+	}
+	for i := range comments {
+		res.Comments = append(res.Comments, comments[i].ToGrpc())
+	}
+	return res, nil
+}
+
+// checkTodoAccess returns an error unless a todo with a specified ID exists and the user owns it
+// or can view it
+func (t *TodoManagerServer) checkTodoAccess(ctx context.Context, id uint64, user string) error {
+	found := TodoEntry{}
+	_, span := trace.StartSpan(ctx, "db-get")
+	t.db.Select("id, owner").Preload("Shares").First(&found, id)
+	span.End()
+	return checkAccess(&found, user, todomgrpb.Permission_VIEW)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 )
@@ -13,6 +14,18 @@ type Config struct {
 	OcAgentHost    string
 	EnableFailures bool
 	EnableTracing  bool
+	// TLSCertFile and TLSKeyFile are paths to the PEM encoded certificate and key used to serve
+	// gRPC over TLS; plaintext is served when both are empty
+	TLSCertFile string
+	TLSKeyFile  string
+	// GrpcMaxMessageSize is the max size in bytes of the gRPC messages received from and sent to
+	// apiserver; the defaults of gRPC are used when 0
+	GrpcMaxMessageSize int
+	// MaxTodosPerOwner is the max number of todos an owner can have, not counting the ones in the
+	// trash; there's no limit when 0
+	MaxTodosPerOwner int
+	// MaxTodosPerOwnerOverrides overrides MaxTodosPerOwner for single owners, with 0 for no limit
+	MaxTodosPerOwnerOverrides map[string]int
 }
 
 // NewConfig loads config from environment variables
@@ -47,13 +60,43 @@ func NewConfig() *Config {
 	if boolEnableTracing && ocAgentHost == "" {
 		panic("Required environment variable 'OC_AGENT_HOST' not set")
 	}
+	tlsCertFile := os.Getenv("TLS_CERT_FILE")
+	tlsKeyFile := os.Getenv("TLS_KEY_FILE")
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		panic("Environment variables 'TLS_CERT_FILE' and 'TLS_KEY_FILE' have to be set together")
+	}
+	grpcMaxMessageSize := 0
+	if v := os.Getenv("GRPC_MAX_MESSAGE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			panic("Environment variable 'GRPC_MAX_MESSAGE_SIZE' must be a positive integer")
+		}
+		grpcMaxMessageSize = n
+	}
+	maxTodosPerOwner := 0
+	if v := os.Getenv("MAX_TODOS_PER_OWNER"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			panic("Environment variable 'MAX_TODOS_PER_OWNER' must be a non-negative integer")
+		}
+		maxTodosPerOwner = n
+	}
+	maxTodosPerOwnerOverrides, err := parseQuotaOverrides(os.Getenv("MAX_TODOS_PER_OWNER_OVERRIDES"))
+	if err != nil {
+		panic(fmt.Sprintf("Environment variable 'MAX_TODOS_PER_OWNER_OVERRIDES' is invalid: %v", err))
+	}
 
 	return &Config{
-		MysqlHost:      mysqlHost,
-		MysqlUser:      mysqlUser,
-		MysqlPass:      mysqlPass,
-		OcAgentHost:    ocAgentHost,
-		EnableFailures: boolEnableFailures,
-		EnableTracing:  boolEnableTracing,
+		MysqlHost:                 mysqlHost,
+		MysqlUser:                 mysqlUser,
+		MysqlPass:                 mysqlPass,
+		OcAgentHost:               ocAgentHost,
+		EnableFailures:            boolEnableFailures,
+		EnableTracing:             boolEnableTracing,
+		TLSCertFile:               tlsCertFile,
+		TLSKeyFile:                tlsKeyFile,
+		GrpcMaxMessageSize:        grpcMaxMessageSize,
+		MaxTodosPerOwner:          maxTodosPerOwner,
+		MaxTodosPerOwnerOverrides: maxTodosPerOwnerOverrides,
 	}
 }
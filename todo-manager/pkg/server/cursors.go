@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
+	"github.com/jinzhu/gorm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NextCursorMetadataKey is the gRPC header metadata key carrying the cursor of the page after the
+// todos listed, if the page is full
+const NextCursorMetadataKey = "x-next-cursor"
+
+// listCursor is the position after the last todo of a page of todos: its value of the sort
+// column and its ID, breaking ties. The sort and the order are kept to reject cursors of
+// other listings.
+type listCursor struct {
+	Sort  string          `json:"s"`
+	Order string          `json:"o"`
+	Key   json.RawMessage `json:"k"`
+	ID    uint            `json:"i"`
+}
+
+// sortKey returns the value of the sort column of todo
+func sortKey(sort string, todo *TodoEntry) interface{} {
+	switch sort {
+	case "id":
+		return todo.ID
+	case "text":
+		return todo.Text
+	case "done":
+		return todo.Done
+	case "created_at":
+		return todo.CreatedAt
+	case "updated_at":
+		return todo.UpdatedAt
+	case "due_date":
+		return todo.DueDate
+	default:
+		return todo.Position
+	}
+}
+
+// newSortKey returns a pointer to a new value of the type of the sort column, to decode keys into
+func newSortKey(sort string) interface{} {
+	switch sort {
+	case "id":
+		return new(uint)
+	case "text":
+		return new(string)
+	case "done":
+		return new(bool)
+	case "created_at", "updated_at", "due_date":
+		return new(time.Time)
+	default:
+		return new(float64)
+	}
+}
+
+// encodeCursor returns the opaque cursor of the todos after todo, listed by sort and order
+func encodeCursor(sort string, order todomgrpb.ListTodosReq_Order, todo *TodoEntry) string {
+	key, _ := json.Marshal(sortKey(sort, todo))
+	data, _ := json.Marshal(listCursor{Sort: sort, Order: order.String(), Key: key, ID: todo.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// seekCursor makes query, ordered by sort and order, skip the todos up to the one the cursor was
+// created for; cursors that can't be decoded or were created for another sort or order are
+// rejected with InvalidArgument
+func seekCursor(query *gorm.DB, column, sort string, order todomgrpb.ListTodosReq_Order, cursor string) (*gorm.DB, error) {
+	invalid := status.Error(codes.InvalidArgument, "Invalid cursor")
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, invalid
+	}
+	if c.Sort != sort || c.Order != order.String() {
+		return nil, status.Errorf(codes.InvalidArgument, "Cursor was created for todos sorted by %s %s", c.Sort, c.Order)
+	}
+	key := newSortKey(sort)
+	if err := json.Unmarshal(c.Key, key); err != nil {
+		return nil, invalid
+	}
+	// ties of the sort column are always ordered by ascending ID
+	operator := ">"
+	if order == todomgrpb.ListTodosReq_DESC {
+		operator = "<"
+	}
+	value := reflect.ValueOf(key).Elem().Interface()
+	return query.Where(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id > ?))", column, operator), value, value, c.ID), nil
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
+)
+
+// eventBufferSize is the number of events buffered for each subscriber; events for subscribers
+// that can't keep up are dropped
+const eventBufferSize = 64
+
+// eventBroker delivers todo events to the subscribers of their owner. Events are only delivered
+// within a single todo-manager process, so watchers see changes made through the same replica.
+type eventBroker struct {
+	lock        sync.Mutex
+	subscribers map[string]map[chan *todomgrpb.TodoEvent]struct{}
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{
+		subscribers: map[string]map[chan *todomgrpb.TodoEvent]struct{}{},
+	}
+}
+
+// subscribe returns a channel receiving the events of owner's todos and a function
+// to stop receiving them
+func (b *eventBroker) subscribe(owner string) (<-chan *todomgrpb.TodoEvent, func()) {
+	ch := make(chan *todomgrpb.TodoEvent, eventBufferSize)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.subscribers[owner] == nil {
+		b.subscribers[owner] = map[chan *todomgrpb.TodoEvent]struct{}{}
+	}
+	b.subscribers[owner][ch] = struct{}{}
+	return ch, func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		delete(b.subscribers[owner], ch)
+		if len(b.subscribers[owner]) == 0 {
+			delete(b.subscribers, owner)
+		}
+	}
+}
+
+// publish sends an event about todo to all the subscribers of its owner without blocking
+func (b *eventBroker) publish(eventType todomgrpb.TodoEvent_Type, todo *todomgrpb.Todo) {
+	event := &todomgrpb.TodoEvent{Type: eventType, Todo: todo}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for ch := range b.subscribers[todo.Owner] {
+		select {
+		case ch <- event:
+		default:
+			log.Warnf("Dropping %s event of todo %d, subscriber is too slow", eventType, todo.Id)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package server
 
 import (
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/jinzhu/gorm"
 
 	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
@@ -9,27 +13,188 @@ import (
 // TodoEntry is an object used for ORM mapping into the DB
 type TodoEntry struct {
 	gorm.Model
-	Text  string
-	Done  bool
-	Owner string
+	Text     string
+	Done     bool
+	Owner    string
+	DueDate  *time.Time
+	Priority int32 `gorm:"index"`
+	Tags     []TodoTag
+	Version  uint64 `gorm:"not null;default:1"`
+	Archived bool   `gorm:"not null;default:false;index"`
+	Subtasks []TodoSubtask
+	Shares   []TodoShare
+	// RecurrenceFrequency is FREQUENCY_UNSPECIFIED for todos that don't recur
+	RecurrenceFrequency int32
+	RecurrenceInterval  uint32
+	// Position is the sort key of the todo among the todos of its owner
+	Position float64 `gorm:"not null;default:0;index"`
+}
+
+// TodoTag is an object used for ORM mapping of todo tags into the DB
+type TodoTag struct {
+	ID          uint   `gorm:"primary_key"`
+	TodoEntryID uint   `gorm:"index"`
+	Name        string `gorm:"index"`
+}
+
+// TodoSubtask is an object used for ORM mapping of todo subtasks into the DB; subtasks are
+// ordered by ID
+type TodoSubtask struct {
+	ID          uint `gorm:"primary_key"`
+	TodoEntryID uint `gorm:"index"`
+	Text        string
+	Done        bool
+}
+
+// TodoShare is an object used for ORM mapping of the users a todo is shared with into the DB
+type TodoShare struct {
+	ID          uint   `gorm:"primary_key"`
+	TodoEntryID uint   `gorm:"index"`
+	SharedWith  string `gorm:"index"`
+	Permission  int32
+}
+
+// TodoComment is an object used for ORM mapping of todo comments into the DB
+type TodoComment struct {
+	ID          uint `gorm:"primary_key"`
+	TodoEntryID uint `gorm:"index"`
+	Author      string
+	Text        string
+	CreatedAt   time.Time
+}
+
+// ToGrpc returns GRPC object from DB object
+func (c *TodoComment) ToGrpc() *todomgrpb.Comment {
+	createdAt, _ := ptypes.TimestampProto(c.CreatedAt)
+	return &todomgrpb.Comment{
+		Id:        uint64(c.ID),
+		TodoId:    uint64(c.TodoEntryID),
+		Author:    c.Author,
+		Text:      c.Text,
+		CreatedAt: createdAt,
+	}
 }
 
 // ToGrpc returns GRPC object from DB object
 func (e *TodoEntry) ToGrpc() *todomgrpb.Todo {
-	return &todomgrpb.Todo{
-		Id:    uint64(e.ID),
-		Text:  e.Text,
-		Done:  e.Done,
-		Owner: e.Owner,
+	createdAt, _ := ptypes.TimestampProto(e.CreatedAt)
+	updatedAt, _ := ptypes.TimestampProto(e.UpdatedAt)
+	todo := &todomgrpb.Todo{
+		Id:        uint64(e.ID),
+		Text:      e.Text,
+		Done:      e.Done,
+		Owner:     e.Owner,
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+		DueDate:   timeToGrpc(e.DueDate),
+		Priority:  todomgrpb.Priority(e.Priority),
+		Tags:      tagsToGrpc(e.Tags),
+		Version:   e.Version,
+		DeletedAt: timeToGrpc(e.DeletedAt),
+		Archived:  e.Archived,
+		Subtasks:  subtasksToGrpc(e.Subtasks),
+		Position:  e.Position,
+	}
+	for _, share := range e.Shares {
+		todo.SharedWith = append(todo.SharedWith, &todomgrpb.Share{
+			User:       share.SharedWith,
+			Permission: todomgrpb.Permission(share.Permission),
+		})
+	}
+	if e.RecurrenceFrequency != int32(todomgrpb.Frequency_FREQUENCY_UNSPECIFIED) {
+		todo.Recurrence = &todomgrpb.Recurrence{
+			Frequency: todomgrpb.Frequency(e.RecurrenceFrequency),
+			Interval:  e.RecurrenceInterval,
+		}
 	}
+	return todo
 }
 
-// FromGrpc returns DB object from GRPC object
+// FromGrpc returns DB object of a new todo from GRPC object; timestamps, version and position are
+// managed by the DB layer only, so they are not copied
 func FromGrpc(grpcTodo *todomgrpb.Todo) *TodoEntry {
-	return &TodoEntry{
-		Model: gorm.Model{ID: uint(grpcTodo.Id)},
-		Text:  grpcTodo.Text,
-		Done:  grpcTodo.Done,
-		Owner: grpcTodo.Owner,
+	entry := &TodoEntry{
+		Model:    gorm.Model{ID: uint(grpcTodo.Id)},
+		Text:     grpcTodo.Text,
+		Done:     grpcTodo.Done,
+		Owner:    grpcTodo.Owner,
+		DueDate:  timeFromGrpc(grpcTodo.DueDate),
+		Priority: int32(priorityOrDefault(grpcTodo.Priority)),
+		Tags:     tagsFromGrpc(grpcTodo.Tags),
+		Subtasks: subtasksFromGrpc(grpcTodo.Subtasks),
+		Version:  1,
+	}
+	entry.setRecurrence(grpcTodo.Recurrence)
+	return entry
+}
+
+// setRecurrence sets the recurrence of a todo from an optional GRPC recurrence
+func (e *TodoEntry) setRecurrence(recurrence *todomgrpb.Recurrence) {
+	e.RecurrenceFrequency = int32(recurrence.GetFrequency())
+	e.RecurrenceInterval = recurrence.GetInterval()
+}
+
+// tagsToGrpc returns GRPC tag names from DB tags
+func tagsToGrpc(tags []TodoTag) []string {
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+// tagsFromGrpc returns DB tags from GRPC tag names
+func tagsFromGrpc(names []string) []TodoTag {
+	var tags []TodoTag
+	for _, name := range names {
+		tags = append(tags, TodoTag{Name: name})
+	}
+	return tags
+}
+
+// subtasksToGrpc returns GRPC subtasks from DB subtasks
+func subtasksToGrpc(subtasks []TodoSubtask) []*todomgrpb.Subtask {
+	var res []*todomgrpb.Subtask
+	for _, subtask := range subtasks {
+		res = append(res, &todomgrpb.Subtask{Text: subtask.Text, Done: subtask.Done})
+	}
+	return res
+}
+
+// subtasksFromGrpc returns DB subtasks from GRPC subtasks
+func subtasksFromGrpc(grpcSubtasks []*todomgrpb.Subtask) []TodoSubtask {
+	var subtasks []TodoSubtask
+	for _, subtask := range grpcSubtasks {
+		subtasks = append(subtasks, TodoSubtask{Text: subtask.GetText(), Done: subtask.GetDone()})
+	}
+	return subtasks
+}
+
+// priorityOrDefault returns MEDIUM for unspecified priority
+func priorityOrDefault(p todomgrpb.Priority) todomgrpb.Priority {
+	if p == todomgrpb.Priority_PRIORITY_UNSPECIFIED {
+		return todomgrpb.Priority_MEDIUM
+	}
+	return p
+}
+
+// timeToGrpc returns GRPC timestamp from an optional DB time
+func timeToGrpc(t *time.Time) *timestamp.Timestamp {
+	if t == nil {
+		return nil
+	}
+	ts, _ := ptypes.TimestampProto(*t)
+	return ts
+}
+
+// timeFromGrpc returns an optional DB time from GRPC timestamp
+func timeFromGrpc(ts *timestamp.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return nil
 	}
+	return &t
 }
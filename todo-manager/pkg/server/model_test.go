@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/jinzhu/gorm"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
+)
+
+func TestFromGrpcIgnoresTimestamps(t *testing.T) {
+	spoofed, _ := ptypes.TimestampProto(time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC))
+	entry := FromGrpc(&todomgrpb.Todo{Text: "spoofed", Owner: "alice", CreatedAt: spoofed, UpdatedAt: spoofed, Version: 7})
+	if !entry.CreatedAt.IsZero() || !entry.UpdatedAt.IsZero() {
+		t.Errorf("expected timestamps to be left to the DB, got %v and %v", entry.CreatedAt, entry.UpdatedAt)
+	}
+	if entry.Version != 1 {
+		t.Errorf("expected version 1 of a new todo, got %d", entry.Version)
+	}
+}
+
+func TestToGrpcTimestamps(t *testing.T) {
+	createdAt := time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.Add(time.Hour)
+	entry := &TodoEntry{Model: gorm.Model{ID: 1, CreatedAt: createdAt, UpdatedAt: updatedAt}, Text: "todo"}
+	todo := entry.ToGrpc()
+	if got, _ := ptypes.Timestamp(todo.CreatedAt); !got.Equal(createdAt) {
+		t.Errorf("expected created_at %v, got %v", createdAt, got)
+	}
+	if got, _ := ptypes.Timestamp(todo.UpdatedAt); !got.Equal(updatedAt) {
+		t.Errorf("expected updated_at %v, got %v", updatedAt, got)
+	}
+	if todo.DeletedAt != nil {
+		t.Errorf("expected no deleted_at, got %v", todo.DeletedAt)
+	}
+}
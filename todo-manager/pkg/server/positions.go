@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
+	"github.com/jinzhu/gorm"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// lastPosition returns the largest position of the todos of owner, also the ones in the trash, so
+// that new todos can be placed after all of them; it's 0 if owner has no todos
+func lastPosition(db *gorm.DB, owner string) (float64, error) {
+	var res struct{ Position float64 }
+	err := db.Unscoped().Model(&TodoEntry{}).Select("COALESCE(MAX(position), 0) AS position").Where("owner = ?", owner).Scan(&res).Error
+	return res.Position, err
+}
+
+// positionAt returns a position placing a todo at index of todos ordered by position, halfway
+// between its neighbours; false is returned if there's no room left between them, because of the
+// limited precision of positions or because they have the same position
+func positionAt(todos []TodoEntry, index int) (float64, bool) {
+	switch {
+	case len(todos) == 0:
+		return 1, true
+	case index == 0:
+		return todos[0].Position - 1, true
+	case index == len(todos):
+		return todos[len(todos)-1].Position + 1, true
+	}
+	prev, next := todos[index-1].Position, todos[index].Position
+	position := prev + (next-prev)/2
+	return position, prev < position && position < next
+}
+
+// MoveTodo moves a todo with a specified ID and owner to the index of the request among the active
+// todos of the owner ordered by position. The todo gets a position halfway between its new
+// neighbours, so only the moved todo changes; when there's no room between them, all the todos of
+// the owner are reindexed without changing their version. The todos of the owner are locked while
+// moving, so that concurrent moves are applied one after the other.
+func (t *TodoManagerServer) MoveTodo(ctx context.Context, req *todomgrpb.MoveTodoReq) (*todomgrpb.Todo, error) {
+	found := TodoEntry{}
+	_, span := trace.StartSpan(ctx, "db-move-get")
+	preloadAssociations(t.db).First(&found, req.GetId())
+	span.End()
+	if found.ID == 0 || found.Owner != req.GetOwner() {
+		return nil, status.Error(codes.NotFound, "Todo not found")
+	}
+
+	_, span = trace.StartSpan(ctx, "db-move-save")
+	tx := t.db.Begin()
+	err := moveTodo(ctx, tx, &found, int(req.GetPosition()), req.GetExpectedVersion())
+	if err == nil {
+		err = tx.Commit().Error
+	} else {
+		tx.Rollback()
+	}
+	span.End()
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, errors.New("Error updating record in DB")
+	}
+
+	res := found.ToGrpc()
+	t.events.publish(todomgrpb.TodoEvent_UPDATED, res)
+	return res, nil
+}
+
+// moveTodo stores the new position of todo at index in the transaction tx, if expectedVersion is
+// 0 or matches the version of the todo
+func moveTodo(ctx context.Context, tx *gorm.DB, todo *TodoEntry, index int, expectedVersion uint64) error {
+	var locked []TodoEntry
+	err := tx.Set("gorm:query_option", "FOR UPDATE").Select("id, position, version").
+		Where("owner = ? AND (archived = ? OR id = ?)", todo.Owner, false, todo.ID).
+		Order("position").Order("id").Find(&locked).Error
+	if err != nil {
+		return err
+	}
+	var others []TodoEntry
+	for _, entry := range locked {
+		if entry.ID == todo.ID {
+			// the todo may have changed since it was loaded
+			todo.Version = entry.Version
+			continue
+		}
+		others = append(others, entry)
+	}
+	if expectedVersion != 0 && expectedVersion != todo.Version {
+		return versionMismatch(ctx, todo.Version)
+	}
+	if index > len(others) {
+		index = len(others)
+	}
+	position, ok := positionAt(others, index)
+	if !ok {
+		// positions start from 1 again, leaving the index of the moved todo free
+		for i := range others {
+			reindexed := i + 1
+			if i >= index {
+				reindexed++
+			}
+			if err := tx.Model(&others[i]).UpdateColumn("position", float64(reindexed)).Error; err != nil {
+				return err
+			}
+		}
+		position = float64(index + 1)
+	}
+	todo.Position = position
+	return saveVersionedIn(ctx, tx, todo, map[string]interface{}{"position": position})
+}
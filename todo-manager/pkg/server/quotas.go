@@ -0,0 +1,103 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QuotaSubjectPrefix prefixes the owner in the subject of the QuotaFailure details of errors
+// returned when an owner has reached the max number of todos
+const QuotaSubjectPrefix = "owner:"
+
+// parseQuotaOverrides parses a comma separated list of owner:max pairs overriding the max number
+// of todos of single owners; empty items are skipped
+func parseQuotaOverrides(v string) (map[string]int, error) {
+	overrides := map[string]int{}
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		// owners can have colons, the max is after the last one
+		i := strings.LastIndex(item, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("quota override %q is not an owner:max pair", item)
+		}
+		max, err := strconv.Atoi(item[i+1:])
+		if err != nil || max < 0 {
+			return nil, fmt.Errorf("quota override %q doesn't have a non-negative max", item)
+		}
+		overrides[item[:i]] = max
+	}
+	return overrides, nil
+}
+
+// maxTodos returns the max number of todos owner can have; there's no limit when 0
+func (c *Config) maxTodos(owner string) int {
+	if max, found := c.MaxTodosPerOwnerOverrides[owner]; found {
+		return max
+	}
+	return c.MaxTodosPerOwner
+}
+
+// checkQuota checks that owner can have n more todos. The todos of the owner are locked in the
+// transaction tx, so that concurrent creates wait for it to end and count the todos it creates;
+// todos in the trash don't count.
+func (t *TodoManagerServer) checkQuota(tx *gorm.DB, owner string, n int) error {
+	max := t.config.maxTodos(owner)
+	if max == 0 {
+		return nil
+	}
+	var count int
+	err := tx.Set("gorm:query_option", "FOR UPDATE").Model(&TodoEntry{}).Where("owner = ?", owner).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count+n > max {
+		return quotaExceeded(owner, max)
+	}
+	return nil
+}
+
+// checkBatchQuota checks that the owners of todos can have all of them, in the transaction tx
+func (t *TodoManagerServer) checkBatchQuota(tx *gorm.DB, todos []*TodoEntry) error {
+	counts := map[string]int{}
+	var owners []string
+	for _, todo := range todos {
+		if counts[todo.Owner] == 0 {
+			owners = append(owners, todo.Owner)
+		}
+		counts[todo.Owner]++
+	}
+	// owners are locked in a stable order, so that concurrent batches don't deadlock
+	sort.Strings(owners)
+	for _, owner := range owners {
+		if err := t.checkQuota(tx, owner, counts[owner]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quotaExceeded returns the error of an owner that has reached the max number of todos, with
+// QuotaFailure details telling it apart from other failed preconditions
+func quotaExceeded(owner string, max int) error {
+	s := status.Newf(codes.FailedPrecondition, "Todo quota exceeded: %s can have at most %d todos", owner, max)
+	detailed, err := s.WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     QuotaSubjectPrefix + owner,
+			Description: fmt.Sprintf("at most %d todos", max),
+		}},
+	})
+	if err != nil {
+		return s.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
+	"github.com/jinzhu/gorm"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validateRecurrence checks if an optional recurrence has a known frequency
+func validateRecurrence(recurrence *todomgrpb.Recurrence) error {
+	if recurrence == nil {
+		return nil
+	}
+	if _, found := todomgrpb.Frequency_name[int32(recurrence.Frequency)]; !found ||
+		recurrence.Frequency == todomgrpb.Frequency_FREQUENCY_UNSPECIFIED {
+		return status.Error(codes.InvalidArgument, "Recurrence frequency must be one of: DAILY, WEEKLY, MONTHLY")
+	}
+	return nil
+}
+
+// nextDueDate returns the due date of the next occurrence of a recurring todo; it's advanced from the
+// due date of the todo or from now, if the todo has no due date
+func nextDueDate(todo *TodoEntry) time.Time {
+	from := gorm.NowFunc()
+	if todo.DueDate != nil {
+		from = *todo.DueDate
+	}
+	interval := int(todo.RecurrenceInterval)
+	if interval == 0 {
+		interval = 1
+	}
+	switch todomgrpb.Frequency(todo.RecurrenceFrequency) {
+	case todomgrpb.Frequency_DAILY:
+		return from.AddDate(0, 0, interval)
+	case todomgrpb.Frequency_WEEKLY:
+		return from.AddDate(0, 0, 7*interval)
+	default:
+		return from.AddDate(0, interval, 0)
+	}
+}
+
+// spawnNextOccurrence creates the next occurrence of a recurring todo, if it was just completed;
+// failures are only logged, as the completion itself is already stored
+func (t *TodoManagerServer) spawnNextOccurrence(ctx context.Context, todo *TodoEntry, wasDone bool) {
+	if wasDone || !todo.Done || todo.RecurrenceFrequency == int32(todomgrpb.Frequency_FREQUENCY_UNSPECIFIED) {
+		return
+	}
+	next := todo.ToGrpc()
+	next.Id = 0
+	next.Done = false
+	next.DueDate = timeToGrpc(timePtr(nextDueDate(todo)))
+	for _, subtask := range next.Subtasks {
+		subtask.Done = false
+	}
+	if _, err := t.CreateTodo(ctx, next); err != nil {
+		log.Errorf("Error creating the next occurrence of recurring todo %d: %v", todo.ID, err)
+	}
+}
+
+// timePtr returns a pointer to a copy of t
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -4,13 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 
 	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
 	"github.com/jinzhu/gorm"
 	log "github.com/sirupsen/logrus"
 	"go.opencensus.io/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	// initialize mysql gorm driver
 	_ "github.com/jinzhu/gorm/dialects/mysql"
@@ -18,10 +26,36 @@ import (
 
 const dbName = "todo"
 
+// TotalCountMetadataKey is the gRPC header metadata key carrying the total number of todos
+// matching a ListTodos request, regardless of the requested page
+const TotalCountMetadataKey = "x-total-count"
+
+// VersionMetadataKey is the gRPC trailer metadata key carrying the current version of a todo
+// when an update is rejected because of a version mismatch
+const VersionMetadataKey = "x-todo-version"
+
+// maxTodoID is the largest todo ID, the IDs are stored as unsigned 32-bit integers
+const maxTodoID = math.MaxUint32
+
+// sortColumns maps the fields todos can be sorted by to DB columns
+var sortColumns = map[string]string{
+	"id":         "id",
+	"text":       "text",
+	"done":       "done",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"position":   "position",
+	"due_date":   "due_date",
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 // TodoManagerServer implements gRPC server for todo manager
 type TodoManagerServer struct {
 	config *Config
 	db     *gorm.DB
+	events *eventBroker
 }
 
 // NewTodoManagerServer creates a new TodoManagerServer
@@ -33,11 +67,12 @@ func NewTodoManagerServer(config *Config) *TodoManagerServer {
 	if err != nil {
 		panic(fmt.Sprintf("Failed to connect database: %v", err))
 	}
-	db.AutoMigrate(&TodoEntry{})
+	db.AutoMigrate(&TodoEntry{}, &TodoTag{}, &TodoSubtask{}, &TodoComment{}, &TodoShare{})
 
 	mgr := &TodoManagerServer{
 		config: config,
 		db:     db,
+		events: newEventBroker(),
 	}
 	return mgr
 }
@@ -47,22 +82,259 @@ func (t *TodoManagerServer) Stop() {
 	t.db.Close()
 }
 
-// CreateTodo stores new todo in database
+// CreateTodo stores new todo in database; its ID is generated unless the request sets one, which
+// fails with AlreadyExists if a todo with the ID exists, also in the trash
 func (t *TodoManagerServer) CreateTodo(ctx context.Context, todo *todomgrpb.Todo) (*todomgrpb.Todo, error) {
+	if err := validateRecurrence(todo.Recurrence); err != nil {
+		return nil, err
+	}
+	if todo.Id > maxTodoID {
+		return nil, status.Errorf(codes.InvalidArgument, "Todo ID can't be larger than %d", maxTodoID)
+	}
 	dbTodo := FromGrpc(todo)
 	_, span := trace.StartSpan(ctx, "db-create")
-	t.db.Create(dbTodo)
-	span.End()
-	if dbTodo.ID == 0 {
+	defer span.End()
+	if todo.Id != 0 && t.todoExists(todo.Id) {
+		return nil, status.Errorf(codes.AlreadyExists, "Todo %d already exists", todo.Id)
+	}
+	tx := t.db.Begin()
+	err := t.checkQuota(tx, dbTodo.Owner, 1)
+	if err == nil {
+		if dbTodo.Position, err = lastPosition(tx, dbTodo.Owner); err == nil {
+			dbTodo.Position++
+			err = tx.Create(dbTodo).Error
+		}
+	}
+	if err == nil {
+		err = tx.Commit().Error
+	} else {
+		tx.Rollback()
+	}
+	if _, ok := status.FromError(err); ok && err != nil {
+		return nil, err
+	}
+	if err != nil && todo.Id != 0 && t.todoExists(todo.Id) {
+		// created concurrently by another request
+		return nil, status.Errorf(codes.AlreadyExists, "Todo %d already exists", todo.Id)
+	}
+	if err != nil || dbTodo.ID == 0 {
 		return nil, errors.New("Error inserting to database")
 	}
-	return dbTodo.ToGrpc(), nil
+	res := dbTodo.ToGrpc()
+	t.events.publish(todomgrpb.TodoEvent_CREATED, res)
+	return res, nil
+}
+
+// todoExists checks if a todo with id is stored, also in the trash
+func (t *TodoManagerServer) todoExists(id uint64) bool {
+	var count int
+	t.db.Unscoped().Model(&TodoEntry{}).Where("id = ?", id).Count(&count)
+	return count > 0
+}
+
+// BatchCreateTodos stores all the todos received from the stream in database in a single transaction
+func (t *TodoManagerServer) BatchCreateTodos(srv todomgrpb.TodoManager_BatchCreateTodosServer) error {
+	var dbTodos []*TodoEntry
+	for {
+		todo, err := srv.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := validateRecurrence(todo.Recurrence); err != nil {
+			return err
+		}
+		dbTodos = append(dbTodos, FromGrpc(todo))
+	}
+
+	_, span := trace.StartSpan(srv.Context(), "db-batch-create")
+	tx := t.db.Begin()
+	if err := t.checkBatchQuota(tx, dbTodos); err != nil {
+		tx.Rollback()
+		span.End()
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return errors.New("Error inserting to database")
+	}
+	for _, dbTodo := range dbTodos {
+		position, err := lastPosition(tx, dbTodo.Owner)
+		if err == nil {
+			dbTodo.Position = position + 1
+			err = tx.Create(dbTodo).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			span.End()
+			return errors.New("Error inserting to database")
+		}
+	}
+	err := tx.Commit().Error
+	span.End()
+	if err != nil {
+		return errors.New("Error inserting to database")
+	}
+
+	res := &todomgrpb.TodoList{}
+	for _, dbTodo := range dbTodos {
+		todo := dbTodo.ToGrpc()
+		res.Todos = append(res.Todos, todo)
+		t.events.publish(todomgrpb.TodoEvent_CREATED, todo)
+	}
+	return srv.SendAndClose(res)
+}
+
+// StreamCreateTodos creates every todo received from the stream as soon as it's received, each in its
+// own transaction, and sends back the result of each one. Todos that are invalid or already exist are
+// reported in their result and the stream goes on; other errors, like the owner reaching the max
+// number of todos, end the stream, keeping the todos created so far.
+func (t *TodoManagerServer) StreamCreateTodos(srv todomgrpb.TodoManager_StreamCreateTodosServer) error {
+	for {
+		todo, err := srv.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		res := &todomgrpb.CreateTodoRes{}
+		res.Todo, err = t.CreateTodo(srv.Context(), todo)
+		if err != nil {
+			switch code := status.Code(err); code {
+			case codes.InvalidArgument, codes.AlreadyExists:
+				res.Code = uint32(code)
+				res.Error = status.Convert(err).Message()
+			default:
+				return err
+			}
+		}
+		if err := srv.Send(res); err != nil {
+			return err
+		}
+	}
+}
+
+// preloadAssociations makes query load the tags, the shares and the subtasks of todos
+func preloadAssociations(query *gorm.DB) *gorm.DB {
+	return query.Preload("Tags").Preload("Shares").Preload("Subtasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+}
+
+// filterQuery returns a query selecting all the todos matching the filters of the request, owned
+// by the user or shared with them
+func (t *TodoManagerServer) filterQuery(req *todomgrpb.ListTodosReq) *gorm.DB {
+	query := t.db.Model(&TodoEntry{}).Where("(owner = ? OR id IN (SELECT todo_entry_id FROM todo_shares WHERE shared_with = ?))", req.Owner, req.Owner)
+	if req.Deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	query = query.Where("archived = ?", req.Archived)
+	if req.Done != nil {
+		query = query.Where("done = ?", req.Done.Value)
+	}
+	if dueBefore := timeFromGrpc(req.DueBefore); dueBefore != nil {
+		query = query.Where("due_date < ?", *dueBefore)
+	}
+	if dueAfter := timeFromGrpc(req.DueAfter); dueAfter != nil {
+		query = query.Where("due_date >= ?", *dueAfter)
+	}
+	if createdAfter := timeFromGrpc(req.CreatedAfter); createdAfter != nil {
+		query = query.Where("created_at >= ?", *createdAfter)
+	}
+	if createdBefore := timeFromGrpc(req.CreatedBefore); createdBefore != nil {
+		query = query.Where("created_at < ?", *createdBefore)
+	}
+	if req.Priority != todomgrpb.Priority_PRIORITY_UNSPECIFIED {
+		query = query.Where("priority = ?", int32(req.Priority))
+	}
+	for _, tag := range req.Tags {
+		query = query.Where("id IN (SELECT todo_entry_id FROM todo_tags WHERE name = ?)", tag)
+	}
+	if req.TextQuery != "" {
+		query = query.Where("LOWER(text) LIKE ?", "%"+likeEscaper.Replace(strings.ToLower(req.TextQuery))+"%")
+	}
+	return query
+}
+
+// CountTodos returns the number of todos matching the filters of the request; pagination and
+// sorting options of the request are ignored
+func (t *TodoManagerServer) CountTodos(ctx context.Context, req *todomgrpb.ListTodosReq) (*todomgrpb.CountTodosRes, error) {
+	var count uint64
+	_, span := trace.StartSpan(ctx, "db-count")
+	err := t.filterQuery(req).Count(&count).Error
+	span.End()
+	if err != nil {
+		return nil, errors.New("Error counting records in DB")
+	}
+	return &todomgrpb.CountTodosRes{Count: count}, nil
+}
+
+// TodoStats summarizes the todos matching the filters of the request with a single aggregate
+// query; pagination and sorting options of the request are ignored
+func (t *TodoManagerServer) TodoStats(ctx context.Context, req *todomgrpb.ListTodosReq) (*todomgrpb.TodoStatsRes, error) {
+	var stats struct {
+		Total          uint64
+		Done           uint64
+		Overdue        uint64
+		LowPriority    uint64
+		MediumPriority uint64
+		HighPriority   uint64
+	}
+	_, span := trace.StartSpan(ctx, "db-stats")
+	err := t.filterQuery(req).Select(
+		"COUNT(*) AS total, "+
+			"COALESCE(SUM(CASE WHEN done THEN 1 ELSE 0 END), 0) AS done, "+
+			"COALESCE(SUM(CASE WHEN NOT done AND due_date < ? THEN 1 ELSE 0 END), 0) AS overdue, "+
+			"COALESCE(SUM(CASE WHEN priority = ? THEN 1 ELSE 0 END), 0) AS low_priority, "+
+			"COALESCE(SUM(CASE WHEN priority = ? THEN 1 ELSE 0 END), 0) AS medium_priority, "+
+			"COALESCE(SUM(CASE WHEN priority = ? THEN 1 ELSE 0 END), 0) AS high_priority",
+		time.Now(), int32(todomgrpb.Priority_LOW), int32(todomgrpb.Priority_MEDIUM), int32(todomgrpb.Priority_HIGH),
+	).Scan(&stats).Error
+	span.End()
+	if err != nil {
+		return nil, errors.New("Error computing stats of records in DB")
+	}
+	return &todomgrpb.TodoStatsRes{
+		Total:          stats.Total,
+		Done:           stats.Done,
+		Overdue:        stats.Overdue,
+		LowPriority:    stats.LowPriority,
+		MediumPriority: stats.MediumPriority,
+		HighPriority:   stats.HighPriority,
+	}, nil
 }
 
-// ListTodos lists all todos owned by the user sent in request
+// ListTodos lists all todos owned by the user sent in request or shared with them
 func (t *TodoManagerServer) ListTodos(req *todomgrpb.ListTodosReq, srv todomgrpb.TodoManager_ListTodosServer) error {
 	var todos []TodoEntry
-	_, span := trace.StartSpan(srv.Context(), "db-list")
+	sort := req.Sort
+	if sort == "" {
+		sort = "position"
+	}
+	column, found := sortColumns[sort]
+	if !found {
+		return status.Errorf(codes.InvalidArgument, "Can't sort by %q", req.Sort)
+	}
+	direction := "asc"
+	if req.Order == todomgrpb.ListTodosReq_DESC {
+		direction = "desc"
+	}
+	query := t.filterQuery(req)
+
+	var total int
+	_, span := trace.StartSpan(srv.Context(), "db-count")
+	query.Count(&total)
+	span.End()
+	if req.Cursor != "" {
+		var err error
+		if query, err = seekCursor(query, column, sort, req.Order, req.Cursor); err != nil {
+			return err
+		}
+	}
+
+	_, span = trace.StartSpan(srv.Context(), "db-list")
 	log.Info("DB: starting 'list all' query for all the entries of an owner.")
 	if t.config.EnableFailures {
 		if num := rand.Int() % 10; num == 0 {
@@ -70,8 +342,22 @@ func (t *TodoManagerServer) ListTodos(req *todomgrpb.ListTodosReq, srv todomgrpb
 			time.Sleep(time.Duration(rand.Int()%3+1) * time.Second)
 		}
 	}
-	t.db.Where("owner = ?", req.Owner).Find(&todos)
+	query = query.Order(fmt.Sprintf("%s %s", column, direction)).Order("id")
+	if req.Offset > 0 && req.Cursor == "" {
+		query = query.Offset(req.Offset)
+	}
+	if req.Limit > 0 {
+		query = query.Limit(req.Limit)
+	}
+	preloadAssociations(query).Find(&todos)
 	span.End()
+	header := metadata.Pairs(TotalCountMetadataKey, strconv.Itoa(total))
+	if req.Limit > 0 && len(todos) == int(req.Limit) {
+		header.Set(NextCursorMetadataKey, encodeCursor(sort, req.Order, &todos[len(todos)-1]))
+	}
+	if err := srv.SendHeader(header); err != nil {
+		return err
+	}
 	for _, t := range todos {
 		todo := t.ToGrpc()
 		srv.Send(todo)
@@ -79,53 +365,358 @@ func (t *TodoManagerServer) ListTodos(req *todomgrpb.ListTodosReq, srv todomgrpb
 	return nil
 }
 
-// GetTodo returns todo with specified ID and owner, if it exists
+// GetTodo returns todo with specified ID, if it exists and the user sent in request can view it
 func (t *TodoManagerServer) GetTodo(ctx context.Context, grpcTodo *todomgrpb.TodoIdReq) (*todomgrpb.Todo, error) {
 	found := TodoEntry{}
 	_, span := trace.StartSpan(ctx, "db-get")
-	t.db.First(&found, grpcTodo.GetId())
+	preloadAssociations(t.db).First(&found, grpcTodo.GetId())
 	span.End()
-	if found.ID == 0 || found.Owner != grpcTodo.GetOwner() {
-		return nil, errors.New("Todo not found")
+	if err := checkAccess(&found, grpcTodo.GetOwner(), todomgrpb.Permission_VIEW); err != nil {
+		return nil, err
 	}
 	return found.ToGrpc(), nil
 }
 
-// UpdateTodo updates a todo with a specified ID and owner, if it exists
+// UpdateTodo updates a todo with a specified ID, if it exists and the user sent in request can edit it;
+// if the version of the request is set, the todo is updated only if it matches the stored version
 func (t *TodoManagerServer) UpdateTodo(ctx context.Context, grpcTodo *todomgrpb.Todo) (*todomgrpb.Todo, error) {
+	if err := validateRecurrence(grpcTodo.Recurrence); err != nil {
+		return nil, err
+	}
 	found := TodoEntry{}
 	_, span := trace.StartSpan(ctx, "db-update-get")
-	t.db.First(&found, grpcTodo.GetId())
+	preloadAssociations(t.db).First(&found, grpcTodo.GetId())
 	span.End()
-	if found.ID == 0 || found.Owner != grpcTodo.GetOwner() {
-		return nil, errors.New("Todo not found")
+	if err := checkAccess(&found, grpcTodo.GetOwner(), todomgrpb.Permission_EDIT); err != nil {
+		return nil, err
+	}
+	if grpcTodo.Version != 0 && grpcTodo.Version != found.Version {
+		return nil, versionMismatch(ctx, found.Version)
 	}
 
+	wasDone := found.Done
 	found.Text = grpcTodo.Text
 	found.Done = grpcTodo.Done
+	found.DueDate = timeFromGrpc(grpcTodo.DueDate)
+	found.Priority = int32(priorityOrDefault(grpcTodo.Priority))
+	found.setRecurrence(grpcTodo.Recurrence)
+	updates := map[string]interface{}{
+		"text":                 found.Text,
+		"done":                 found.Done,
+		"due_date":             gorm.Expr("NULL"),
+		"priority":             found.Priority,
+		"recurrence_frequency": found.RecurrenceFrequency,
+		"recurrence_interval":  found.RecurrenceInterval,
+	}
+	if found.DueDate != nil {
+		updates["due_date"] = found.DueDate
+	}
 	_, span = trace.StartSpan(ctx, "db-update-save")
-	t.db.Save(&found)
+	err := t.saveVersioned(ctx, &found, updates)
+	if err == nil {
+		err = t.replaceTags(&found, grpcTodo.Tags)
+	}
+	if err == nil {
+		err = t.replaceSubtasks(&found, grpcTodo.Subtasks)
+	}
 	span.End()
-	if found.ID == 0 {
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
 		return nil, errors.New("Error updating record in DB")
 	}
 
-	return found.ToGrpc(), nil
+	res := found.ToGrpc()
+	t.events.publish(todomgrpb.TodoEvent_UPDATED, res)
+	t.spawnNextOccurrence(ctx, &found, wasDone)
+	return res, nil
 }
 
-// DeleteTodo deletes a todo with a specified ID and owner, if it exists
-func (t *TodoManagerServer) DeleteTodo(ctx context.Context, grpcTodo *todomgrpb.TodoIdReq) (*todomgrpb.DeleteTodoRes, error) {
+// PatchTodo updates only the fields set in the patch of a todo with a specified ID, if it exists and the
+// user sent in request can edit it; if the expected version of the patch is set, the todo is updated
+// only if it matches the stored version
+func (t *TodoManagerServer) PatchTodo(ctx context.Context, patch *todomgrpb.TodoPatch) (*todomgrpb.Todo, error) {
+	if err := validateRecurrence(patch.Recurrence); err != nil {
+		return nil, err
+	}
+	found := TodoEntry{}
+	_, span := trace.StartSpan(ctx, "db-patch-get")
+	preloadAssociations(t.db).First(&found, patch.GetId())
+	span.End()
+	if err := checkAccess(&found, patch.GetOwner(), todomgrpb.Permission_EDIT); err != nil {
+		return nil, err
+	}
+	if patch.ExpectedVersion != 0 && patch.ExpectedVersion != found.Version {
+		return nil, versionMismatch(ctx, found.Version)
+	}
+
+	wasDone := found.Done
+	updates := map[string]interface{}{}
+	if patch.Text != nil {
+		found.Text = patch.Text.Value
+		updates["text"] = found.Text
+	}
+	if patch.Done != nil {
+		found.Done = patch.Done.Value
+		updates["done"] = found.Done
+	}
+	if patch.ClearDueDate {
+		found.DueDate = nil
+		updates["due_date"] = gorm.Expr("NULL")
+	} else if patch.DueDate != nil {
+		found.DueDate = timeFromGrpc(patch.DueDate)
+		updates["due_date"] = found.DueDate
+	}
+	if patch.Priority != todomgrpb.Priority_PRIORITY_UNSPECIFIED {
+		found.Priority = int32(patch.Priority)
+		updates["priority"] = found.Priority
+	}
+	if patch.Archived != nil {
+		found.Archived = patch.Archived.Value
+		updates["archived"] = found.Archived
+	}
+	if patch.ClearRecurrence || patch.Recurrence != nil {
+		found.setRecurrence(patch.Recurrence)
+		if patch.ClearRecurrence {
+			found.setRecurrence(nil)
+		}
+		updates["recurrence_frequency"] = found.RecurrenceFrequency
+		updates["recurrence_interval"] = found.RecurrenceInterval
+	}
+	if len(updates) == 0 && patch.Tags == nil {
+		return found.ToGrpc(), nil
+	}
+	_, span = trace.StartSpan(ctx, "db-patch-save")
+	err := t.saveVersioned(ctx, &found, updates)
+	if err == nil && patch.Tags != nil {
+		err = t.replaceTags(&found, patch.Tags.Tags)
+	}
+	span.End()
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, errors.New("Error updating record in DB")
+	}
+
+	res := found.ToGrpc()
+	t.events.publish(todomgrpb.TodoEvent_UPDATED, res)
+	t.spawnNextOccurrence(ctx, &found, wasDone)
+	return res, nil
+}
+
+// saveVersioned stores the updates of a todo and increments its version, only if the stored version
+// didn't change since the todo was loaded
+func (t *TodoManagerServer) saveVersioned(ctx context.Context, todo *TodoEntry, updates map[string]interface{}) error {
+	return saveVersionedIn(ctx, t.db, todo, updates)
+}
+
+// saveVersionedIn is saveVersioned running the queries in db, like a transaction
+func saveVersionedIn(ctx context.Context, db *gorm.DB, todo *TodoEntry, updates map[string]interface{}) error {
+	now := gorm.NowFunc()
+	updates["version"] = todo.Version + 1
+	updates["updated_at"] = now
+	res := db.Model(&TodoEntry{}).Where("id = ? AND version = ?", todo.ID, todo.Version).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		current := TodoEntry{}
+		if err := db.Select("version").First(&current, todo.ID).Error; err != nil {
+			return err
+		}
+		return versionMismatch(ctx, current.Version)
+	}
+	todo.Version++
+	todo.UpdatedAt = now
+	return nil
+}
+
+// versionMismatch returns the error of an update rejected because of a version mismatch and sets
+// the current version in the trailer metadata
+func versionMismatch(ctx context.Context, current uint64) error {
+	grpc.SetTrailer(ctx, metadata.Pairs(VersionMetadataKey, strconv.FormatUint(current, 10)))
+	return status.Errorf(codes.FailedPrecondition, "Todo version mismatch, current version is %d", current)
+}
+
+// replaceTags replaces all the tags of a todo stored in DB with the given ones
+func (t *TodoManagerServer) replaceTags(todo *TodoEntry, names []string) error {
+	if err := t.db.Where("todo_entry_id = ?", todo.ID).Delete(&TodoTag{}).Error; err != nil {
+		return err
+	}
+	todo.Tags = tagsFromGrpc(names)
+	for i := range todo.Tags {
+		todo.Tags[i].TodoEntryID = todo.ID
+		if err := t.db.Create(&todo.Tags[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceSubtasks replaces all the subtasks of a todo stored in DB with the given ones
+func (t *TodoManagerServer) replaceSubtasks(todo *TodoEntry, subtasks []*todomgrpb.Subtask) error {
+	if err := t.db.Where("todo_entry_id = ?", todo.ID).Delete(&TodoSubtask{}).Error; err != nil {
+		return err
+	}
+	todo.Subtasks = subtasksFromGrpc(subtasks)
+	for i := range todo.Subtasks {
+		todo.Subtasks[i].TodoEntryID = todo.ID
+		if err := t.db.Create(&todo.Subtasks[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteTodo moves a todo with a specified ID and owner to the trash, if it exists; hard deletes
+// remove the todo with its tags, subtasks, comments and shares permanently, also if it's already in the trash
+func (t *TodoManagerServer) DeleteTodo(ctx context.Context, req *todomgrpb.DeleteTodoReq) (*todomgrpb.DeleteTodoRes, error) {
+	db := t.db
+	if req.GetHard() {
+		db = db.Unscoped()
+	}
 	found := TodoEntry{}
 	_, span := trace.StartSpan(ctx, "db-update-get")
-	t.db.First(&found, grpcTodo.GetId())
+	db.First(&found, req.GetId())
 	span.End()
-	if found.ID == 0 || found.Owner != grpcTodo.GetOwner() {
-		return nil, errors.New("Todo not found")
+	if found.ID == 0 || found.Owner != req.GetOwner() {
+		return nil, status.Error(codes.NotFound, "Todo not found")
 	}
 
 	_, span = trace.StartSpan(ctx, "db-update-delete")
-	t.db.Delete(&found)
+	var err error
+	if req.GetHard() {
+		err = t.db.Where("todo_entry_id = ?", found.ID).Delete(&TodoTag{}).Error
+		if err == nil {
+			err = t.db.Where("todo_entry_id = ?", found.ID).Delete(&TodoSubtask{}).Error
+		}
+		if err == nil {
+			err = t.db.Where("todo_entry_id = ?", found.ID).Delete(&TodoComment{}).Error
+		}
+		if err == nil {
+			err = t.db.Where("todo_entry_id = ?", found.ID).Delete(&TodoShare{}).Error
+		}
+	}
+	if err == nil {
+		err = db.Delete(&found).Error
+	}
 	span.End()
+	if err != nil {
+		return nil, errors.New("Error deleting record in DB")
+	}
 
+	t.events.publish(todomgrpb.TodoEvent_DELETED, found.ToGrpc())
 	return &todomgrpb.DeleteTodoRes{Success: true}, nil
 }
+
+// RestoreTodo moves a todo with a specified ID and owner out of the trash, if it's there
+func (t *TodoManagerServer) RestoreTodo(ctx context.Context, req *todomgrpb.TodoIdReq) (*todomgrpb.Todo, error) {
+	found := TodoEntry{}
+	_, span := trace.StartSpan(ctx, "db-restore-get")
+	preloadAssociations(t.db.Unscoped()).First(&found, req.GetId())
+	span.End()
+	if found.ID == 0 || found.Owner != req.GetOwner() || found.DeletedAt == nil {
+		return nil, status.Error(codes.NotFound, "Todo not found in trash")
+	}
+
+	now := gorm.NowFunc()
+	_, span = trace.StartSpan(ctx, "db-restore-save")
+	// restored todos count again in the quota of the owner
+	tx := t.db.Begin()
+	err := t.checkQuota(tx, found.Owner, 1)
+	if err == nil {
+		err = tx.Unscoped().Model(&TodoEntry{}).Where("id = ?", found.ID).Updates(map[string]interface{}{
+			"deleted_at": gorm.Expr("NULL"),
+			"version":    found.Version + 1,
+			"updated_at": now,
+		}).Error
+	}
+	if err == nil {
+		err = tx.Commit().Error
+	} else {
+		tx.Rollback()
+	}
+	span.End()
+	if _, ok := status.FromError(err); ok && err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.New("Error updating record in DB")
+	}
+	found.DeletedAt = nil
+	found.Version++
+	found.UpdatedAt = now
+
+	res := found.ToGrpc()
+	t.events.publish(todomgrpb.TodoEvent_CREATED, res)
+	return res, nil
+}
+
+// WatchTodos streams events about changes of the todos of an owner until the client cancels the call
+func (t *TodoManagerServer) WatchTodos(req *todomgrpb.WatchTodosReq, srv todomgrpb.TodoManager_WatchTodosServer) error {
+	events, unsubscribe := t.events.subscribe(req.GetOwner())
+	defer unsubscribe()
+	// send headers right away, so clients know the subscription is active
+	if err := srv.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case event := <-events:
+			if err := srv.Send(event); err != nil {
+				return err
+			}
+		case <-srv.Context().Done():
+			return nil
+		}
+	}
+}
+
+// SetTodosDone sets the done state of all the todos owned by the user sent in request matching its
+// filter with a single update; events are published and recurring todos that got completed spawn
+// their next occurrences, like when they are updated one by one
+func (t *TodoManagerServer) SetTodosDone(ctx context.Context, req *todomgrpb.SetTodosDoneReq) (*todomgrpb.CountTodosRes, error) {
+	filter := req.GetFilter()
+	if filter == nil || filter.Owner == "" {
+		return nil, status.Error(codes.InvalidArgument, "Owner of the todos can't be empty")
+	}
+	var todos []TodoEntry
+	_, span := trace.StartSpan(ctx, "db-set-done-get")
+	err := preloadAssociations(t.filterQuery(filter)).Where("owner = ? AND done <> ?", filter.Owner, req.Done).Find(&todos).Error
+	span.End()
+	if err != nil {
+		return nil, errors.New("Error reading records from DB")
+	}
+	if len(todos) == 0 {
+		return &todomgrpb.CountTodosRes{}, nil
+	}
+	ids := make([]uint, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+
+	now := gorm.NowFunc()
+	_, span = trace.StartSpan(ctx, "db-set-done-save")
+	// todos changed to the same state in the meantime are skipped
+	res := t.db.Unscoped().Model(&TodoEntry{}).Where("id IN (?) AND done <> ?", ids, req.Done).Updates(map[string]interface{}{
+		"done":       req.Done,
+		"version":    gorm.Expr("version + 1"),
+		"updated_at": now,
+	})
+	span.End()
+	if res.Error != nil {
+		return nil, errors.New("Error updating records in DB")
+	}
+
+	for i := range todos {
+		todo := &todos[i]
+		todo.Done = req.Done
+		todo.Version++
+		todo.UpdatedAt = now
+		t.events.publish(todomgrpb.TodoEvent_UPDATED, todo.ToGrpc())
+		t.spawnNextOccurrence(ctx, todo, !req.Done)
+	}
+	return &todomgrpb.CountTodosRes{Count: uint64(res.RowsAffected)}, nil
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkAccess returns a NotFound error unless the todo is owned by user or shared with them, and a
+// PermissionDenied error if it's shared with a lower permission than required; the todo has to be
+// loaded with its shares
+func checkAccess(todo *TodoEntry, user string, required todomgrpb.Permission) error {
+	if todo.ID == 0 {
+		return status.Error(codes.NotFound, "Todo not found")
+	}
+	if todo.Owner == user {
+		return nil
+	}
+	for _, share := range todo.Shares {
+		if share.SharedWith != user {
+			continue
+		}
+		if share.Permission < int32(required) {
+			return status.Error(codes.PermissionDenied, "Todo is shared with a lower permission")
+		}
+		return nil
+	}
+	return status.Error(codes.NotFound, "Todo not found")
+}
+
+// ShareTodo shares a todo with a specified ID and owner with another user, replacing their
+// permission if it's already shared with them
+func (t *TodoManagerServer) ShareTodo(ctx context.Context, req *todomgrpb.ShareTodoReq) (*todomgrpb.Todo, error) {
+	if req.GetUser() == "" {
+		return nil, status.Error(codes.InvalidArgument, "User to share with can't be empty")
+	}
+	if req.GetUser() == req.GetOwner() {
+		return nil, status.Error(codes.InvalidArgument, "Todo can't be shared with its owner")
+	}
+	permission := req.GetPermission()
+	if permission == todomgrpb.Permission_PERMISSION_UNSPECIFIED {
+		permission = todomgrpb.Permission_VIEW
+	}
+	if _, found := todomgrpb.Permission_name[int32(permission)]; !found {
+		return nil, status.Error(codes.InvalidArgument, "Permission must be one of: VIEW, EDIT")
+	}
+
+	found := TodoEntry{}
+	_, span := trace.StartSpan(ctx, "db-share-get")
+	preloadAssociations(t.db).First(&found, req.GetId())
+	span.End()
+	if found.ID == 0 || found.Owner != req.GetOwner() {
+		return nil, status.Error(codes.NotFound, "Todo not found")
+	}
+
+	share := TodoShare{
+		TodoEntryID: found.ID,
+		SharedWith:  req.GetUser(),
+		Permission:  int32(permission),
+	}
+	_, span = trace.StartSpan(ctx, "db-share-save")
+	err := t.db.Where("todo_entry_id = ? AND shared_with = ?", found.ID, share.SharedWith).Delete(&TodoShare{}).Error
+	if err == nil {
+		err = t.db.Create(&share).Error
+	}
+	span.End()
+	if err != nil {
+		return nil, errors.New("Error updating record in DB")
+	}
+	shares := []TodoShare{share}
+	for _, s := range found.Shares {
+		if s.SharedWith != share.SharedWith {
+			shares = append(shares, s)
+		}
+	}
+	found.Shares = shares
+
+	res := found.ToGrpc()
+	t.events.publish(todomgrpb.TodoEvent_UPDATED, res)
+	return res, nil
+}
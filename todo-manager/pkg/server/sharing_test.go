@@ -0,0 +1,41 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
+)
+
+func TestCheckAccess(t *testing.T) {
+	todo := &TodoEntry{
+		Model: gorm.Model{ID: 1},
+		Owner: "alice",
+		Shares: []TodoShare{
+			{SharedWith: "bob", Permission: int32(todomgrpb.Permission_VIEW)},
+			{SharedWith: "dave", Permission: int32(todomgrpb.Permission_EDIT)},
+		},
+	}
+	for _, tc := range []struct {
+		user     string
+		required todomgrpb.Permission
+		want     codes.Code
+	}{
+		{"alice", todomgrpb.Permission_EDIT, codes.OK},
+		{"bob", todomgrpb.Permission_VIEW, codes.OK},
+		{"bob", todomgrpb.Permission_EDIT, codes.PermissionDenied},
+		{"dave", todomgrpb.Permission_VIEW, codes.OK},
+		{"dave", todomgrpb.Permission_EDIT, codes.OK},
+		{"carol", todomgrpb.Permission_VIEW, codes.NotFound},
+	} {
+		if code := status.Code(checkAccess(todo, tc.user, tc.required)); code != tc.want {
+			t.Errorf("expected %s for %s requiring %s, got %s", tc.want, tc.user, tc.required, code)
+		}
+	}
+	if code := status.Code(checkAccess(&TodoEntry{}, "alice", todomgrpb.Permission_VIEW)); code != codes.NotFound {
+		t.Errorf("expected todos that weren't found to be reported as NotFound, got %s", code)
+	}
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	todomgrpb "github.com/giantswarm/giantswarm-todo-app/todo-manager/pkg/proto"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddSubtask appends a subtask to a todo with a specified ID and owner, if it exists
+func (t *TodoManagerServer) AddSubtask(ctx context.Context, req *todomgrpb.AddSubtaskReq) (*todomgrpb.Todo, error) {
+	if req.GetSubtask().GetText() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Subtask text can't be empty")
+	}
+	found, err := t.findSubtaskTodo(ctx, req.GetId(), req.GetOwner(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	subtask := TodoSubtask{
+		TodoEntryID: found.ID,
+		Text:        req.GetSubtask().GetText(),
+		Done:        req.GetSubtask().GetDone(),
+	}
+	_, span := trace.StartSpan(ctx, "db-subtask-add")
+	err = t.saveVersioned(ctx, found, map[string]interface{}{})
+	if err == nil {
+		err = t.db.Create(&subtask).Error
+	}
+	span.End()
+	if err != nil {
+		return nil, subtaskSaveError(err)
+	}
+	found.Subtasks = append(found.Subtasks, subtask)
+
+	res := found.ToGrpc()
+	t.events.publish(todomgrpb.TodoEvent_UPDATED, res)
+	return res, nil
+}
+
+// UpdateSubtask updates the fields set in the request of the subtask at index of a todo with a specified
+// ID and owner; if requested, the todo is marked done when all of its subtasks are done
+func (t *TodoManagerServer) UpdateSubtask(ctx context.Context, req *todomgrpb.UpdateSubtaskReq) (*todomgrpb.Todo, error) {
+	if req.Text != nil && req.Text.Value == "" {
+		return nil, status.Error(codes.InvalidArgument, "Subtask text can't be empty")
+	}
+	found, err := t.findSubtaskTodo(ctx, req.GetId(), req.GetOwner(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+	if int(req.GetIndex()) >= len(found.Subtasks) {
+		return nil, status.Error(codes.NotFound, "Subtask not found")
+	}
+
+	subtask := &found.Subtasks[req.GetIndex()]
+	subtaskUpdates := map[string]interface{}{}
+	if req.Text != nil {
+		subtask.Text = req.Text.Value
+		subtaskUpdates["text"] = subtask.Text
+	}
+	if req.Done != nil {
+		subtask.Done = req.Done.Value
+		subtaskUpdates["done"] = subtask.Done
+	}
+	wasDone := found.Done
+	updates := map[string]interface{}{}
+	if req.CompleteParent && !found.Done && allSubtasksDone(found.Subtasks) {
+		found.Done = true
+		updates["done"] = true
+	}
+	_, span := trace.StartSpan(ctx, "db-subtask-update")
+	err = t.saveVersioned(ctx, found, updates)
+	if err == nil && len(subtaskUpdates) > 0 {
+		err = t.db.Model(subtask).Updates(subtaskUpdates).Error
+	}
+	span.End()
+	if err != nil {
+		return nil, subtaskSaveError(err)
+	}
+
+	res := found.ToGrpc()
+	t.events.publish(todomgrpb.TodoEvent_UPDATED, res)
+	t.spawnNextOccurrence(ctx, found, wasDone)
+	return res, nil
+}
+
+// RemoveSubtask removes the subtask at index of a todo with a specified ID and owner
+func (t *TodoManagerServer) RemoveSubtask(ctx context.Context, req *todomgrpb.SubtaskIdReq) (*todomgrpb.Todo, error) {
+	found, err := t.findSubtaskTodo(ctx, req.GetId(), req.GetOwner(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, err
+	}
+	if int(req.GetIndex()) >= len(found.Subtasks) {
+		return nil, status.Error(codes.NotFound, "Subtask not found")
+	}
+
+	subtask := found.Subtasks[req.GetIndex()]
+	_, span := trace.StartSpan(ctx, "db-subtask-remove")
+	err = t.saveVersioned(ctx, found, map[string]interface{}{})
+	if err == nil {
+		err = t.db.Delete(&subtask).Error
+	}
+	span.End()
+	if err != nil {
+		return nil, subtaskSaveError(err)
+	}
+	found.Subtasks = append(found.Subtasks[:req.GetIndex()], found.Subtasks[req.GetIndex()+1:]...)
+
+	res := found.ToGrpc()
+	t.events.publish(todomgrpb.TodoEvent_UPDATED, res)
+	return res, nil
+}
+
+// findSubtaskTodo returns the todo with a specified ID whose subtasks are changed by a user who
+// owns it or can edit it; if
+// expectedVersion is set, the todo is returned only if it matches the stored version
+func (t *TodoManagerServer) findSubtaskTodo(ctx context.Context, id uint64, owner string, expectedVersion uint64) (*TodoEntry, error) {
+	found := TodoEntry{}
+	_, span := trace.StartSpan(ctx, "db-subtask-get")
+	preloadAssociations(t.db).First(&found, id)
+	span.End()
+	if err := checkAccess(&found, owner, todomgrpb.Permission_EDIT); err != nil {
+		return nil, err
+	}
+	if expectedVersion != 0 && expectedVersion != found.Version {
+		return nil, versionMismatch(ctx, found.Version)
+	}
+	return &found, nil
+}
+
+// subtaskSaveError returns the error of a failed subtask change, keeping gRPC status errors
+func subtaskSaveError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return errors.New("Error updating record in DB")
+}
+
+// allSubtasksDone checks if there are subtasks and all of them are done
+func allSubtasksDone(subtasks []TodoSubtask) bool {
+	for _, subtask := range subtasks {
+		if !subtask.Done {
+			return false
+		}
+	}
+	return len(subtasks) > 0
+}